@@ -1,31 +1,59 @@
 package compute
 
 import (
+	"fmt"
 	"math/rand"
+	"slices"
 	"time"
 
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/properties"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/availabilityzones"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
 )
 
 //counterfeiter:generate . AvailabilityZoneProvider
 type AvailabilityZoneProvider interface {
-	GetAvailabilityZones(cloudProperties properties.CreateVM) []string
+	GetAvailabilityZones(cloudProperties properties.CreateVM, identity properties.InstanceIdentity) ([]string, error)
 }
 
 type availabilityZoneProvider struct {
+	serviceClients utils.ServiceClients
+	computeFacade  ComputeFacade
+	logger         utils.Logger
 }
 
-func NewAvailabilityZoneProvider() availabilityZoneProvider {
-	return availabilityZoneProvider{}
+func NewAvailabilityZoneProvider(
+	serviceClients utils.ServiceClients,
+	computeFacade ComputeFacade,
+	logger utils.Logger,
+) availabilityZoneProvider {
+	return availabilityZoneProvider{
+		serviceClients: serviceClients,
+		computeFacade:  computeFacade,
+		logger:         logger,
+	}
 }
 
-func (a availabilityZoneProvider) GetAvailabilityZones(cloudProperties properties.CreateVM) []string {
+func (a availabilityZoneProvider) GetAvailabilityZones(cloudProperties properties.CreateVM, identity properties.InstanceIdentity) ([]string, error) {
 
 	if len(cloudProperties.AvailabilityZones) > 0 {
-		return a.shuffleAZs(cloudProperties)
+		zones, err := a.availableAZs(a.shuffleAZs(cloudProperties))
+		if err != nil {
+			return nil, err
+		}
+
+		if cloudProperties.GetAvailabilityZonesStrategy() == properties.AvailabilityZonesStrategyBalanced {
+			zones, err = a.balanceAZs(zones, identity)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return a.preferDiskAZ(zones, cloudProperties.DiskAvailabilityZone), nil
 	}
 
-	return []string{cloudProperties.AvailabilityZone}
+	return []string{cloudProperties.AvailabilityZone}, nil
 }
 
 func (a availabilityZoneProvider) shuffleAZs(cloudProperties properties.CreateVM) []string {
@@ -37,3 +65,91 @@ func (a availabilityZoneProvider) shuffleAZs(cloudProperties properties.CreateVM
 	})
 	return shuffledZones
 }
+
+// availableAZs removes the availability zones which Nova reports as unavailable. All zones are kept if the
+// availability zones cannot be listed, e.g. because the policy of the cloud does not permit it.
+func (a availabilityZoneProvider) availableAZs(zones []string) ([]string, error) {
+	page, err := a.computeFacade.ListAvailabilityZones(a.serviceClients.RetryableServiceClient)
+	if err != nil {
+		a.logger.Warn("availability_zone_provider", fmt.Sprintf("failed to list availability zones, using all configured availability zones: %v", err))
+		return zones, nil
+	}
+
+	allZones, err := a.computeFacade.ExtractAvailabilityZones(page)
+	if err != nil {
+		a.logger.Warn("availability_zone_provider", fmt.Sprintf("failed to extract availability zones, using all configured availability zones: %v", err))
+		return zones, nil
+	}
+
+	var availableZones []string
+	for _, zone := range zones {
+		zoneIndex := slices.IndexFunc(allZones, func(availabilityZone availabilityzones.AvailabilityZone) bool {
+			return availabilityZone.ZoneName == zone
+		})
+
+		if zoneIndex >= 0 && !allZones[zoneIndex].ZoneState.Available {
+			a.logger.Warn("availability_zone_provider", fmt.Sprintf("skipping unavailable availability zone '%s'", zone))
+			continue
+		}
+		availableZones = append(availableZones, zone)
+	}
+
+	if len(availableZones) == 0 {
+		return nil, fmt.Errorf("none of the availability zones %v is available", zones)
+	}
+
+	return availableZones, nil
+}
+
+// balanceAZs orders the availability zones by the number of servers of the same BOSH instance group,
+// so that the least populated zone is tried first. Zones with the same number keep their random order.
+func (a availabilityZoneProvider) balanceAZs(zones []string, identity properties.InstanceIdentity) ([]string, error) {
+	if identity.Deployment == "" || identity.InstanceGroup == "" {
+		a.logger.Info("availability_zone_provider", "no instance group known, using a random availability zone order")
+		return zones, nil
+	}
+
+	page, err := a.computeFacade.ListServers(a.serviceClients.RetryableServiceClient, servers.ListOpts{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list servers: %w", err)
+	}
+
+	allServers, err := a.computeFacade.ExtractServersWithAZ(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract servers: %w", err)
+	}
+
+	serversPerZone := map[string]int{}
+	for _, server := range allServers {
+		if a.isInInstanceGroup(server.Metadata, identity) {
+			serversPerZone[server.AvailabilityZone]++
+		}
+	}
+
+	balancedZones := slices.Clone(zones)
+	slices.SortStableFunc(balancedZones, func(zone1, zone2 string) int {
+		return serversPerZone[zone1] - serversPerZone[zone2]
+	})
+	a.logger.Info("availability_zone_provider", fmt.Sprintf("servers of instance group '%s' per availability zone: %v", identity.InstanceGroup, serversPerZone))
+
+	return balancedZones, nil
+}
+
+func (a availabilityZoneProvider) isInInstanceGroup(metadata map[string]string, identity properties.InstanceIdentity) bool {
+	if identity.Director != "" && metadata["director"] != "" && metadata["director"] != identity.Director {
+		return false
+	}
+
+	return metadata["deployment"] == identity.Deployment && metadata["instance_group"] == identity.InstanceGroup
+}
+
+// preferDiskAZ moves the availability zone of the persistent disks to the front, so that the
+// disks can be attached without crossing availability zones if the VM fits into it.
+func (a availabilityZoneProvider) preferDiskAZ(zones []string, diskZone string) []string {
+	index := slices.Index(zones, diskZone)
+	if diskZone == "" || index < 0 {
+		return zones
+	}
+
+	return append([]string{diskZone}, slices.Delete(slices.Clone(zones), index, index+1)...)
+}
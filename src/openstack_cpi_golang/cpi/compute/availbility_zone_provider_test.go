@@ -1,13 +1,39 @@
 package compute_test
 
 import (
+	"errors"
+
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/compute"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/compute/computefakes"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/properties"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils/utilsfakes"
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/availabilityzones"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
 
 var _ = Describe("AvailabilityZoneProvider", func() {
+	var serviceClients utils.ServiceClients
+	var computeFacade computefakes.FakeComputeFacade
+	var logger utilsfakes.FakeLogger
+	var identity properties.InstanceIdentity
+
+	BeforeEach(func() {
+		serviceClients = utils.ServiceClients{ServiceClient: &gophercloud.ServiceClient{}, RetryableServiceClient: &gophercloud.ServiceClient{}}
+		computeFacade = computefakes.FakeComputeFacade{}
+		logger = utilsfakes.FakeLogger{}
+		identity = properties.InstanceIdentity{Director: "the-director", Deployment: "the-deployment", InstanceGroup: "the-instance-group"}
+
+		computeFacade.ExtractAvailabilityZonesReturns([]availabilityzones.AvailabilityZone{
+			{ZoneName: "z1", ZoneState: availabilityzones.ZoneState{Available: true}},
+			{ZoneName: "z2", ZoneState: availabilityzones.ZoneState{Available: true}},
+			{ZoneName: "z3", ZoneState: availabilityzones.ZoneState{Available: true}},
+		}, nil)
+	})
+
 	Context("GetAvailabilityZones", func() {
 
 		var cloudProps properties.CreateVM
@@ -21,21 +47,173 @@ var _ = Describe("AvailabilityZoneProvider", func() {
 
 			It("returns a single availability zone", func() {
 				cloudProps.AvailabilityZones = []string{"z1"}
-				zone := compute.NewAvailabilityZoneProvider().
-					GetAvailabilityZones(cloudProps)
+				zone, err := compute.NewAvailabilityZoneProvider(serviceClients, &computeFacade, &logger).
+					GetAvailabilityZones(cloudProps, identity)
 
+				Expect(err).ToNot(HaveOccurred())
 				Expect(len(zone)).To(Equal(1))
 				Expect(zone).To(ContainElement("z1"))
 			})
 
 			It("returns a single availability zone", func() {
 				cloudProps.AvailabilityZones = []string{"z1", "z2", "z3"}
-				zone := compute.NewAvailabilityZoneProvider().
-					GetAvailabilityZones(cloudProps)
+				zone, err := compute.NewAvailabilityZoneProvider(serviceClients, &computeFacade, &logger).
+					GetAvailabilityZones(cloudProps, identity)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(zone)).To(Equal(3))
+				Expect(zone).To(ContainElements("z1", "z2", "z3"))
+				Expect(computeFacade.ListServersCallCount()).To(Equal(0))
+			})
+
+			It("returns the availability zone of the persistent disks first", func() {
+				cloudProps.AvailabilityZones = []string{"z1", "z2", "z3"}
+				cloudProps.DiskAvailabilityZone = "z2"
+				zone, err := compute.NewAvailabilityZoneProvider(serviceClients, &computeFacade, &logger).
+					GetAvailabilityZones(cloudProps, identity)
 
+				Expect(err).ToNot(HaveOccurred())
 				Expect(len(zone)).To(Equal(3))
+				Expect(zone[0]).To(Equal("z2"))
 				Expect(zone).To(ContainElements("z1", "z2", "z3"))
 			})
+
+			It("ignores the availability zone of the persistent disks if it is not configured", func() {
+				cloudProps.AvailabilityZones = []string{"z1", "z2"}
+				cloudProps.DiskAvailabilityZone = "z3"
+				zone, err := compute.NewAvailabilityZoneProvider(serviceClients, &computeFacade, &logger).
+					GetAvailabilityZones(cloudProps, identity)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(zone).To(ConsistOf("z1", "z2"))
+			})
+
+			It("skips availability zones reported as unavailable", func() {
+				computeFacade.ExtractAvailabilityZonesReturns([]availabilityzones.AvailabilityZone{
+					{ZoneName: "z1", ZoneState: availabilityzones.ZoneState{Available: true}},
+					{ZoneName: "z2", ZoneState: availabilityzones.ZoneState{Available: false}},
+				}, nil)
+				cloudProps.AvailabilityZones = []string{"z1", "z2", "z3"}
+
+				zone, err := compute.NewAvailabilityZoneProvider(serviceClients, &computeFacade, &logger).
+					GetAvailabilityZones(cloudProps, identity)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(zone).To(ConsistOf("z1", "z3"))
+			})
+
+			It("returns an error if no availability zone is available", func() {
+				computeFacade.ExtractAvailabilityZonesReturns([]availabilityzones.AvailabilityZone{
+					{ZoneName: "z1", ZoneState: availabilityzones.ZoneState{Available: false}},
+				}, nil)
+				cloudProps.AvailabilityZones = []string{"z1"}
+
+				_, err := compute.NewAvailabilityZoneProvider(serviceClients, &computeFacade, &logger).
+					GetAvailabilityZones(cloudProps, identity)
+
+				Expect(err.Error()).To(Equal("none of the availability zones [z1] is available"))
+			})
+
+			It("uses all configured availability zones if the availability zones cannot be listed", func() {
+				computeFacade.ListAvailabilityZonesReturns(nil, errors.New("boom"))
+				cloudProps.AvailabilityZones = []string{"z1", "z2"}
+
+				zones, err := compute.NewAvailabilityZoneProvider(serviceClients, &computeFacade, &logger).
+					GetAvailabilityZones(cloudProps, identity)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(zones).To(ConsistOf("z1", "z2"))
+				_, message, _ := logger.WarnArgsForCall(0)
+				Expect(message).To(Equal("failed to list availability zones, using all configured availability zones: boom"))
+			})
+
+			It("uses all configured availability zones if the availability zones cannot be extracted", func() {
+				computeFacade.ExtractAvailabilityZonesReturns(nil, errors.New("boom"))
+				cloudProps.AvailabilityZones = []string{"z1", "z2"}
+
+				zones, err := compute.NewAvailabilityZoneProvider(serviceClients, &computeFacade, &logger).
+					GetAvailabilityZones(cloudProps, identity)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(zones).To(ConsistOf("z1", "z2"))
+				_, message, _ := logger.WarnArgsForCall(0)
+				Expect(message).To(Equal("failed to extract availability zones, using all configured availability zones: boom"))
+			})
+
+			Context("with the balanced strategy", func() {
+
+				BeforeEach(func() {
+					cloudProps.AvailabilityZones = []string{"z1", "z2", "z3"}
+					cloudProps.AvailabilityZonesStrategy = properties.AvailabilityZonesStrategyBalanced
+
+					groupMetadata := map[string]string{"director": "the-director", "deployment": "the-deployment", "instance_group": "the-instance-group"}
+					computeFacade.ExtractServersWithAZReturns([]compute.ServerWithAZ{
+						serverWithAZ("z1", groupMetadata),
+						serverWithAZ("z1", groupMetadata),
+						serverWithAZ("z3", groupMetadata),
+						serverWithAZ("z2", map[string]string{"deployment": "the-deployment", "instance_group": "other-instance-group"}),
+						serverWithAZ("z2", map[string]string{"deployment": "other-deployment", "instance_group": "the-instance-group"}),
+						serverWithAZ("z2", map[string]string{"director": "other-director", "deployment": "the-deployment", "instance_group": "the-instance-group"}),
+					}, nil)
+				})
+
+				It("orders the availability zones by the number of servers of the instance group", func() {
+					zone, err := compute.NewAvailabilityZoneProvider(serviceClients, &computeFacade, &logger).
+						GetAvailabilityZones(cloudProps, identity)
+
+					Expect(err).ToNot(HaveOccurred())
+					Expect(zone).To(Equal([]string{"z2", "z3", "z1"}))
+				})
+
+				It("skips availability zones reported as unavailable", func() {
+					computeFacade.ExtractAvailabilityZonesReturns([]availabilityzones.AvailabilityZone{
+						{ZoneName: "z2", ZoneState: availabilityzones.ZoneState{Available: false}},
+					}, nil)
+
+					zone, err := compute.NewAvailabilityZoneProvider(serviceClients, &computeFacade, &logger).
+						GetAvailabilityZones(cloudProps, identity)
+
+					Expect(err).ToNot(HaveOccurred())
+					Expect(zone).To(Equal([]string{"z3", "z1"}))
+				})
+
+				It("returns the availability zone of the persistent disks first", func() {
+					cloudProps.DiskAvailabilityZone = "z1"
+
+					zone, err := compute.NewAvailabilityZoneProvider(serviceClients, &computeFacade, &logger).
+						GetAvailabilityZones(cloudProps, identity)
+
+					Expect(err).ToNot(HaveOccurred())
+					Expect(zone).To(Equal([]string{"z1", "z2", "z3"}))
+				})
+
+				It("does not list servers without a known instance group", func() {
+					zone, err := compute.NewAvailabilityZoneProvider(serviceClients, &computeFacade, &logger).
+						GetAvailabilityZones(cloudProps, properties.InstanceIdentity{})
+
+					Expect(err).ToNot(HaveOccurred())
+					Expect(zone).To(ConsistOf("z1", "z2", "z3"))
+					Expect(computeFacade.ListServersCallCount()).To(Equal(0))
+				})
+
+				It("returns an error if the servers cannot be listed", func() {
+					computeFacade.ListServersReturns(nil, errors.New("boom"))
+
+					_, err := compute.NewAvailabilityZoneProvider(serviceClients, &computeFacade, &logger).
+						GetAvailabilityZones(cloudProps, identity)
+
+					Expect(err.Error()).To(Equal("failed to list servers: boom"))
+				})
+
+				It("returns an error if the servers cannot be extracted", func() {
+					computeFacade.ExtractServersWithAZReturns(nil, errors.New("boom"))
+
+					_, err := compute.NewAvailabilityZoneProvider(serviceClients, &computeFacade, &logger).
+						GetAvailabilityZones(cloudProps, identity)
+
+					Expect(err.Error()).To(Equal("failed to extract servers: boom"))
+				})
+			})
 		})
 
 		Context("with cloud property availability_zone", func() {
@@ -47,12 +225,21 @@ var _ = Describe("AvailabilityZoneProvider", func() {
 
 			It("returns a single availability zone", func() {
 				cloudProps.AvailabilityZone = "z1"
-				zone := compute.NewAvailabilityZoneProvider().
-					GetAvailabilityZones(cloudProps)
+				zone, err := compute.NewAvailabilityZoneProvider(serviceClients, &computeFacade, &logger).
+					GetAvailabilityZones(cloudProps, identity)
 
+				Expect(err).ToNot(HaveOccurred())
 				Expect(len(zone)).To(Equal(1))
 				Expect(zone).To(ContainElement("z1"))
+				Expect(computeFacade.ListAvailabilityZonesCallCount()).To(Equal(0))
 			})
 		})
 	})
 })
+
+func serverWithAZ(availabilityZone string, metadata map[string]string) compute.ServerWithAZ {
+	return compute.ServerWithAZ{
+		Server:                    servers.Server{Metadata: metadata},
+		ServerAvailabilityZoneExt: availabilityzones.ServerAvailabilityZoneExt{AvailabilityZone: availabilityZone},
+	}
+}
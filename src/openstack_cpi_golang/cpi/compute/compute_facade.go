@@ -28,6 +28,14 @@ type ComputeFacade interface {
 
 	GetServerWithAZ(client utils.RetryableServiceClient, serverID string) (*ServerWithAZ, error)
 
+	ListServers(client utils.RetryableServiceClient, opts servers.ListOptsBuilder) (pagination.Page, error)
+
+	ExtractServersWithAZ(page pagination.Page) ([]ServerWithAZ, error)
+
+	ListAvailabilityZones(client utils.RetryableServiceClient) (pagination.Page, error)
+
+	ExtractAvailabilityZones(page pagination.Page) ([]availabilityzones.AvailabilityZone, error)
+
 	ListFlavors(client utils.RetryableServiceClient, opts flavors.ListOpts) (pagination.Page, error)
 
 	ExtractFlavors(page pagination.Page) ([]flavors.Flavor, error)
@@ -78,6 +86,24 @@ func (c computeFacade) GetServerWithAZ(client utils.RetryableServiceClient, serv
 	return &serverWithAz, err
 }
 
+func (c computeFacade) ListServers(client utils.RetryableServiceClient, opts servers.ListOptsBuilder) (pagination.Page, error) {
+	return servers.List(client, opts).AllPages()
+}
+
+func (c computeFacade) ExtractServersWithAZ(page pagination.Page) ([]ServerWithAZ, error) {
+	var serversWithAz []ServerWithAZ
+	err := servers.ExtractServersInto(page, &serversWithAz)
+	return serversWithAz, err
+}
+
+func (c computeFacade) ListAvailabilityZones(client utils.RetryableServiceClient) (pagination.Page, error) {
+	return availabilityzones.List(client).AllPages()
+}
+
+func (c computeFacade) ExtractAvailabilityZones(page pagination.Page) ([]availabilityzones.AvailabilityZone, error) {
+	return availabilityzones.ExtractAvailabilityZones(page)
+}
+
 func (c computeFacade) ListFlavors(client utils.RetryableServiceClient, opts flavors.ListOpts) (pagination.Page, error) {
 	return flavors.ListDetail(client, opts).AllPages()
 }
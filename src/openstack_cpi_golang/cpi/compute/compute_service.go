@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/cloudfoundry/bosh-cpi-go/apiv1"
@@ -33,6 +34,7 @@ type ComputeService interface {
 		networkConfig properties.NetworkConfig,
 		agentID apiv1.AgentID,
 		env apiv1.VMEnv,
+		diskBus properties.DiskBus,
 		cpiConfig config.CpiConfig,
 	) (*servers.Server, error)
 
@@ -125,8 +127,13 @@ func NewComputeService(
 func (c computeService) GetServer(
 	serverID string,
 ) (*servers.Server, error) {
+	var errDefault404 gophercloud.ErrDefault404
+
 	server, err := c.computeFacade.GetServer(c.serviceClients.RetryableServiceClient, serverID)
 	if err != nil {
+		if errors.As(err, &errDefault404) {
+			return nil, utils.NewVMNotFoundError(fmt.Errorf("failed to retrieve server information: %w", err))
+		}
 		return nil, fmt.Errorf("failed to retrieve server information: %w", err)
 	}
 	return server, nil
@@ -149,6 +156,7 @@ func (c computeService) CreateServer(
 	networkConfig properties.NetworkConfig,
 	agentID apiv1.AgentID,
 	env apiv1.VMEnv,
+	diskBus properties.DiskBus,
 	cpiConfig config.CpiConfig,
 ) (*servers.Server, error) {
 	openstackConfig := cpiConfig.Cloud.Properties.Openstack
@@ -163,14 +171,9 @@ func (c computeService) CreateServer(
 		return nil, fmt.Errorf("failed to resolve keypair: %w", err)
 	}
 
-	blockDevices, err := c.volumeConfigurator.ConfigureVolumes(stemcellCID.AsString(), openstackConfig, cloudProps, flavor)
-	if err != nil {
-		return nil, fmt.Errorf("failed to configure volumes: %w", err)
-	}
-
 	vmName := c.getVMName()
 
-	userData, err := c.createServerUserData(networkConfig, cpiConfig, vmName, flavor, agentID, env)
+	userData, err := c.createServerUserData(networkConfig, cpiConfig, cloudProps, vmName, flavor, agentID, env, diskBus)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create user data: %w", err)
 	}
@@ -180,38 +183,59 @@ func (c computeService) CreateServer(
 		return nil, fmt.Errorf("failed to marshal user data: %w", err)
 	}
 
-	var server *servers.Server
-	availabilityZones := c.availabilityZoneProvider.GetAvailabilityZones(cloudProps)
+	boshEnv, err := properties.NewBoshEnv(env)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse vm environment: %w", err)
+	}
 
-	for _, availabilityZone := range availabilityZones {
-		createOpts := c.getServerCreateOpts(vmName, availabilityZone, stemcellCID, networkConfig, flavor, keyname, blockDevices, userDataJson)
+	availabilityZones, err := c.availabilityZoneProvider.GetAvailabilityZones(cloudProps, properties.NewInstanceIdentity(boshEnv, agentID.AsString()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get availability zones: %w", err)
+	}
 
-		server, err = c.computeFacade.CreateServer(c.serviceClients.ServiceClient, createOpts)
+	timeout := time.Duration(openstackConfig.StateTimeOut) * time.Second
+
+	var attemptedZones []string
+	var attemptErrors []error
+	var retryable bool
+	for _, availabilityZone := range availabilityZones {
+		blockDevices, err := c.volumeConfigurator.ConfigureVolumes(stemcellCID.AsString(), availabilityZone, openstackConfig, cloudProps, flavor)
 		if err != nil {
-			if availabilityZone == availabilityZones[len(availabilityZones)-1] {
-				return nil, fmt.Errorf("failed to create server in availability zone '%s': %w", availabilityZone, err)
-			}
-			c.logger.Warn("failed to create server in availability zone '%s': %v, "+
-				"retrying in a different availability zone", availabilityZone, err)
+			return nil, fmt.Errorf("failed to configure volumes: %w", err)
+		}
+
+		createOpts := c.getServerCreateOpts(vmName, availabilityZone, stemcellCID, networkConfig, flavor, keyname, blockDevices, userDataJson)
 
-			continue
+		server, placementFailed, err := c.createServerInAvailabilityZone(c.serverCreateClient(blockDevices), createOpts, availabilityZone, timeout)
+		if err == nil {
+			return server, nil
 		}
 
-		server, err = c.waitForServerToBecomeActive(server.ID, time.Duration(openstackConfig.StateTimeOut)*time.Second)
-		if err != nil {
-			if availabilityZone == availabilityZones[len(availabilityZones)-1] {
-				return server, fmt.Errorf("failed while waiting on the server creation in availability zone '%s': %w", availabilityZone, err)
+		attemptedZones = append(attemptedZones, availabilityZone)
+		attemptErrors = append(attemptErrors, err)
+
+		if server != nil {
+			c.logger.Warn("compute_service", fmt.Sprintf("deleting server '%s' which failed in availability zone '%s'", server.ID, availabilityZone))
+
+			cleanupErr := c.deleteFailedServer(server.ID, timeout)
+			if cleanupErr != nil {
+				attemptErrors = append(attemptErrors, fmt.Errorf("failed to delete server '%s' in availability zone '%s': %w", server.ID, availabilityZone, cleanupErr))
+				return server, utils.NewVMCreationFailedError(c.availabilityZoneAttemptsError(attemptedZones, attemptErrors), false)
 			}
-			c.logger.Warn("failed while waiting on the server creation in availability zone '%s': %v, "+
-				"retrying in a different availability zone", availabilityZone, err)
+		}
 
-			continue
+		retryable = placementFailed
+		if !placementFailed {
+			c.logger.Warn("compute_service", fmt.Sprintf("server creation in availability zone '%s' failed with a non-placement error, "+
+				"not retrying in a different availability zone", availabilityZone))
+			break
 		}
 
-		break
+		c.logger.Warn("compute_service", fmt.Sprintf("server creation in availability zone '%s' failed with a placement error: %v", availabilityZone, err))
 	}
 
-	return server, nil
+	// the director may retry a server creation which failed for lack of capacity, it might be available later
+	return nil, utils.NewVMCreationFailedError(c.availabilityZoneAttemptsError(attemptedZones, attemptErrors), retryable)
 }
 
 func (c computeService) DeleteServer(
@@ -235,7 +259,7 @@ func (c computeService) DeleteServer(
 	}
 
 	timeout := time.Duration(cpiConfig.Cloud.Properties.Openstack.StateTimeOut) * time.Second
-	err = c.waitForServerToBecomeDeleted(serverID, timeout)
+	err = c.waitForServerToBecomeDeleted(serverID, timeout, false)
 	if err != nil {
 		return fmt.Errorf("failed while waiting on the server deletion: %w", err)
 	}
@@ -407,13 +431,21 @@ func (c computeService) GetMatchingFlavor(vmResources apiv1.VMResources, bootFro
 func (c computeService) createServerUserData(
 	networkConfig properties.NetworkConfig,
 	cpiConfig config.CpiConfig,
+	cloudProps properties.CreateVM,
 	vmName string,
 	flavor flavors.Flavor,
 	agentID apiv1.AgentID,
 	env apiv1.VMEnv,
+	diskBus properties.DiskBus,
 ) (properties.UserData, error) {
 	userDataNetwork := map[string]properties.UserdataNetwork{}
+	var allNetworks []properties.Network
 	for _, network := range networkConfig.AllNetworks() {
+		allNetworks = append(allNetworks, network)
+		allNetworks = append(allNetworks, network.AdditionalNetworks()...)
+	}
+
+	for _, network := range allNetworks {
 
 		userdataNetwork := properties.UserdataNetwork{
 			Default:    network.Default,
@@ -424,6 +456,8 @@ func (c computeService) createServerUserData(
 			Type:       network.Type,
 			CloudProps: network.CloudProps,
 			Mac:        network.Mac,
+			MTU:        network.MTU,
+			Routes:     network.Routes,
 		}
 
 		if network.Type != "vip" {
@@ -438,18 +472,55 @@ func (c computeService) createServerUserData(
 		return properties.UserData{}, fmt.Errorf("failed to marshal environment")
 	}
 
+	ephemeralDiskSize := flavor.Ephemeral
+	var ephemeralDiskDevice string
+	if cloudProps.EphemeralDisk != nil {
+		ephemeralDiskSize = cloudProps.EphemeralDisk.Size
+		ephemeralDiskDevice = c.getEphemeralVolumeDevice(flavor, diskBus)
+	}
+
 	return properties.NewUserDataBuilder().
 		WithServer(properties.Server{Name: vmName}).
 		WithNetworks(userDataNetwork).
 		WithVM(properties.VM{Name: vmName}).
 		WithNetworks(userDataNetwork).
-		WithEphemeralDiskSize(flavor.Ephemeral).
+		WithDiskBus(diskBus).
+		WithEphemeralDiskSize(ephemeralDiskSize).
+		WithEphemeralDiskDevice(ephemeralDiskDevice).
 		WithAgentID(agentID).
 		WithEnvironment(environment).
 		WithConfig(cpiConfig).
 		Build(), nil
 }
 
+// getEphemeralVolumeDevice returns the device of a Cinder backed ephemeral disk. Nova maps
+// the flavor's local ephemeral and swap disks before the volumes of the block device mapping.
+func (c computeService) getEphemeralVolumeDevice(flavor flavors.Flavor, diskBus properties.DiskBus) string {
+	deviceLetter := 'b'
+	if flavor.Ephemeral > 0 {
+		deviceLetter++
+	}
+	if flavor.Swap > 0 {
+		deviceLetter++
+	}
+
+	return fmt.Sprintf("%s%c", diskBus.DevicePrefix(), deviceLetter)
+}
+
+// serverCreateClient returns the client used for the server creation. Volume types in
+// the block device mapping require at least compute API microversion 2.67.
+func (c computeService) serverCreateClient(blockDevices []bootfromvolume.BlockDevice) utils.ServiceClient {
+	for _, blockDevice := range blockDevices {
+		if blockDevice.VolumeType != "" {
+			serviceClient := *c.serviceClients.ServiceClient
+			serviceClient.Microversion = "2.67"
+			return &serviceClient
+		}
+	}
+
+	return c.serviceClients.ServiceClient
+}
+
 func (c computeService) getServerCreateOpts(
 	vmName string,
 	availabilityZone string,
@@ -526,6 +597,56 @@ func (c computeService) getVMName() string {
 	return "vm-" + uuid.New().String()
 }
 
+func (c computeService) createServerInAvailabilityZone(
+	serviceClient utils.ServiceClient,
+	createOpts servers.CreateOptsBuilder,
+	availabilityZone string,
+	timeout time.Duration,
+) (*servers.Server, bool, error) {
+	server, err := c.computeFacade.CreateServer(serviceClient, createOpts)
+	if err != nil {
+		return nil, isPlacementError(err), fmt.Errorf("failed to create server in availability zone '%s': %w", availabilityZone, err)
+	}
+
+	activeServer, err := c.waitForServerToBecomeActive(server.ID, timeout)
+	if err != nil {
+		return server, isPlacementError(err), fmt.Errorf("failed while waiting on the server creation in availability zone '%s': %w", availabilityZone, err)
+	}
+
+	return activeServer, false, nil
+}
+
+func (c computeService) deleteFailedServer(serverID string, timeout time.Duration) error {
+	var errDefault404 gophercloud.ErrDefault404
+
+	err := c.computeFacade.DeleteServer(c.serviceClients.RetryableServiceClient, serverID)
+	if err != nil {
+		if errors.As(err, &errDefault404) {
+			return nil
+		}
+		return fmt.Errorf("failed to delete server: %w", err)
+	}
+
+	// a server which failed to boot usually remains in ERROR state until it is gone
+	err = c.waitForServerToBecomeDeleted(serverID, timeout, true)
+	if err != nil {
+		return fmt.Errorf("failed while waiting on the server deletion: %w", err)
+	}
+
+	c.logger.Info("compute_service", fmt.Sprintf("Deleted failed server with id '%s'", serverID))
+
+	return nil
+}
+
+func (c computeService) availabilityZoneAttemptsError(availabilityZones []string, attemptErrors []error) error {
+	if len(attemptErrors) == 1 {
+		return attemptErrors[0]
+	}
+
+	return fmt.Errorf("failed to create server in availability zones '%s': %w",
+		strings.Join(availabilityZones, "', '"), errors.Join(attemptErrors...))
+}
+
 func (c computeService) waitForServerToBecomeActive(serverID string, timeout time.Duration) (*servers.Server, error) {
 	timeoutTimer := time.NewTimer(timeout)
 
@@ -543,6 +664,9 @@ func (c computeService) waitForServerToBecomeActive(serverID string, timeout tim
 			case "ACTIVE":
 				return server, nil
 			case "ERROR":
+				if server.Fault.Message != "" {
+					return server, fmt.Errorf("server became ERROR state while waiting to become ACTIVE: %s", server.Fault.Message)
+				}
 				return server, fmt.Errorf("server became ERROR state while waiting to become ACTIVE")
 			case "DELETED":
 				return server, fmt.Errorf("server became DELETED state while waiting to become ACTIVE")
@@ -553,7 +677,7 @@ func (c computeService) waitForServerToBecomeActive(serverID string, timeout tim
 	}
 }
 
-func (c computeService) waitForServerToBecomeDeleted(serverID string, timeout time.Duration) error {
+func (c computeService) waitForServerToBecomeDeleted(serverID string, timeout time.Duration, ignoreErrorState bool) error {
 	var errDefault404 gophercloud.ErrDefault404
 	timeoutTimer := time.NewTimer(timeout)
 
@@ -576,7 +700,9 @@ func (c computeService) waitForServerToBecomeDeleted(serverID string, timeout ti
 			case "TERMINATED":
 				return nil
 			case "ERROR":
-				return fmt.Errorf("server became ERROR state while waiting to become DELETED")
+				if !ignoreErrorState {
+					return fmt.Errorf("server became ERROR state while waiting to become DELETED")
+				}
 			}
 
 			time.Sleep(ComputeServicePollingInterval)
@@ -584,6 +710,29 @@ func (c computeService) waitForServerToBecomeDeleted(serverID string, timeout ti
 	}
 }
 
+// isPlacementError reports whether a server creation failed because the scheduler could not place
+// the server in the requested availability zone. Only those failures are worth retrying in another zone,
+// everything else (e.g. a broken image or an exceeded quota) would fail in the same way there.
+func isPlacementError(err error) bool {
+	placementErrorPatterns := []string{
+		"no valid host",
+		"novalidhost",
+		"not enough hosts available",
+		"insufficient compute resources",
+		"exceeded maximum number of retries",
+		"availability zone is not available",
+	}
+
+	message := strings.ToLower(err.Error())
+	for _, pattern := range placementErrorPatterns {
+		if strings.Contains(message, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (c computeService) AttachVolume(serverID string, volumeID string, device string) (*volumeattach.VolumeAttachment, error) {
 	// see: https://github.com/gophercloud/gophercloud/blob/master/openstack/compute/v2/volumeattach/doc.go
 	opts := volumeattach.CreateOpts{
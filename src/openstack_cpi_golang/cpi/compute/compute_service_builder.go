@@ -40,7 +40,7 @@ func (b computeServiceBuilder) Build() (ComputeService, error) {
 		computeFacade,
 		NewFlavorResolver(serviceClients, computeFacade),
 		NewVolumeConfigurator(),
-		NewAvailabilityZoneProvider(),
+		NewAvailabilityZoneProvider(serviceClients, computeFacade, b.logger),
 		b.logger,
 	), nil
 }
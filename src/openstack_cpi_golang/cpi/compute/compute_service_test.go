@@ -57,7 +57,7 @@ var _ = Describe("ComputeService", func() {
 		flavorResolver.ResolveFlavorForInstanceTypeReturns(flavors.Flavor{ID: "the_flavor_id", Name: "the_instance_type", RAM: 4096, Ephemeral: 10}, nil)
 		computeFacade.GetOSKeyPairReturns(&keypairs.KeyPair{Name: "the_os_keypair_name"}, nil)
 		defaultCloudConfig = properties.CreateVM{InstanceType: "the_instance_type", RootDisk: properties.Disk{Size: 1}}
-		availabilityZoneProvider.GetAvailabilityZonesReturns([]string{"z1"})
+		availabilityZoneProvider.GetAvailabilityZonesReturns([]string{"z1"}, nil)
 		agentID = apiv1.NewAgentID("agent-id")
 		env = apiv1.VMEnv{}
 	})
@@ -71,6 +71,14 @@ var _ = Describe("ComputeService", func() {
 			Expect(err.Error()).To(Equal("failed to retrieve server information: boom"))
 		})
 
+		It("returns a VMNotFound error if the server does not exist", func() {
+			computeFacade.GetServerReturns(nil, gophercloud.ErrDefault404{})
+			_, err := computeService.GetServer("123-456")
+
+			Expect(err.(utils.CloudError).Type()).To(Equal(utils.VMNotFoundErrorType))
+			Expect(errors.As(err, &gophercloud.ErrDefault404{})).To(BeTrue())
+		})
+
 		It("returns an active server", func() {
 			computeFacade.GetServerReturns(&servers.Server{ID: "123-456", Status: "ACTIVE"}, nil)
 			server, err := computeService.GetServer("123-456")
@@ -93,6 +101,7 @@ var _ = Describe("ComputeService", func() {
 				networkConfig,
 				agentID,
 				env,
+				properties.DiskBus{},
 				createCpiConfig(10),
 			)
 
@@ -109,6 +118,7 @@ var _ = Describe("ComputeService", func() {
 				networkConfig,
 				agentID,
 				env,
+				properties.DiskBus{},
 				createCpiConfig(10),
 			)
 
@@ -128,6 +138,7 @@ var _ = Describe("ComputeService", func() {
 				networkConfig,
 				agentID,
 				env,
+				properties.DiskBus{},
 				createCpiConfig(10),
 			)
 
@@ -148,6 +159,7 @@ var _ = Describe("ComputeService", func() {
 				networkConfig,
 				agentID,
 				env,
+				properties.DiskBus{},
 				cpiConfig,
 			)
 
@@ -168,6 +180,7 @@ var _ = Describe("ComputeService", func() {
 				networkConfig,
 				agentID,
 				env,
+				properties.DiskBus{},
 				cpiConfig,
 			)
 
@@ -183,6 +196,7 @@ var _ = Describe("ComputeService", func() {
 				networkConfig,
 				agentID,
 				env,
+				properties.DiskBus{},
 				createCpiConfig(10),
 			)
 
@@ -198,6 +212,7 @@ var _ = Describe("ComputeService", func() {
 				networkConfig,
 				agentID,
 				env,
+				properties.DiskBus{},
 				createCpiConfig(10),
 			)
 
@@ -245,6 +260,7 @@ var _ = Describe("ComputeService", func() {
 					networkConfig,
 					agentID,
 					env,
+					properties.DiskBus{},
 					createCpiConfig(10),
 				)
 				Expect(err).ToNot(HaveOccurred())
@@ -290,6 +306,7 @@ var _ = Describe("ComputeService", func() {
 					networkConfig,
 					agentID,
 					env,
+					properties.DiskBus{},
 					createCpiConfig(10),
 				)
 				Expect(err).ToNot(HaveOccurred())
@@ -324,12 +341,199 @@ var _ = Describe("ComputeService", func() {
 
 				Expect(environment).To(Equal(expectedEnv))
 			})
+
+			It("advertises additional IPs of a port as networks in the user data", func() {
+				networkConfig.ManualNetworks[0].Mac = "the-mac"
+				networkConfig.ManualNetworks[0].AdditionalIPs = []properties.AdditionalIP{
+					{SubnetID: "the-ipv6-subnet-id", IP: "fd00::10", Netmask: "ffff:ffff:ffff:ffff::", Gateway: "fd00::1"},
+				}
+
+				_, err := computeService.CreateServer(
+					apiv1.NewStemcellCID("the_stemcell_id"),
+					defaultCloudConfig,
+					networkConfig,
+					agentID,
+					env,
+					properties.DiskBus{},
+					createCpiConfig(10),
+				)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, opts := computeFacade.CreateServerArgsForCall(0)
+				createMap, err := opts.ToServerCreateMap()
+				Expect(err).ToNot(HaveOccurred())
+				server := createMap["server"].(map[string]interface{})
+
+				userDataBytes, err := base64.StdEncoding.DecodeString(*server["user_data"].(*string))
+				Expect(err).ToNot(HaveOccurred())
+
+				userData := properties.UserData{}
+				_ = json.Unmarshal(userDataBytes, &userData) //nolint:errcheck
+				Expect(userData.Networks["bosh"].IP).To(Equal("1.2.3.4"))
+				Expect(userData.Networks["bosh_1"].IP).To(Equal("fd00::10"))
+				Expect(userData.Networks["bosh_1"].Netmask).To(Equal("ffff:ffff:ffff:ffff::"))
+				Expect(userData.Networks["bosh_1"].Gateway).To(Equal("fd00::1"))
+				Expect(userData.Networks["bosh_1"].Mac).To(Equal("the-mac"))
+				Expect(userData.Networks["bosh_1"].Type).To(Equal("manual"))
+			})
+
+			It("advertises the routes and mtu of a network in the user data", func() {
+				networkConfig.ManualNetworks[0].Routes = []properties.Route{{Destination: "10.0.0.0", Gateway: "1.2.3.1", Netmask: "255.0.0.0"}}
+				networkConfig.ManualNetworks[0].MTU = 1450
+
+				_, err := computeService.CreateServer(
+					apiv1.NewStemcellCID("the_stemcell_id"),
+					defaultCloudConfig,
+					networkConfig,
+					agentID,
+					env,
+					properties.DiskBus{},
+					createCpiConfig(10),
+				)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, opts := computeFacade.CreateServerArgsForCall(0)
+				createMap, err := opts.ToServerCreateMap()
+				Expect(err).ToNot(HaveOccurred())
+				server := createMap["server"].(map[string]interface{})
+
+				userDataBytes, err := base64.StdEncoding.DecodeString(*server["user_data"].(*string))
+				Expect(err).ToNot(HaveOccurred())
+
+				userData := properties.UserData{}
+				_ = json.Unmarshal(userDataBytes, &userData) //nolint:errcheck
+				Expect(userData.Networks["bosh"].Routes).To(Equal([]properties.Route{{Destination: "10.0.0.0", Gateway: "1.2.3.1", Netmask: "255.0.0.0"}}))
+				Expect(userData.Networks["bosh"].MTU).To(Equal(1450))
+			})
+		})
+
+		Context("with an ephemeral disk volume", func() {
+
+			BeforeEach(func() {
+				volumeConfigurator.ConfigureVolumesReturns([]bootfromvolume.BlockDevice{
+					{UUID: "the_stemcell_id", SourceType: bootfromvolume.SourceImage, DestinationType: bootfromvolume.DestinationLocal},
+					{SourceType: bootfromvolume.SourceBlank, DestinationType: bootfromvolume.DestinationVolume, VolumeSize: 20, VolumeType: "the_volume_type", BootIndex: -1},
+				}, nil)
+				flavorResolver.ResolveFlavorForInstanceTypeReturns(flavors.Flavor{ID: "the_flavor_id", Name: "the_instance_type", RAM: 4096, Swap: 1024}, nil)
+				defaultCloudConfig.EphemeralDisk = &properties.EphemeralDisk{Size: 20, Type: "the_volume_type"}
+			})
+
+			It("advertises the ephemeral disk volume in the user data", func() {
+				_, err := computeService.CreateServer(
+					apiv1.NewStemcellCID("the_stemcell_id"),
+					defaultCloudConfig,
+					networkConfig,
+					agentID,
+					env,
+					properties.DiskBus{},
+					createCpiConfig(10),
+				)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, opts := computeFacade.CreateServerArgsForCall(0)
+				createMap, err := opts.ToServerCreateMap()
+				Expect(err).ToNot(HaveOccurred())
+				server := createMap["server"].(map[string]interface{})
+
+				userDataBytes, err := base64.StdEncoding.DecodeString(*server["user_data"].(*string))
+				Expect(err).ToNot(HaveOccurred())
+
+				userData := properties.UserData{}
+				_ = json.Unmarshal(userDataBytes, &userData) //nolint:errcheck
+				Expect(userData.Disks.Ephemeral).To(Equal("/dev/sdc"))
+			})
+
+			It("advertises the devices of the disk bus in the user data", func() {
+				_, err := computeService.CreateServer(
+					apiv1.NewStemcellCID("the_stemcell_id"),
+					defaultCloudConfig,
+					networkConfig,
+					agentID,
+					env,
+					properties.DiskBus{Bus: "virtio"},
+					createCpiConfig(10),
+				)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, opts := computeFacade.CreateServerArgsForCall(0)
+				createMap, err := opts.ToServerCreateMap()
+				Expect(err).ToNot(HaveOccurred())
+				server := createMap["server"].(map[string]interface{})
+
+				userDataBytes, err := base64.StdEncoding.DecodeString(*server["user_data"].(*string))
+				Expect(err).ToNot(HaveOccurred())
+
+				userData := properties.UserData{}
+				_ = json.Unmarshal(userDataBytes, &userData) //nolint:errcheck
+				Expect(userData.Disks.System).To(Equal("/dev/vda"))
+				Expect(userData.Disks.Ephemeral).To(Equal("/dev/vdc"))
+			})
+
+			It("uses a compute microversion which supports volume types", func() {
+				_, err := computeService.CreateServer(
+					apiv1.NewStemcellCID("the_stemcell_id"),
+					defaultCloudConfig,
+					networkConfig,
+					agentID,
+					env,
+					properties.DiskBus{},
+					createCpiConfig(10),
+				)
+				Expect(err).ToNot(HaveOccurred())
+
+				sClient, _ := computeFacade.CreateServerArgsForCall(0)
+				Expect(sClient.Microversion).To(Equal("2.67"))
+				Expect(serviceClient.Microversion).To(BeEmpty())
+			})
+		})
+
+		It("gets the availability zones for the instance group of the server", func() {
+			env = apiv1.NewVMEnv(map[string]interface{}{
+				"bosh": map[string]interface{}{"groups": []string{"the-director", "the-deployment", "the-instance-group"}},
+			})
+
+			_, err := computeService.CreateServer(
+				apiv1.StemcellCID{},
+				defaultCloudConfig,
+				networkConfig,
+				agentID,
+				env,
+				properties.DiskBus{},
+				createCpiConfig(10),
+			)
+
+			Expect(err).ToNot(HaveOccurred())
+			cloudProps, identity := availabilityZoneProvider.GetAvailabilityZonesArgsForCall(0)
+			Expect(cloudProps).To(Equal(defaultCloudConfig))
+			Expect(identity).To(Equal(properties.InstanceIdentity{
+				Director:      "the-director",
+				Deployment:    "the-deployment",
+				InstanceGroup: "the-instance-group",
+				AgentID:       "agent-id",
+			}))
+		})
+
+		It("returns an error if the availability zones cannot be retrieved", func() {
+			availabilityZoneProvider.GetAvailabilityZonesReturns(nil, errors.New("boom"))
+
+			_, err := computeService.CreateServer(
+				apiv1.StemcellCID{},
+				defaultCloudConfig,
+				networkConfig,
+				agentID,
+				env,
+				properties.DiskBus{},
+				createCpiConfig(10),
+			)
+
+			Expect(err.Error()).To(Equal("failed to get availability zones: boom"))
+			Expect(computeFacade.CreateServerCallCount()).To(Equal(0))
 		})
 
 		It("runs server creation in multiple AZs on creation failure", func() {
-			availabilityZoneProvider.GetAvailabilityZonesReturns([]string{"z1", "z2"})
+			availabilityZoneProvider.GetAvailabilityZonesReturns([]string{"z1", "z2"}, nil)
 
-			computeFacade.CreateServerReturnsOnCall(0, nil, errors.New("boom"))
+			computeFacade.CreateServerReturnsOnCall(0, nil, errors.New("No valid host was found"))
 			computeFacade.CreateServerReturnsOnCall(1, &servers.Server{ID: "123-456"}, nil)
 
 			_, _ = computeService.CreateServer( //nolint:errcheck
@@ -338,6 +542,7 @@ var _ = Describe("ComputeService", func() {
 				networkConfig,
 				agentID,
 				env,
+				properties.DiskBus{},
 				createCpiConfig(10),
 			)
 
@@ -354,12 +559,34 @@ var _ = Describe("ComputeService", func() {
 			Expect(computeFacade.CreateServerCallCount()).To(Equal(2))
 		})
 
-		It("runs server creation in multiple AZs if waiting in server fails", func() {
-			availabilityZoneProvider.GetAvailabilityZonesReturns([]string{"z1", "z2"})
+		It("configures the volumes for each availability zone", func() {
+			availabilityZoneProvider.GetAvailabilityZonesReturns([]string{"z1", "z2"}, nil)
 
-			computeFacade.GetServerReturns(&servers.Server{ID: "123-456", Status: "not-active"}, nil)
+			computeFacade.CreateServerReturnsOnCall(0, nil, errors.New("No valid host was found"))
+			computeFacade.CreateServerReturnsOnCall(1, &servers.Server{ID: "123-456"}, nil)
+
+			_, _ = computeService.CreateServer( //nolint:errcheck
+				apiv1.StemcellCID{},
+				defaultCloudConfig,
+				networkConfig,
+				agentID,
+				env,
+				properties.DiskBus{},
+				createCpiConfig(10),
+			)
 
-			compute.ComputeServicePollingInterval = 0
+			Expect(volumeConfigurator.ConfigureVolumesCallCount()).To(Equal(2))
+			_, availabilityZone, _, _, _ := volumeConfigurator.ConfigureVolumesArgsForCall(0)
+			Expect(availabilityZone).To(Equal("z1"))
+			_, availabilityZone, _, _, _ = volumeConfigurator.ConfigureVolumesArgsForCall(1)
+			Expect(availabilityZone).To(Equal("z2"))
+		})
+
+		It("runs server creation in multiple AZs if waiting in server fails", func() {
+			availabilityZoneProvider.GetAvailabilityZonesReturns([]string{"z1", "z2"}, nil)
+
+			computeFacade.GetServerReturnsOnCall(0, &servers.Server{ID: "123-456", Status: "ERROR", Fault: servers.Fault{Message: "No valid host was found."}}, nil)
+			computeFacade.GetServerReturnsOnCall(1, nil, gophercloud.ErrDefault404{})
 
 			_, _ = computeService.CreateServer( //nolint:errcheck
 				apiv1.StemcellCID{},
@@ -367,7 +594,8 @@ var _ = Describe("ComputeService", func() {
 				networkConfig,
 				agentID,
 				env,
-				createCpiConfig(0),
+				properties.DiskBus{},
+				createCpiConfig(10),
 			)
 
 			_, opts := computeFacade.CreateServerArgsForCall(0)
@@ -383,6 +611,117 @@ var _ = Describe("ComputeService", func() {
 			Expect(computeFacade.CreateServerCallCount()).To(Equal(2))
 		})
 
+		It("deletes the server which failed in an AZ before retrying in the next AZ", func() {
+			availabilityZoneProvider.GetAvailabilityZonesReturns([]string{"z1", "z2"}, nil)
+			computeFacade.CreateServerReturnsOnCall(0, &servers.Server{ID: "failed-server-id"}, nil)
+			computeFacade.CreateServerReturnsOnCall(1, &servers.Server{ID: "123-456"}, nil)
+
+			computeFacade.GetServerReturnsOnCall(0, &servers.Server{ID: "failed-server-id", Status: "ERROR", Fault: servers.Fault{Message: "No valid host was found."}}, nil)
+			computeFacade.GetServerReturnsOnCall(1, &servers.Server{ID: "failed-server-id", Status: "ERROR"}, nil)
+			computeFacade.GetServerReturnsOnCall(2, nil, gophercloud.ErrDefault404{})
+
+			server, err := computeService.CreateServer(
+				apiv1.StemcellCID{},
+				defaultCloudConfig,
+				networkConfig,
+				agentID,
+				env,
+				properties.DiskBus{},
+				createCpiConfig(10),
+			)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(server.ID).To(Equal("123-456"))
+			Expect(computeFacade.DeleteServerCallCount()).To(Equal(1))
+			_, deletedServerID := computeFacade.DeleteServerArgsForCall(0)
+			Expect(deletedServerID).To(Equal("failed-server-id"))
+		})
+
+		It("does not retry in the next AZ if the server creation fails with a non-placement error", func() {
+			availabilityZoneProvider.GetAvailabilityZonesReturns([]string{"z1", "z2"}, nil)
+			computeFacade.CreateServerReturns(nil, errors.New("Quota exceeded for cores"))
+
+			server, err := computeService.CreateServer(
+				apiv1.StemcellCID{},
+				defaultCloudConfig,
+				networkConfig,
+				agentID,
+				env,
+				properties.DiskBus{},
+				createCpiConfig(10),
+			)
+
+			Expect(err.Error()).To(Equal("failed to create server in availability zone 'z1': Quota exceeded for cores"))
+			Expect(err.(utils.CloudError).Type()).To(Equal(utils.VMCreationFailedErrorType))
+			Expect(err.(utils.CloudError).CanRetry()).To(BeFalse())
+			Expect(server).To(BeNil())
+			Expect(computeFacade.CreateServerCallCount()).To(Equal(1))
+		})
+
+		It("does not retry in the next AZ if the server becomes ERROR for a non-placement reason", func() {
+			availabilityZoneProvider.GetAvailabilityZonesReturns([]string{"z1", "z2"}, nil)
+			computeFacade.GetServerReturnsOnCall(0, &servers.Server{ID: "123-456", Status: "ERROR", Fault: servers.Fault{Message: "Image is corrupt"}}, nil)
+			computeFacade.GetServerReturnsOnCall(1, nil, gophercloud.ErrDefault404{})
+
+			server, err := computeService.CreateServer(
+				apiv1.StemcellCID{},
+				defaultCloudConfig,
+				networkConfig,
+				agentID,
+				env,
+				properties.DiskBus{},
+				createCpiConfig(10),
+			)
+
+			Expect(err.Error()).To(Equal("failed while waiting on the server creation in availability zone 'z1': server became ERROR state while waiting to become ACTIVE: Image is corrupt"))
+			Expect(server).To(BeNil())
+			Expect(computeFacade.CreateServerCallCount()).To(Equal(1))
+			Expect(computeFacade.DeleteServerCallCount()).To(Equal(1))
+		})
+
+		It("returns an error listing each AZ attempt if the server creation fails in all AZs", func() {
+			availabilityZoneProvider.GetAvailabilityZonesReturns([]string{"z1", "z2"}, nil)
+			computeFacade.CreateServerReturnsOnCall(0, nil, errors.New("No valid host was found"))
+			computeFacade.CreateServerReturnsOnCall(1, nil, errors.New("The requested availability zone is not available"))
+
+			server, err := computeService.CreateServer(
+				apiv1.StemcellCID{},
+				defaultCloudConfig,
+				networkConfig,
+				agentID,
+				env,
+				properties.DiskBus{},
+				createCpiConfig(10),
+			)
+
+			Expect(err.Error()).To(Equal("failed to create server in availability zones 'z1', 'z2': " +
+				"failed to create server in availability zone 'z1': No valid host was found\n" +
+				"failed to create server in availability zone 'z2': The requested availability zone is not available"))
+			Expect(err.(utils.CloudError).Type()).To(Equal(utils.VMCreationFailedErrorType))
+			Expect(err.(utils.CloudError).CanRetry()).To(BeTrue())
+			Expect(server).To(BeNil())
+		})
+
+		It("returns the server and stops retrying if the failed server cannot be deleted", func() {
+			availabilityZoneProvider.GetAvailabilityZonesReturns([]string{"z1", "z2"}, nil)
+			computeFacade.GetServerReturnsOnCall(0, &servers.Server{ID: "123-456", Status: "ERROR", Fault: servers.Fault{Message: "No valid host was found."}}, nil)
+			computeFacade.DeleteServerReturns(errors.New("boom"))
+
+			server, err := computeService.CreateServer(
+				apiv1.StemcellCID{},
+				defaultCloudConfig,
+				networkConfig,
+				agentID,
+				env,
+				properties.DiskBus{},
+				createCpiConfig(10),
+			)
+
+			Expect(err.Error()).To(ContainSubstring("failed to delete server '123-456' in availability zone 'z1': failed to delete server: boom"))
+			Expect(server.ID).To(Equal("123-456"))
+			Expect(computeFacade.CreateServerCallCount()).To(Equal(1))
+		})
+
 		It("returns an error if the server creation fails", func() {
 			computeFacade.CreateServerReturns(nil, errors.New("boom"))
 
@@ -392,6 +731,7 @@ var _ = Describe("ComputeService", func() {
 				networkConfig,
 				agentID,
 				env,
+				properties.DiskBus{},
 				createCpiConfig(10),
 			)
 
@@ -409,6 +749,7 @@ var _ = Describe("ComputeService", func() {
 				networkConfig,
 				agentID,
 				env,
+				properties.DiskBus{},
 				createCpiConfig(10),
 			)
 
@@ -418,7 +759,8 @@ var _ = Describe("ComputeService", func() {
 		})
 
 		It("returns an error while waiting if getting server information fails", func() {
-			computeFacade.GetServerReturns(nil, errors.New("boom"))
+			computeFacade.GetServerReturnsOnCall(0, nil, errors.New("boom"))
+			computeFacade.GetServerReturnsOnCall(1, nil, gophercloud.ErrDefault404{})
 
 			server, err := computeService.CreateServer(
 				apiv1.StemcellCID{},
@@ -426,6 +768,7 @@ var _ = Describe("ComputeService", func() {
 				networkConfig,
 				agentID,
 				env,
+				properties.DiskBus{},
 				createCpiConfig(10),
 			)
 
@@ -434,7 +777,8 @@ var _ = Describe("ComputeService", func() {
 		})
 
 		It("returns an error while waiting if the server creation finishes in state ERROR", func() {
-			computeFacade.GetServerReturns(&servers.Server{ID: "123-456", Status: "ERROR"}, nil)
+			computeFacade.GetServerReturnsOnCall(0, &servers.Server{ID: "123-456", Status: "ERROR"}, nil)
+			computeFacade.GetServerReturnsOnCall(1, nil, gophercloud.ErrDefault404{})
 
 			server, err := computeService.CreateServer(
 				apiv1.StemcellCID{},
@@ -442,11 +786,13 @@ var _ = Describe("ComputeService", func() {
 				networkConfig,
 				agentID,
 				env,
+				properties.DiskBus{},
 				createCpiConfig(10),
 			)
 
 			Expect(err.Error()).To(Equal("failed while waiting on the server creation in availability zone 'z1': server became ERROR state while waiting to become ACTIVE"))
-			Expect(server).To(Equal(&servers.Server{ID: "123-456", Status: "ERROR"}))
+			Expect(server).To(BeNil())
+			Expect(computeFacade.DeleteServerCallCount()).To(Equal(1))
 		})
 
 		It("returns an error while waiting if the server creation finishes in state DELETED", func() {
@@ -458,15 +804,17 @@ var _ = Describe("ComputeService", func() {
 				networkConfig,
 				agentID,
 				env,
+				properties.DiskBus{},
 				createCpiConfig(10),
 			)
 
 			Expect(err.Error()).To(Equal("failed while waiting on the server creation in availability zone 'z1': server became DELETED state while waiting to become ACTIVE"))
-			Expect(server).To(Equal(&servers.Server{ID: "123-456", Status: "DELETED"}))
+			Expect(server).To(BeNil())
 		})
 
 		It("returns an error while waiting if the server creation times out", func() {
 			computeFacade.GetServerReturns(&servers.Server{ID: "123-456", Status: "not-active"}, nil)
+			computeFacade.DeleteServerReturns(gophercloud.ErrDefault404{})
 
 			server, err := computeService.CreateServer(
 				apiv1.StemcellCID{},
@@ -474,6 +822,7 @@ var _ = Describe("ComputeService", func() {
 				networkConfig,
 				agentID,
 				env,
+				properties.DiskBus{},
 				createCpiConfig(0),
 			)
 
@@ -488,6 +837,7 @@ var _ = Describe("ComputeService", func() {
 				networkConfig,
 				agentID,
 				env,
+				properties.DiskBus{},
 				createCpiConfig(10),
 			)
 
@@ -9,38 +9,42 @@ import (
 )
 
 type FakeAvailabilityZoneProvider struct {
-	GetAvailabilityZonesStub        func(properties.CreateVM) []string
+	GetAvailabilityZonesStub        func(properties.CreateVM, properties.InstanceIdentity) ([]string, error)
 	getAvailabilityZonesMutex       sync.RWMutex
 	getAvailabilityZonesArgsForCall []struct {
 		arg1 properties.CreateVM
+		arg2 properties.InstanceIdentity
 	}
 	getAvailabilityZonesReturns struct {
 		result1 []string
+		result2 error
 	}
 	getAvailabilityZonesReturnsOnCall map[int]struct {
 		result1 []string
+		result2 error
 	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeAvailabilityZoneProvider) GetAvailabilityZones(arg1 properties.CreateVM) []string {
+func (fake *FakeAvailabilityZoneProvider) GetAvailabilityZones(arg1 properties.CreateVM, arg2 properties.InstanceIdentity) ([]string, error) {
 	fake.getAvailabilityZonesMutex.Lock()
 	ret, specificReturn := fake.getAvailabilityZonesReturnsOnCall[len(fake.getAvailabilityZonesArgsForCall)]
 	fake.getAvailabilityZonesArgsForCall = append(fake.getAvailabilityZonesArgsForCall, struct {
 		arg1 properties.CreateVM
-	}{arg1})
+		arg2 properties.InstanceIdentity
+	}{arg1, arg2})
 	stub := fake.GetAvailabilityZonesStub
 	fakeReturns := fake.getAvailabilityZonesReturns
-	fake.recordInvocation("GetAvailabilityZones", []interface{}{arg1})
+	fake.recordInvocation("GetAvailabilityZones", []interface{}{arg1, arg2})
 	fake.getAvailabilityZonesMutex.Unlock()
 	if stub != nil {
-		return stub(arg1)
+		return stub(arg1, arg2)
 	}
 	if specificReturn {
-		return ret.result1
+		return ret.result1, ret.result2
 	}
-	return fakeReturns.result1
+	return fakeReturns.result1, fakeReturns.result2
 }
 
 func (fake *FakeAvailabilityZoneProvider) GetAvailabilityZonesCallCount() int {
@@ -49,40 +53,43 @@ func (fake *FakeAvailabilityZoneProvider) GetAvailabilityZonesCallCount() int {
 	return len(fake.getAvailabilityZonesArgsForCall)
 }
 
-func (fake *FakeAvailabilityZoneProvider) GetAvailabilityZonesCalls(stub func(properties.CreateVM) []string) {
+func (fake *FakeAvailabilityZoneProvider) GetAvailabilityZonesCalls(stub func(properties.CreateVM, properties.InstanceIdentity) ([]string, error)) {
 	fake.getAvailabilityZonesMutex.Lock()
 	defer fake.getAvailabilityZonesMutex.Unlock()
 	fake.GetAvailabilityZonesStub = stub
 }
 
-func (fake *FakeAvailabilityZoneProvider) GetAvailabilityZonesArgsForCall(i int) properties.CreateVM {
+func (fake *FakeAvailabilityZoneProvider) GetAvailabilityZonesArgsForCall(i int) (properties.CreateVM, properties.InstanceIdentity) {
 	fake.getAvailabilityZonesMutex.RLock()
 	defer fake.getAvailabilityZonesMutex.RUnlock()
 	argsForCall := fake.getAvailabilityZonesArgsForCall[i]
-	return argsForCall.arg1
+	return argsForCall.arg1, argsForCall.arg2
 }
 
-func (fake *FakeAvailabilityZoneProvider) GetAvailabilityZonesReturns(result1 []string) {
+func (fake *FakeAvailabilityZoneProvider) GetAvailabilityZonesReturns(result1 []string, result2 error) {
 	fake.getAvailabilityZonesMutex.Lock()
 	defer fake.getAvailabilityZonesMutex.Unlock()
 	fake.GetAvailabilityZonesStub = nil
 	fake.getAvailabilityZonesReturns = struct {
 		result1 []string
-	}{result1}
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeAvailabilityZoneProvider) GetAvailabilityZonesReturnsOnCall(i int, result1 []string) {
+func (fake *FakeAvailabilityZoneProvider) GetAvailabilityZonesReturnsOnCall(i int, result1 []string, result2 error) {
 	fake.getAvailabilityZonesMutex.Lock()
 	defer fake.getAvailabilityZonesMutex.Unlock()
 	fake.GetAvailabilityZonesStub = nil
 	if fake.getAvailabilityZonesReturnsOnCall == nil {
 		fake.getAvailabilityZonesReturnsOnCall = make(map[int]struct {
 			result1 []string
+			result2 error
 		})
 	}
 	fake.getAvailabilityZonesReturnsOnCall[i] = struct {
 		result1 []string
-	}{result1}
+		result2 error
+	}{result1, result2}
 }
 
 func (fake *FakeAvailabilityZoneProvider) Invocations() map[string][][]interface{} {
@@ -7,6 +7,7 @@ import (
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/compute"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils"
 	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/availabilityzones"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/keypairs"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/volumeattach"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/flavors"
@@ -82,6 +83,19 @@ type FakeComputeFacade struct {
 	detachVolumeReturnsOnCall map[int]struct {
 		result1 error
 	}
+	ExtractAvailabilityZonesStub        func(pagination.Page) ([]availabilityzones.AvailabilityZone, error)
+	extractAvailabilityZonesMutex       sync.RWMutex
+	extractAvailabilityZonesArgsForCall []struct {
+		arg1 pagination.Page
+	}
+	extractAvailabilityZonesReturns struct {
+		result1 []availabilityzones.AvailabilityZone
+		result2 error
+	}
+	extractAvailabilityZonesReturnsOnCall map[int]struct {
+		result1 []availabilityzones.AvailabilityZone
+		result2 error
+	}
 	ExtractFlavorsStub        func(pagination.Page) ([]flavors.Flavor, error)
 	extractFlavorsMutex       sync.RWMutex
 	extractFlavorsArgsForCall []struct {
@@ -95,6 +109,19 @@ type FakeComputeFacade struct {
 		result1 []flavors.Flavor
 		result2 error
 	}
+	ExtractServersWithAZStub        func(pagination.Page) ([]compute.ServerWithAZ, error)
+	extractServersWithAZMutex       sync.RWMutex
+	extractServersWithAZArgsForCall []struct {
+		arg1 pagination.Page
+	}
+	extractServersWithAZReturns struct {
+		result1 []compute.ServerWithAZ
+		result2 error
+	}
+	extractServersWithAZReturnsOnCall map[int]struct {
+		result1 []compute.ServerWithAZ
+		result2 error
+	}
 	GetOSKeyPairStub        func(utils.RetryableServiceClient, string, keypairs.GetOpts) (*keypairs.KeyPair, error)
 	getOSKeyPairMutex       sync.RWMutex
 	getOSKeyPairArgsForCall []struct {
@@ -152,6 +179,19 @@ type FakeComputeFacade struct {
 		result1 *compute.ServerWithAZ
 		result2 error
 	}
+	ListAvailabilityZonesStub        func(utils.RetryableServiceClient) (pagination.Page, error)
+	listAvailabilityZonesMutex       sync.RWMutex
+	listAvailabilityZonesArgsForCall []struct {
+		arg1 utils.RetryableServiceClient
+	}
+	listAvailabilityZonesReturns struct {
+		result1 pagination.Page
+		result2 error
+	}
+	listAvailabilityZonesReturnsOnCall map[int]struct {
+		result1 pagination.Page
+		result2 error
+	}
 	ListFlavorsStub        func(utils.RetryableServiceClient, flavors.ListOpts) (pagination.Page, error)
 	listFlavorsMutex       sync.RWMutex
 	listFlavorsArgsForCall []struct {
@@ -166,6 +206,20 @@ type FakeComputeFacade struct {
 		result1 pagination.Page
 		result2 error
 	}
+	ListServersStub        func(utils.RetryableServiceClient, servers.ListOptsBuilder) (pagination.Page, error)
+	listServersMutex       sync.RWMutex
+	listServersArgsForCall []struct {
+		arg1 utils.RetryableServiceClient
+		arg2 servers.ListOptsBuilder
+	}
+	listServersReturns struct {
+		result1 pagination.Page
+		result2 error
+	}
+	listServersReturnsOnCall map[int]struct {
+		result1 pagination.Page
+		result2 error
+	}
 	ListVolumeAttachmentsStub        func(*gophercloud.ServiceClient, string) ([]volumeattach.VolumeAttachment, error)
 	listVolumeAttachmentsMutex       sync.RWMutex
 	listVolumeAttachmentsArgsForCall []struct {
@@ -546,6 +600,70 @@ func (fake *FakeComputeFacade) DetachVolumeReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeComputeFacade) ExtractAvailabilityZones(arg1 pagination.Page) ([]availabilityzones.AvailabilityZone, error) {
+	fake.extractAvailabilityZonesMutex.Lock()
+	ret, specificReturn := fake.extractAvailabilityZonesReturnsOnCall[len(fake.extractAvailabilityZonesArgsForCall)]
+	fake.extractAvailabilityZonesArgsForCall = append(fake.extractAvailabilityZonesArgsForCall, struct {
+		arg1 pagination.Page
+	}{arg1})
+	stub := fake.ExtractAvailabilityZonesStub
+	fakeReturns := fake.extractAvailabilityZonesReturns
+	fake.recordInvocation("ExtractAvailabilityZones", []interface{}{arg1})
+	fake.extractAvailabilityZonesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeComputeFacade) ExtractAvailabilityZonesCallCount() int {
+	fake.extractAvailabilityZonesMutex.RLock()
+	defer fake.extractAvailabilityZonesMutex.RUnlock()
+	return len(fake.extractAvailabilityZonesArgsForCall)
+}
+
+func (fake *FakeComputeFacade) ExtractAvailabilityZonesCalls(stub func(pagination.Page) ([]availabilityzones.AvailabilityZone, error)) {
+	fake.extractAvailabilityZonesMutex.Lock()
+	defer fake.extractAvailabilityZonesMutex.Unlock()
+	fake.ExtractAvailabilityZonesStub = stub
+}
+
+func (fake *FakeComputeFacade) ExtractAvailabilityZonesArgsForCall(i int) pagination.Page {
+	fake.extractAvailabilityZonesMutex.RLock()
+	defer fake.extractAvailabilityZonesMutex.RUnlock()
+	argsForCall := fake.extractAvailabilityZonesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeComputeFacade) ExtractAvailabilityZonesReturns(result1 []availabilityzones.AvailabilityZone, result2 error) {
+	fake.extractAvailabilityZonesMutex.Lock()
+	defer fake.extractAvailabilityZonesMutex.Unlock()
+	fake.ExtractAvailabilityZonesStub = nil
+	fake.extractAvailabilityZonesReturns = struct {
+		result1 []availabilityzones.AvailabilityZone
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeComputeFacade) ExtractAvailabilityZonesReturnsOnCall(i int, result1 []availabilityzones.AvailabilityZone, result2 error) {
+	fake.extractAvailabilityZonesMutex.Lock()
+	defer fake.extractAvailabilityZonesMutex.Unlock()
+	fake.ExtractAvailabilityZonesStub = nil
+	if fake.extractAvailabilityZonesReturnsOnCall == nil {
+		fake.extractAvailabilityZonesReturnsOnCall = make(map[int]struct {
+			result1 []availabilityzones.AvailabilityZone
+			result2 error
+		})
+	}
+	fake.extractAvailabilityZonesReturnsOnCall[i] = struct {
+		result1 []availabilityzones.AvailabilityZone
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeComputeFacade) ExtractFlavors(arg1 pagination.Page) ([]flavors.Flavor, error) {
 	fake.extractFlavorsMutex.Lock()
 	ret, specificReturn := fake.extractFlavorsReturnsOnCall[len(fake.extractFlavorsArgsForCall)]
@@ -610,6 +728,70 @@ func (fake *FakeComputeFacade) ExtractFlavorsReturnsOnCall(i int, result1 []flav
 	}{result1, result2}
 }
 
+func (fake *FakeComputeFacade) ExtractServersWithAZ(arg1 pagination.Page) ([]compute.ServerWithAZ, error) {
+	fake.extractServersWithAZMutex.Lock()
+	ret, specificReturn := fake.extractServersWithAZReturnsOnCall[len(fake.extractServersWithAZArgsForCall)]
+	fake.extractServersWithAZArgsForCall = append(fake.extractServersWithAZArgsForCall, struct {
+		arg1 pagination.Page
+	}{arg1})
+	stub := fake.ExtractServersWithAZStub
+	fakeReturns := fake.extractServersWithAZReturns
+	fake.recordInvocation("ExtractServersWithAZ", []interface{}{arg1})
+	fake.extractServersWithAZMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeComputeFacade) ExtractServersWithAZCallCount() int {
+	fake.extractServersWithAZMutex.RLock()
+	defer fake.extractServersWithAZMutex.RUnlock()
+	return len(fake.extractServersWithAZArgsForCall)
+}
+
+func (fake *FakeComputeFacade) ExtractServersWithAZCalls(stub func(pagination.Page) ([]compute.ServerWithAZ, error)) {
+	fake.extractServersWithAZMutex.Lock()
+	defer fake.extractServersWithAZMutex.Unlock()
+	fake.ExtractServersWithAZStub = stub
+}
+
+func (fake *FakeComputeFacade) ExtractServersWithAZArgsForCall(i int) pagination.Page {
+	fake.extractServersWithAZMutex.RLock()
+	defer fake.extractServersWithAZMutex.RUnlock()
+	argsForCall := fake.extractServersWithAZArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeComputeFacade) ExtractServersWithAZReturns(result1 []compute.ServerWithAZ, result2 error) {
+	fake.extractServersWithAZMutex.Lock()
+	defer fake.extractServersWithAZMutex.Unlock()
+	fake.ExtractServersWithAZStub = nil
+	fake.extractServersWithAZReturns = struct {
+		result1 []compute.ServerWithAZ
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeComputeFacade) ExtractServersWithAZReturnsOnCall(i int, result1 []compute.ServerWithAZ, result2 error) {
+	fake.extractServersWithAZMutex.Lock()
+	defer fake.extractServersWithAZMutex.Unlock()
+	fake.ExtractServersWithAZStub = nil
+	if fake.extractServersWithAZReturnsOnCall == nil {
+		fake.extractServersWithAZReturnsOnCall = make(map[int]struct {
+			result1 []compute.ServerWithAZ
+			result2 error
+		})
+	}
+	fake.extractServersWithAZReturnsOnCall[i] = struct {
+		result1 []compute.ServerWithAZ
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeComputeFacade) GetOSKeyPair(arg1 utils.RetryableServiceClient, arg2 string, arg3 keypairs.GetOpts) (*keypairs.KeyPair, error) {
 	fake.getOSKeyPairMutex.Lock()
 	ret, specificReturn := fake.getOSKeyPairReturnsOnCall[len(fake.getOSKeyPairArgsForCall)]
@@ -871,6 +1053,70 @@ func (fake *FakeComputeFacade) GetServerWithAZReturnsOnCall(i int, result1 *comp
 	}{result1, result2}
 }
 
+func (fake *FakeComputeFacade) ListAvailabilityZones(arg1 utils.RetryableServiceClient) (pagination.Page, error) {
+	fake.listAvailabilityZonesMutex.Lock()
+	ret, specificReturn := fake.listAvailabilityZonesReturnsOnCall[len(fake.listAvailabilityZonesArgsForCall)]
+	fake.listAvailabilityZonesArgsForCall = append(fake.listAvailabilityZonesArgsForCall, struct {
+		arg1 utils.RetryableServiceClient
+	}{arg1})
+	stub := fake.ListAvailabilityZonesStub
+	fakeReturns := fake.listAvailabilityZonesReturns
+	fake.recordInvocation("ListAvailabilityZones", []interface{}{arg1})
+	fake.listAvailabilityZonesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeComputeFacade) ListAvailabilityZonesCallCount() int {
+	fake.listAvailabilityZonesMutex.RLock()
+	defer fake.listAvailabilityZonesMutex.RUnlock()
+	return len(fake.listAvailabilityZonesArgsForCall)
+}
+
+func (fake *FakeComputeFacade) ListAvailabilityZonesCalls(stub func(utils.RetryableServiceClient) (pagination.Page, error)) {
+	fake.listAvailabilityZonesMutex.Lock()
+	defer fake.listAvailabilityZonesMutex.Unlock()
+	fake.ListAvailabilityZonesStub = stub
+}
+
+func (fake *FakeComputeFacade) ListAvailabilityZonesArgsForCall(i int) utils.RetryableServiceClient {
+	fake.listAvailabilityZonesMutex.RLock()
+	defer fake.listAvailabilityZonesMutex.RUnlock()
+	argsForCall := fake.listAvailabilityZonesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeComputeFacade) ListAvailabilityZonesReturns(result1 pagination.Page, result2 error) {
+	fake.listAvailabilityZonesMutex.Lock()
+	defer fake.listAvailabilityZonesMutex.Unlock()
+	fake.ListAvailabilityZonesStub = nil
+	fake.listAvailabilityZonesReturns = struct {
+		result1 pagination.Page
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeComputeFacade) ListAvailabilityZonesReturnsOnCall(i int, result1 pagination.Page, result2 error) {
+	fake.listAvailabilityZonesMutex.Lock()
+	defer fake.listAvailabilityZonesMutex.Unlock()
+	fake.ListAvailabilityZonesStub = nil
+	if fake.listAvailabilityZonesReturnsOnCall == nil {
+		fake.listAvailabilityZonesReturnsOnCall = make(map[int]struct {
+			result1 pagination.Page
+			result2 error
+		})
+	}
+	fake.listAvailabilityZonesReturnsOnCall[i] = struct {
+		result1 pagination.Page
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeComputeFacade) ListFlavors(arg1 utils.RetryableServiceClient, arg2 flavors.ListOpts) (pagination.Page, error) {
 	fake.listFlavorsMutex.Lock()
 	ret, specificReturn := fake.listFlavorsReturnsOnCall[len(fake.listFlavorsArgsForCall)]
@@ -936,6 +1182,71 @@ func (fake *FakeComputeFacade) ListFlavorsReturnsOnCall(i int, result1 paginatio
 	}{result1, result2}
 }
 
+func (fake *FakeComputeFacade) ListServers(arg1 utils.RetryableServiceClient, arg2 servers.ListOptsBuilder) (pagination.Page, error) {
+	fake.listServersMutex.Lock()
+	ret, specificReturn := fake.listServersReturnsOnCall[len(fake.listServersArgsForCall)]
+	fake.listServersArgsForCall = append(fake.listServersArgsForCall, struct {
+		arg1 utils.RetryableServiceClient
+		arg2 servers.ListOptsBuilder
+	}{arg1, arg2})
+	stub := fake.ListServersStub
+	fakeReturns := fake.listServersReturns
+	fake.recordInvocation("ListServers", []interface{}{arg1, arg2})
+	fake.listServersMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeComputeFacade) ListServersCallCount() int {
+	fake.listServersMutex.RLock()
+	defer fake.listServersMutex.RUnlock()
+	return len(fake.listServersArgsForCall)
+}
+
+func (fake *FakeComputeFacade) ListServersCalls(stub func(utils.RetryableServiceClient, servers.ListOptsBuilder) (pagination.Page, error)) {
+	fake.listServersMutex.Lock()
+	defer fake.listServersMutex.Unlock()
+	fake.ListServersStub = stub
+}
+
+func (fake *FakeComputeFacade) ListServersArgsForCall(i int) (utils.RetryableServiceClient, servers.ListOptsBuilder) {
+	fake.listServersMutex.RLock()
+	defer fake.listServersMutex.RUnlock()
+	argsForCall := fake.listServersArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeComputeFacade) ListServersReturns(result1 pagination.Page, result2 error) {
+	fake.listServersMutex.Lock()
+	defer fake.listServersMutex.Unlock()
+	fake.ListServersStub = nil
+	fake.listServersReturns = struct {
+		result1 pagination.Page
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeComputeFacade) ListServersReturnsOnCall(i int, result1 pagination.Page, result2 error) {
+	fake.listServersMutex.Lock()
+	defer fake.listServersMutex.Unlock()
+	fake.ListServersStub = nil
+	if fake.listServersReturnsOnCall == nil {
+		fake.listServersReturnsOnCall = make(map[int]struct {
+			result1 pagination.Page
+			result2 error
+		})
+	}
+	fake.listServersReturnsOnCall[i] = struct {
+		result1 pagination.Page
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeComputeFacade) ListVolumeAttachments(arg1 *gophercloud.ServiceClient, arg2 string) ([]volumeattach.VolumeAttachment, error) {
 	fake.listVolumeAttachmentsMutex.Lock()
 	ret, specificReturn := fake.listVolumeAttachmentsReturnsOnCall[len(fake.listVolumeAttachmentsArgsForCall)]
@@ -1209,8 +1520,12 @@ func (fake *FakeComputeFacade) Invocations() map[string][][]interface{} {
 	defer fake.deleteServerMetaDataMutex.RUnlock()
 	fake.detachVolumeMutex.RLock()
 	defer fake.detachVolumeMutex.RUnlock()
+	fake.extractAvailabilityZonesMutex.RLock()
+	defer fake.extractAvailabilityZonesMutex.RUnlock()
 	fake.extractFlavorsMutex.RLock()
 	defer fake.extractFlavorsMutex.RUnlock()
+	fake.extractServersWithAZMutex.RLock()
+	defer fake.extractServersWithAZMutex.RUnlock()
 	fake.getOSKeyPairMutex.RLock()
 	defer fake.getOSKeyPairMutex.RUnlock()
 	fake.getServerMutex.RLock()
@@ -1219,8 +1534,12 @@ func (fake *FakeComputeFacade) Invocations() map[string][][]interface{} {
 	defer fake.getServerMetadataMutex.RUnlock()
 	fake.getServerWithAZMutex.RLock()
 	defer fake.getServerWithAZMutex.RUnlock()
+	fake.listAvailabilityZonesMutex.RLock()
+	defer fake.listAvailabilityZonesMutex.RUnlock()
 	fake.listFlavorsMutex.RLock()
 	defer fake.listFlavorsMutex.RUnlock()
+	fake.listServersMutex.RLock()
+	defer fake.listServersMutex.RUnlock()
 	fake.listVolumeAttachmentsMutex.RLock()
 	defer fake.listVolumeAttachmentsMutex.RUnlock()
 	fake.rebootServerMutex.RLock()
@@ -29,7 +29,7 @@ type FakeComputeService struct {
 		result1 *volumeattach.VolumeAttachment
 		result2 error
 	}
-	CreateServerStub        func(apiv1.StemcellCID, properties.CreateVM, properties.NetworkConfig, apiv1.AgentID, apiv1.VMEnv, config.CpiConfig) (*servers.Server, error)
+	CreateServerStub        func(apiv1.StemcellCID, properties.CreateVM, properties.NetworkConfig, apiv1.AgentID, apiv1.VMEnv, properties.DiskBus, config.CpiConfig) (*servers.Server, error)
 	createServerMutex       sync.RWMutex
 	createServerArgsForCall []struct {
 		arg1 apiv1.StemcellCID
@@ -37,7 +37,8 @@ type FakeComputeService struct {
 		arg3 properties.NetworkConfig
 		arg4 apiv1.AgentID
 		arg5 apiv1.VMEnv
-		arg6 config.CpiConfig
+		arg6 properties.DiskBus
+		arg7 config.CpiConfig
 	}
 	createServerReturns struct {
 		result1 *servers.Server
@@ -271,7 +272,7 @@ func (fake *FakeComputeService) AttachVolumeReturnsOnCall(i int, result1 *volume
 	}{result1, result2}
 }
 
-func (fake *FakeComputeService) CreateServer(arg1 apiv1.StemcellCID, arg2 properties.CreateVM, arg3 properties.NetworkConfig, arg4 apiv1.AgentID, arg5 apiv1.VMEnv, arg6 config.CpiConfig) (*servers.Server, error) {
+func (fake *FakeComputeService) CreateServer(arg1 apiv1.StemcellCID, arg2 properties.CreateVM, arg3 properties.NetworkConfig, arg4 apiv1.AgentID, arg5 apiv1.VMEnv, arg6 properties.DiskBus, arg7 config.CpiConfig) (*servers.Server, error) {
 	fake.createServerMutex.Lock()
 	ret, specificReturn := fake.createServerReturnsOnCall[len(fake.createServerArgsForCall)]
 	fake.createServerArgsForCall = append(fake.createServerArgsForCall, struct {
@@ -280,14 +281,15 @@ func (fake *FakeComputeService) CreateServer(arg1 apiv1.StemcellCID, arg2 proper
 		arg3 properties.NetworkConfig
 		arg4 apiv1.AgentID
 		arg5 apiv1.VMEnv
-		arg6 config.CpiConfig
-	}{arg1, arg2, arg3, arg4, arg5, arg6})
+		arg6 properties.DiskBus
+		arg7 config.CpiConfig
+	}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
 	stub := fake.CreateServerStub
 	fakeReturns := fake.createServerReturns
-	fake.recordInvocation("CreateServer", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6})
+	fake.recordInvocation("CreateServer", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6, arg7})
 	fake.createServerMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3, arg4, arg5, arg6)
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6, arg7)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -301,17 +303,17 @@ func (fake *FakeComputeService) CreateServerCallCount() int {
 	return len(fake.createServerArgsForCall)
 }
 
-func (fake *FakeComputeService) CreateServerCalls(stub func(apiv1.StemcellCID, properties.CreateVM, properties.NetworkConfig, apiv1.AgentID, apiv1.VMEnv, config.CpiConfig) (*servers.Server, error)) {
+func (fake *FakeComputeService) CreateServerCalls(stub func(apiv1.StemcellCID, properties.CreateVM, properties.NetworkConfig, apiv1.AgentID, apiv1.VMEnv, properties.DiskBus, config.CpiConfig) (*servers.Server, error)) {
 	fake.createServerMutex.Lock()
 	defer fake.createServerMutex.Unlock()
 	fake.CreateServerStub = stub
 }
 
-func (fake *FakeComputeService) CreateServerArgsForCall(i int) (apiv1.StemcellCID, properties.CreateVM, properties.NetworkConfig, apiv1.AgentID, apiv1.VMEnv, config.CpiConfig) {
+func (fake *FakeComputeService) CreateServerArgsForCall(i int) (apiv1.StemcellCID, properties.CreateVM, properties.NetworkConfig, apiv1.AgentID, apiv1.VMEnv, properties.DiskBus, config.CpiConfig) {
 	fake.createServerMutex.RLock()
 	defer fake.createServerMutex.RUnlock()
 	argsForCall := fake.createServerArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6, argsForCall.arg7
 }
 
 func (fake *FakeComputeService) CreateServerReturns(result1 *servers.Server, result2 error) {
@@ -1104,34 +1106,6 @@ func (fake *FakeComputeService) UpdateServerMetadataReturnsOnCall(i int, result1
 func (fake *FakeComputeService) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.attachVolumeMutex.RLock()
-	defer fake.attachVolumeMutex.RUnlock()
-	fake.createServerMutex.RLock()
-	defer fake.createServerMutex.RUnlock()
-	fake.deleteServerMutex.RLock()
-	defer fake.deleteServerMutex.RUnlock()
-	fake.deleteServerMetaDataMutex.RLock()
-	defer fake.deleteServerMetaDataMutex.RUnlock()
-	fake.detachVolumeMutex.RLock()
-	defer fake.detachVolumeMutex.RUnlock()
-	fake.getFlavorByIdMutex.RLock()
-	defer fake.getFlavorByIdMutex.RUnlock()
-	fake.getMatchingFlavorMutex.RLock()
-	defer fake.getMatchingFlavorMutex.RUnlock()
-	fake.getMetadataMutex.RLock()
-	defer fake.getMetadataMutex.RUnlock()
-	fake.getServerMutex.RLock()
-	defer fake.getServerMutex.RUnlock()
-	fake.getServerAZMutex.RLock()
-	defer fake.getServerAZMutex.RUnlock()
-	fake.listVolumeAttachmentsMutex.RLock()
-	defer fake.listVolumeAttachmentsMutex.RUnlock()
-	fake.rebootServerMutex.RLock()
-	defer fake.rebootServerMutex.RUnlock()
-	fake.updateServerMutex.RLock()
-	defer fake.updateServerMutex.RUnlock()
-	fake.updateServerMetadataMutex.RLock()
-	defer fake.updateServerMetadataMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -12,13 +12,14 @@ import (
 )
 
 type FakeVolumeConfigurator struct {
-	ConfigureVolumesStub        func(string, config.OpenstackConfig, properties.CreateVM, flavors.Flavor) ([]bootfromvolume.BlockDevice, error)
+	ConfigureVolumesStub        func(string, string, config.OpenstackConfig, properties.CreateVM, flavors.Flavor) ([]bootfromvolume.BlockDevice, error)
 	configureVolumesMutex       sync.RWMutex
 	configureVolumesArgsForCall []struct {
 		arg1 string
-		arg2 config.OpenstackConfig
-		arg3 properties.CreateVM
-		arg4 flavors.Flavor
+		arg2 string
+		arg3 config.OpenstackConfig
+		arg4 properties.CreateVM
+		arg5 flavors.Flavor
 	}
 	configureVolumesReturns struct {
 		result1 []bootfromvolume.BlockDevice
@@ -32,21 +33,22 @@ type FakeVolumeConfigurator struct {
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeVolumeConfigurator) ConfigureVolumes(arg1 string, arg2 config.OpenstackConfig, arg3 properties.CreateVM, arg4 flavors.Flavor) ([]bootfromvolume.BlockDevice, error) {
+func (fake *FakeVolumeConfigurator) ConfigureVolumes(arg1 string, arg2 string, arg3 config.OpenstackConfig, arg4 properties.CreateVM, arg5 flavors.Flavor) ([]bootfromvolume.BlockDevice, error) {
 	fake.configureVolumesMutex.Lock()
 	ret, specificReturn := fake.configureVolumesReturnsOnCall[len(fake.configureVolumesArgsForCall)]
 	fake.configureVolumesArgsForCall = append(fake.configureVolumesArgsForCall, struct {
 		arg1 string
-		arg2 config.OpenstackConfig
-		arg3 properties.CreateVM
-		arg4 flavors.Flavor
-	}{arg1, arg2, arg3, arg4})
+		arg2 string
+		arg3 config.OpenstackConfig
+		arg4 properties.CreateVM
+		arg5 flavors.Flavor
+	}{arg1, arg2, arg3, arg4, arg5})
 	stub := fake.ConfigureVolumesStub
 	fakeReturns := fake.configureVolumesReturns
-	fake.recordInvocation("ConfigureVolumes", []interface{}{arg1, arg2, arg3, arg4})
+	fake.recordInvocation("ConfigureVolumes", []interface{}{arg1, arg2, arg3, arg4, arg5})
 	fake.configureVolumesMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3, arg4)
+		return stub(arg1, arg2, arg3, arg4, arg5)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -60,17 +62,17 @@ func (fake *FakeVolumeConfigurator) ConfigureVolumesCallCount() int {
 	return len(fake.configureVolumesArgsForCall)
 }
 
-func (fake *FakeVolumeConfigurator) ConfigureVolumesCalls(stub func(string, config.OpenstackConfig, properties.CreateVM, flavors.Flavor) ([]bootfromvolume.BlockDevice, error)) {
+func (fake *FakeVolumeConfigurator) ConfigureVolumesCalls(stub func(string, string, config.OpenstackConfig, properties.CreateVM, flavors.Flavor) ([]bootfromvolume.BlockDevice, error)) {
 	fake.configureVolumesMutex.Lock()
 	defer fake.configureVolumesMutex.Unlock()
 	fake.ConfigureVolumesStub = stub
 }
 
-func (fake *FakeVolumeConfigurator) ConfigureVolumesArgsForCall(i int) (string, config.OpenstackConfig, properties.CreateVM, flavors.Flavor) {
+func (fake *FakeVolumeConfigurator) ConfigureVolumesArgsForCall(i int) (string, string, config.OpenstackConfig, properties.CreateVM, flavors.Flavor) {
 	fake.configureVolumesMutex.RLock()
 	defer fake.configureVolumesMutex.RUnlock()
 	argsForCall := fake.configureVolumesArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
 }
 
 func (fake *FakeVolumeConfigurator) ConfigureVolumesReturns(result1 []bootfromvolume.BlockDevice, result2 error) {
@@ -102,8 +104,6 @@ func (fake *FakeVolumeConfigurator) ConfigureVolumesReturnsOnCall(i int, result1
 func (fake *FakeVolumeConfigurator) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.configureVolumesMutex.RLock()
-	defer fake.configureVolumesMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -13,6 +13,7 @@ import (
 type VolumeConfigurator interface {
 	ConfigureVolumes(
 		imageID string,
+		availabilityZone string,
 		openstackConfig config.OpenstackConfig,
 		cloudProperties properties.CreateVM,
 		flavor flavors.Flavor,
@@ -24,24 +25,48 @@ type volumeConfigurator struct{}
 func NewVolumeConfigurator() volumeConfigurator {
 	return volumeConfigurator{}
 }
-func (v volumeConfigurator) ConfigureVolumes(imageID string, openstackConfig config.OpenstackConfig, cloudProperties properties.CreateVM, flavor flavors.Flavor) ([]bootfromvolume.BlockDevice, error) {
+
+func (v volumeConfigurator) ConfigureVolumes(imageID string, availabilityZone string, openstackConfig config.OpenstackConfig, cloudProperties properties.CreateVM, flavor flavors.Flavor) ([]bootfromvolume.BlockDevice, error) {
 	bootVolumeSize, err := v.select_boot_volume_size(flavor, cloudProperties)
 	if err != nil {
 		return []bootfromvolume.BlockDevice{}, fmt.Errorf("failed to get volume size: %w", err)
 	}
 
-	if !v.bootFromVolume(openstackConfig, cloudProperties) {
-		return []bootfromvolume.BlockDevice{}, nil
+	blockDevices := []bootfromvolume.BlockDevice{}
+
+	if v.bootFromVolume(openstackConfig, cloudProperties) {
+		blockDevices = append(blockDevices, bootfromvolume.BlockDevice{
+			UUID:                imageID,
+			SourceType:          bootfromvolume.SourceImage,
+			DestinationType:     bootfromvolume.DestinationVolume,
+			VolumeSize:          bootVolumeSize,
+			VolumeType:          v.selectBootVolumeType(availabilityZone, openstackConfig, cloudProperties),
+			BootIndex:           0,
+			DeleteOnTermination: cloudProperties.RootDisk.DeleteVolumeOnTermination(),
+		})
+	} else if cloudProperties.EphemeralDisk != nil {
+		// As soon as a block device mapping is passed, the image has to be mapped explicitly as local boot disk
+		blockDevices = append(blockDevices, bootfromvolume.BlockDevice{
+			UUID:                imageID,
+			SourceType:          bootfromvolume.SourceImage,
+			DestinationType:     bootfromvolume.DestinationLocal,
+			BootIndex:           0,
+			DeleteOnTermination: true,
+		})
 	}
 
-	return []bootfromvolume.BlockDevice{{
-		UUID:                imageID,
-		SourceType:          bootfromvolume.SourceImage,
-		DestinationType:     bootfromvolume.DestinationVolume,
-		VolumeSize:          bootVolumeSize,
-		BootIndex:           0,
-		DeleteOnTermination: true,
-	}}, nil
+	if cloudProperties.EphemeralDisk != nil {
+		blockDevices = append(blockDevices, bootfromvolume.BlockDevice{
+			SourceType:          bootfromvolume.SourceBlank,
+			DestinationType:     bootfromvolume.DestinationVolume,
+			VolumeSize:          cloudProperties.EphemeralDisk.Size,
+			VolumeType:          cloudProperties.EphemeralDisk.Type,
+			BootIndex:           -1,
+			DeleteOnTermination: cloudProperties.EphemeralDisk.DeleteVolumeOnTermination(),
+		})
+	}
+
+	return blockDevices, nil
 }
 
 func (v volumeConfigurator) bootFromVolume(openstackConfig config.OpenstackConfig, cloudProperties properties.CreateVM) bool {
@@ -52,6 +77,21 @@ func (v volumeConfigurator) bootFromVolume(openstackConfig config.OpenstackConfi
 	return *cloudProperties.BootFromVolume
 }
 
+// selectBootVolumeType prefers the root disk type over the volume type mapped to the availability zone
+// and the default volume type. Nova creates the boot volume in the availability zone of the server.
+func (v volumeConfigurator) selectBootVolumeType(availabilityZone string, openstackConfig config.OpenstackConfig, cloudProperties properties.CreateVM) string {
+	if cloudProperties.RootDisk.Type != "" {
+		return cloudProperties.RootDisk.Type
+	}
+
+	volumeType := openstackConfig.GetVolumeAvailabilityZone(availabilityZone).VolumeType
+	if volumeType != "" {
+		return volumeType
+	}
+
+	return openstackConfig.DefaultVolumeType
+}
+
 func (v volumeConfigurator) select_boot_volume_size(flavor flavors.Flavor, cloudProperties properties.CreateVM) (int, error) {
 	rootDiskSize := cloudProperties.RootDisk.Size
 	if rootDiskSize == 0 {
@@ -21,6 +21,7 @@ var _ = Describe("VolumeConfigurator", func() {
 			bootFromVolume := true
 			volumes, err := compute.NewVolumeConfigurator().ConfigureVolumes(
 				"the_image_id",
+				"z1",
 				config.OpenstackConfig{},
 				properties.CreateVM{
 					RootDisk:       properties.Disk{Size: 888},
@@ -37,6 +38,7 @@ var _ = Describe("VolumeConfigurator", func() {
 			bootFromVolume := true
 			volumes, err := compute.NewVolumeConfigurator().ConfigureVolumes(
 				"the_image_id",
+				"z1",
 				config.OpenstackConfig{},
 				properties.CreateVM{
 					RootDisk:       properties.Disk{Size: 0},
@@ -54,6 +56,7 @@ var _ = Describe("VolumeConfigurator", func() {
 			bootFromVolume := true
 			_, err := compute.NewVolumeConfigurator().ConfigureVolumes(
 				"the_image_id",
+				"z1",
 				config.OpenstackConfig{},
 				properties.CreateVM{
 					RootDisk:       properties.Disk{Size: 0},
@@ -73,6 +76,7 @@ var _ = Describe("VolumeConfigurator", func() {
 			bootFromVolume := true
 			volumes, err := compute.NewVolumeConfigurator().ConfigureVolumes(
 				"the_image_id",
+				"z1",
 				config.OpenstackConfig{},
 				properties.CreateVM{
 					RootDisk:       properties.Disk{Size: 0},
@@ -96,6 +100,7 @@ var _ = Describe("VolumeConfigurator", func() {
 
 			volumes, err := compute.NewVolumeConfigurator().ConfigureVolumes(
 				"the_image_id",
+				"z1",
 				config.OpenstackConfig{},
 				properties.CreateVM{
 					RootDisk: properties.Disk{Size: 0},
@@ -109,6 +114,151 @@ var _ = Describe("VolumeConfigurator", func() {
 			Expect(len(volumes)).To(Equal(0))
 		})
 
+		Context("with a root disk volume type", func() {
+			var bootFromVolume bool
+
+			BeforeEach(func() {
+				bootFromVolume = true
+			})
+
+			It("uses the volume type from the root disk cloud properties", func() {
+				volumes, err := compute.NewVolumeConfigurator().ConfigureVolumes(
+					"the_image_id",
+					"z1",
+					config.OpenstackConfig{DefaultVolumeType: "the_default_volume_type"},
+					properties.CreateVM{
+						RootDisk:       properties.Disk{Size: 10, Type: "the_volume_type"},
+						BootFromVolume: &bootFromVolume,
+					},
+					flavors.Flavor{},
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(volumes[0].VolumeType).To(Equal("the_volume_type"))
+			})
+
+			It("uses the volume type mapped to the availability zone", func() {
+				volumes, err := compute.NewVolumeConfigurator().ConfigureVolumes(
+					"the_image_id",
+					"z1",
+					config.OpenstackConfig{
+						DefaultVolumeType:       "the_default_volume_type",
+						VolumeAvailabilityZones: map[string]config.VolumeAvailabilityZone{"z1": {VolumeType: "the_z1_volume_type"}},
+					},
+					properties.CreateVM{
+						RootDisk:       properties.Disk{Size: 10},
+						BootFromVolume: &bootFromVolume,
+					},
+					flavors.Flavor{},
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(volumes[0].VolumeType).To(Equal("the_z1_volume_type"))
+			})
+
+			It("falls back to the default volume type", func() {
+				volumes, err := compute.NewVolumeConfigurator().ConfigureVolumes(
+					"the_image_id",
+					"z1",
+					config.OpenstackConfig{DefaultVolumeType: "the_default_volume_type"},
+					properties.CreateVM{
+						RootDisk:       properties.Disk{Size: 10},
+						BootFromVolume: &bootFromVolume,
+					},
+					flavors.Flavor{},
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(volumes[0].VolumeType).To(Equal("the_default_volume_type"))
+			})
+
+			It("keeps the boot volume if delete_on_termination is false", func() {
+				deleteOnTermination := false
+				volumes, err := compute.NewVolumeConfigurator().ConfigureVolumes(
+					"the_image_id",
+					"z1",
+					config.OpenstackConfig{},
+					properties.CreateVM{
+						RootDisk:       properties.Disk{Size: 10, DeleteOnTermination: &deleteOnTermination},
+						BootFromVolume: &bootFromVolume,
+					},
+					flavors.Flavor{},
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(volumes[0].DeleteOnTermination).To(BeFalse())
+			})
+		})
+
+		Context("with an ephemeral disk", func() {
+			var ephemeralDisk *properties.EphemeralDisk
+
+			BeforeEach(func() {
+				ephemeralDisk = &properties.EphemeralDisk{Size: 20, Type: "the_volume_type"}
+			})
+
+			It("maps the image as local boot disk and adds a blank volume", func() {
+				volumes, err := compute.NewVolumeConfigurator().ConfigureVolumes(
+					"the_image_id",
+					"z1",
+					config.OpenstackConfig{},
+					properties.CreateVM{
+						EphemeralDisk: ephemeralDisk,
+					},
+					flavors.Flavor{
+						Disk: 999,
+					},
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(volumes).To(Equal([]bootfromvolume.BlockDevice{
+					{
+						UUID:                "the_image_id",
+						SourceType:          bootfromvolume.SourceImage,
+						DestinationType:     bootfromvolume.DestinationLocal,
+						BootIndex:           0,
+						DeleteOnTermination: true,
+					},
+					{
+						SourceType:          bootfromvolume.SourceBlank,
+						DestinationType:     bootfromvolume.DestinationVolume,
+						VolumeSize:          20,
+						VolumeType:          "the_volume_type",
+						BootIndex:           -1,
+						DeleteOnTermination: true,
+					},
+				}))
+			})
+
+			It("adds a blank volume after the boot volume", func() {
+				bootFromVolume := true
+				deleteOnTermination := false
+				ephemeralDisk.DeleteOnTermination = &deleteOnTermination
+
+				volumes, err := compute.NewVolumeConfigurator().ConfigureVolumes(
+					"the_image_id",
+					"z1",
+					config.OpenstackConfig{},
+					properties.CreateVM{
+						BootFromVolume: &bootFromVolume,
+						EphemeralDisk:  ephemeralDisk,
+					},
+					flavors.Flavor{
+						Disk: 999,
+					},
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(len(volumes)).To(Equal(2))
+				Expect(volumes[0].DestinationType).To(Equal(bootfromvolume.DestinationVolume))
+				Expect(volumes[0].BootIndex).To(Equal(0))
+				Expect(volumes[1].SourceType).To(Equal(bootfromvolume.SourceBlank))
+				Expect(volumes[1].VolumeSize).To(Equal(20))
+				Expect(volumes[1].BootIndex).To(Equal(-1))
+				Expect(volumes[1].DeleteOnTermination).To(BeFalse())
+			})
+		})
+
 		//It("return error if list flavors fails", func() {
 		//	computeFacade.ListFlavorsReturns(nil, errors.New("boom"))
 		//
@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"io"
 	"io/fs"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/gophercloud/gophercloud"
 )
@@ -31,29 +33,38 @@ type Properties struct {
 }
 
 type OpenstackConfig struct {
-	AuthURL                      string   `json:"auth_url"`
-	Username                     string   `json:"username"`
-	APIKey                       string   `json:"api_key"`
-	ApplicationCredentialID      string   `json:"application_credential_id"`
-	ApplicationCredentialSecret  string   `json:"application_credential_secret"`
-	Region                       string   `json:"region"`
-	EndpointType                 string   `json:"endpoint_type"`
-	DefaultKeyName               string   `json:"default_key_name"`
-	DefaultSecurityGroups        []string `json:"default_security_groups"`
-	DefaultVolumeType            string   `json:"default_volume_type"`
-	WaitResourcePollInterval     int      `json:"wait_resource_poll_interval"`
-	BootFromVolume               bool     `json:"boot_from_volume"`
-	ConfigDrive                  string   `json:"config_drive"`
-	UseDHCP                      bool     `json:"use_dhcp"`
-	IgnoreServerAvailabilityZone bool     `json:"ignore_server_availability_zone"`
-	HumanReadableVMNames         bool     `json:"human_readable_vm_names"`
-	UseNovaNetworking            bool     `json:"use_nova_networking"`
-	ConnectionOptions            string   `json:"connection_options"`
-	DomainName                   string   `json:"domain"`
-	ProjectName                  string   `json:"project"`
-	Tenant                       string   `json:"tenant"`
-	StateTimeOut                 int      `json:"state_timeout"`
-	StemcellPubliclyVisible      bool     `json:"stemcell_public_visibility"`
+	AuthURL                      string                            `json:"auth_url"`
+	Username                     string                            `json:"username"`
+	APIKey                       string                            `json:"api_key"`
+	ApplicationCredentialID      string                            `json:"application_credential_id"`
+	ApplicationCredentialSecret  string                            `json:"application_credential_secret"`
+	Region                       string                            `json:"region"`
+	EndpointType                 string                            `json:"endpoint_type"`
+	DefaultKeyName               string                            `json:"default_key_name"`
+	DefaultSecurityGroups        []string                          `json:"default_security_groups"`
+	DefaultVolumeType            string                            `json:"default_volume_type"`
+	WaitResourcePollInterval     int                               `json:"wait_resource_poll_interval"`
+	BootFromVolume               bool                              `json:"boot_from_volume"`
+	ConfigDrive                  string                            `json:"config_drive"`
+	UseDHCP                      bool                              `json:"use_dhcp"`
+	IgnoreServerAvailabilityZone bool                              `json:"ignore_server_availability_zone"`
+	HumanReadableVMNames         bool                              `json:"human_readable_vm_names"`
+	UseNovaNetworking            bool                              `json:"use_nova_networking"`
+	ConnectionOptions            string                            `json:"connection_options"`
+	DomainName                   string                            `json:"domain"`
+	ProjectName                  string                            `json:"project"`
+	Tenant                       string                            `json:"tenant"`
+	StateTimeOut                 int                               `json:"state_timeout"`
+	StemcellPubliclyVisible      bool                              `json:"stemcell_public_visibility"`
+	PortConflictPolicy           string                            `json:"port_conflict_policy,omitempty"`
+	PreservePorts                bool                              `json:"preserve_ports,omitempty"`
+	ParkedPortExpiryHours        int                               `json:"parked_port_expiry_hours,omitempty"`
+	UseSubnetNetworkSettings     bool                              `json:"use_subnet_network_settings,omitempty"`
+	VolumeAvailabilityZones      map[string]VolumeAvailabilityZone `json:"volume_availability_zones,omitempty"`
+	DiskHintFormats              map[int]string                    `json:"disk_hint_formats,omitempty"`
+	DiskBus                      string                            `json:"disk_bus,omitempty"`
+	PropagateVMMetadata          bool                              `json:"propagate_vm_metadata,omitempty"`
+	PropagatedVMMetadataKeys     []string                          `json:"propagated_vm_metadata_keys,omitempty"`
 	VM                           struct {
 		Stemcell struct {
 			APIVersion int `json:"api_version"`
@@ -61,6 +72,99 @@ type OpenstackConfig struct {
 	} `json:"vm"`
 }
 
+const (
+	PortConflictPolicyNever         = "never"
+	PortConflictPolicyBoshOwnedOnly = "bosh_owned_only"
+	PortConflictPolicyAnyUnbound    = "any_unbound"
+)
+
+// GetPortConflictPolicy returns which unbound ports holding a requested IP may be deleted.
+// It defaults to 'any_unbound', which deletes any port without device.
+func (o OpenstackConfig) GetPortConflictPolicy() string {
+	if o.PortConflictPolicy == "" {
+		return PortConflictPolicyAnyUnbound
+	}
+
+	return o.PortConflictPolicy
+}
+
+// defaultParkedPortExpiry is the time after which ports kept with 'preserve_ports' are deleted if no VM adopted them.
+const defaultParkedPortExpiry = 7 * 24 * time.Hour
+
+// GetParkedPortExpiry returns the time after which parked ports are garbage-collected.
+func (o OpenstackConfig) GetParkedPortExpiry() time.Duration {
+	if o.ParkedPortExpiryHours == 0 {
+		return defaultParkedPortExpiry
+	}
+
+	return time.Duration(o.ParkedPortExpiryHours) * time.Hour
+}
+
+// VolumeAvailabilityZone is the Cinder availability zone and the volume type used for the volumes
+// of servers in a Nova availability zone.
+type VolumeAvailabilityZone struct {
+	AvailabilityZone string `json:"availability_zone,omitempty"`
+	VolumeType       string `json:"volume_type,omitempty"`
+}
+
+// GetVolumeAvailabilityZone returns the volume availability zone and volume type mapped to a compute
+// availability zone. Without mapping, the volume availability zone has the name of the compute one.
+func (o OpenstackConfig) GetVolumeAvailabilityZone(computeAvailabilityZone string) VolumeAvailabilityZone {
+	volumeAvailabilityZone := o.VolumeAvailabilityZones[computeAvailabilityZone]
+	if volumeAvailabilityZone.AvailabilityZone == "" {
+		volumeAvailabilityZone.AvailabilityZone = computeAvailabilityZone
+	}
+
+	return volumeAvailabilityZone
+}
+
+// GetComputeAvailabilityZones returns the compute availability zones whose volumes are placed into a volume
+// availability zone. Without mapping to it, the compute availability zone has the name of the volume one.
+func (o OpenstackConfig) GetComputeAvailabilityZones(volumeAvailabilityZone string) []string {
+	var computeAvailabilityZones []string
+	for computeAvailabilityZone := range o.VolumeAvailabilityZones {
+		if o.GetVolumeAvailabilityZone(computeAvailabilityZone).AvailabilityZone == volumeAvailabilityZone {
+			computeAvailabilityZones = append(computeAvailabilityZones, computeAvailabilityZone)
+		}
+	}
+
+	if len(computeAvailabilityZones) == 0 {
+		return []string{volumeAvailabilityZone}
+	}
+
+	slices.Sort(computeAvailabilityZones)
+	return computeAvailabilityZones
+}
+
+const (
+	DiskHintFormatString = "string"
+	DiskHintFormatMap    = "map"
+)
+
+// GetDiskHintFormat returns the format of the disk hints returned to the director for a stemcell API version.
+// Stemcells with API version 2 or later resolve the device from the volume ID of a map hint by default.
+func (o OpenstackConfig) GetDiskHintFormat(stemcellAPIVersion int) string {
+	if diskHintFormat, ok := o.DiskHintFormats[stemcellAPIVersion]; ok {
+		return diskHintFormat
+	}
+
+	if stemcellAPIVersion >= 2 {
+		return DiskHintFormatMap
+	}
+
+	return DiskHintFormatString
+}
+
+// PropagatesVMMetadataKey reports whether a VM metadata key is set on the ports, floating IPs, boot volume
+// and load balancer pool members of the VM. All keys are propagated unless a subset is configured.
+func (o OpenstackConfig) PropagatesVMMetadataKey(key string) bool {
+	if !o.PropagateVMMetadata {
+		return false
+	}
+
+	return len(o.PropagatedVMMetadataKeys) == 0 || slices.Contains(o.PropagatedVMMetadataKeys, key)
+}
+
 type RetryConfigMap map[string]RetryConfig
 
 func (r RetryConfigMap) Default() RetryConfig {
@@ -111,6 +215,37 @@ func (o OpenstackConfig) Validate() error {
 		return fmt.Errorf("invalid OpenStack cloud properties: config_drive must be either 'cdrom' or 'disk'")
 	}
 
+	portConflictPolicies := []string{PortConflictPolicyNever, PortConflictPolicyBoshOwnedOnly, PortConflictPolicyAnyUnbound}
+	if !slices.Contains(portConflictPolicies, o.GetPortConflictPolicy()) {
+		return fmt.Errorf("invalid OpenStack cloud properties: port_conflict_policy must be one of %v", portConflictPolicies)
+	}
+
+	if o.ParkedPortExpiryHours < 0 {
+		return fmt.Errorf("invalid OpenStack cloud properties: parked_port_expiry_hours must not be negative")
+	}
+
+	for computeAvailabilityZone, volumeAvailabilityZone := range o.VolumeAvailabilityZones {
+		if computeAvailabilityZone == "" {
+			return fmt.Errorf("invalid OpenStack cloud properties: volume_availability_zones must not map an empty availability zone")
+		}
+
+		if volumeAvailabilityZone == (VolumeAvailabilityZone{}) {
+			return fmt.Errorf("invalid OpenStack cloud properties: volume_availability_zones must map availability zone '%s' to an availability_zone or volume_type", computeAvailabilityZone)
+		}
+	}
+
+	diskBuses := []string{"virtio", "scsi", "sata", "ide", "usb", "xen"}
+	if o.DiskBus != "" && !slices.Contains(diskBuses, o.DiskBus) {
+		return fmt.Errorf("invalid OpenStack cloud properties: disk_bus must be one of %v", diskBuses)
+	}
+
+	diskHintFormats := []string{DiskHintFormatString, DiskHintFormatMap}
+	for stemcellAPIVersion, diskHintFormat := range o.DiskHintFormats {
+		if !slices.Contains(diskHintFormats, diskHintFormat) {
+			return fmt.Errorf("invalid OpenStack cloud properties: disk_hint_formats of stemcell API version %d must be one of %v", stemcellAPIVersion, diskHintFormats)
+		}
+	}
+
 	return nil
 }
 
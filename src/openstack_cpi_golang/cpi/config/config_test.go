@@ -2,6 +2,7 @@ package config_test
 
 import (
 	"testing/fstest"
+	"time"
 
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/config"
 	. "github.com/onsi/ginkgo/v2"
@@ -59,6 +60,88 @@ var _ = Describe("OpenstackConfig", func() {
 						}
 					}`),
 			},
+			"some/path/volume_availability_zones.json": &fstest.MapFile{
+				Data: []byte(`{
+						"cloud": {
+							"properties": {
+								"openstack": {
+									"application_credential_id": "the_application_credential_id",
+									"application_credential_secret": "the_application_credential_secret",
+									"volume_availability_zones": {
+										"az1-compute": {"availability_zone": "az1-storage", "volume_type": "az1-ssd"}
+									}
+								}
+							}
+						}
+					}`),
+			},
+			"some/path/invalid_volume_availability_zones.json": &fstest.MapFile{
+				Data: []byte(`{
+						"cloud": {
+							"properties": {
+								"openstack": {
+									"application_credential_id": "the_application_credential_id",
+									"application_credential_secret": "the_application_credential_secret",
+									"volume_availability_zones": {
+										"az1-compute": {}
+									}
+								}
+							}
+						}
+					}`),
+			},
+			"some/path/disk_hint_formats.json": &fstest.MapFile{
+				Data: []byte(`{
+						"cloud": {
+							"properties": {
+								"openstack": {
+									"application_credential_id": "the_application_credential_id",
+									"application_credential_secret": "the_application_credential_secret",
+									"disk_hint_formats": {"2": "string", "3": "map"}
+								}
+							}
+						}
+					}`),
+			},
+			"some/path/invalid_disk_hint_formats.json": &fstest.MapFile{
+				Data: []byte(`{
+						"cloud": {
+							"properties": {
+								"openstack": {
+									"application_credential_id": "the_application_credential_id",
+									"application_credential_secret": "the_application_credential_secret",
+									"disk_hint_formats": {"2": "path"}
+								}
+							}
+						}
+					}`),
+			},
+			"some/path/invalid_disk_bus.json": &fstest.MapFile{
+				Data: []byte(`{
+						"cloud": {
+							"properties": {
+								"openstack": {
+									"application_credential_id": "the_application_credential_id",
+									"application_credential_secret": "the_application_credential_secret",
+									"disk_bus": "nvme"
+								}
+							}
+						}
+					}`),
+			},
+			"some/path/invalid_port_conflict_policy.json": &fstest.MapFile{
+				Data: []byte(`{
+						"cloud": {
+							"properties": {
+								"openstack": {
+									"application_credential_id": "the_application_credential_id",
+									"application_credential_secret": "the_application_credential_secret",
+									"port_conflict_policy": "always"
+								}
+							}
+						}
+					}`),
+			},
 			"some/path/disk_config_drive.json": &fstest.MapFile{
 				Data: []byte(`{
 						"cloud": {
@@ -224,6 +307,89 @@ var _ = Describe("OpenstackConfig", func() {
 				Expect(err.Error()).To(ContainSubstring("invalid OpenStack cloud properties: config_drive must be either 'cdrom' or 'disk'"))
 			})
 
+			It("defaults the port conflict policy to any_unbound", func() {
+				cpiConfig, err := config.NewConfigFromPath(fileSystem, "some/path/disk_config_drive.json")
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(cpiConfig.Cloud.Properties.Openstack.GetPortConflictPolicy()).To(Equal(config.PortConflictPolicyAnyUnbound))
+			})
+
+			It("returns an error if the port conflict policy is invalid", func() {
+				_, err := config.NewConfigFromPath(fileSystem, "some/path/invalid_port_conflict_policy.json")
+
+				Expect(err.Error()).To(ContainSubstring("invalid OpenStack cloud properties: port_conflict_policy must be one of [never bosh_owned_only any_unbound]"))
+			})
+
+			It("defaults the parked port expiry to a week", func() {
+				Expect(config.OpenstackConfig{}.GetParkedPortExpiry()).To(Equal(7 * 24 * time.Hour))
+				Expect(config.OpenstackConfig{ParkedPortExpiryHours: 2}.GetParkedPortExpiry()).To(Equal(2 * time.Hour))
+			})
+
+			It("maps compute availability zones to volume availability zones and volume types", func() {
+				cpiConfig, err := config.NewConfigFromPath(fileSystem, "some/path/volume_availability_zones.json")
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(cpiConfig.Cloud.Properties.Openstack.GetVolumeAvailabilityZone("az1-compute")).To(Equal(
+					config.VolumeAvailabilityZone{AvailabilityZone: "az1-storage", VolumeType: "az1-ssd"}))
+				Expect(cpiConfig.Cloud.Properties.Openstack.GetVolumeAvailabilityZone("az2")).To(Equal(
+					config.VolumeAvailabilityZone{AvailabilityZone: "az2"}))
+			})
+
+			It("returns the compute availability zones mapped to a volume availability zone", func() {
+				openstackConfig := config.OpenstackConfig{VolumeAvailabilityZones: map[string]config.VolumeAvailabilityZone{
+					"az1-compute-b": {AvailabilityZone: "az1-storage"},
+					"az1-compute-a": {AvailabilityZone: "az1-storage"},
+					"az2":           {VolumeType: "az2-ssd"},
+				}}
+
+				Expect(openstackConfig.GetComputeAvailabilityZones("az1-storage")).To(Equal([]string{"az1-compute-a", "az1-compute-b"}))
+				Expect(openstackConfig.GetComputeAvailabilityZones("az2")).To(Equal([]string{"az2"}))
+				Expect(openstackConfig.GetComputeAvailabilityZones("az3")).To(Equal([]string{"az3"}))
+			})
+
+			It("returns an error if a volume availability zone mapping is empty", func() {
+				_, err := config.NewConfigFromPath(fileSystem, "some/path/invalid_volume_availability_zones.json")
+
+				Expect(err.Error()).To(ContainSubstring("invalid OpenStack cloud properties: volume_availability_zones must map availability zone 'az1-compute' to an availability_zone or volume_type"))
+			})
+
+			It("returns the disk hint format configured for a stemcell API version", func() {
+				cpiConfig, err := config.NewConfigFromPath(fileSystem, "some/path/disk_hint_formats.json")
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(cpiConfig.Cloud.Properties.Openstack.GetDiskHintFormat(2)).To(Equal(config.DiskHintFormatString))
+				Expect(cpiConfig.Cloud.Properties.Openstack.GetDiskHintFormat(3)).To(Equal(config.DiskHintFormatMap))
+			})
+
+			It("defaults the disk hint format to a map for stemcell API version 2 or later", func() {
+				Expect(config.OpenstackConfig{}.GetDiskHintFormat(1)).To(Equal(config.DiskHintFormatString))
+				Expect(config.OpenstackConfig{}.GetDiskHintFormat(2)).To(Equal(config.DiskHintFormatMap))
+			})
+
+			It("returns an error if a disk hint format is unknown", func() {
+				_, err := config.NewConfigFromPath(fileSystem, "some/path/invalid_disk_hint_formats.json")
+
+				Expect(err.Error()).To(ContainSubstring("invalid OpenStack cloud properties: disk_hint_formats of stemcell API version 2 must be one of [string map]"))
+			})
+
+			It("returns an error if the disk bus is unknown", func() {
+				_, err := config.NewConfigFromPath(fileSystem, "some/path/invalid_disk_bus.json")
+
+				Expect(err.Error()).To(ContainSubstring("invalid OpenStack cloud properties: disk_bus must be one of [virtio scsi sata ide usb xen]"))
+			})
+
+			It("propagates no VM metadata by default", func() {
+				Expect(config.OpenstackConfig{}.PropagatesVMMetadataKey("name")).To(BeFalse())
+			})
+
+			It("propagates all VM metadata keys unless a subset is configured", func() {
+				Expect(config.OpenstackConfig{PropagateVMMetadata: true}.PropagatesVMMetadataKey("name")).To(BeTrue())
+
+				openstackConfig := config.OpenstackConfig{PropagateVMMetadata: true, PropagatedVMMetadataKeys: []string{"deployment"}}
+				Expect(openstackConfig.PropagatesVMMetadataKey("deployment")).To(BeTrue())
+				Expect(openstackConfig.PropagatesVMMetadataKey("name")).To(BeFalse())
+			})
+
 			It("returns an error if config is empty", func() {
 				_, err := config.NewConfigFromPath(fileSystem, "some/path/empty_config.json")
 
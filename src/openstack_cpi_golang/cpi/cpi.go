@@ -2,7 +2,9 @@ package cpi
 
 import (
 	"fmt"
+	"os"
 
+	"github.com/cloudfoundry/bosh-cpi-go/apiv1"
 	"github.com/cloudfoundry/bosh-cpi-go/rpc"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/config"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils"
@@ -10,9 +12,12 @@ import (
 
 func Execute(cpiConfig config.CpiConfig, cpiLogger utils.Logger) error {
 
-	cli := rpc.NewFactory(cpiLogger.TargetLogger()).NewCLI(
-		NewFactory(cpiConfig, cpiLogger),
+	dispatcher := rpc.NewJSONDispatcher(
+		apiv1.NewActionFactory(NewFactory(cpiConfig, cpiLogger)),
+		cloudErrorCaller{caller: rpc.NewJSONCaller()},
+		cpiLogger.TargetLogger(),
 	)
+	cli := rpc.NewCLI(os.Stdin, os.Stdout, dispatcher, cpiLogger.TargetLogger())
 
 	err := cli.ServeOnce()
 	if err != nil {
@@ -21,3 +26,14 @@ func Execute(cpiConfig config.CpiConfig, cpiLogger utils.Logger) error {
 
 	return nil
 }
+
+// cloudErrorCaller passes the type and retry flag of classified errors to the dispatcher.
+type cloudErrorCaller struct {
+	caller rpc.Caller
+}
+
+func (c cloudErrorCaller) Call(action interface{}, args []interface{}) (interface{}, error) {
+	result, err := c.caller.Call(action, args)
+
+	return result, utils.ClassifyCloudError(err)
+}
@@ -83,6 +83,7 @@ func (f Factory) New(ctx apiv1.CallContext) (apiv1.CPI, error) {
 			network.NewNetworkServiceBuilder(openstackService, f.cpiConfig, f.logger),
 			compute.NewComputeServiceBuilder(openstackService, f.cpiConfig, f.logger),
 			loadbalancer.NewLoadbalancerServiceBuilder(openstackService, f.cpiConfig, f.logger),
+			volume.NewVolumeServiceBuilder(openstackService, f.cpiConfig, f.logger),
 			f.cpiConfig,
 			f.logger,
 		),
@@ -114,6 +115,9 @@ func (f Factory) New(ctx apiv1.CallContext) (apiv1.CPI, error) {
 
 		methods.NewSetVMMetadataMethod(
 			compute.NewComputeServiceBuilder(openstackService, f.cpiConfig, f.logger),
+			network.NewNetworkServiceBuilder(openstackService, f.cpiConfig, f.logger),
+			volume.NewVolumeServiceBuilder(openstackService, f.cpiConfig, f.logger),
+			loadbalancer.NewLoadbalancerServiceBuilder(openstackService, f.cpiConfig, f.logger),
 			f.logger,
 			f.cpiConfig),
 		methods.NewGetDisksMethod(
@@ -131,6 +135,7 @@ func (f Factory) New(ctx apiv1.CallContext) (apiv1.CPI, error) {
 			f.logger,
 		),
 		methods.NewAttachDiskMethod(
+			image.NewImageServiceBuilder(openstackService, f.cpiConfig, f.logger),
 			compute.NewComputeServiceBuilder(openstackService, f.cpiConfig, f.logger),
 			volume.NewVolumeServiceBuilder(openstackService, f.cpiConfig, f.logger),
 			f.cpiConfig,
@@ -26,6 +26,10 @@ type ImageService interface {
 		imageID string,
 	) (string, error)
 
+	GetImageProperties(
+		imageID string,
+	) (map[string]interface{}, error)
+
 	UploadImage(
 		imageID string,
 		imageFilePath string,
@@ -82,6 +86,16 @@ func (c imageService) GetImage(imageID string) (string, error) {
 	return image.ID, nil
 }
 
+// GetImageProperties returns the additional properties of an image, e.g. 'hw_disk_bus'.
+func (c imageService) GetImageProperties(imageID string) (map[string]interface{}, error) {
+	image, err := c.imagesFacade.GetImage(c.serviceClients.RetryableServiceClient, imageID)
+	if err != nil {
+		return nil, fmt.Errorf("could not find the image '%s' in OpenStack: %w", imageID, err)
+	}
+
+	return image.Properties, nil
+}
+
 func (c imageService) UploadImage(imageID string, imageFilePath string) error {
 	imageData, err := os.ReadFile(imageFilePath)
 	if err != nil {
@@ -239,6 +239,30 @@ var _ = Describe("ImageService", func() {
 
 	})
 
+	Context("GetImageProperties", func() {
+
+		It("returns the properties of the image", func() {
+			imagesFacade.GetImageReturns(&images.Image{ID: "123-456", Properties: map[string]interface{}{"hw_disk_bus": "virtio"}}, nil)
+
+			imageProperties, err := image.NewImageService(serviceClients, &imagesFacade, &httpClient, &logger).
+				GetImageProperties("123-456")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(imageProperties).To(Equal(map[string]interface{}{"hw_disk_bus": "virtio"}))
+			_, imageID := imagesFacade.GetImageArgsForCall(0)
+			Expect(imageID).To(Equal("123-456"))
+		})
+
+		It("returns an error if the image cannot be found in OpenStack", func() {
+			imagesFacade.GetImageReturns(nil, errors.New("boom"))
+
+			_, err := image.NewImageService(serviceClients, &imagesFacade, &httpClient, &logger).
+				GetImageProperties("123-456")
+
+			Expect(err.Error()).To(Equal("could not find the image '123-456' in OpenStack: boom"))
+		})
+	})
+
 	Context("UploadImage", func() {
 		BeforeEach(func() {
 
@@ -48,6 +48,19 @@ type FakeImageService struct {
 		result1 string
 		result2 error
 	}
+	GetImagePropertiesStub        func(string) (map[string]interface{}, error)
+	getImagePropertiesMutex       sync.RWMutex
+	getImagePropertiesArgsForCall []struct {
+		arg1 string
+	}
+	getImagePropertiesReturns struct {
+		result1 map[string]interface{}
+		result2 error
+	}
+	getImagePropertiesReturnsOnCall map[int]struct {
+		result1 map[string]interface{}
+		result2 error
+	}
 	UploadImageStub        func(string, string) error
 	uploadImageMutex       sync.RWMutex
 	uploadImageArgsForCall []struct {
@@ -254,6 +267,70 @@ func (fake *FakeImageService) GetImageReturnsOnCall(i int, result1 string, resul
 	}{result1, result2}
 }
 
+func (fake *FakeImageService) GetImageProperties(arg1 string) (map[string]interface{}, error) {
+	fake.getImagePropertiesMutex.Lock()
+	ret, specificReturn := fake.getImagePropertiesReturnsOnCall[len(fake.getImagePropertiesArgsForCall)]
+	fake.getImagePropertiesArgsForCall = append(fake.getImagePropertiesArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.GetImagePropertiesStub
+	fakeReturns := fake.getImagePropertiesReturns
+	fake.recordInvocation("GetImageProperties", []interface{}{arg1})
+	fake.getImagePropertiesMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeImageService) GetImagePropertiesCallCount() int {
+	fake.getImagePropertiesMutex.RLock()
+	defer fake.getImagePropertiesMutex.RUnlock()
+	return len(fake.getImagePropertiesArgsForCall)
+}
+
+func (fake *FakeImageService) GetImagePropertiesCalls(stub func(string) (map[string]interface{}, error)) {
+	fake.getImagePropertiesMutex.Lock()
+	defer fake.getImagePropertiesMutex.Unlock()
+	fake.GetImagePropertiesStub = stub
+}
+
+func (fake *FakeImageService) GetImagePropertiesArgsForCall(i int) string {
+	fake.getImagePropertiesMutex.RLock()
+	defer fake.getImagePropertiesMutex.RUnlock()
+	argsForCall := fake.getImagePropertiesArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeImageService) GetImagePropertiesReturns(result1 map[string]interface{}, result2 error) {
+	fake.getImagePropertiesMutex.Lock()
+	defer fake.getImagePropertiesMutex.Unlock()
+	fake.GetImagePropertiesStub = nil
+	fake.getImagePropertiesReturns = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeImageService) GetImagePropertiesReturnsOnCall(i int, result1 map[string]interface{}, result2 error) {
+	fake.getImagePropertiesMutex.Lock()
+	defer fake.getImagePropertiesMutex.Unlock()
+	fake.GetImagePropertiesStub = nil
+	if fake.getImagePropertiesReturnsOnCall == nil {
+		fake.getImagePropertiesReturnsOnCall = make(map[int]struct {
+			result1 map[string]interface{}
+			result2 error
+		})
+	}
+	fake.getImagePropertiesReturnsOnCall[i] = struct {
+		result1 map[string]interface{}
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeImageService) UploadImage(arg1 string, arg2 string) error {
 	fake.uploadImageMutex.Lock()
 	ret, specificReturn := fake.uploadImageReturnsOnCall[len(fake.uploadImageArgsForCall)]
@@ -319,14 +396,6 @@ func (fake *FakeImageService) UploadImageReturnsOnCall(i int, result1 error) {
 func (fake *FakeImageService) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.createImageMutex.RLock()
-	defer fake.createImageMutex.RUnlock()
-	fake.deleteImageMutex.RLock()
-	defer fake.deleteImageMutex.RUnlock()
-	fake.getImageMutex.RLock()
-	defer fake.getImageMutex.RUnlock()
-	fake.uploadImageMutex.RLock()
-	defer fake.uploadImageMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
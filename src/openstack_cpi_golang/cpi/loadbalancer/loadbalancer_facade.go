@@ -27,6 +27,10 @@ type LoadbalancerFacade interface {
 	CreatePoolMember(client utils.ServiceClient, poolID string, opts pools.CreateMemberOpts) (*pools.Member, error)
 
 	DeletePoolMember(client utils.RetryableServiceClient, poolID string, memberID string) error
+
+	GetPoolMember(client utils.RetryableServiceClient, poolID string, memberID string) (*pools.Member, error)
+
+	UpdatePoolMember(client utils.ServiceClient, poolID string, memberID string, opts pools.UpdateMemberOpts) (*pools.Member, error)
 }
 
 type loadbalancerFacade struct {
@@ -71,3 +75,11 @@ func (l loadbalancerFacade) CreatePoolMember(client utils.ServiceClient, poolID
 func (l loadbalancerFacade) DeletePoolMember(client utils.RetryableServiceClient, poolID string, memberID string) error {
 	return pools.DeleteMember(client, poolID, memberID).ExtractErr()
 }
+
+func (l loadbalancerFacade) GetPoolMember(client utils.RetryableServiceClient, poolID string, memberID string) (*pools.Member, error) {
+	return pools.GetMember(client, poolID, memberID).Extract()
+}
+
+func (l loadbalancerFacade) UpdatePoolMember(client utils.ServiceClient, poolID string, memberID string, opts pools.UpdateMemberOpts) (*pools.Member, error) {
+	return pools.UpdateMember(client, poolID, memberID, opts).Extract()
+}
@@ -21,6 +21,8 @@ type LoadbalancerService interface {
 	CreatePoolMember(pool pools.Pool, ip string, poolProperties properties.LoadbalancerPool, subnetID string, timeout int) (*pools.Member, error)
 
 	DeletePoolMember(poolID string, memberID string, timeout int) error
+
+	UpdatePoolMemberName(poolID string, memberID string, name string, timeout int) error
 }
 
 type loadbalancerService struct {
@@ -174,6 +176,53 @@ func (l loadbalancerService) DeletePoolMember(poolID string, memberID string, st
 	return nil
 }
 
+// UpdatePoolMemberName names a pool member, e.g. after the VM of the member. Members which already have the name are not updated.
+func (l loadbalancerService) UpdatePoolMemberName(poolID string, memberID string, name string, stateTimeOut int) error {
+	var errDefault404 gophercloud.ErrDefault404
+
+	member, err := l.loadbalancerFacade.GetPoolMember(l.serviceClients.RetryableServiceClient, poolID, memberID)
+	if err != nil {
+		if errors.As(err, &errDefault404) {
+			l.logger.Info("loadbalancer_service", fmt.Sprintf("SKIPPING update: pool member with id '%s' in pool '%s' is not found", memberID, poolID))
+			return nil
+		}
+		return fmt.Errorf("failed to get pool member with ID '%s' in pool '%s': %w", memberID, poolID, err)
+	}
+
+	if member.Name == name {
+		return nil
+	}
+
+	pool, err := l.loadbalancerFacade.GetPool(l.serviceClients.RetryableServiceClient, poolID)
+	if err != nil {
+		return fmt.Errorf("failed to get pool with ID '%s': %w", poolID, err)
+	}
+
+	loadbalancerId, err := l.getLoadbalancerId(*pool)
+	if err != nil {
+		return fmt.Errorf("failed to get loadbalancer ID: %w", err)
+	}
+
+	timeoutDuration := time.Duration(stateTimeOut) * time.Second
+	_, err = l.waitForLoadbalancerToBecomeActive(loadbalancerId, timeoutDuration)
+	if err != nil {
+		return fmt.Errorf("failed while waiting for loadbalancer to become active: %w", err)
+	}
+
+	_, err = l.loadbalancerFacade.UpdatePoolMember(l.serviceClients.ServiceClient, poolID, memberID, pools.UpdateMemberOpts{Name: &name})
+	if err != nil {
+		return fmt.Errorf("failed to update pool member with ID '%s' in pool '%s': %w", memberID, poolID, err)
+	}
+	l.logger.Info("loadbalancer_service", fmt.Sprintf("Renamed pool member with id '%s' in pool '%s' to '%s'", memberID, poolID, name))
+
+	_, err = l.waitForLoadbalancerToBecomeActive(loadbalancerId, timeoutDuration)
+	if err != nil {
+		return fmt.Errorf("failed while waiting for loadbalancer '%s' to become active: %w", loadbalancerId, err)
+	}
+
+	return nil
+}
+
 func (l loadbalancerService) createPoolMember(loadbalancerID string, poolID string, createMemberOpts pools.CreateMemberOpts, timeout time.Duration) (*pools.Member, error) {
 	_, err := l.waitForLoadbalancerToBecomeActive(loadbalancerID, timeout)
 	if err != nil {
@@ -399,4 +399,61 @@ var _ = Describe("LoadbalancerService", func() {
 		})
 	})
 
+	Context("UpdatePoolMemberName", func() {
+		BeforeEach(func() {
+			loadbalancerFacade.GetLoadbalancerReturns(&loadbalancers.LoadBalancer{ID: "the-lb-id", ProvisioningStatus: "ACTIVE"}, nil)
+			loadbalancerFacade.GetPoolMemberReturns(&mockMember, nil)
+		})
+
+		It("names the pool member", func() {
+			err := loadbalancer.NewLoadbalancerService(serviceClients, &loadbalancerFacade, &logger).
+				UpdatePoolMemberName("pool-id", "the-member-id", "the-job/the-id", 1)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loadbalancerFacade.UpdatePoolMemberCallCount()).To(Equal(1))
+			_, poolID, memberID, updateOpts := loadbalancerFacade.UpdatePoolMemberArgsForCall(0)
+			Expect(poolID).To(Equal("pool-id"))
+			Expect(memberID).To(Equal("the-member-id"))
+			Expect(*updateOpts.Name).To(Equal("the-job/the-id"))
+			Expect(loadbalancerFacade.GetLoadbalancerCallCount()).To(Equal(2))
+		})
+
+		It("does not update a pool member which already has the name", func() {
+			mockMember.Name = "the-job/the-id"
+
+			err := loadbalancer.NewLoadbalancerService(serviceClients, &loadbalancerFacade, &logger).
+				UpdatePoolMemberName("pool-id", "the-member-id", "the-job/the-id", 1)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loadbalancerFacade.UpdatePoolMemberCallCount()).To(Equal(0))
+		})
+
+		It("skips pool members which are not found", func() {
+			loadbalancerFacade.GetPoolMemberReturns(nil, gophercloud.ErrDefault404{})
+
+			err := loadbalancer.NewLoadbalancerService(serviceClients, &loadbalancerFacade, &logger).
+				UpdatePoolMemberName("pool-id", "the-member-id", "the-job/the-id", 1)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(loadbalancerFacade.UpdatePoolMemberCallCount()).To(Equal(0))
+		})
+
+		It("returns an error if getting the pool member fails", func() {
+			loadbalancerFacade.GetPoolMemberReturns(nil, errors.New("boom"))
+
+			err := loadbalancer.NewLoadbalancerService(serviceClients, &loadbalancerFacade, &logger).
+				UpdatePoolMemberName("pool-id", "the-member-id", "the-job/the-id", 1)
+
+			Expect(err.Error()).To(Equal("failed to get pool member with ID 'the-member-id' in pool 'pool-id': boom"))
+		})
+
+		It("returns an error if updating the pool member fails", func() {
+			loadbalancerFacade.UpdatePoolMemberReturns(nil, errors.New("boom"))
+
+			err := loadbalancer.NewLoadbalancerService(serviceClients, &loadbalancerFacade, &logger).
+				UpdatePoolMemberName("pool-id", "the-member-id", "the-job/the-id", 1)
+
+			Expect(err.Error()).To(Equal("failed to update pool member with ID 'the-member-id' in pool 'pool-id': boom"))
+		})
+	})
 })
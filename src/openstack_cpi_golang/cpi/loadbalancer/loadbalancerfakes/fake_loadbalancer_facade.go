@@ -109,6 +109,21 @@ type FakeLoadbalancerFacade struct {
 		result1 *pools.Pool
 		result2 error
 	}
+	GetPoolMemberStub        func(utils.RetryableServiceClient, string, string) (*pools.Member, error)
+	getPoolMemberMutex       sync.RWMutex
+	getPoolMemberArgsForCall []struct {
+		arg1 utils.RetryableServiceClient
+		arg2 string
+		arg3 string
+	}
+	getPoolMemberReturns struct {
+		result1 *pools.Member
+		result2 error
+	}
+	getPoolMemberReturnsOnCall map[int]struct {
+		result1 *pools.Member
+		result2 error
+	}
 	ListPoolMembersStub        func(utils.RetryableServiceClient, string, pools.ListMembersOpts) (pagination.Page, error)
 	listPoolMembersMutex       sync.RWMutex
 	listPoolMembersArgsForCall []struct {
@@ -138,6 +153,22 @@ type FakeLoadbalancerFacade struct {
 		result1 pagination.Page
 		result2 error
 	}
+	UpdatePoolMemberStub        func(utils.ServiceClient, string, string, pools.UpdateMemberOpts) (*pools.Member, error)
+	updatePoolMemberMutex       sync.RWMutex
+	updatePoolMemberArgsForCall []struct {
+		arg1 utils.ServiceClient
+		arg2 string
+		arg3 string
+		arg4 pools.UpdateMemberOpts
+	}
+	updatePoolMemberReturns struct {
+		result1 *pools.Member
+		result2 error
+	}
+	updatePoolMemberReturnsOnCall map[int]struct {
+		result1 *pools.Member
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -594,6 +625,72 @@ func (fake *FakeLoadbalancerFacade) GetPoolReturnsOnCall(i int, result1 *pools.P
 	}{result1, result2}
 }
 
+func (fake *FakeLoadbalancerFacade) GetPoolMember(arg1 utils.RetryableServiceClient, arg2 string, arg3 string) (*pools.Member, error) {
+	fake.getPoolMemberMutex.Lock()
+	ret, specificReturn := fake.getPoolMemberReturnsOnCall[len(fake.getPoolMemberArgsForCall)]
+	fake.getPoolMemberArgsForCall = append(fake.getPoolMemberArgsForCall, struct {
+		arg1 utils.RetryableServiceClient
+		arg2 string
+		arg3 string
+	}{arg1, arg2, arg3})
+	stub := fake.GetPoolMemberStub
+	fakeReturns := fake.getPoolMemberReturns
+	fake.recordInvocation("GetPoolMember", []interface{}{arg1, arg2, arg3})
+	fake.getPoolMemberMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeLoadbalancerFacade) GetPoolMemberCallCount() int {
+	fake.getPoolMemberMutex.RLock()
+	defer fake.getPoolMemberMutex.RUnlock()
+	return len(fake.getPoolMemberArgsForCall)
+}
+
+func (fake *FakeLoadbalancerFacade) GetPoolMemberCalls(stub func(utils.RetryableServiceClient, string, string) (*pools.Member, error)) {
+	fake.getPoolMemberMutex.Lock()
+	defer fake.getPoolMemberMutex.Unlock()
+	fake.GetPoolMemberStub = stub
+}
+
+func (fake *FakeLoadbalancerFacade) GetPoolMemberArgsForCall(i int) (utils.RetryableServiceClient, string, string) {
+	fake.getPoolMemberMutex.RLock()
+	defer fake.getPoolMemberMutex.RUnlock()
+	argsForCall := fake.getPoolMemberArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeLoadbalancerFacade) GetPoolMemberReturns(result1 *pools.Member, result2 error) {
+	fake.getPoolMemberMutex.Lock()
+	defer fake.getPoolMemberMutex.Unlock()
+	fake.GetPoolMemberStub = nil
+	fake.getPoolMemberReturns = struct {
+		result1 *pools.Member
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLoadbalancerFacade) GetPoolMemberReturnsOnCall(i int, result1 *pools.Member, result2 error) {
+	fake.getPoolMemberMutex.Lock()
+	defer fake.getPoolMemberMutex.Unlock()
+	fake.GetPoolMemberStub = nil
+	if fake.getPoolMemberReturnsOnCall == nil {
+		fake.getPoolMemberReturnsOnCall = make(map[int]struct {
+			result1 *pools.Member
+			result2 error
+		})
+	}
+	fake.getPoolMemberReturnsOnCall[i] = struct {
+		result1 *pools.Member
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeLoadbalancerFacade) ListPoolMembers(arg1 utils.RetryableServiceClient, arg2 string, arg3 pools.ListMembersOpts) (pagination.Page, error) {
 	fake.listPoolMembersMutex.Lock()
 	ret, specificReturn := fake.listPoolMembersReturnsOnCall[len(fake.listPoolMembersArgsForCall)]
@@ -725,27 +822,76 @@ func (fake *FakeLoadbalancerFacade) ListPoolsReturnsOnCall(i int, result1 pagina
 	}{result1, result2}
 }
 
+func (fake *FakeLoadbalancerFacade) UpdatePoolMember(arg1 utils.ServiceClient, arg2 string, arg3 string, arg4 pools.UpdateMemberOpts) (*pools.Member, error) {
+	fake.updatePoolMemberMutex.Lock()
+	ret, specificReturn := fake.updatePoolMemberReturnsOnCall[len(fake.updatePoolMemberArgsForCall)]
+	fake.updatePoolMemberArgsForCall = append(fake.updatePoolMemberArgsForCall, struct {
+		arg1 utils.ServiceClient
+		arg2 string
+		arg3 string
+		arg4 pools.UpdateMemberOpts
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.UpdatePoolMemberStub
+	fakeReturns := fake.updatePoolMemberReturns
+	fake.recordInvocation("UpdatePoolMember", []interface{}{arg1, arg2, arg3, arg4})
+	fake.updatePoolMemberMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeLoadbalancerFacade) UpdatePoolMemberCallCount() int {
+	fake.updatePoolMemberMutex.RLock()
+	defer fake.updatePoolMemberMutex.RUnlock()
+	return len(fake.updatePoolMemberArgsForCall)
+}
+
+func (fake *FakeLoadbalancerFacade) UpdatePoolMemberCalls(stub func(utils.ServiceClient, string, string, pools.UpdateMemberOpts) (*pools.Member, error)) {
+	fake.updatePoolMemberMutex.Lock()
+	defer fake.updatePoolMemberMutex.Unlock()
+	fake.UpdatePoolMemberStub = stub
+}
+
+func (fake *FakeLoadbalancerFacade) UpdatePoolMemberArgsForCall(i int) (utils.ServiceClient, string, string, pools.UpdateMemberOpts) {
+	fake.updatePoolMemberMutex.RLock()
+	defer fake.updatePoolMemberMutex.RUnlock()
+	argsForCall := fake.updatePoolMemberArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeLoadbalancerFacade) UpdatePoolMemberReturns(result1 *pools.Member, result2 error) {
+	fake.updatePoolMemberMutex.Lock()
+	defer fake.updatePoolMemberMutex.Unlock()
+	fake.UpdatePoolMemberStub = nil
+	fake.updatePoolMemberReturns = struct {
+		result1 *pools.Member
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeLoadbalancerFacade) UpdatePoolMemberReturnsOnCall(i int, result1 *pools.Member, result2 error) {
+	fake.updatePoolMemberMutex.Lock()
+	defer fake.updatePoolMemberMutex.Unlock()
+	fake.UpdatePoolMemberStub = nil
+	if fake.updatePoolMemberReturnsOnCall == nil {
+		fake.updatePoolMemberReturnsOnCall = make(map[int]struct {
+			result1 *pools.Member
+			result2 error
+		})
+	}
+	fake.updatePoolMemberReturnsOnCall[i] = struct {
+		result1 *pools.Member
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeLoadbalancerFacade) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.createPoolMemberMutex.RLock()
-	defer fake.createPoolMemberMutex.RUnlock()
-	fake.deletePoolMemberMutex.RLock()
-	defer fake.deletePoolMemberMutex.RUnlock()
-	fake.extractPoolMembersMutex.RLock()
-	defer fake.extractPoolMembersMutex.RUnlock()
-	fake.extractPoolsMutex.RLock()
-	defer fake.extractPoolsMutex.RUnlock()
-	fake.getListenerMutex.RLock()
-	defer fake.getListenerMutex.RUnlock()
-	fake.getLoadbalancerMutex.RLock()
-	defer fake.getLoadbalancerMutex.RUnlock()
-	fake.getPoolMutex.RLock()
-	defer fake.getPoolMutex.RUnlock()
-	fake.listPoolMembersMutex.RLock()
-	defer fake.listPoolMembersMutex.RUnlock()
-	fake.listPoolsMutex.RLock()
-	defer fake.listPoolsMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
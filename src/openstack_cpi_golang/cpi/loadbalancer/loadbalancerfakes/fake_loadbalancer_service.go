@@ -53,6 +53,20 @@ type FakeLoadbalancerService struct {
 		result1 pools.Pool
 		result2 error
 	}
+	UpdatePoolMemberNameStub        func(string, string, string, int) error
+	updatePoolMemberNameMutex       sync.RWMutex
+	updatePoolMemberNameArgsForCall []struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 int
+	}
+	updatePoolMemberNameReturns struct {
+		result1 error
+	}
+	updatePoolMemberNameReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -252,15 +266,73 @@ func (fake *FakeLoadbalancerService) GetPoolReturnsOnCall(i int, result1 pools.P
 	}{result1, result2}
 }
 
+func (fake *FakeLoadbalancerService) UpdatePoolMemberName(arg1 string, arg2 string, arg3 string, arg4 int) error {
+	fake.updatePoolMemberNameMutex.Lock()
+	ret, specificReturn := fake.updatePoolMemberNameReturnsOnCall[len(fake.updatePoolMemberNameArgsForCall)]
+	fake.updatePoolMemberNameArgsForCall = append(fake.updatePoolMemberNameArgsForCall, struct {
+		arg1 string
+		arg2 string
+		arg3 string
+		arg4 int
+	}{arg1, arg2, arg3, arg4})
+	stub := fake.UpdatePoolMemberNameStub
+	fakeReturns := fake.updatePoolMemberNameReturns
+	fake.recordInvocation("UpdatePoolMemberName", []interface{}{arg1, arg2, arg3, arg4})
+	fake.updatePoolMemberNameMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeLoadbalancerService) UpdatePoolMemberNameCallCount() int {
+	fake.updatePoolMemberNameMutex.RLock()
+	defer fake.updatePoolMemberNameMutex.RUnlock()
+	return len(fake.updatePoolMemberNameArgsForCall)
+}
+
+func (fake *FakeLoadbalancerService) UpdatePoolMemberNameCalls(stub func(string, string, string, int) error) {
+	fake.updatePoolMemberNameMutex.Lock()
+	defer fake.updatePoolMemberNameMutex.Unlock()
+	fake.UpdatePoolMemberNameStub = stub
+}
+
+func (fake *FakeLoadbalancerService) UpdatePoolMemberNameArgsForCall(i int) (string, string, string, int) {
+	fake.updatePoolMemberNameMutex.RLock()
+	defer fake.updatePoolMemberNameMutex.RUnlock()
+	argsForCall := fake.updatePoolMemberNameArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeLoadbalancerService) UpdatePoolMemberNameReturns(result1 error) {
+	fake.updatePoolMemberNameMutex.Lock()
+	defer fake.updatePoolMemberNameMutex.Unlock()
+	fake.UpdatePoolMemberNameStub = nil
+	fake.updatePoolMemberNameReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeLoadbalancerService) UpdatePoolMemberNameReturnsOnCall(i int, result1 error) {
+	fake.updatePoolMemberNameMutex.Lock()
+	defer fake.updatePoolMemberNameMutex.Unlock()
+	fake.UpdatePoolMemberNameStub = nil
+	if fake.updatePoolMemberNameReturnsOnCall == nil {
+		fake.updatePoolMemberNameReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.updatePoolMemberNameReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeLoadbalancerService) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.createPoolMemberMutex.RLock()
-	defer fake.createPoolMemberMutex.RUnlock()
-	fake.deletePoolMemberMutex.RLock()
-	defer fake.deletePoolMemberMutex.RUnlock()
-	fake.getPoolMutex.RLock()
-	defer fake.getPoolMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
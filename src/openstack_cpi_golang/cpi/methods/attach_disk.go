@@ -3,11 +3,14 @@ package methods
 import (
 	"fmt"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/cloudfoundry/bosh-cpi-go/apiv1"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/compute"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/config"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/image"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/properties"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/volume"
 	"github.com/gophercloud/gophercloud/openstack/blockstorage/v3/volumes"
@@ -16,6 +19,7 @@ import (
 )
 
 type AttachDiskMethod struct {
+	imageServiceBuilder   image.ImageServiceBuilder
 	computeServiceBuilder compute.ComputeServiceBuilder
 	volumeServiceBuilder  volume.VolumeServiceBuilder
 	cpiConfig             config.CpiConfig
@@ -23,12 +27,14 @@ type AttachDiskMethod struct {
 }
 
 func NewAttachDiskMethod(
+	imageServiceBuilder image.ImageServiceBuilder,
 	computeServiceBuilder compute.ComputeServiceBuilder,
 	volumeServiceBuilder volume.VolumeServiceBuilder,
 	cpiConfig config.CpiConfig,
 	logger utils.Logger,
 ) AttachDiskMethod {
 	return AttachDiskMethod{
+		imageServiceBuilder:   imageServiceBuilder,
 		computeServiceBuilder: computeServiceBuilder,
 		volumeServiceBuilder:  volumeServiceBuilder,
 		cpiConfig:             cpiConfig,
@@ -38,6 +44,9 @@ func NewAttachDiskMethod(
 
 var initialDiskHint = apiv1.DiskHint{}
 
+// deviceSelectionAttempts is the number of devices requested before Nova chooses the device.
+const deviceSelectionAttempts = 3
+
 func (a AttachDiskMethod) attachDisk(vmCID apiv1.VMCID, diskCID apiv1.DiskCID, returnDiskHint bool) (apiv1.DiskHint, error) {
 
 	openstackConfig := a.cpiConfig.Cloud.Properties.Openstack
@@ -55,7 +64,7 @@ func (a AttachDiskMethod) attachDisk(vmCID apiv1.VMCID, diskCID apiv1.DiskCID, r
 	if len(diskVolume.Attachments) == 1 && diskVolume.Attachments[0].ServerID == vmCID.AsString() {
 		a.logger.Info("attach_disk", fmt.Sprintf("Volume ID %s is already attached to VM ID %s", diskCID.AsString(), vmCID.AsString()))
 		if returnDiskHint {
-			diskHint = a.getDiskHint(*diskVolume, nil)
+			diskHint = a.getDiskHint(*diskVolume, nil, a.getDiskBusOfVM(vmCID))
 		}
 		return diskHint, nil
 	}
@@ -72,28 +81,99 @@ func (a AttachDiskMethod) attachDisk(vmCID apiv1.VMCID, diskCID apiv1.DiskCID, r
 	if err != nil {
 		return diskHint, fmt.Errorf("attach_disk: Failed to get VM %s for disk ID %s: %w", vmCID.AsString(), diskCID.AsString(), err)
 	}
-	mountPoint, err := a.getMountPoint(computeService, *server)
-	if err != nil {
-		return diskHint, fmt.Errorf("attach_disk: Failed to get mount point for disk ID %s: %w", diskCID.AsString(), err)
-	}
-	a.logger.Debug("attach_disk", fmt.Sprintf("Attaching volume ID: %s, server: %s, mountPoint: %s", diskCID.AsString(), vmCID.AsString(), mountPoint))
-	volumeAttachment, err := computeService.AttachVolume(server.ID, diskCID.AsString(), mountPoint)
+	diskBus := a.getDiskBus(*server)
+	volumeAttachment, err := a.attachVolume(computeService, *server, diskCID, diskBus)
 	if err != nil {
-		return diskHint, fmt.Errorf("attach_disk: Failed to attach volume ID %s to VM ID %s: %w", diskVolume.ID, server.ID, err)
+		return diskHint, err
 	}
 	a.logger.Debug("attach_disk", fmt.Sprintf("Attaching volume DONE: Volume ID: %s, VM ID: %s, mountPoint: %s", volumeAttachment.VolumeID, volumeAttachment.ServerID, volumeAttachment.Device))
 	a.logger.Debug("attach_disk", fmt.Sprintf("Waiting for volume ID %s to get in use by VM ID %s (time: %d secs)", diskCID.AsString(), vmCID.AsString(), openstackConfig.StateTimeOut))
 	err = volumeService.WaitForVolumeToBecomeStatus(diskCID.AsString(), time.Duration(a.cpiConfig.Cloud.Properties.Openstack.StateTimeOut)*time.Second, "in-use")
 	if err != nil {
-		return diskHint, fmt.Errorf("attach_disk: Timeout on waiting to attach volume ID %s to VM %s (waiting: %d sec): %w", diskVolume.ID, server.ID, a.cpiConfig.Cloud.Properties.Openstack.StateTimeOut, err)
+		return diskHint, utils.NewDiskNotAttachedError(fmt.Errorf("attach_disk: Timeout on waiting to attach volume ID %s to VM %s (waiting: %d sec): %w", diskVolume.ID, server.ID, a.cpiConfig.Cloud.Properties.Openstack.StateTimeOut, err), true)
 	}
 	a.logger.Info("attach_disk", fmt.Sprintf("Successfully attached volume ID %s to VM %s (Volume status now: 'in-use')", diskCID.AsString(), vmCID.AsString()))
 	if returnDiskHint {
-		diskHint = a.getDiskHint(*diskVolume, volumeService)
+		diskHint = a.getAttachedDiskHint(*volumeAttachment, *diskVolume, volumeService, diskBus)
 	}
 	return diskHint, nil
 }
 
+// attachVolume attaches the volume to the next free device of the server. A concurrent attach_disk call
+// for the same server may take that device first, Nova then rejects the request as the device is in use.
+// The device is selected again from the current attachments, and finally left to Nova to choose.
+func (a AttachDiskMethod) attachVolume(computeService compute.ComputeService, server servers.Server, diskCID apiv1.DiskCID, diskBus properties.DiskBus) (*volumeattach.VolumeAttachment, error) {
+	for attempt := 1; attempt <= deviceSelectionAttempts; attempt++ {
+		mountPoint, err := a.getMountPoint(computeService, server, diskBus)
+		if err != nil {
+			return nil, fmt.Errorf("attach_disk: Failed to get mount point for disk ID %s: %w", diskCID.AsString(), err)
+		}
+		a.logger.Debug("attach_disk", fmt.Sprintf("Attaching volume ID: %s, server: %s, mountPoint: %s", diskCID.AsString(), server.ID, mountPoint))
+		volumeAttachment, err := computeService.AttachVolume(server.ID, diskCID.AsString(), mountPoint)
+		if err == nil {
+			return volumeAttachment, nil
+		}
+		if !isDeviceInUseError(err) {
+			return nil, fmt.Errorf("attach_disk: Failed to attach volume ID %s to VM ID %s: %w", diskCID.AsString(), server.ID, err)
+		}
+		a.logger.Warn("attach_disk", fmt.Sprintf("Device %s of VM ID %s is already in use (attempt %d): %v", mountPoint, server.ID, attempt, err))
+	}
+
+	a.logger.Warn("attach_disk", fmt.Sprintf("Letting Nova choose the device to attach volume ID %s to VM ID %s", diskCID.AsString(), server.ID))
+	volumeAttachment, err := computeService.AttachVolume(server.ID, diskCID.AsString(), "")
+	if err != nil {
+		return nil, fmt.Errorf("attach_disk: Failed to attach volume ID %s to VM ID %s: %w", diskCID.AsString(), server.ID, err)
+	}
+	return volumeAttachment, nil
+}
+
+// isDeviceInUseError reports whether Nova rejected an attachment because the requested device is reserved
+// by another attachment, e.g. "The supplied device path (/dev/vdb) is in use."
+func isDeviceInUseError(err error) bool {
+	return strings.Contains(strings.ToLower(err.Error()), "is in use")
+}
+
+// getDiskBus returns the disk bus of the server's image. It is not known for servers booted from volume
+// or whose image was deleted, their devices are named '/dev/sd*' and the devices reported by Nova are kept.
+func (a AttachDiskMethod) getDiskBus(server servers.Server) properties.DiskBus {
+	openstackConfig := a.cpiConfig.OpenStackConfig()
+	imageID, _ := server.Image["id"].(string)
+	if openstackConfig.DiskBus != "" || imageID == "" {
+		return properties.NewDiskBus(nil, openstackConfig)
+	}
+
+	imageService, err := a.imageServiceBuilder.Build()
+	if err != nil {
+		a.logger.Warn("attach_disk", fmt.Sprintf("Failed to get image service, disk bus of VM ID %s is unknown: %v", server.ID, err))
+		return properties.NewDiskBus(nil, openstackConfig)
+	}
+	imageProperties, err := imageService.GetImageProperties(imageID)
+	if err != nil {
+		a.logger.Warn("attach_disk", fmt.Sprintf("Failed to get image ID %s, disk bus of VM ID %s is unknown: %v", imageID, server.ID, err))
+		return properties.NewDiskBus(nil, openstackConfig)
+	}
+
+	diskBus := properties.NewDiskBus(imageProperties, openstackConfig)
+	a.logger.Debug("attach_disk", fmt.Sprintf("Using disk %s of image ID %s for VM ID %s", diskBus, imageID, server.ID))
+	return diskBus
+}
+
+// getDiskBusOfVM returns the disk bus of a VM the disk is already attached to.
+func (a AttachDiskMethod) getDiskBusOfVM(vmCID apiv1.VMCID) properties.DiskBus {
+	computeService, err := a.computeServiceBuilder.Build()
+	if err != nil {
+		a.logger.Warn("attach_disk", fmt.Sprintf("Failed to get compute service, disk bus of VM ID %s is unknown: %v", vmCID.AsString(), err))
+		return properties.NewDiskBus(nil, a.cpiConfig.OpenStackConfig())
+	}
+	server, err := computeService.GetServer(vmCID.AsString())
+	if err != nil {
+		a.logger.Warn("attach_disk", fmt.Sprintf("Failed to get VM, disk bus of VM ID %s is unknown: %v", vmCID.AsString(), err))
+		return properties.NewDiskBus(nil, a.cpiConfig.OpenStackConfig())
+	}
+
+	return a.getDiskBus(*server)
+}
+
 func (a AttachDiskMethod) checkDiskAttach(diskVolume volumes.Volume, vmCID apiv1.VMCID) error {
 
 	if (len(diskVolume.Attachments) > 1) || (len(diskVolume.Attachments) == 1 && diskVolume.Attachments[0].ServerID != vmCID.AsString()) {
@@ -139,7 +219,7 @@ func (a AttachDiskMethod) getFirstDeviceNameLetter(computeService compute.Comput
 	return inspectChar, nil
 }
 
-func (a AttachDiskMethod) getMountPoint(computeService compute.ComputeService, server servers.Server) (string, error) {
+func (a AttachDiskMethod) getMountPoint(computeService compute.ComputeService, server servers.Server, diskBus properties.DiskBus) (string, error) {
 	inspectChar, err := a.getFirstDeviceNameLetter(computeService, server)
 	if err != nil {
 		return "", fmt.Errorf("getMountPoint: Failed to get first device letter service: %w", err)
@@ -156,7 +236,7 @@ func (a AttachDiskMethod) getMountPoint(computeService compute.ComputeService, s
 	if err != nil {
 		return "", fmt.Errorf("getMountPoint: failed to get device letter for server ID %s: %w", server.ID, err)
 	}
-	return fmt.Sprintf("/dev/sd%c", inspectChar), nil
+	return fmt.Sprintf("%s%c", diskBus.DevicePrefix(), inspectChar), nil
 }
 
 func (a AttachDiskMethod) getDeviceChar(inspectChar rune, attachments []volumeattach.VolumeAttachment) (rune, error) {
@@ -181,7 +261,16 @@ func (a AttachDiskMethod) getDeviceChar(inspectChar rune, attachments []volumeat
 	return ' ', fmt.Errorf("failed to get device letter")
 }
 
-func (a AttachDiskMethod) getDiskHint(diskVolume volumes.Volume, volumeService volume.VolumeService) apiv1.DiskHint {
+// getAttachedDiskHint prefers the device reported by Nova for the new attachment, as it may differ from the requested one.
+func (a AttachDiskMethod) getAttachedDiskHint(volumeAttachment volumeattach.VolumeAttachment, diskVolume volumes.Volume, volumeService volume.VolumeService, diskBus properties.DiskBus) apiv1.DiskHint {
+	if volumeAttachment.Device != "" {
+		a.logger.Debug("attach_disk", fmt.Sprintf("Use device of the volume attachment: %s", volumeAttachment.Device))
+		return a.newDiskHint(diskVolume.ID, volumeAttachment.Device, diskBus)
+	}
+	return a.getDiskHint(diskVolume, volumeService, diskBus)
+}
+
+func (a AttachDiskMethod) getDiskHint(diskVolume volumes.Volume, volumeService volume.VolumeService, diskBus properties.DiskBus) apiv1.DiskHint {
 	diskHint := initialDiskHint
 	if volumeService != nil {
 		currVolume, err := volumeService.GetVolume(diskVolume.ID)
@@ -190,19 +279,35 @@ func (a AttachDiskMethod) getDiskHint(diskVolume volumes.Volume, volumeService v
 		} else {
 			if len(currVolume.Attachments) != 0 {
 				attachment := currVolume.Attachments[0]
-				diskHint = apiv1.NewDiskHintFromString(attachment.Device)
+				diskHint = a.newDiskHint(diskVolume.ID, attachment.Device, diskBus)
 			}
 		}
 	} else {
 		if len(diskVolume.Attachments) != 0 {
 			attachment := diskVolume.Attachments[0]
-			diskHint = apiv1.NewDiskHintFromString(attachment.Device)
+			diskHint = a.newDiskHint(diskVolume.ID, attachment.Device, diskBus)
 		}
 	}
 	a.logger.Debug("attach_disk", fmt.Sprintf("Use disk hint value: %v", diskHint))
 	return diskHint
 }
 
+// newDiskHint returns the device path on the disk bus as disk hint, or a map with the volume ID and the device path
+// for stemcells resolving the device by ID. The agent then finds the volume in /dev/disk/by-id/virtio-<volume ID prefix>,
+// as the device path reported by Nova may differ from the one of the VM, e.g. '/dev/sdc' instead of '/dev/vdb'.
+func (a AttachDiskMethod) newDiskHint(volumeID string, device string, diskBus properties.DiskBus) apiv1.DiskHint {
+	device = diskBus.DevicePath(device)
+	openstackConfig := a.cpiConfig.OpenStackConfig()
+	if openstackConfig.GetDiskHintFormat(openstackConfig.VM.Stemcell.APIVersion) == config.DiskHintFormatMap {
+		return apiv1.NewDiskHintFromMap(map[string]interface{}{
+			"volume_id": volumeID,
+			"path":      device,
+		})
+	}
+
+	return apiv1.NewDiskHintFromString(device)
+}
+
 func (a AttachDiskMethod) AttachDisk(vmCID apiv1.VMCID, diskCID apiv1.DiskCID) error {
 	_, err := a.attachDisk(vmCID, diskCID, false)
 	return err
@@ -3,11 +3,15 @@ package methods_test
 import (
 	"errors"
 	"fmt"
+	"sync"
 
 	"github.com/cloudfoundry/bosh-cpi-go/apiv1"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/compute/computefakes"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/config"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/image/imagefakes"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/methods"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/properties"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils/utilsfakes"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/volume/volumefakes"
 	"github.com/gophercloud/gophercloud/openstack/blockstorage/v3/volumes"
@@ -35,6 +39,8 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 	)
 
 	var (
+		imageServiceBuilder   *imagefakes.FakeImageServiceBuilder
+		imageService          *imagefakes.FakeImageService
 		computeServiceBuilder *computefakes.FakeComputeServiceBuilder
 		computeService        *computefakes.FakeComputeService
 		volumeServiceBuilder  *volumefakes.FakeVolumeServiceBuilder
@@ -46,6 +52,9 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 
 	Context("attaching disk to VM", func() {
 		BeforeEach(func() {
+			imageServiceBuilder = new(imagefakes.FakeImageServiceBuilder)
+			imageService = new(imagefakes.FakeImageService)
+			imageServiceBuilder.BuildReturns(imageService, nil)
 			computeServiceBuilder = new(computefakes.FakeComputeServiceBuilder)
 			computeService = new(computefakes.FakeComputeService)
 			volumeServiceBuilder = new(volumefakes.FakeVolumeServiceBuilder)
@@ -57,7 +66,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 
 		It("fails on volume service builder (V1)", func() {
 			volumeServiceBuilder.BuildReturns(nil, errors.New("boom"))
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			vmCID := apiv1.VMCID{}
 			diskId := apiv1.DiskCID{}
 			err := attachDiskMethod.AttachDisk(vmCID, diskId)
@@ -67,7 +76,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 		It("fails on get server", func() {
 			volumeService.GetVolumeReturns(nil, errors.New("boom"))
 			volumeServiceBuilder.BuildReturns(volumeService, nil)
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			vmCID := apiv1.VMCID{}
 			diskId := apiv1.NewDiskCID(volumeId1)
 			err := attachDiskMethod.AttachDisk(vmCID, diskId)
@@ -86,7 +95,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			}
 			volumeService.GetVolumeReturns(&volume, nil)
 			volumeServiceBuilder.BuildReturns(volumeService, nil)
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			vmCID := apiv1.NewVMCID(serverId)
 			diskCID := apiv1.NewDiskCID(volumeId1)
 			err := attachDiskMethod.AttachDisk(vmCID, diskCID)
@@ -105,7 +114,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			}
 			volumeService.GetVolumeReturns(&volume, nil)
 			volumeServiceBuilder.BuildReturns(volumeService, nil)
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			vmCID := apiv1.NewVMCID(serverId)
 			diskCID := apiv1.NewDiskCID(volumeId1)
 			err := attachDiskMethod.AttachDisk(vmCID, diskCID)
@@ -124,7 +133,8 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			}
 			volumeService.GetVolumeReturns(&volume, nil)
 			volumeServiceBuilder.BuildReturns(volumeService, nil)
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			computeService.GetServerReturns(&servers.Server{ID: serverId}, nil)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			vmCID := apiv1.NewVMCID(serverId)
 			diskCID := apiv1.NewDiskCID(volumeId1)
 			diskHint, err := attachDiskMethod.AttachDiskV2(vmCID, diskCID)
@@ -141,7 +151,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			}
 			volumeService.GetVolumeReturns(&volume, nil)
 			volumeServiceBuilder.BuildReturns(volumeService, nil)
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			vmCID := apiv1.NewVMCID(serverId)
 			diskCID := apiv1.NewDiskCID(volumeId1)
 			err := attachDiskMethod.AttachDisk(vmCID, diskCID)
@@ -156,7 +166,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			}
 			volumeService.GetVolumeReturns(&volume, nil)
 			volumeServiceBuilder.BuildReturns(volumeService, nil)
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			vmCID := apiv1.NewVMCID(serverId)
 			diskCID := apiv1.NewDiskCID(volumeId1)
 			err := attachDiskMethod.AttachDisk(vmCID, diskCID)
@@ -172,7 +182,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			}
 			volumeService.GetVolumeReturns(&volume, nil)
 			volumeServiceBuilder.BuildReturns(volumeService, nil)
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			vmCID := apiv1.NewVMCID(serverId)
 			diskCID := apiv1.NewDiskCID(volumeId1)
 			err := attachDiskMethod.AttachDisk(vmCID, diskCID)
@@ -191,7 +201,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			}
 			volumeService.GetVolumeReturns(&volume, nil)
 			volumeServiceBuilder.BuildReturns(volumeService, nil)
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			vmCID := apiv1.NewVMCID(serverId)
 			diskCID := apiv1.NewDiskCID(volumeId1)
 			// since we do not explicitly check any more for VM status (DELETED, TERMINATED), we expect an error from attachDisk itself
@@ -215,7 +225,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			volumeService.GetVolumeReturns(&volume, nil)
 			volumeServiceBuilder.BuildReturns(volumeService, nil)
 			computeService.AttachVolumeReturns(nil, errors.New("boom"))
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			vmCID := apiv1.NewVMCID(serverId)
 			diskCID := apiv1.NewDiskCID(volumeId1)
 			err := attachDiskMethod.AttachDisk(vmCID, diskCID)
@@ -238,11 +248,13 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			computeService.AttachVolumeReturns(&volumeAttach, nil)
 			volumeService.WaitForVolumeToBecomeStatusReturns(errors.New("boom"))
 			cpiConfig.Cloud.Properties.Openstack.StateTimeOut = 60
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			vmCID := apiv1.NewVMCID(serverId)
 			diskCID := apiv1.NewDiskCID(volumeId1)
 			err := attachDiskMethod.AttachDisk(vmCID, diskCID)
 			Expect(err.Error()).To(Equal(fmt.Sprintf("attach_disk: Timeout on waiting to attach volume ID %s to VM %s (waiting: 60 sec): boom", volumeId1, serverId)))
+			Expect(err.(utils.CloudError).Type()).To(Equal(utils.DiskNotAttachedErrorType))
+			Expect(err.(utils.CloudError).CanRetry()).To(BeTrue())
 		})
 
 		It("success on attach disk (V1)", func() {
@@ -260,7 +272,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			volumeAttach := volumeattach.VolumeAttachment{}
 			computeService.AttachVolumeReturns(&volumeAttach, nil)
 			volumeService.WaitForVolumeToBecomeStatusReturns(nil)
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			vmCID := apiv1.NewVMCID(serverId)
 			diskCID := apiv1.NewDiskCID(volumeId1)
 			err := attachDiskMethod.AttachDisk(vmCID, diskCID)
@@ -294,7 +306,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			volumeAttach := volumeattach.VolumeAttachment{}
 			computeService.AttachVolumeReturns(&volumeAttach, nil)
 			volumeService.WaitForVolumeToBecomeStatusReturns(nil)
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			vmCID := apiv1.NewVMCID(serverId)
 			diskCID := apiv1.NewDiskCID(volumeId1)
 			diskHint, err := attachDiskMethod.AttachDiskV2(vmCID, diskCID)
@@ -321,7 +333,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			volumeAttach := volumeattach.VolumeAttachment{}
 			computeService.AttachVolumeReturns(&volumeAttach, nil)
 			volumeService.WaitForVolumeToBecomeStatusReturns(nil)
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			vmCID := apiv1.NewVMCID(serverId)
 			diskCID := apiv1.NewDiskCID(volumeId1)
 			diskHint, err := attachDiskMethod.AttachDiskV2(vmCID, diskCID)
@@ -332,9 +344,276 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 
 	})
 
+	Context("attaching disks in parallel", func() {
+		var lock sync.Mutex
+		var attachedDevices map[string]string
+
+		BeforeEach(func() {
+			imageServiceBuilder = new(imagefakes.FakeImageServiceBuilder)
+			imageService = new(imagefakes.FakeImageService)
+			imageServiceBuilder.BuildReturns(imageService, nil)
+			computeServiceBuilder = new(computefakes.FakeComputeServiceBuilder)
+			computeService = new(computefakes.FakeComputeService)
+			volumeServiceBuilder = new(volumefakes.FakeVolumeServiceBuilder)
+			volumeService = new(volumefakes.FakeVolumeService)
+			logger = new(utilsfakes.FakeLogger)
+			computeServiceBuilder.BuildReturns(computeService, nil)
+			volumeServiceBuilder.BuildReturns(volumeService, nil)
+			cpiConfig = config.CpiConfig{}
+
+			computeService.GetServerReturns(&servers.Server{ID: serverId, Status: serverStatusActive}, nil)
+			volumeService.GetVolumeStub = func(volumeID string) (*volumes.Volume, error) {
+				return &volumes.Volume{ID: volumeID, Status: diskStatusAvailable}, nil
+			}
+
+			// Nova rejects a device which is reserved by another attachment of the server
+			attachedDevices = map[string]string{}
+			computeService.ListVolumeAttachmentsStub = func(serverID string) ([]volumeattach.VolumeAttachment, error) {
+				lock.Lock()
+				defer lock.Unlock()
+				var attachments []volumeattach.VolumeAttachment
+				for device, volumeID := range attachedDevices {
+					attachments = append(attachments, volumeattach.VolumeAttachment{VolumeID: volumeID, Device: device})
+				}
+				return attachments, nil
+			}
+			computeService.AttachVolumeStub = func(serverID string, volumeID string, device string) (*volumeattach.VolumeAttachment, error) {
+				lock.Lock()
+				defer lock.Unlock()
+				if _, inUse := attachedDevices[device]; inUse {
+					return nil, fmt.Errorf("The supplied device path (%s) is in use.", device)
+				}
+				attachedDevices[device] = volumeID
+				return &volumeattach.VolumeAttachment{VolumeID: volumeID, ServerID: serverID, Device: device}, nil
+			}
+		})
+
+		It("attaches the disks to different devices if they select the same device", func() {
+			var listed sync.WaitGroup
+			listed.Add(2)
+			listStub := computeService.ListVolumeAttachmentsStub
+			computeService.ListVolumeAttachmentsStub = func(serverID string) ([]volumeattach.VolumeAttachment, error) {
+				attachments, err := listStub(serverID)
+				// both attach_disk calls select their device before any of them attaches
+				if computeService.ListVolumeAttachmentsCallCount() <= 2 {
+					listed.Done()
+					listed.Wait()
+				}
+				return attachments, err
+			}
+
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			diskHints := make([]apiv1.DiskHint, 2)
+			errs := make([]error, 2)
+			var attached sync.WaitGroup
+			for i := range 2 {
+				attached.Add(1)
+				go func() {
+					defer GinkgoRecover()
+					defer attached.Done()
+					diskHints[i], errs[i] = attachDiskMethod.AttachDiskV2(apiv1.NewVMCID(serverId), apiv1.NewDiskCID(fmt.Sprintf("vol%d-id", i+1)))
+				}()
+			}
+			attached.Wait()
+
+			Expect(errs).To(Equal([]error{nil, nil}))
+			Expect(diskHints).To(ConsistOf(apiv1.NewDiskHintFromString(deviceB), apiv1.NewDiskHintFromString(deviceC)))
+			Expect(attachedDevices).To(HaveLen(2))
+			Expect(computeService.AttachVolumeCallCount()).To(Equal(3))
+		})
+
+		It("lets Nova choose the device if the selected devices remain in use", func() {
+			computeService.AttachVolumeStub = func(serverID string, volumeID string, device string) (*volumeattach.VolumeAttachment, error) {
+				if device != "" {
+					return nil, fmt.Errorf("The supplied device path (%s) is in use.", device)
+				}
+				return &volumeattach.VolumeAttachment{VolumeID: volumeID, ServerID: serverID, Device: "/dev/vdd"}, nil
+			}
+
+			diskHint, err := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger).
+				AttachDiskV2(apiv1.NewVMCID(serverId), apiv1.NewDiskCID(volumeId1))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diskHint).To(Equal(apiv1.NewDiskHintFromString("/dev/vdd")))
+			Expect(computeService.AttachVolumeCallCount()).To(Equal(4))
+			_, _, device := computeService.AttachVolumeArgsForCall(3)
+			Expect(device).To(Equal(""))
+		})
+
+		It("does not select a device again for other attach errors", func() {
+			computeService.AttachVolumeReturns(nil, errors.New("boom"))
+
+			_, err := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger).
+				AttachDiskV2(apiv1.NewVMCID(serverId), apiv1.NewDiskCID(volumeId1))
+
+			Expect(err.Error()).To(Equal(fmt.Sprintf("attach_disk: Failed to attach volume ID %s to VM ID %s: boom", volumeId1, serverId)))
+			Expect(computeService.AttachVolumeCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("disk hint formats", func() {
+		var volume volumes.Volume
+
+		BeforeEach(func() {
+			imageServiceBuilder = new(imagefakes.FakeImageServiceBuilder)
+			imageService = new(imagefakes.FakeImageService)
+			imageServiceBuilder.BuildReturns(imageService, nil)
+			computeServiceBuilder = new(computefakes.FakeComputeServiceBuilder)
+			computeService = new(computefakes.FakeComputeService)
+			volumeServiceBuilder = new(volumefakes.FakeVolumeServiceBuilder)
+			volumeService = new(volumefakes.FakeVolumeService)
+			logger = new(utilsfakes.FakeLogger)
+			computeServiceBuilder.BuildReturns(computeService, nil)
+			volumeServiceBuilder.BuildReturns(volumeService, nil)
+			cpiConfig = config.CpiConfig{}
+
+			volume = volumes.Volume{ID: volumeId1, Status: diskStatusAvailable}
+			volumeService.GetVolumeReturns(&volume, nil)
+			computeService.GetServerReturns(&servers.Server{ID: serverId, Status: serverStatusActive}, nil)
+			computeService.AttachVolumeReturns(&volumeattach.VolumeAttachment{VolumeID: volumeId1, ServerID: serverId, Device: deviceB}, nil)
+		})
+
+		It("returns the volume ID and the device for stemcell API version 2", func() {
+			cpiConfig.Cloud.Properties.Openstack.VM.Stemcell.APIVersion = 2
+
+			diskHint, err := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger).
+				AttachDiskV2(apiv1.NewVMCID(serverId), apiv1.NewDiskCID(volumeId1))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diskHint).To(Equal(apiv1.NewDiskHintFromMap(map[string]interface{}{
+				"volume_id": volumeId1,
+				"path":      deviceB,
+			})))
+		})
+
+		It("returns the volume ID of a volume already attached to the VM", func() {
+			cpiConfig.Cloud.Properties.Openstack.VM.Stemcell.APIVersion = 2
+			volume.Attachments = []volumes.Attachment{{Device: deviceA, ServerID: serverId}}
+
+			diskHint, err := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger).
+				AttachDiskV2(apiv1.NewVMCID(serverId), apiv1.NewDiskCID(volumeId1))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diskHint).To(Equal(apiv1.NewDiskHintFromMap(map[string]interface{}{
+				"volume_id": volumeId1,
+				"path":      deviceA,
+			})))
+		})
+
+		It("returns the device for stemcell API version 1", func() {
+			cpiConfig.Cloud.Properties.Openstack.VM.Stemcell.APIVersion = 1
+
+			diskHint, err := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger).
+				AttachDiskV2(apiv1.NewVMCID(serverId), apiv1.NewDiskCID(volumeId1))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diskHint).To(Equal(apiv1.NewDiskHintFromString(deviceB)))
+		})
+
+		It("returns the format configured for the stemcell API version", func() {
+			cpiConfig.Cloud.Properties.Openstack.VM.Stemcell.APIVersion = 2
+			cpiConfig.Cloud.Properties.Openstack.DiskHintFormats = map[int]string{2: config.DiskHintFormatString}
+
+			diskHint, err := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger).
+				AttachDiskV2(apiv1.NewVMCID(serverId), apiv1.NewDiskCID(volumeId1))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diskHint).To(Equal(apiv1.NewDiskHintFromString(deviceB)))
+		})
+	})
+
+	Context("disk buses", func() {
+		var volume volumes.Volume
+
+		BeforeEach(func() {
+			imageServiceBuilder = new(imagefakes.FakeImageServiceBuilder)
+			imageService = new(imagefakes.FakeImageService)
+			imageServiceBuilder.BuildReturns(imageService, nil)
+			computeServiceBuilder = new(computefakes.FakeComputeServiceBuilder)
+			computeService = new(computefakes.FakeComputeService)
+			volumeServiceBuilder = new(volumefakes.FakeVolumeServiceBuilder)
+			volumeService = new(volumefakes.FakeVolumeService)
+			logger = new(utilsfakes.FakeLogger)
+			computeServiceBuilder.BuildReturns(computeService, nil)
+			volumeServiceBuilder.BuildReturns(volumeService, nil)
+			cpiConfig = config.CpiConfig{}
+
+			volume = volumes.Volume{ID: volumeId1, Status: diskStatusAvailable}
+			volumeService.GetVolumeReturns(&volume, nil)
+			computeService.GetServerReturns(&servers.Server{
+				ID:     serverId,
+				Status: serverStatusActive,
+				Image:  map[string]interface{}{"id": "the-image-id"},
+			}, nil)
+			computeService.AttachVolumeReturns(&volumeattach.VolumeAttachment{VolumeID: volumeId1, ServerID: serverId, Device: deviceB}, nil)
+			imageService.GetImagePropertiesReturns(map[string]interface{}{"hw_disk_bus": "virtio"}, nil)
+		})
+
+		It("attaches the disk to a device of the bus of the server image", func() {
+			diskHint, err := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger).
+				AttachDiskV2(apiv1.NewVMCID(serverId), apiv1.NewDiskCID(volumeId1))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(imageService.GetImagePropertiesArgsForCall(0)).To(Equal("the-image-id"))
+			_, _, device := computeService.AttachVolumeArgsForCall(0)
+			Expect(device).To(Equal("/dev/vdb"))
+			Expect(diskHint).To(Equal(apiv1.NewDiskHintFromString("/dev/vdb")))
+		})
+
+		It("returns the device of the bus of a volume already attached to the VM", func() {
+			volume.Attachments = []volumes.Attachment{{Device: deviceC, ServerID: serverId}}
+
+			diskHint, err := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger).
+				AttachDiskV2(apiv1.NewVMCID(serverId), apiv1.NewDiskCID(volumeId1))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diskHint).To(Equal(apiv1.NewDiskHintFromString("/dev/vdc")))
+		})
+
+		It("uses the configured disk bus", func() {
+			cpiConfig.Cloud.Properties.Openstack.DiskBus = "scsi"
+
+			diskHint, err := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger).
+				AttachDiskV2(apiv1.NewVMCID(serverId), apiv1.NewDiskCID(volumeId1))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(imageService.GetImagePropertiesCallCount()).To(Equal(0))
+			Expect(diskHint).To(Equal(apiv1.NewDiskHintFromString(deviceB)))
+		})
+
+		It("keeps the device reported by Nova if the server image cannot be retrieved", func() {
+			imageService.GetImagePropertiesReturns(nil, errors.New("boom"))
+			computeService.AttachVolumeReturns(&volumeattach.VolumeAttachment{VolumeID: volumeId1, ServerID: serverId, Device: "/dev/vdb"}, nil)
+
+			diskHint, err := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger).
+				AttachDiskV2(apiv1.NewVMCID(serverId), apiv1.NewDiskCID(volumeId1))
+
+			Expect(err).ToNot(HaveOccurred())
+			_, _, device := computeService.AttachVolumeArgsForCall(0)
+			Expect(device).To(Equal(deviceB))
+			Expect(diskHint).To(Equal(apiv1.NewDiskHintFromString("/dev/vdb")))
+		})
+
+		It("returns the device of the bus in map disk hints", func() {
+			cpiConfig.Cloud.Properties.Openstack.VM.Stemcell.APIVersion = 2
+
+			diskHint, err := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger).
+				AttachDiskV2(apiv1.NewVMCID(serverId), apiv1.NewDiskCID(volumeId1))
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diskHint).To(Equal(apiv1.NewDiskHintFromMap(map[string]interface{}{
+				"volume_id": volumeId1,
+				"path":      "/dev/vdb",
+			})))
+		})
+	})
+
 	Context("getting mount point", func() {
 
 		BeforeEach(func() {
+			imageServiceBuilder = new(imagefakes.FakeImageServiceBuilder)
+			imageService = new(imagefakes.FakeImageService)
+			imageServiceBuilder.BuildReturns(imageService, nil)
 			computeServiceBuilder = new(computefakes.FakeComputeServiceBuilder)
 			computeService = new(computefakes.FakeComputeService)
 			volumeServiceBuilder = new(volumefakes.FakeVolumeServiceBuilder)
@@ -352,8 +631,8 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			computeService.GetFlavorByIdReturns(flavors.Flavor{}, errors.New("boom"))
 			volume := volumes.Volume{}
 			volumeService.GetVolumeReturns(&volume, nil)
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
-			result, err := attachDiskMethod.GetMountPoint(computeService, server)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			result, err := attachDiskMethod.GetMountPoint(computeService, server, properties.DiskBus{})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(Equal(deviceB))
 		})
@@ -362,8 +641,8 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			volumeService.GetVolumeReturns(nil, errors.New("boom"))
 			volumeServiceBuilder.BuildReturns(volumeService, nil)
 			server = servers.Server{}
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
-			result, err := attachDiskMethod.GetMountPoint(computeService, server)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			result, err := attachDiskMethod.GetMountPoint(computeService, server, properties.DiskBus{})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(Equal(deviceB))
 		})
@@ -375,12 +654,21 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			volumeAttachments = append(volumeAttachments, volume1, volume2)
 			computeService.ListVolumeAttachmentsReturns(volumeAttachments, nil)
 			server = servers.Server{ID: serverId}
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
-			result, err := attachDiskMethod.GetMountPoint(computeService, server)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			result, err := attachDiskMethod.GetMountPoint(computeService, server, properties.DiskBus{})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(Equal(deviceC))
 		})
 
+		It("returns a device of the disk bus", func() {
+			computeService.ListVolumeAttachmentsReturns([]volumeattach.VolumeAttachment{{VolumeID: volumeId1, Device: "/dev/vdb"}}, nil)
+			server = servers.Server{ID: serverId}
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			result, err := attachDiskMethod.GetMountPoint(computeService, server, properties.DiskBus{Bus: "virtio"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal("/dev/vdc"))
+		})
+
 		It("check overflow of device character search", func() {
 			var attachedVolumes []volumeattach.VolumeAttachment
 			for i := 1; i < 26; i++ { // omit "a"; search start with "b"
@@ -388,7 +676,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 				device := fmt.Sprintf("/dev/sd%c", 'a'+i)
 				attachedVolumes = append(attachedVolumes, volumeattach.VolumeAttachment{VolumeID: id, Device: device})
 			}
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			_, err := attachDiskMethod.GetDeviceChar('b', attachedVolumes)
 			Expect(err).To(HaveOccurred())
 			Expect(err.Error()).To(Equal("failed to get device letter"))
@@ -412,7 +700,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 				}
 				attachedVolumes = append(attachedVolumes, volumeattach.VolumeAttachment{VolumeID: id, Device: device})
 			}
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			driveLetter, err := attachDiskMethod.GetDeviceChar('b', attachedVolumes)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(driveLetter).To(Equal('e'))
@@ -423,6 +711,9 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 	Context("determine device name letter", func() {
 
 		BeforeEach(func() {
+			imageServiceBuilder = new(imagefakes.FakeImageServiceBuilder)
+			imageService = new(imagefakes.FakeImageService)
+			imageServiceBuilder.BuildReturns(imageService, nil)
 			computeServiceBuilder = new(computefakes.FakeComputeServiceBuilder)
 			volumeServiceBuilder = new(volumefakes.FakeVolumeServiceBuilder)
 			computeService = new(computefakes.FakeComputeService)
@@ -432,7 +723,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 		})
 
 		It("returns default first device name letter on no flavor + no config", func() {
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			result, err := attachDiskMethod.GetFirstDeviceNameLetterWrapper(computeService, server)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(Equal('b'))
@@ -445,7 +736,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			server = servers.Server{Flavor: flavorMap}
 			computeService.GetFlavorByIdReturns(flavors.Flavor{}, errors.New("boom"))
 
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			result, err := attachDiskMethod.GetFirstDeviceNameLetterWrapper(computeService, server)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(Equal('b'))
@@ -462,7 +753,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			server = servers.Server{Flavor: flavorMap}
 			computeService.GetFlavorByIdReturns(flavor, nil)
 
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			result, err := attachDiskMethod.GetFirstDeviceNameLetterWrapper(computeService, server)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(Equal('c'))
@@ -479,7 +770,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			server = servers.Server{Flavor: flavorMap}
 			computeService.GetFlavorByIdReturns(flavor, nil)
 
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			result, err := attachDiskMethod.GetFirstDeviceNameLetterWrapper(computeService, server)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(Equal('c'))
@@ -497,7 +788,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			server = servers.Server{Flavor: flavorMap}
 			computeService.GetFlavorByIdReturns(flavor, nil)
 
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			result, err := attachDiskMethod.GetFirstDeviceNameLetterWrapper(computeService, server)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(Equal('d'))
@@ -514,7 +805,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			server = servers.Server{Flavor: flavorMap}
 			computeService.GetFlavorByIdReturns(flavor, nil)
 
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			result, err := attachDiskMethod.GetFirstDeviceNameLetterWrapper(computeService, server)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(Equal('c'))
@@ -533,7 +824,7 @@ var _ = Describe("AttachDiskMethod Unit Tests", func() {
 			server = servers.Server{Flavor: flavorMap}
 			computeService.GetFlavorByIdReturns(flavor, nil)
 
-			attachDiskMethod := methods.NewAttachDiskMethod(computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
+			attachDiskMethod := methods.NewAttachDiskMethod(imageServiceBuilder, computeServiceBuilder, volumeServiceBuilder, cpiConfig, logger)
 			result, err := attachDiskMethod.GetFirstDeviceNameLetterWrapper(computeService, server)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(Equal('e'))
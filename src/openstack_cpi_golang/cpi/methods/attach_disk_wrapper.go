@@ -2,6 +2,7 @@ package methods
 
 import (
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/compute"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/properties"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/extensions/volumeattach"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
 )
@@ -10,8 +11,8 @@ func (a AttachDiskMethod) GetFirstDeviceNameLetterWrapper(computeService compute
 	return a.getFirstDeviceNameLetter(computeService, server)
 }
 
-func (a AttachDiskMethod) GetMountPoint(computeService compute.ComputeService, server servers.Server) (string, error) {
-	return a.getMountPoint(computeService, server)
+func (a AttachDiskMethod) GetMountPoint(computeService compute.ComputeService, server servers.Server, diskBus properties.DiskBus) (string, error) {
+	return a.getMountPoint(computeService, server, diskBus)
 }
 
 func (a AttachDiskMethod) GetDeviceChar(inspectChar rune, attachments []volumeattach.VolumeAttachment) (rune, error) {
@@ -2,6 +2,7 @@ package methods
 
 import (
 	"fmt"
+	"math"
 
 	"github.com/cloudfoundry/bosh-cpi-go/apiv1"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/compute"
@@ -57,5 +58,12 @@ func (a CalculateVMCloudPropertiesMethod) vmCloudProperties(requirements apiv1.V
 			"size": fmt.Sprintf("%.1f", requiredBootVolumeSize),
 		}
 	}
+
+	// flavors without local ephemeral storage get a Cinder backed ephemeral disk instead
+	if !bootFromVolume && flavor.Ephemeral == 0 && requirements.EphemeralDiskSize > 0 {
+		vmProperties["ephemeral_disk"] = map[string]interface{}{
+			"size": int(math.Ceil(float64(requirements.EphemeralDiskSize) / 1024)),
+		}
+	}
 	return apiv1.NewVMCloudPropsFromMap(vmProperties)
 }
@@ -77,6 +77,27 @@ var _ = Describe("HasVMMethod", func() {
 				})))
 				Expect(err).ToNot(HaveOccurred())
 			})
+
+			It("calculate the vm cloud properties with ephemeral disk if the flavor has no ephemeral disk", func() {
+				computeService.GetMatchingFlavorReturns(flavors.Flavor{ID: "the_flavor_id", Name: "the_instance_type", VCPUs: 2, RAM: 4096, Disk: 20}, nil)
+				vmResources.EphemeralDiskSize = 10000
+
+				vmCloudProperties, err := methods.NewCalculateVMCloudPropertiesMethod(
+					&computeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CalculateVMCloudProperties(
+					vmResources,
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(vmCloudProperties).To(Equal(apiv1.NewVMCloudPropsFromMap(map[string]interface{}{
+					"instance_type": "the_instance_type",
+					"ephemeral_disk": map[string]interface{}{
+						"size": 10,
+					},
+				})))
+			})
 		})
 
 		Context("bootFromVolume is true", func() {
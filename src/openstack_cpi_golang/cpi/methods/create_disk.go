@@ -75,6 +75,15 @@ func (a CreateDiskMethod) CreateDisk(
 		if err != nil {
 			return apiv1.DiskCID{}, fmt.Errorf("create_disk: %w", err)
 		}
+
+		volumeAZ := openstackConfig.GetVolumeAvailabilityZone(az)
+		if volumeAZ.AvailabilityZone != az {
+			a.logger.Info("create_disk", fmt.Sprintf("Mapping availability zone '%s' to volume availability zone '%s'", az, volumeAZ.AvailabilityZone))
+		}
+		az = volumeAZ.AvailabilityZone
+		if cloudProps.VolumeType == "" {
+			cloudProps.VolumeType = volumeAZ.VolumeType
+		}
 	}
 
 	a.logger.Info("create_disk", "Creating new volume...")
@@ -157,6 +157,50 @@ var _ = Describe("CreateDisk", func() {
 				Expect(az).To(Equal("AZ"))
 			})
 
+			It("maps the server availability zone to the volume availability zone and volume type", func() {
+				cpiConfig.Cloud.Properties.Openstack.VolumeAvailabilityZones = map[string]config.VolumeAvailabilityZone{
+					"AZ": {AvailabilityZone: "AZ-storage", VolumeType: "the-volume-type"},
+				}
+
+				_, err := methods.NewCreateDiskMethod(
+					&computeServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateDisk(
+					size,
+					apiv1.CloudPropsImpl{RawMessage: []byte(`{}`)},
+					&apiv1.VMCID{},
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				_, cloudProps, az := volumeService.CreateVolumeArgsForCall(0)
+				Expect(az).To(Equal("AZ-storage"))
+				Expect(cloudProps.VolumeType).To(Equal("the-volume-type"))
+			})
+
+			It("prefers the volume type of the disk cloud properties over the mapped volume type", func() {
+				cpiConfig.Cloud.Properties.Openstack.VolumeAvailabilityZones = map[string]config.VolumeAvailabilityZone{
+					"AZ": {VolumeType: "the-volume-type"},
+				}
+
+				_, err := methods.NewCreateDiskMethod(
+					&computeServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateDisk(
+					size,
+					apiv1.CloudPropsImpl{RawMessage: []byte(`{"type": "the-disk-type"}`)},
+					&apiv1.VMCID{},
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				_, cloudProps, az := volumeService.CreateVolumeArgsForCall(0)
+				Expect(az).To(Equal("AZ"))
+				Expect(cloudProps.VolumeType).To(Equal("the-disk-type"))
+			})
+
 			It("returns an error if GetServerAZ failed", func() {
 				computeService.GetServerAZReturns("", errors.New("boom"))
 
@@ -2,6 +2,8 @@ package methods
 
 import (
 	"fmt"
+	"net"
+	"slices"
 	"strconv"
 
 	"github.com/cloudfoundry/bosh-cpi-go/apiv1"
@@ -12,6 +14,7 @@ import (
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/network"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/properties"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/volume"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
 	"github.com/gophercloud/gophercloud/openstack/loadbalancer/v2/pools"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
@@ -22,6 +25,7 @@ type CreateVMMethod struct {
 	networkServiceBuilder      network.NetworkServiceBuilder
 	computeServiceBuilder      compute.ComputeServiceBuilder
 	loadbalancerServiceBuilder loadbalancer.LoadbalancerServiceBuilder
+	volumeServiceBuilder       volume.VolumeServiceBuilder
 	cpiConfig                  config.CpiConfig
 	logger                     utils.Logger
 }
@@ -31,6 +35,7 @@ func NewCreateVMMethod(
 	networkServiceBuilder network.NetworkServiceBuilder,
 	computeServiceBuilder compute.ComputeServiceBuilder,
 	loadbalancerServiceBuilder loadbalancer.LoadbalancerServiceBuilder,
+	volumeServiceBuilder volume.VolumeServiceBuilder,
 	cpiConfig config.CpiConfig,
 	logger utils.Logger,
 ) CreateVMMethod {
@@ -39,6 +44,7 @@ func NewCreateVMMethod(
 		networkServiceBuilder:      networkServiceBuilder,
 		computeServiceBuilder:      computeServiceBuilder,
 		loadbalancerServiceBuilder: loadbalancerServiceBuilder,
+		volumeServiceBuilder:       volumeServiceBuilder,
 		cpiConfig:                  cpiConfig,
 		logger:                     logger,
 	}
@@ -48,7 +54,10 @@ func (m CreateVMMethod) CreateVM(
 	agentID apiv1.AgentID, stemcellCID apiv1.StemcellCID, cloudProps apiv1.VMCloudProps,
 	networks apiv1.Networks, diskCIDs []apiv1.DiskCID, env apiv1.VMEnv) (apiv1.VMCID, error) {
 
-	return apiv1.VMCID{}, nil
+	// the agent settings are passed in the user data for both API versions, v1 only omits the network response
+	vmCID, _, err := m.CreateVMV2(agentID, stemcellCID, cloudProps, networks, diskCIDs, env)
+
+	return vmCID, err
 }
 
 func (m CreateVMMethod) CreateVMV2(
@@ -88,28 +97,112 @@ func (m CreateVMMethod) CreateVMV2(
 		return apiv1.VMCID{}, apiv1.Networks{}, fmt.Errorf("failed to create loadbalancer service: %w", err)
 	}
 
+	volumeService, err := m.volumeServiceBuilder.Build()
+	if err != nil {
+		return apiv1.VMCID{}, apiv1.Networks{}, fmt.Errorf("failed to create volume service: %w", err)
+	}
+
 	_, err = imageService.GetImage(stemcellCID.AsString())
 	if err != nil {
 		return apiv1.VMCID{}, apiv1.Networks{}, fmt.Errorf("failed to resolve stemcell: %w", err)
 	}
 
+	diskBus := m.getDiskBus(imageService, stemcellCID)
+
+	if len(diskCIDs) > 0 {
+		err = m.configureDiskAvailabilityZone(volumeService, diskCIDs, &cloudProps)
+		if err != nil {
+			return apiv1.VMCID{}, apiv1.Networks{}, fmt.Errorf("failed to select the availability zone of the persistent disks: %w", err)
+		}
+	}
+
 	networkConfig, err := networkService.GetNetworkConfiguration(networks, m.cpiConfig.Cloud.Properties.Openstack, cloudProps)
 	if err != nil {
 		return apiv1.VMCID{}, apiv1.Networks{}, fmt.Errorf("failed to create network config: %w", err)
 	}
 
+	boshEnv, err := properties.NewBoshEnv(env)
+	if err != nil {
+		return apiv1.VMCID{}, apiv1.Networks{}, fmt.Errorf("failed to parse vm environment: %w", err)
+	}
+	identity := properties.NewInstanceIdentity(boshEnv, agentID.AsString())
+
+	if len(cloudProps.ManagedSecurityGroups) > 0 {
+		managedSecurityGroupIDs, err := networkService.EnsureManagedSecurityGroups(cloudProps.ManagedSecurityGroups, boshEnv)
+		if err != nil {
+			return apiv1.VMCID{}, apiv1.Networks{}, fmt.Errorf("failed to configure managed security groups: %w", err)
+		}
+
+		networkConfig.AddSecurityGroups(managedSecurityGroupIDs)
+	}
+
+	var preCreatedPortIDs []string
 	manualNetworks := networkConfig.ManualNetworks
 	for i := 0; i < len(manualNetworks); i++ {
 		manualNetwork := &manualNetworks[i]
-		port, err := networkService.CreatePort(*manualNetwork, networkConfig.SecurityGroups, cloudProps)
-		if err != nil {
-			return apiv1.VMCID{}, apiv1.Networks{}, fmt.Errorf("failed to create port: %w", err)
+
+		var port ports.Port
+		if manualNetwork.CloudProps.PortID != "" {
+			port, err = networkService.GetPreCreatedPort(*manualNetwork)
+			if err != nil {
+				return m.cleanupServerResources(
+					nil,
+					createdPortsIds,
+					[]pools.Member{},
+					computeService,
+					loadbalancerService,
+					networkService,
+					fmt.Errorf("failed to use pre-created port: %w", err),
+				)
+			}
+			preCreatedPortIDs = append(preCreatedPortIDs, port.ID)
+		} else {
+			port, err = networkService.CreatePort(*manualNetwork, manualNetwork.SecurityGroupIDs, cloudProps, identity, m.cpiConfig.Cloud.Properties.Openstack)
+			if err != nil {
+				return m.cleanupServerResources(
+					nil,
+					createdPortsIds,
+					[]pools.Member{},
+					computeService,
+					loadbalancerService,
+					networkService,
+					fmt.Errorf("failed to create port: %w", err),
+				)
+			}
+			createdPortsIds = append(createdPortsIds, port)
 		}
 		manualNetwork.ConfigurePort(port)
-		createdPortsIds = append(createdPortsIds, port)
+
+		manualNetwork.AdditionalIPs, err = networkService.GetAdditionalIPs(*manualNetwork, port)
+		if err != nil {
+			return m.cleanupServerResources(
+				nil,
+				createdPortsIds,
+				[]pools.Member{},
+				computeService,
+				loadbalancerService,
+				networkService,
+				fmt.Errorf("failed to get additional ips of port '%s': %w", port.ID, err),
+			)
+		}
+	}
+
+	if m.cpiConfig.Cloud.Properties.Openstack.UseSubnetNetworkSettings {
+		err = m.applyNetworkSettings(networkService, &networkConfig)
+		if err != nil {
+			return m.cleanupServerResources(
+				nil,
+				createdPortsIds,
+				[]pools.Member{},
+				computeService,
+				loadbalancerService,
+				networkService,
+				fmt.Errorf("failed to apply network settings: %w", err),
+			)
+		}
 	}
 
-	server, err := computeService.CreateServer(stemcellCID, cloudProps, networkConfig, agentID, env, m.cpiConfig)
+	server, err := computeService.CreateServer(stemcellCID, cloudProps, networkConfig, agentID, env, diskBus, m.cpiConfig)
 	if err != nil {
 		return m.cleanupServerResources(
 			server,
@@ -122,7 +215,7 @@ func (m CreateVMMethod) CreateVMV2(
 		)
 	}
 
-	err = networkService.ConfigureVIPNetwork(server.ID, networkConfig)
+	floatingIP, err := networkService.ConfigureVIPNetwork(server.ID, networkConfig, identity)
 	if err != nil {
 		return m.cleanupServerResources(
 			server,
@@ -135,6 +228,12 @@ func (m CreateVMMethod) CreateVMV2(
 		)
 	}
 
+	if networkConfig.VIPNetwork != nil {
+		vipNetwork := *networkConfig.VIPNetwork
+		vipNetwork.IP = floatingIP
+		networkConfig.VIPNetwork = &vipNetwork
+	}
+
 	poolMembers, err := m.configureLoadbalancerPools(loadbalancerService, networkService, cloudProps, networkConfig)
 	if err != nil {
 		return m.cleanupServerResources(
@@ -148,7 +247,7 @@ func (m CreateVMMethod) CreateVMV2(
 		)
 	}
 
-	err = computeService.UpdateServerMetadata(server.ID, m.getServerMetadata(poolMembers))
+	err = computeService.UpdateServerMetadata(server.ID, m.getServerMetadata(poolMembers, preCreatedPortIDs))
 	if err != nil {
 		return m.cleanupServerResources(
 			server,
@@ -161,7 +260,112 @@ func (m CreateVMMethod) CreateVMV2(
 		)
 	}
 
-	return apiv1.NewVMCID(server.ID), networks, nil
+	err = m.tagBootVolume(volumeService, server, identity)
+	if err != nil {
+		m.logger.Warn("create_vm_method", fmt.Sprintf("failed to tag boot volume of server '%s': %v", server.ID, err))
+	}
+
+	err = m.configureDynamicNetwork(networkService, server.ID, &networkConfig)
+	if err != nil {
+		m.logger.Warn("create_vm_method", fmt.Sprintf("failed to get the addresses of the dynamic network of server '%s', returning the dynamic network unchanged: %v", server.ID, err))
+	}
+
+	return apiv1.NewVMCID(server.ID), m.getNetworksResponse(networks, networkConfig), nil
+}
+
+// configureDynamicNetwork sets the addresses Neutron assigned to the port of the dynamic network. The first
+// IPv4 address becomes the network IP, every other address of the port is added as an additional IP.
+func (m CreateVMMethod) configureDynamicNetwork(networkService network.NetworkService, serverID string, networkConfig *properties.NetworkConfig) error {
+	if networkConfig.DynamicNetwork == nil {
+		return nil
+	}
+	dynamicNetwork := *networkConfig.DynamicNetwork
+
+	serverPorts, err := networkService.GetPorts(serverID, dynamicNetwork, true)
+	if err != nil {
+		return err
+	}
+
+	if len(serverPorts) == 0 {
+		return fmt.Errorf("no port found in network '%s'", dynamicNetwork.CloudProps.NetID)
+	}
+	dynamicNetwork.ConfigurePort(serverPorts[0])
+
+	addresses, err := networkService.GetAdditionalIPs(dynamicNetwork, serverPorts[0])
+	if err != nil {
+		return fmt.Errorf("failed to get the ips of port '%s': %w", serverPorts[0].ID, err)
+	}
+
+	if len(addresses) > 0 {
+		primaryIndex := max(slices.IndexFunc(addresses, func(address properties.AdditionalIP) bool {
+			return net.ParseIP(address.IP).To4() != nil
+		}), 0)
+
+		dynamicNetwork.IP = addresses[primaryIndex].IP
+		dynamicNetwork.Netmask = addresses[primaryIndex].Netmask
+		dynamicNetwork.Gateway = addresses[primaryIndex].Gateway
+		dynamicNetwork.AdditionalIPs = slices.Delete(addresses, primaryIndex, primaryIndex+1)
+	}
+	networkConfig.DynamicNetwork = &dynamicNetwork
+
+	return nil
+}
+
+// getNetworksResponse adds a network for each additional IP of the manual and dynamic network ports,
+// e.g. the IPv6 address of a dual-stack port, the addresses assigned to the dynamic network and the
+// floating IP allocated for the vip network.
+func (m CreateVMMethod) getNetworksResponse(networks apiv1.Networks, networkConfig properties.NetworkConfig) apiv1.Networks {
+	networksResponse := apiv1.Networks{}
+	for key, network := range networks {
+		networksResponse[key] = network
+	}
+
+	if vipNetwork := networkConfig.VIPNetwork; vipNetwork != nil && networks[vipNetwork.Key] != nil && networks[vipNetwork.Key].IP() != vipNetwork.IP {
+		networksResponse[vipNetwork.Key] = apiv1.NewNetwork(apiv1.NetworkOpts{
+			Type:    vipNetwork.Type,
+			IP:      vipNetwork.IP,
+			Netmask: vipNetwork.Netmask,
+			Gateway: vipNetwork.Gateway,
+			DNS:     vipNetwork.DNS,
+			Default: vipNetwork.Default,
+		})
+	}
+
+	if dynamicNetwork := networkConfig.DynamicNetwork; dynamicNetwork != nil && networks[dynamicNetwork.Key] != nil && dynamicNetwork.IP != "" {
+		network := apiv1.NewNetwork(apiv1.NetworkOpts{
+			Type:    dynamicNetwork.Type,
+			IP:      dynamicNetwork.IP,
+			Netmask: dynamicNetwork.Netmask,
+			Gateway: dynamicNetwork.Gateway,
+			DNS:     dynamicNetwork.DNS,
+			Default: dynamicNetwork.Default,
+		})
+		network.SetMAC(dynamicNetwork.Mac)
+
+		networksResponse[dynamicNetwork.Key] = network
+	}
+
+	portNetworks := networkConfig.ManualNetworks
+	if networkConfig.DynamicNetwork != nil {
+		portNetworks = append(slices.Clone(portNetworks), *networkConfig.DynamicNetwork)
+	}
+
+	for _, portNetwork := range portNetworks {
+		for _, additionalNetwork := range portNetwork.AdditionalNetworks() {
+			network := apiv1.NewNetwork(apiv1.NetworkOpts{
+				Type:    additionalNetwork.Type,
+				IP:      additionalNetwork.IP,
+				Netmask: additionalNetwork.Netmask,
+				Gateway: additionalNetwork.Gateway,
+				DNS:     additionalNetwork.DNS,
+			})
+			network.SetMAC(additionalNetwork.Mac)
+
+			networksResponse[additionalNetwork.Key] = network
+		}
+	}
+
+	return networksResponse
 }
 
 func (m CreateVMMethod) configureLoadbalancerPools(
@@ -183,9 +387,12 @@ func (m CreateVMMethod) configureLoadbalancerPools(
 
 		defaultNetworkID := networkConfig.DefaultNetwork.CloudProps.NetID
 
-		subnetID, err := networkService.GetSubnetID(defaultNetworkID, ip)
-		if err != nil {
-			return poolMemberships, fmt.Errorf("failed to get subnet: %w", err)
+		subnetID := networkConfig.DefaultNetwork.CloudProps.SubnetID
+		if subnetID == "" {
+			subnetID, err = networkService.GetSubnetID(defaultNetworkID, ip)
+			if err != nil {
+				return poolMemberships, fmt.Errorf("failed to get subnet: %w", err)
+			}
 		}
 
 		poolMember, err := loadbalancerService.CreatePoolMember(pool, ip, poolProperties, subnetID, m.cpiConfig.Cloud.Properties.Openstack.StateTimeOut)
@@ -202,7 +409,9 @@ func (m CreateVMMethod) configureLoadbalancerPools(
 	return poolMemberships, nil
 }
 
-func (m CreateVMMethod) getServerMetadata(members []pools.Member) properties.ServerMetadata {
+// getServerMetadata records the pool memberships and the pre-created ports of the server,
+// so that delete_vm removes the pool members and keeps the pre-created ports.
+func (m CreateVMMethod) getServerMetadata(members []pools.Member, preCreatedPortIDs []string) properties.ServerMetadata {
 	tags := properties.ServerMetadata{}
 
 	var index = 1
@@ -212,9 +421,153 @@ func (m CreateVMMethod) getServerMetadata(members []pools.Member) properties.Ser
 		index++
 	}
 
+	for i, portID := range preCreatedPortIDs {
+		tags["pre_created_port_"+strconv.Itoa(i+1)] = portID
+	}
+
 	return tags
 }
 
+func (m CreateVMMethod) tagBootVolume(volumeService volume.VolumeService, server *servers.Server, identity properties.InstanceIdentity) error {
+	for _, attachedVolume := range server.AttachedVolumes {
+		bootVolume, err := volumeService.GetVolume(attachedVolume.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get volume '%s': %w", attachedVolume.ID, err)
+		}
+
+		if bootVolume.Bootable != "true" {
+			continue
+		}
+
+		metadata := map[string]string{
+			"server_id": server.ID,
+			"agent_id":  identity.AgentID,
+		}
+		for key, value := range map[string]string{
+			"director":       identity.Director,
+			"deployment":     identity.Deployment,
+			"instance_group": identity.InstanceGroup,
+		} {
+			if value != "" {
+				metadata[key] = value
+			}
+		}
+
+		err = volumeService.SetDiskMetadata(bootVolume.ID, metadata)
+		if err != nil {
+			return fmt.Errorf("failed to set metadata of volume '%s': %w", bootVolume.ID, err)
+		}
+		m.logger.Info("create_vm_method", fmt.Sprintf("tagged boot volume '%s' of server '%s'", bootVolume.ID, server.ID))
+	}
+
+	return nil
+}
+
+// getDiskBus returns the disk bus of the stemcell. The stemcell properties are not read if 'disk_bus' is configured,
+// the default bus is used if they cannot be read.
+func (m CreateVMMethod) getDiskBus(imageService image.ImageService, stemcellCID apiv1.StemcellCID) properties.DiskBus {
+	openstackConfig := m.cpiConfig.Cloud.Properties.Openstack
+	if openstackConfig.DiskBus != "" {
+		return properties.NewDiskBus(nil, openstackConfig)
+	}
+
+	imageProperties, err := imageService.GetImageProperties(stemcellCID.AsString())
+	if err != nil {
+		m.logger.Warn("create_vm_method", fmt.Sprintf("failed to resolve properties of stemcell '%s', using the default disk bus: %v", stemcellCID.AsString(), err))
+		return properties.NewDiskBus(nil, openstackConfig)
+	}
+
+	return properties.NewDiskBus(imageProperties, openstackConfig)
+}
+
+// configureDiskAvailabilityZone places the VM into the availability zone of its persistent disks. It is enforced,
+// unless 'ignore_server_availability_zone' allows attaching the disks across availability zones. The volume availability
+// zone of the disks is mapped back to the compute availability zones with 'volume_availability_zones'.
+func (m CreateVMMethod) configureDiskAvailabilityZone(volumeService volume.VolumeService, diskCIDs []apiv1.DiskCID, cloudProps *properties.CreateVM) error {
+	var diskZones []string
+	for _, diskCID := range diskCIDs {
+		disk, err := volumeService.GetVolume(diskCID.AsString())
+		if err != nil {
+			return fmt.Errorf("failed to get disk '%s': %w", diskCID.AsString(), err)
+		}
+
+		if disk.AvailabilityZone != "" && !slices.Contains(diskZones, disk.AvailabilityZone) {
+			diskZones = append(diskZones, disk.AvailabilityZone)
+		}
+	}
+
+	ignoreServerAvailabilityZone := m.cpiConfig.Cloud.Properties.Openstack.IgnoreServerAvailabilityZone
+	if len(diskZones) == 0 {
+		return nil
+	} else if len(diskZones) > 1 {
+		if ignoreServerAvailabilityZone {
+			m.logger.Info("create_vm_method", fmt.Sprintf("persistent disks are in multiple availability zones %v, not preferring any of them", diskZones))
+			return nil
+		}
+		return fmt.Errorf("persistent disks are in multiple availability zones %v", diskZones)
+	}
+
+	diskZone := diskZones[0]
+	computeZones := m.cpiConfig.Cloud.Properties.Openstack.GetComputeAvailabilityZones(diskZone)
+
+	configuredZones := cloudProps.AvailabilityZones
+	if len(configuredZones) == 0 && cloudProps.AvailabilityZone != "" {
+		configuredZones = []string{cloudProps.AvailabilityZone}
+	}
+
+	if len(configuredZones) > 0 {
+		computeZones = slices.DeleteFunc(computeZones, func(computeZone string) bool {
+			return !slices.Contains(configuredZones, computeZone)
+		})
+	}
+
+	if ignoreServerAvailabilityZone {
+		if len(computeZones) > 0 {
+			cloudProps.DiskAvailabilityZone = computeZones[0]
+		}
+		return nil
+	}
+
+	if len(computeZones) == 0 {
+		return fmt.Errorf("persistent disks are in availability zone '%s' which is not one of the configured availability zones %v", diskZone, configuredZones)
+	} else if len(computeZones) > 1 {
+		m.logger.Info("create_vm_method", fmt.Sprintf("persistent disks are in availability zone '%s' of the availability zones %v, not selecting any of them", diskZone, computeZones))
+		return nil
+	}
+
+	m.logger.Info("create_vm_method", fmt.Sprintf("using availability zone '%s' of the persistent disks", computeZones[0]))
+	cloudProps.AvailabilityZone = computeZones[0]
+	cloudProps.AvailabilityZones = nil
+
+	return nil
+}
+
+// applyNetworkSettings adds the subnet routes, DNS servers and MTU to the manual and dynamic networks.
+func (m CreateVMMethod) applyNetworkSettings(networkService network.NetworkService, networkConfig *properties.NetworkConfig) error {
+	for i := range networkConfig.ManualNetworks {
+		manualNetwork := &networkConfig.ManualNetworks[i]
+
+		settings, err := networkService.GetNetworkSettings(*manualNetwork)
+		if err != nil {
+			return fmt.Errorf("failed to get settings of network '%s': %w", manualNetwork.Key, err)
+		}
+		manualNetwork.ApplyNetworkSettings(settings)
+	}
+
+	if networkConfig.DynamicNetwork != nil {
+		dynamicNetwork := *networkConfig.DynamicNetwork
+
+		settings, err := networkService.GetNetworkSettings(dynamicNetwork)
+		if err != nil {
+			return fmt.Errorf("failed to get settings of network '%s': %w", dynamicNetwork.Key, err)
+		}
+		dynamicNetwork.ApplyNetworkSettings(settings)
+		networkConfig.DynamicNetwork = &dynamicNetwork
+	}
+
+	return nil
+}
+
 func (m CreateVMMethod) cleanupServerResources(
 	server *servers.Server, ports []ports.Port, poolMembers []pools.Member, computeService compute.ComputeService,
 	loadbalancerService loadbalancer.LoadbalancerService, networkService network.NetworkService, errorMsg error) (
@@ -238,10 +591,26 @@ func (m CreateVMMethod) cleanupServerResources(
 		}
 	}
 
-	err := networkService.DeletePorts(ports)
-	if err != nil {
-		m.logger.Warn("create_vm_method",
-			fmt.Sprintf("failed while cleaning up ports: '%+v' with error: %s", ports, err.Error()))
+	if server != nil {
+		err := networkService.ReleaseFloatingIPs(server.ID)
+		if err != nil {
+			m.logger.Warn("create_vm_method",
+				fmt.Sprintf("failed while releasing floating IPs of server '%s' with error: %s", server.ID, err.Error()))
+		}
+	}
+
+	if m.cpiConfig.Cloud.Properties.Openstack.PreservePorts {
+		err := networkService.ParkPorts(ports)
+		if err != nil {
+			m.logger.Warn("create_vm_method",
+				fmt.Sprintf("failed while parking ports: '%+v' with error: %s", ports, err.Error()))
+		}
+	} else {
+		err := networkService.DeletePorts(ports)
+		if err != nil {
+			m.logger.Warn("create_vm_method",
+				fmt.Sprintf("failed while cleaning up ports: '%+v' with error: %s", ports, err.Error()))
+		}
 	}
 
 	return apiv1.VMCID{}, apiv1.Networks{}, errorMsg
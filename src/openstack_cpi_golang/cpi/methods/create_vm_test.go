@@ -12,6 +12,8 @@ import (
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/network/networkfakes"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/properties"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils/utilsfakes"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/volume/volumefakes"
+	"github.com/gophercloud/gophercloud/openstack/blockstorage/v3/volumes"
 	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
 	"github.com/gophercloud/gophercloud/openstack/loadbalancer/v2/pools"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
@@ -25,10 +27,12 @@ var _ = Describe("CreateVMMethod", func() {
 	var networkServiceBuilder networkfakes.FakeNetworkServiceBuilder
 	var imageServiceBuilder imagefakes.FakeImageServiceBuilder
 	var loadbalancerServiceBuilder loadbalancerfakes.FakeLoadbalancerServiceBuilder
+	var volumeServiceBuilder volumefakes.FakeVolumeServiceBuilder
 	var computeService computefakes.FakeComputeService
 	var networkService networkfakes.FakeNetworkService
 	var imageService imagefakes.FakeImageService
 	var loadbalancerService loadbalancerfakes.FakeLoadbalancerService
+	var volumeService volumefakes.FakeVolumeService
 	var logger utilsfakes.FakeLogger
 	var networks apiv1.Networks
 	var jsonStr string
@@ -44,10 +48,12 @@ var _ = Describe("CreateVMMethod", func() {
 			networkServiceBuilder = networkfakes.FakeNetworkServiceBuilder{}
 			imageServiceBuilder = imagefakes.FakeImageServiceBuilder{}
 			loadbalancerServiceBuilder = loadbalancerfakes.FakeLoadbalancerServiceBuilder{}
+			volumeServiceBuilder = volumefakes.FakeVolumeServiceBuilder{}
 			computeService = computefakes.FakeComputeService{}
 			networkService = networkfakes.FakeNetworkService{}
 			imageService = imagefakes.FakeImageService{}
 			loadbalancerService = loadbalancerfakes.FakeLoadbalancerService{}
+			volumeService = volumefakes.FakeVolumeService{}
 			logger = utilsfakes.FakeLogger{}
 			env = apiv1.VMEnv{}
 
@@ -55,8 +61,9 @@ var _ = Describe("CreateVMMethod", func() {
 			networkServiceBuilder.BuildReturns(&networkService, nil)
 			imageServiceBuilder.BuildReturns(&imageService, nil)
 			loadbalancerServiceBuilder.BuildReturns(&loadbalancerService, nil)
+			volumeServiceBuilder.BuildReturns(&volumeService, nil)
 			computeService.CreateServerReturns(&servers.Server{ID: "123-456"}, nil)
-			networkService.ConfigureVIPNetworkReturns(nil)
+			networkService.ConfigureVIPNetworkReturns("", nil)
 
 			cpiConfig = config.CpiConfig{}
 			cpiConfig.Cloud.Properties.Openstack = config.OpenstackConfig{
@@ -106,6 +113,7 @@ var _ = Describe("CreateVMMethod", func() {
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -128,6 +136,7 @@ var _ = Describe("CreateVMMethod", func() {
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -150,6 +159,7 @@ var _ = Describe("CreateVMMethod", func() {
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -172,6 +182,7 @@ var _ = Describe("CreateVMMethod", func() {
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -194,6 +205,7 @@ var _ = Describe("CreateVMMethod", func() {
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -216,6 +228,7 @@ var _ = Describe("CreateVMMethod", func() {
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -238,6 +251,7 @@ var _ = Describe("CreateVMMethod", func() {
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -260,6 +274,7 @@ var _ = Describe("CreateVMMethod", func() {
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -276,14 +291,15 @@ var _ = Describe("CreateVMMethod", func() {
 				Expect(networks).To(Equal(apiv1.Networks{}))
 			})
 
-			It("returns an error if the stemcell cannot be found", func() {
-				imageService.GetImageReturns("", errors.New("boom"))
+			It("returns an error if the volume service cannot be retrieved", func() {
+				volumeServiceBuilder.BuildReturns(nil, errors.New("boom"))
 
 				stemcellCID, networks, err := methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -295,19 +311,20 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				Expect(err.Error()).To(ContainSubstring("failed to resolve stemcell: boom"))
+				Expect(err.Error()).To(Equal("failed to create volume service: boom"))
 				Expect(stemcellCID).To(Equal(apiv1.VMCID{}))
 				Expect(networks).To(Equal(apiv1.Networks{}))
 			})
 
-			It("returns an error if the network config creation fails", func() {
-				networkService.GetNetworkConfigurationReturns(properties.NetworkConfig{}, errors.New("boom"))
+			It("returns an error if the stemcell cannot be found", func() {
+				imageService.GetImageReturns("", errors.New("boom"))
 
 				stemcellCID, networks, err := methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -319,20 +336,20 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				Expect(err.Error()).To(ContainSubstring("failed to create network config: boom"))
+				Expect(err.Error()).To(ContainSubstring("failed to resolve stemcell: boom"))
 				Expect(stemcellCID).To(Equal(apiv1.VMCID{}))
 				Expect(networks).To(Equal(apiv1.Networks{}))
 			})
-		})
 
-		Context("Port creation", func() {
-			It("creates a port per manual network", func() {
+			It("uses the default disk bus if the stemcell properties cannot be retrieved", func() {
+				imageService.GetImagePropertiesReturns(nil, errors.New("boom"))
 
-				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+				_, _, err := methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -344,17 +361,24 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				Expect(networkService.CreatePortCallCount()).To(Equal(2))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(imageService.GetImagePropertiesArgsForCall(0)).To(Equal("stemcell-id"))
+				_, _, _, _, _, diskBus, _ := computeService.CreateServerArgsForCall(0)
+				Expect(diskBus).To(Equal(properties.DiskBus{}))
+				_, message, _ := logger.WarnArgsForCall(0)
+				Expect(message).To(Equal("failed to resolve properties of stemcell 'stemcell-id', using the default disk bus: boom"))
 			})
 
-			It("returns an error if port creation fails", func() {
-				networkService.CreatePortReturns(ports.Port{}, errors.New("boom"))
+			It("does not read the stemcell properties if the disk bus is configured", func() {
+				cpiConfig.Cloud.Properties.Openstack.DiskBus = "scsi"
+				imageService.GetImagePropertiesReturns(nil, errors.New("boom"))
 
 				_, _, err := methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -366,15 +390,21 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				Expect(err.Error()).To(Equal("failed to create port: boom"))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(imageService.GetImagePropertiesCallCount()).To(Equal(0))
+				_, _, _, _, _, diskBus, _ := computeService.CreateServerArgsForCall(0)
+				Expect(diskBus).To(Equal(properties.DiskBus{Bus: "scsi"}))
 			})
 
-			It("configures the created ports in the network config", func() {
-				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+			It("returns an error if the network config creation fails", func() {
+				networkService.GetNetworkConfigurationReturns(properties.NetworkConfig{}, errors.New("boom"))
+
+				stemcellCID, networks, err := methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -386,19 +416,34 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				manualNetworks := networkConfig.ManualNetworks
-				Expect(manualNetworks[0].Port).To(Equal(port))
-				Expect(manualNetworks[1].Port).To(Equal(port))
+				Expect(err.Error()).To(ContainSubstring("failed to create network config: boom"))
+				Expect(stemcellCID).To(Equal(apiv1.VMCID{}))
+				Expect(networks).To(Equal(apiv1.Networks{}))
 			})
 		})
 
-		Context("Server creation", func() {
-			It("creates a server", func() {
-				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+		Context("Managed security groups", func() {
+			BeforeEach(func() {
+				jsonStr = `{
+					"instance_type": "type1",
+					"managed_security_groups": [{"name": "web", "rules": [{"protocol": "tcp", "port_range_min": 443}]}]
+				}`
+				env = apiv1.NewVMEnv(map[string]interface{}{
+					"bosh": map[string]interface{}{"groups": []string{"the-director", "the-deployment"}},
+				})
+				networkConfig.SecurityGroups = []string{"the-default-group-id"}
+				networkConfig.ManualNetworks[0].SecurityGroupIDs = []string{"the-default-group-id"}
+				networkService.GetNetworkConfigurationReturns(networkConfig, nil)
+				networkService.EnsureManagedSecurityGroupsReturns([]string{"the-managed-group-id"}, nil)
+			})
+
+			It("attaches the managed security groups to the created ports", func() {
+				_, _, err := methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -410,20 +455,26 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				stemcellCID, _, _, agentID, environment, _ := computeService.CreateServerArgsForCall(0)
-				Expect(stemcellCID.AsString()).To(Equal("stemcell-id"))
-				Expect(agentID.AsString()).To(Equal("the_agent-id"))
-				Expect(environment).To(Equal(env))
+				Expect(err).ToNot(HaveOccurred())
+				managedSecurityGroups, boshEnv := networkService.EnsureManagedSecurityGroupsArgsForCall(0)
+				Expect(managedSecurityGroups[0].Name).To(Equal("web"))
+				Expect(boshEnv.Deployment()).To(Equal("the-deployment"))
+
+				_, securityGroups, _, _, _ := networkService.CreatePortArgsForCall(0)
+				Expect(securityGroups).To(Equal([]string{"the-default-group-id", "the-managed-group-id"}))
+				_, securityGroups, _, _, _ = networkService.CreatePortArgsForCall(1)
+				Expect(securityGroups).To(Equal([]string{"the-managed-group-id"}))
 			})
 
-			It("returns an error if the server creation fails", func() {
-				computeService.CreateServerReturns(nil, errors.New("boom"))
+			It("returns an error if the managed security groups cannot be configured", func() {
+				networkService.EnsureManagedSecurityGroupsReturns(nil, errors.New("boom"))
 
-				stemcellCID, networks, err := methods.NewCreateVMMethod(
+				_, _, err := methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -435,17 +486,20 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				Expect(err.Error()).To(Equal("failed to create server: boom"))
-				Expect(stemcellCID).To(Equal(apiv1.VMCID{}))
-				Expect(networks).To(Equal(apiv1.Networks{}))
+				Expect(err.Error()).To(Equal("failed to configure managed security groups: boom"))
+				Expect(networkService.CreatePortCallCount()).To(Equal(0))
 			})
+		})
 
-			It("returns a server ID and a network spec", func() {
-				stemcellCID, networkSpec, err := methods.NewCreateVMMethod(
+		Context("Port creation", func() {
+			It("creates a port per manual network", func() {
+
+				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -457,19 +511,20 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				Expect(err).ToNot(HaveOccurred())
-				Expect(stemcellCID.AsString()).To(Equal("123-456"))
-				Expect(networkSpec).To(Equal(networks))
+				Expect(networkService.CreatePortCallCount()).To(Equal(2))
 			})
-		})
 
-		Context("VIP Network configuration", func() {
-			It("configures the VIP network of the created server", func() {
-				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+			It("creates the ports with the security groups of their network", func() {
+				networkConfig.ManualNetworks[0].SecurityGroupIDs = []string{"the-group-id-1"}
+				networkConfig.ManualNetworks[1].SecurityGroupIDs = []string{"the-group-id-2"}
+				networkService.GetNetworkConfigurationReturns(networkConfig, nil)
+
+				_, _, err := methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -481,18 +536,24 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				serverID, _ := networkService.ConfigureVIPNetworkArgsForCall(0)
-				Expect(serverID).To(Equal("123-456"))
+				Expect(err).ToNot(HaveOccurred())
+				_, securityGroups, _, _, _ := networkService.CreatePortArgsForCall(0)
+				Expect(securityGroups).To(Equal([]string{"the-group-id-1"}))
+				_, securityGroups, _, _, _ = networkService.CreatePortArgsForCall(1)
+				Expect(securityGroups).To(Equal([]string{"the-group-id-2"}))
 			})
 
-			It("returns an error if the vip network configuration fails", func() {
-				networkService.ConfigureVIPNetworkReturns(errors.New("boom"))
+			It("creates the ports for the identity of the instance", func() {
+				env = apiv1.NewVMEnv(map[string]interface{}{
+					"bosh": map[string]interface{}{"groups": []string{"the-director", "the-deployment", "the-instance-group"}},
+				})
 
-				stemcellCID, networks, err := methods.NewCreateVMMethod(
+				_, _, err := methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -504,20 +565,25 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				Expect(err.Error()).To(Equal("failed to configure vip network for server '123-456' with error: boom"))
-				Expect(stemcellCID).To(Equal(apiv1.VMCID{}))
-				Expect(networks).To(Equal(apiv1.Networks{}))
+				Expect(err).ToNot(HaveOccurred())
+				_, _, _, identity, _ := networkService.CreatePortArgsForCall(0)
+				Expect(identity).To(Equal(properties.InstanceIdentity{
+					Director:      "the-director",
+					Deployment:    "the-deployment",
+					InstanceGroup: "the-instance-group",
+					AgentID:       "the_agent-id",
+				}))
 			})
-		})
 
-		Context("Loadbalancer configuration", func() {
-			It("gets pool ids of provided pools", func() {
+			It("returns an error if port creation fails", func() {
+				networkService.CreatePortReturns(ports.Port{}, errors.New("boom"))
 
-				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+				_, _, err := methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -529,18 +595,19 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				poolName := loadbalancerService.GetPoolArgsForCall(0)
-				Expect(poolName).To(Equal("the-pool-name-1"))
+				Expect(err.Error()).To(Equal("failed to create port: boom"))
 			})
 
-			It("returns an error if getting pool ids fails", func() {
-				loadbalancerService.GetPoolReturnsOnCall(0, pools.Pool{}, errors.New("boom"))
+			It("deletes the created ports if the port of another network cannot be created", func() {
+				networkService.CreatePortReturnsOnCall(0, port, nil)
+				networkService.CreatePortReturnsOnCall(1, ports.Port{}, errors.New("boom"))
 
 				_, _, err := methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -552,21 +619,18 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				Expect(err.Error()).To(ContainSubstring("failed to get pool ID of pool 'the-pool-name-1': boom"))
+				Expect(err.Error()).To(Equal("failed to create port: boom"))
+				Expect(networkService.DeletePortsArgsForCall(0)).To(Equal([]ports.Port{port}))
+				Expect(computeService.CreateServerCallCount()).To(Equal(0))
 			})
 
-			It("gets subnets of the default network", func() {
-				jsonStr := `{
-					"instance_type": "type1",
-					"loadbalancer_pools": [{"name": "the-pool-name-1","port": 1234,"monitoring_port": 5678}],
-					"availability_zones": ["z1", "z2"]
-				}`
-
+			It("configures the created ports in the network config", func() {
 				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -578,17 +642,21 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				Expect(loadbalancerService.CreatePoolMemberCallCount()).To(Equal(1))
+				manualNetworks := networkConfig.ManualNetworks
+				Expect(manualNetworks[0].Port).To(Equal(port))
+				Expect(manualNetworks[1].Port).To(Equal(port))
 			})
 
-			It("returns an error if getting subnets fails", func() {
-				networkService.GetSubnetIDReturns("", errors.New("boom"))
+			It("configures the additional ips of the created ports in the network config", func() {
+				additionalIPs := []properties.AdditionalIP{{SubnetID: "the-ipv6-subnet-id", IP: "fd00::10"}}
+				networkService.GetAdditionalIPsReturns(additionalIPs, nil)
 
-				_, _, err := methods.NewCreateVMMethod(
+				_, _, _ = methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -598,17 +666,22 @@ var _ = Describe("CreateVMMethod", func() {
 					networks,
 					[]apiv1.DiskCID{},
 					env,
-				)
+				) //nolint:errcheck
 
-				Expect(err.Error()).To(ContainSubstring("failed to get subnet: boom"))
+				Expect(networkService.GetAdditionalIPsCallCount()).To(Equal(2))
+				_, _, serverNetworkConfig, _, _, _, _ := computeService.CreateServerArgsForCall(0)
+				Expect(serverNetworkConfig.ManualNetworks[0].AdditionalIPs).To(Equal(additionalIPs))
 			})
 
-			It("Creates a single pool member", func() {
-				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+			It("deletes the created ports if getting additional ips fails", func() {
+				networkService.GetAdditionalIPsReturns(nil, errors.New("boom"))
+
+				_, _, err := methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -620,29 +693,30 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				pool, ip, poolProps, subnetID, stateTimeOut := loadbalancerService.CreatePoolMemberArgsForCall(0)
-				Expect(pool.ID).To(Equal("the-pool-id-1"))
-				Expect(ip).To(Equal("1.1.1.1"))
-				Expect(poolProps.Name).To(Equal("the-pool-name-1"))
-				Expect(subnetID).To(Equal("the-subnet-id"))
-				Expect(stateTimeOut).To(Equal(1))
+				Expect(err.Error()).To(Equal("failed to get additional ips of port 'the-port-id': boom"))
+				Expect(networkService.DeletePortsCallCount()).To(Equal(1))
+				Expect(computeService.CreateServerCallCount()).To(Equal(0))
 			})
+		})
 
-			It("Creates multiple pool members", func() {
-				jsonStr = `{
-					"instance_type": "type1",
-					"loadbalancer_pools": [
-						{"name": "the-pool-name-1","port": 1234,"monitoring_port": 5678},
-						{"name": "the-pool-name-2","port": 1234,"monitoring_port": 5678}
-					],
-					"availability_zones": ["z1", "z2"]
-				}`
+		Context("Pre-created ports", func() {
+			var preCreatedPort ports.Port
 
-				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+			BeforeEach(func() {
+				networkConfig.ManualNetworks[1].CloudProps.PortID = "the-pre-created-port-id"
+				networkService.GetNetworkConfigurationReturns(networkConfig, nil)
+				preCreatedPort = ports.Port{ID: "the-pre-created-port-id", MACAddress: "fa:16:3e:00:00:01"}
+				networkService.GetPreCreatedPortReturns(preCreatedPort, nil)
+				jsonStr = `{"instance_type": "type1"}`
+			})
+
+			It("uses the pre-created port instead of creating one", func() {
+				_, _, err := methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -654,29 +728,22 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				pool, ip, poolProps, subnetID, stateTimeOut := loadbalancerService.CreatePoolMemberArgsForCall(0)
-				Expect(pool.ID).To(Equal("the-pool-id-1"))
-				Expect(ip).To(Equal("1.1.1.1"))
-				Expect(poolProps.Name).To(Equal("the-pool-name-1"))
-				Expect(subnetID).To(Equal("the-subnet-id"))
-				Expect(stateTimeOut).To(Equal(1))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(networkService.CreatePortCallCount()).To(Equal(1))
+				Expect(networkService.GetPreCreatedPortArgsForCall(0).Key).To(Equal("key-2"))
 
-				pool, ip, poolProps, subnetID, stateTimeOut = loadbalancerService.CreatePoolMemberArgsForCall(1)
-				Expect(pool.ID).To(Equal("the-pool-id-2"))
-				Expect(ip).To(Equal("1.1.1.1"))
-				Expect(poolProps.Name).To(Equal("the-pool-name-2"))
-				Expect(subnetID).To(Equal("the-subnet-id"))
-				Expect(stateTimeOut).To(Equal(1))
+				_, _, serverNetworkConfig, _, _, _, _ := computeService.CreateServerArgsForCall(0)
+				Expect(serverNetworkConfig.ManualNetworks[1].Port).To(Equal(preCreatedPort))
+				Expect(serverNetworkConfig.ManualNetworks[1].Mac).To(Equal("fa:16:3e:00:00:01"))
 			})
 
-			It("returns an error if pool member creation fails", func() {
-				loadbalancerService.CreatePoolMemberReturns(nil, errors.New("boom"))
-
+			It("records the pre-created port in the server metadata", func() {
 				_, _, err := methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -688,29 +755,20 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				Expect(err.Error()).To(ContainSubstring("failed to create pool membership of IP '1.1.1.1' in pool 'the-pool-id-1': boom"))
+				Expect(err).ToNot(HaveOccurred())
+				_, metadata := computeService.UpdateServerMetadataArgsForCall(0)
+				Expect(metadata).To(Equal(properties.ServerMetadata{"pre_created_port_1": "the-pre-created-port-id"}))
 			})
-		})
-
-		Context("VM Metadata", func() {
-			It("sets VM metadata", func() {
-				jsonStr = `{
-					"instance_type": "type1",
-					"loadbalancer_pools": [
-						{"name": "the-pool-name-1","port": 1234,"monitoring_port": 5678},
-						{"name": "the-pool-name-2","port": 1234,"monitoring_port": 5678}
-					],
-					"availability_zones": ["z1", "z2"]
-				}`
 
-				loadbalancerService.CreatePoolMemberReturnsOnCall(0, &pools.Member{ID: "the-member-id", PoolID: "the-pool-id-1"}, nil)
-				loadbalancerService.CreatePoolMemberReturnsOnCall(1, &pools.Member{ID: "the-member-id-1", PoolID: "the-pool-id-2"}, nil)
+			It("does not delete the pre-created port if the server creation fails", func() {
+				computeService.CreateServerReturns(nil, errors.New("boom"))
 
-				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+				_, _, err := methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -722,30 +780,19 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				_, tags := computeService.UpdateServerMetadataArgsForCall(0)
-				Expect(tags["lbaas_pool_1"]).To(Equal("the-pool-id-1/the-member-id"))
-				Expect(tags["lbaas_pool_2"]).To(Equal("the-pool-id-2/the-member-id-1"))
+				Expect(err.Error()).To(Equal("failed to create server: boom"))
+				Expect(networkService.DeletePortsArgsForCall(0)).To(Equal([]ports.Port{port}))
 			})
 
-			It("returns an error if setting VM metadata fails", func() {
-				jsonStr = `{
-					"instance_type": "type1",
-					"loadbalancer_pools": [
-						{"name": "the-pool-name-1","port": 1234,"monitoring_port": 5678},
-						{"name": "the-pool-name-2","port": 1234,"monitoring_port": 5678}
-					],
-					"availability_zones": ["z1", "z2"]
-				}`
-
-				computeService.UpdateServerMetadataReturns(errors.New("boom"))
-				loadbalancerService.CreatePoolMemberReturnsOnCall(0, &pools.Member{ID: "the-member-id", PoolID: "the-pool-id-1"}, nil)
-				loadbalancerService.CreatePoolMemberReturnsOnCall(1, &pools.Member{ID: "the-member-id-1", PoolID: "the-pool-id-2"}, nil)
+			It("deletes the created ports if the pre-created port cannot be used", func() {
+				networkService.GetPreCreatedPortReturns(ports.Port{}, errors.New("boom"))
 
 				_, _, err := methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -757,19 +804,40 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				Expect(err.Error()).To(ContainSubstring("failed to update metadata for server '123-456' with error: boom"))
+				Expect(err.Error()).To(Equal("failed to use pre-created port: boom"))
+				Expect(networkService.DeletePortsArgsForCall(0)).To(Equal([]ports.Port{port}))
+				Expect(computeService.CreateServerCallCount()).To(Equal(0))
 			})
 		})
 
-		Context("Cleanup resources on error", func() {
-			It("deletes ports if server creation fails", func() {
-				computeService.CreateServerReturns(nil, errors.New("boom"))
+		Context("Network settings", func() {
+			var settings properties.NetworkSettings
+
+			BeforeEach(func() {
+				cpiConfig.Cloud.Properties.Openstack.UseSubnetNetworkSettings = true
+				networkConfig.DynamicNetwork = &properties.Network{
+					Key:        "key-3",
+					Type:       "dynamic",
+					CloudProps: properties.NetworkCloudProps{NetID: "the-net-id-3"},
+				}
+				networkService.GetNetworkConfigurationReturns(networkConfig, nil)
+				settings = properties.NetworkSettings{
+					Routes: []properties.Route{{Destination: "10.0.0.0", Gateway: "1.1.1.253", Netmask: "255.0.0.0"}},
+					DNS:    []string{"8.8.8.8"},
+					MTU:    1450,
+				}
+				networkService.GetNetworkSettingsReturns(settings, nil)
+				networkService.GetPortsReturns([]ports.Port{port}, nil)
+				jsonStr = `{"instance_type": "type1"}`
+			})
 
-				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+			It("applies the network settings to the manual and dynamic networks", func() {
+				_, _, err := methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -781,17 +849,27 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				Expect(networkService.DeletePortsCallCount()).To(Equal(1))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(networkService.GetNetworkSettingsCallCount()).To(Equal(3))
+				Expect(networkService.GetNetworkSettingsArgsForCall(2).Key).To(Equal("key-3"))
+
+				_, _, serverNetworkConfig, _, _, _, _ := computeService.CreateServerArgsForCall(0)
+				for _, network := range serverNetworkConfig.AllNetworks() {
+					Expect(network.Routes).To(Equal(settings.Routes))
+					Expect(network.DNS).To(Equal(settings.DNS))
+					Expect(network.MTU).To(Equal(1450))
+				}
 			})
 
-			It("deletes ports and server if configuring vip network fails", func() {
-				networkService.ConfigureVIPNetworkReturns(errors.New("boom"))
+			It("does not apply network settings if disabled", func() {
+				cpiConfig.Cloud.Properties.Openstack.UseSubnetNetworkSettings = false
 
-				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+				_, _, err := methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -803,18 +881,19 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				Expect(networkService.DeletePortsCallCount()).To(Equal(1))
-				Expect(computeService.DeleteServerCallCount()).To(Equal(1))
+				Expect(err).ToNot(HaveOccurred())
+				Expect(networkService.GetNetworkSettingsCallCount()).To(Equal(0))
 			})
 
-			It("deletes ports, server, and pool members if update server metadata fails", func() {
-				networkService.ConfigureVIPNetworkReturns(errors.New("boom"))
+			It("deletes the created ports if the network settings cannot be retrieved", func() {
+				networkService.GetNetworkSettingsReturns(properties.NetworkSettings{}, errors.New("boom"))
 
-				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+				_, _, err := methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -826,32 +905,46 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				Expect(networkService.DeletePortsCallCount()).To(Equal(1))
-				Expect(computeService.DeleteServerCallCount()).To(Equal(1))
+				Expect(err.Error()).To(Equal("failed to apply network settings: failed to get settings of network 'key-1': boom"))
+				Expect(networkService.DeletePortsArgsForCall(0)).To(Equal([]ports.Port{port, port}))
+				Expect(computeService.CreateServerCallCount()).To(Equal(0))
 			})
+		})
 
-			It("ignores errors from cleaning up resources", func() {
-				jsonStr = `{
-					"instance_type": "type1",
-					"loadbalancer_pools": [
-						{"name": "the-pool-name-1","port": 1234,"monitoring_port": 5678},
-						{"name": "the-pool-name-2","port": 1234,"monitoring_port": 5678}
-					],
-					"availability_zones": ["z1", "z2"]
-				}`
+		Context("Server creation", func() {
+			It("creates a server", func() {
+				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
 
-				loadbalancerService.DeletePoolMemberReturns(errors.New("boom"))
-				computeService.DeleteServerReturns(errors.New("boom"))
-				networkService.DeletePortsReturns(errors.New("boom"))
-				computeService.UpdateServerMetadataReturns(errors.New("boom"))
-				loadbalancerService.CreatePoolMemberReturnsOnCall(0, &pools.Member{ID: "the-member-id", PoolID: "the-pool-id-1"}, nil)
-				loadbalancerService.CreatePoolMemberReturnsOnCall(1, &pools.Member{ID: "the-member-id-1", PoolID: "the-pool-id-2"}, nil)
+				stemcellCID, _, _, agentID, environment, _, _ := computeService.CreateServerArgsForCall(0)
+				Expect(stemcellCID.AsString()).To(Equal("stemcell-id"))
+				Expect(agentID.AsString()).To(Equal("the_agent-id"))
+				Expect(environment).To(Equal(env))
+			})
+
+			It("creates the server with the disk bus of the stemcell", func() {
+				imageService.GetImagePropertiesReturns(map[string]interface{}{"hw_disk_bus": "virtio"}, nil)
 
 				_, _, err := methods.NewCreateVMMethod(
 					&imageServiceBuilder,
 					&networkServiceBuilder,
 					&computeServiceBuilder,
 					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
 					cpiConfig,
 					&logger,
 				).CreateVMV2(
@@ -863,8 +956,1248 @@ var _ = Describe("CreateVMMethod", func() {
 					env,
 				)
 
-				Expect(err.Error()).To(ContainSubstring("failed to update metadata for server '123-456' with error: boom"))
+				Expect(err).ToNot(HaveOccurred())
+				_, _, _, _, _, diskBus, _ := computeService.CreateServerArgsForCall(0)
+				Expect(diskBus).To(Equal(properties.DiskBus{Bus: "virtio"}))
 			})
-		})
-	})
-})
+
+			It("returns an error if the server creation fails", func() {
+				computeService.CreateServerReturns(nil, errors.New("boom"))
+
+				stemcellCID, networks, err := methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(err.Error()).To(Equal("failed to create server: boom"))
+				Expect(stemcellCID).To(Equal(apiv1.VMCID{}))
+				Expect(networks).To(Equal(apiv1.Networks{}))
+			})
+
+			It("returns a server ID and a network spec", func() {
+				stemcellCID, networkSpec, err := methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stemcellCID.AsString()).To(Equal("123-456"))
+				Expect(networkSpec).To(Equal(networks))
+			})
+
+			It("returns a network per additional ip of the created ports", func() {
+				networkService.GetAdditionalIPsReturnsOnCall(0, []properties.AdditionalIP{
+					{SubnetID: "the-ipv6-subnet-id", IP: "fd00::10", Netmask: "ffff:ffff:ffff:ffff::", Gateway: "fd00::1"},
+				}, nil)
+
+				_, networkSpec, err := methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(networkSpec).To(HaveLen(len(networks) + 1))
+				Expect(networkSpec["key-1_1"].IP()).To(Equal("fd00::10"))
+				Expect(networkSpec["key-1_1"].Netmask()).To(Equal("ffff:ffff:ffff:ffff::"))
+				Expect(networkSpec["key-1_1"].Gateway()).To(Equal("fd00::1"))
+				Expect(networkSpec["key-1_1"].Type()).To(Equal("manual"))
+			})
+
+			Context("with a dynamic network", func() {
+				BeforeEach(func() {
+					networkConfig.DynamicNetwork = &properties.Network{
+						Key:        "the-dynamic",
+						Type:       "dynamic",
+						DNS:        []string{"8.8.8.8"},
+						Default:    []string{"dns", "gateway"},
+						CloudProps: properties.NetworkCloudProps{NetID: "the-dynamic-net-id"},
+					}
+					networkService.GetNetworkConfigurationReturns(networkConfig, nil)
+					networkService.GetPortsReturns([]ports.Port{{ID: "the-dynamic-port-id", MACAddress: "fa:16:3e:00:00:02"}}, nil)
+					networkService.GetAdditionalIPsReturnsOnCall(2, []properties.AdditionalIP{
+						{SubnetID: "the-ipv6-subnet-id", IP: "fd00::20", Netmask: "ffff:ffff:ffff:ffff::", Gateway: "fd00::1"},
+						{SubnetID: "the-subnet-id", IP: "3.3.3.3", Netmask: "255.255.255.0", Gateway: "3.3.3.1"},
+					}, nil)
+					networks = apiv1.Networks{"the-dynamic": apiv1.NewNetwork(apiv1.NetworkOpts{Type: "dynamic"})}
+				})
+
+				It("returns the addresses assigned to the port of the dynamic network", func() {
+					_, networkSpec, err := methods.NewCreateVMMethod(
+						&imageServiceBuilder,
+						&networkServiceBuilder,
+						&computeServiceBuilder,
+						&loadbalancerServiceBuilder,
+						&volumeServiceBuilder,
+						cpiConfig,
+						&logger,
+					).CreateVMV2(
+						apiv1.NewAgentID("the_agent-id"),
+						apiv1.NewStemcellCID("stemcell-id"),
+						apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+						networks,
+						[]apiv1.DiskCID{},
+						env,
+					)
+
+					Expect(err).ToNot(HaveOccurred())
+					serverID, portNetwork, retryable := networkService.GetPortsArgsForCall(0)
+					Expect(serverID).To(Equal("123-456"))
+					Expect(portNetwork.CloudProps.NetID).To(Equal("the-dynamic-net-id"))
+					Expect(retryable).To(BeTrue())
+					dynamicNetwork, dynamicPort := networkService.GetAdditionalIPsArgsForCall(2)
+					Expect(dynamicPort.ID).To(Equal("the-dynamic-port-id"))
+					Expect(dynamicNetwork).To(Equal(properties.Network{
+						Key:        "the-dynamic",
+						Type:       "dynamic",
+						DNS:        []string{"8.8.8.8"},
+						Default:    []string{"dns", "gateway"},
+						CloudProps: properties.NetworkCloudProps{NetID: "the-dynamic-net-id"},
+						Port:       ports.Port{ID: "the-dynamic-port-id", MACAddress: "fa:16:3e:00:00:02"},
+						Mac:        "fa:16:3e:00:00:02",
+					}))
+
+					Expect(networkSpec["the-dynamic"].Type()).To(Equal("dynamic"))
+					Expect(networkSpec["the-dynamic"].IP()).To(Equal("3.3.3.3"))
+					Expect(networkSpec["the-dynamic"].Netmask()).To(Equal("255.255.255.0"))
+					Expect(networkSpec["the-dynamic"].Gateway()).To(Equal("3.3.3.1"))
+					Expect(networkSpec["the-dynamic"].DNS()).To(Equal([]string{"8.8.8.8"}))
+					Expect(networkSpec["the-dynamic"].Default()).To(Equal([]string{"dns", "gateway"}))
+					Expect(networkSpec["the-dynamic_1"].IP()).To(Equal("fd00::20"))
+					Expect(networkSpec["the-dynamic_1"].Gateway()).To(Equal("fd00::1"))
+					Expect(networks["the-dynamic"].IP()).To(BeEmpty())
+				})
+
+				It("keeps the server and returns the dynamic network unchanged if the port of the dynamic network cannot be found", func() {
+					networkService.GetPortsReturns([]ports.Port{}, nil)
+
+					_, networkSpec, err := methods.NewCreateVMMethod(
+						&imageServiceBuilder,
+						&networkServiceBuilder,
+						&computeServiceBuilder,
+						&loadbalancerServiceBuilder,
+						&volumeServiceBuilder,
+						cpiConfig,
+						&logger,
+					).CreateVMV2(
+						apiv1.NewAgentID("the_agent-id"),
+						apiv1.NewStemcellCID("stemcell-id"),
+						apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+						networks,
+						[]apiv1.DiskCID{},
+						env,
+					)
+
+					Expect(err).ToNot(HaveOccurred())
+					Expect(computeService.DeleteServerCallCount()).To(Equal(0))
+					Expect(networkSpec["the-dynamic"].IP()).To(BeEmpty())
+					_, message, _ := logger.WarnArgsForCall(0)
+					Expect(message).To(Equal("failed to get the addresses of the dynamic network of server '123-456', returning the dynamic network unchanged: no port found in network 'the-dynamic-net-id'"))
+				})
+
+				It("keeps the server and returns the dynamic network unchanged if the addresses of the dynamic network cannot be retrieved", func() {
+					networkService.GetAdditionalIPsReturnsOnCall(2, nil, errors.New("boom"))
+
+					_, networkSpec, err := methods.NewCreateVMMethod(
+						&imageServiceBuilder,
+						&networkServiceBuilder,
+						&computeServiceBuilder,
+						&loadbalancerServiceBuilder,
+						&volumeServiceBuilder,
+						cpiConfig,
+						&logger,
+					).CreateVMV2(
+						apiv1.NewAgentID("the_agent-id"),
+						apiv1.NewStemcellCID("stemcell-id"),
+						apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+						networks,
+						[]apiv1.DiskCID{},
+						env,
+					)
+
+					Expect(err).ToNot(HaveOccurred())
+					Expect(computeService.DeleteServerCallCount()).To(Equal(0))
+					Expect(networkSpec["the-dynamic"].IP()).To(BeEmpty())
+					_, message, _ := logger.WarnArgsForCall(0)
+					Expect(message).To(Equal("failed to get the addresses of the dynamic network of server '123-456', returning the dynamic network unchanged: failed to get the ips of port 'the-dynamic-port-id': boom"))
+				})
+			})
+
+			It("returns the floating ip allocated for the vip network", func() {
+				networkConfig.VIPNetwork = &properties.Network{
+					Key:        "the-vip",
+					Type:       "vip",
+					CloudProps: properties.NetworkCloudProps{FloatingNetworkID: "the-floating-net-id"},
+				}
+				networkService.GetNetworkConfigurationReturns(networkConfig, nil)
+				networkService.ConfigureVIPNetworkReturns("4.4.4.4", nil)
+				networks = apiv1.Networks{"the-vip": apiv1.NewNetwork(apiv1.NetworkOpts{Type: "vip"})}
+
+				_, networkSpec, err := methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(networkSpec["the-vip"].IP()).To(Equal("4.4.4.4"))
+				Expect(networkSpec["the-vip"].Type()).To(Equal("vip"))
+				Expect(networks["the-vip"].IP()).To(BeEmpty())
+			})
+		})
+
+		Context("VIP Network configuration", func() {
+			It("configures the VIP network of the created server", func() {
+				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				serverID, _, identity := networkService.ConfigureVIPNetworkArgsForCall(0)
+				Expect(serverID).To(Equal("123-456"))
+				Expect(identity.AgentID).To(Equal("the_agent-id"))
+			})
+
+			It("returns an error if the vip network configuration fails", func() {
+				networkService.ConfigureVIPNetworkReturns("", errors.New("boom"))
+
+				stemcellCID, networks, err := methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(err.Error()).To(Equal("failed to configure vip network for server '123-456' with error: boom"))
+				Expect(stemcellCID).To(Equal(apiv1.VMCID{}))
+				Expect(networks).To(Equal(apiv1.Networks{}))
+			})
+		})
+
+		Context("Loadbalancer configuration", func() {
+			It("gets pool ids of provided pools", func() {
+
+				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				poolName := loadbalancerService.GetPoolArgsForCall(0)
+				Expect(poolName).To(Equal("the-pool-name-1"))
+			})
+
+			It("returns an error if getting pool ids fails", func() {
+				loadbalancerService.GetPoolReturnsOnCall(0, pools.Pool{}, errors.New("boom"))
+
+				_, _, err := methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(err.Error()).To(ContainSubstring("failed to get pool ID of pool 'the-pool-name-1': boom"))
+			})
+
+			It("gets subnets of the default network", func() {
+				jsonStr := `{
+					"instance_type": "type1",
+					"loadbalancer_pools": [{"name": "the-pool-name-1","port": 1234,"monitoring_port": 5678}],
+					"availability_zones": ["z1", "z2"]
+				}`
+
+				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(loadbalancerService.CreatePoolMemberCallCount()).To(Equal(1))
+				networkID, ip := networkService.GetSubnetIDArgsForCall(0)
+				Expect(networkID).To(Equal("the-net-id"))
+				Expect(ip).To(Equal("1.1.1.1"))
+			})
+
+			It("returns an error if getting subnets fails", func() {
+				networkService.GetSubnetIDReturns("", errors.New("boom"))
+
+				_, _, err := methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(err.Error()).To(ContainSubstring("failed to get subnet: boom"))
+			})
+
+			It("Creates a single pool member", func() {
+				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				pool, ip, poolProps, subnetID, stateTimeOut := loadbalancerService.CreatePoolMemberArgsForCall(0)
+				Expect(pool.ID).To(Equal("the-pool-id-1"))
+				Expect(ip).To(Equal("1.1.1.1"))
+				Expect(poolProps.Name).To(Equal("the-pool-name-1"))
+				Expect(subnetID).To(Equal("the-subnet-id"))
+				Expect(stateTimeOut).To(Equal(1))
+			})
+
+			It("Creates multiple pool members", func() {
+				jsonStr = `{
+					"instance_type": "type1",
+					"loadbalancer_pools": [
+						{"name": "the-pool-name-1","port": 1234,"monitoring_port": 5678},
+						{"name": "the-pool-name-2","port": 1234,"monitoring_port": 5678}
+					],
+					"availability_zones": ["z1", "z2"]
+				}`
+
+				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				pool, ip, poolProps, subnetID, stateTimeOut := loadbalancerService.CreatePoolMemberArgsForCall(0)
+				Expect(pool.ID).To(Equal("the-pool-id-1"))
+				Expect(ip).To(Equal("1.1.1.1"))
+				Expect(poolProps.Name).To(Equal("the-pool-name-1"))
+				Expect(subnetID).To(Equal("the-subnet-id"))
+				Expect(stateTimeOut).To(Equal(1))
+
+				pool, ip, poolProps, subnetID, stateTimeOut = loadbalancerService.CreatePoolMemberArgsForCall(1)
+				Expect(pool.ID).To(Equal("the-pool-id-2"))
+				Expect(ip).To(Equal("1.1.1.1"))
+				Expect(poolProps.Name).To(Equal("the-pool-name-2"))
+				Expect(subnetID).To(Equal("the-subnet-id"))
+				Expect(stateTimeOut).To(Equal(1))
+			})
+
+			It("returns an error if pool member creation fails", func() {
+				loadbalancerService.CreatePoolMemberReturns(nil, errors.New("boom"))
+
+				_, _, err := methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(err.Error()).To(ContainSubstring("failed to create pool membership of IP '1.1.1.1' in pool 'the-pool-id-1': boom"))
+			})
+		})
+
+		Context("VM Metadata", func() {
+			It("sets VM metadata", func() {
+				jsonStr = `{
+					"instance_type": "type1",
+					"loadbalancer_pools": [
+						{"name": "the-pool-name-1","port": 1234,"monitoring_port": 5678},
+						{"name": "the-pool-name-2","port": 1234,"monitoring_port": 5678}
+					],
+					"availability_zones": ["z1", "z2"]
+				}`
+
+				loadbalancerService.CreatePoolMemberReturnsOnCall(0, &pools.Member{ID: "the-member-id", PoolID: "the-pool-id-1"}, nil)
+				loadbalancerService.CreatePoolMemberReturnsOnCall(1, &pools.Member{ID: "the-member-id-1", PoolID: "the-pool-id-2"}, nil)
+
+				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				_, tags := computeService.UpdateServerMetadataArgsForCall(0)
+				Expect(tags["lbaas_pool_1"]).To(Equal("the-pool-id-1/the-member-id"))
+				Expect(tags["lbaas_pool_2"]).To(Equal("the-pool-id-2/the-member-id-1"))
+			})
+
+			It("returns an error if setting VM metadata fails", func() {
+				jsonStr = `{
+					"instance_type": "type1",
+					"loadbalancer_pools": [
+						{"name": "the-pool-name-1","port": 1234,"monitoring_port": 5678},
+						{"name": "the-pool-name-2","port": 1234,"monitoring_port": 5678}
+					],
+					"availability_zones": ["z1", "z2"]
+				}`
+
+				computeService.UpdateServerMetadataReturns(errors.New("boom"))
+				loadbalancerService.CreatePoolMemberReturnsOnCall(0, &pools.Member{ID: "the-member-id", PoolID: "the-pool-id-1"}, nil)
+				loadbalancerService.CreatePoolMemberReturnsOnCall(1, &pools.Member{ID: "the-member-id-1", PoolID: "the-pool-id-2"}, nil)
+
+				_, _, err := methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(err.Error()).To(ContainSubstring("failed to update metadata for server '123-456' with error: boom"))
+			})
+		})
+
+		Context("Persistent disk availability zone", func() {
+			BeforeEach(func() {
+				cpiConfig.Cloud.Properties.Openstack.IgnoreServerAvailabilityZone = false
+				volumeService.GetVolumeReturns(&volumes.Volume{AvailabilityZone: "z2"}, nil)
+				jsonStr = `{"instance_type": "type1"}`
+			})
+
+			It("does not look up disks without persistent disks", func() {
+				_, _, err := methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(volumeService.GetVolumeCallCount()).To(Equal(0))
+			})
+
+			It("places the server into the availability zone of the persistent disks", func() {
+				_, _, err := methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{apiv1.NewDiskCID("disk-1"), apiv1.NewDiskCID("disk-2")},
+					env,
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(volumeService.GetVolumeArgsForCall(0)).To(Equal("disk-1"))
+				Expect(volumeService.GetVolumeArgsForCall(1)).To(Equal("disk-2"))
+				_, cloudProps, _, _, _, _, _ := computeService.CreateServerArgsForCall(0)
+				Expect(cloudProps.AvailabilityZone).To(Equal("z2"))
+			})
+
+			It("returns an error if the persistent disks are in another availability zone than configured", func() {
+				jsonStr = `{"instance_type": "type1", "availability_zone": "z1"}`
+
+				_, _, err := methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{apiv1.NewDiskCID("disk-1"), apiv1.NewDiskCID("disk-2")},
+					env,
+				)
+
+				Expect(err.Error()).To(Equal("failed to select the availability zone of the persistent disks: persistent disks are in availability zone 'z2' which is not one of the configured availability zones [z1]"))
+				Expect(computeService.CreateServerCallCount()).To(Equal(0))
+			})
+
+			It("places the server into the availability zone of the persistent disks if it is one of the configured ones", func() {
+				jsonStr = `{"instance_type": "type1", "availability_zones": ["z2"]}`
+
+				_, _, err := methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{apiv1.NewDiskCID("disk-1")},
+					env,
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				_, cloudProps, _, _, _, _, _ := computeService.CreateServerArgsForCall(0)
+				Expect(cloudProps.AvailabilityZone).To(Equal("z2"))
+				Expect(cloudProps.AvailabilityZones).To(BeEmpty())
+			})
+
+			It("returns an error if the persistent disks are in none of the configured availability zones", func() {
+				jsonStr = `{"instance_type": "type1", "availability_zones": ["z1"]}`
+
+				_, _, err := methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{apiv1.NewDiskCID("disk-1")},
+					env,
+				)
+
+				Expect(err.Error()).To(Equal("failed to select the availability zone of the persistent disks: persistent disks are in availability zone 'z2' which is not one of the configured availability zones [z1]"))
+				Expect(computeService.CreateServerCallCount()).To(Equal(0))
+			})
+
+			It("returns an error if the persistent disks are in multiple availability zones", func() {
+				volumeService.GetVolumeReturnsOnCall(1, &volumes.Volume{AvailabilityZone: "z3"}, nil)
+
+				_, _, err := methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{apiv1.NewDiskCID("disk-1"), apiv1.NewDiskCID("disk-2")},
+					env,
+				)
+
+				Expect(err.Error()).To(Equal("failed to select the availability zone of the persistent disks: persistent disks are in multiple availability zones [z2 z3]"))
+				Expect(computeService.CreateServerCallCount()).To(Equal(0))
+			})
+
+			It("returns an error if a persistent disk cannot be retrieved", func() {
+				volumeService.GetVolumeReturns(nil, errors.New("boom"))
+
+				_, _, err := methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{apiv1.NewDiskCID("disk-1"), apiv1.NewDiskCID("disk-2")},
+					env,
+				)
+
+				Expect(err.Error()).To(Equal("failed to select the availability zone of the persistent disks: failed to get disk 'disk-1': boom"))
+			})
+
+			Context("when volume availability zones are mapped", func() {
+				BeforeEach(func() {
+					cpiConfig.Cloud.Properties.Openstack.VolumeAvailabilityZones = map[string]config.VolumeAvailabilityZone{
+						"az1-compute": {AvailabilityZone: "az1-storage"},
+						"az2-compute": {AvailabilityZone: "az2-storage"},
+					}
+					volumeService.GetVolumeReturns(&volumes.Volume{AvailabilityZone: "az1-storage"}, nil)
+				})
+
+				It("places the server into the compute availability zone mapped to the availability zone of the persistent disks", func() {
+					_, _, err := methods.NewCreateVMMethod(
+						&imageServiceBuilder,
+						&networkServiceBuilder,
+						&computeServiceBuilder,
+						&loadbalancerServiceBuilder,
+						&volumeServiceBuilder,
+						cpiConfig,
+						&logger,
+					).CreateVMV2(
+						apiv1.NewAgentID("the_agent-id"),
+						apiv1.NewStemcellCID("stemcell-id"),
+						apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+						networks,
+						[]apiv1.DiskCID{apiv1.NewDiskCID("disk-1")},
+						env,
+					)
+
+					Expect(err).ToNot(HaveOccurred())
+					_, cloudProps, _, _, _, _, _ := computeService.CreateServerArgsForCall(0)
+					Expect(cloudProps.AvailabilityZone).To(Equal("az1-compute"))
+				})
+
+				It("accepts the configured compute availability zone mapped to the availability zone of the persistent disks", func() {
+					jsonStr = `{"instance_type": "type1", "availability_zone": "az1-compute"}`
+
+					_, _, err := methods.NewCreateVMMethod(
+						&imageServiceBuilder,
+						&networkServiceBuilder,
+						&computeServiceBuilder,
+						&loadbalancerServiceBuilder,
+						&volumeServiceBuilder,
+						cpiConfig,
+						&logger,
+					).CreateVMV2(
+						apiv1.NewAgentID("the_agent-id"),
+						apiv1.NewStemcellCID("stemcell-id"),
+						apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+						networks,
+						[]apiv1.DiskCID{apiv1.NewDiskCID("disk-1")},
+						env,
+					)
+
+					Expect(err).ToNot(HaveOccurred())
+					_, cloudProps, _, _, _, _, _ := computeService.CreateServerArgsForCall(0)
+					Expect(cloudProps.AvailabilityZone).To(Equal("az1-compute"))
+				})
+
+				It("returns an error if the configured compute availability zone is mapped to another volume availability zone", func() {
+					jsonStr = `{"instance_type": "type1", "availability_zone": "az2-compute"}`
+
+					_, _, err := methods.NewCreateVMMethod(
+						&imageServiceBuilder,
+						&networkServiceBuilder,
+						&computeServiceBuilder,
+						&loadbalancerServiceBuilder,
+						&volumeServiceBuilder,
+						cpiConfig,
+						&logger,
+					).CreateVMV2(
+						apiv1.NewAgentID("the_agent-id"),
+						apiv1.NewStemcellCID("stemcell-id"),
+						apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+						networks,
+						[]apiv1.DiskCID{apiv1.NewDiskCID("disk-1")},
+						env,
+					)
+
+					Expect(err.Error()).To(Equal("failed to select the availability zone of the persistent disks: persistent disks are in availability zone 'az1-storage' which is not one of the configured availability zones [az2-compute]"))
+				})
+
+				It("prefers the mapped compute availability zone of the configured ones when ignoring the server availability zone", func() {
+					cpiConfig.Cloud.Properties.Openstack.IgnoreServerAvailabilityZone = true
+					jsonStr = `{"instance_type": "type1", "availability_zones": ["az2-compute", "az1-compute"]}`
+
+					_, _, err := methods.NewCreateVMMethod(
+						&imageServiceBuilder,
+						&networkServiceBuilder,
+						&computeServiceBuilder,
+						&loadbalancerServiceBuilder,
+						&volumeServiceBuilder,
+						cpiConfig,
+						&logger,
+					).CreateVMV2(
+						apiv1.NewAgentID("the_agent-id"),
+						apiv1.NewStemcellCID("stemcell-id"),
+						apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+						networks,
+						[]apiv1.DiskCID{apiv1.NewDiskCID("disk-1")},
+						env,
+					)
+
+					Expect(err).ToNot(HaveOccurred())
+					_, cloudProps, _, _, _, _, _ := computeService.CreateServerArgsForCall(0)
+					Expect(cloudProps.DiskAvailabilityZone).To(Equal("az1-compute"))
+				})
+			})
+
+			Context("when ignoring the server availability zone", func() {
+				BeforeEach(func() {
+					cpiConfig.Cloud.Properties.Openstack.IgnoreServerAvailabilityZone = true
+					jsonStr = `{"instance_type": "type1", "availability_zones": ["z1", "z2"]}`
+				})
+
+				It("prefers the availability zone of the persistent disks", func() {
+					_, _, err := methods.NewCreateVMMethod(
+						&imageServiceBuilder,
+						&networkServiceBuilder,
+						&computeServiceBuilder,
+						&loadbalancerServiceBuilder,
+						&volumeServiceBuilder,
+						cpiConfig,
+						&logger,
+					).CreateVMV2(
+						apiv1.NewAgentID("the_agent-id"),
+						apiv1.NewStemcellCID("stemcell-id"),
+						apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+						networks,
+						[]apiv1.DiskCID{apiv1.NewDiskCID("disk-1"), apiv1.NewDiskCID("disk-2")},
+						env,
+					)
+
+					Expect(err).ToNot(HaveOccurred())
+					_, cloudProps, _, _, _, _, _ := computeService.CreateServerArgsForCall(0)
+					Expect(cloudProps.DiskAvailabilityZone).To(Equal("z2"))
+					Expect(cloudProps.AvailabilityZones).To(Equal([]string{"z1", "z2"}))
+				})
+
+				It("does not prefer an availability zone if the persistent disks are in multiple availability zones", func() {
+					volumeService.GetVolumeReturnsOnCall(1, &volumes.Volume{AvailabilityZone: "z3"}, nil)
+
+					_, _, err := methods.NewCreateVMMethod(
+						&imageServiceBuilder,
+						&networkServiceBuilder,
+						&computeServiceBuilder,
+						&loadbalancerServiceBuilder,
+						&volumeServiceBuilder,
+						cpiConfig,
+						&logger,
+					).CreateVMV2(
+						apiv1.NewAgentID("the_agent-id"),
+						apiv1.NewStemcellCID("stemcell-id"),
+						apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+						networks,
+						[]apiv1.DiskCID{apiv1.NewDiskCID("disk-1"), apiv1.NewDiskCID("disk-2")},
+						env,
+					)
+
+					Expect(err).ToNot(HaveOccurred())
+					_, cloudProps, _, _, _, _, _ := computeService.CreateServerArgsForCall(0)
+					Expect(cloudProps.DiskAvailabilityZone).To(BeEmpty())
+				})
+			})
+		})
+
+		Context("Boot volume metadata", func() {
+			BeforeEach(func() {
+				computeService.CreateServerReturns(&servers.Server{
+					ID:              "123-456",
+					AttachedVolumes: []servers.AttachedVolume{{ID: "the-boot-volume-id"}, {ID: "the-ephemeral-volume-id"}},
+				}, nil)
+				volumeService.GetVolumeReturnsOnCall(0, &volumes.Volume{ID: "the-boot-volume-id", Bootable: "true"}, nil)
+				volumeService.GetVolumeReturnsOnCall(1, &volumes.Volume{ID: "the-ephemeral-volume-id", Bootable: "false"}, nil)
+				env = apiv1.NewVMEnv(map[string]interface{}{
+					"bosh": map[string]interface{}{"groups": []string{"the-director", "the-deployment", "the-instance-group"}},
+				})
+			})
+
+			It("tags the boot volume of the server", func() {
+				_, _, err := methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(volumeService.GetVolumeCallCount()).To(Equal(2))
+				Expect(volumeService.SetDiskMetadataCallCount()).To(Equal(1))
+				volumeID, metadata := volumeService.SetDiskMetadataArgsForCall(0)
+				Expect(volumeID).To(Equal("the-boot-volume-id"))
+				Expect(metadata).To(Equal(map[string]string{
+					"server_id":      "123-456",
+					"agent_id":       "the_agent-id",
+					"director":       "the-director",
+					"deployment":     "the-deployment",
+					"instance_group": "the-instance-group",
+				}))
+			})
+
+			It("does not tag volumes if the server has no attached volumes", func() {
+				computeService.CreateServerReturns(&servers.Server{ID: "123-456"}, nil)
+
+				_, _, err := methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(volumeService.GetVolumeCallCount()).To(Equal(0))
+				Expect(volumeService.SetDiskMetadataCallCount()).To(Equal(0))
+			})
+
+			It("keeps the server if tagging the boot volume fails", func() {
+				volumeService.SetDiskMetadataReturns(errors.New("boom"))
+
+				_, _, err := methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(computeService.DeleteServerCallCount()).To(Equal(0))
+				Expect(networkService.DeletePortsCallCount()).To(Equal(0))
+				_, message, _ := logger.WarnArgsForCall(0)
+				Expect(message).To(Equal("failed to tag boot volume of server '123-456': failed to set metadata of volume 'the-boot-volume-id': boom"))
+			})
+		})
+
+		Context("Cleanup resources on error", func() {
+			It("deletes ports if server creation fails", func() {
+				computeService.CreateServerReturns(nil, errors.New("boom"))
+
+				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(networkService.DeletePortsCallCount()).To(Equal(1))
+			})
+
+			It("parks ports if server creation fails and ports are preserved", func() {
+				computeService.CreateServerReturns(nil, errors.New("boom"))
+				cpiConfig.Cloud.Properties.Openstack.PreservePorts = true
+
+				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(networkService.DeletePortsCallCount()).To(Equal(0))
+				Expect(networkService.ParkPortsArgsForCall(0)).To(Equal([]ports.Port{port, port}))
+			})
+
+			It("deletes ports and server if configuring vip network fails", func() {
+				networkService.ConfigureVIPNetworkReturns("", errors.New("boom"))
+
+				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(networkService.DeletePortsCallCount()).To(Equal(1))
+				Expect(computeService.DeleteServerCallCount()).To(Equal(1))
+			})
+
+			It("releases allocated floating ips if creating the vm fails after the server was created", func() {
+				computeService.UpdateServerMetadataReturns(errors.New("boom"))
+
+				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(networkService.ReleaseFloatingIPsCallCount()).To(Equal(1))
+				Expect(networkService.ReleaseFloatingIPsArgsForCall(0)).To(Equal("123-456"))
+			})
+
+			It("deletes ports, server, and pool members if update server metadata fails", func() {
+				networkService.ConfigureVIPNetworkReturns("", errors.New("boom"))
+
+				_, _, _ = methods.NewCreateVMMethod( //nolint:errcheck
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(networkService.DeletePortsCallCount()).To(Equal(1))
+				Expect(computeService.DeleteServerCallCount()).To(Equal(1))
+			})
+
+			It("ignores errors from cleaning up resources", func() {
+				jsonStr = `{
+					"instance_type": "type1",
+					"loadbalancer_pools": [
+						{"name": "the-pool-name-1","port": 1234,"monitoring_port": 5678},
+						{"name": "the-pool-name-2","port": 1234,"monitoring_port": 5678}
+					],
+					"availability_zones": ["z1", "z2"]
+				}`
+
+				loadbalancerService.DeletePoolMemberReturns(errors.New("boom"))
+				computeService.DeleteServerReturns(errors.New("boom"))
+				networkService.DeletePortsReturns(errors.New("boom"))
+				computeService.UpdateServerMetadataReturns(errors.New("boom"))
+				loadbalancerService.CreatePoolMemberReturnsOnCall(0, &pools.Member{ID: "the-member-id", PoolID: "the-pool-id-1"}, nil)
+				loadbalancerService.CreatePoolMemberReturnsOnCall(1, &pools.Member{ID: "the-member-id-1", PoolID: "the-pool-id-2"}, nil)
+
+				_, _, err := methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				).CreateVMV2(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(err.Error()).To(ContainSubstring("failed to update metadata for server '123-456' with error: boom"))
+			})
+		})
+
+		Context("CPI API versions", func() {
+			var actionFactory apiv1.ActionFactory
+
+			BeforeEach(func() {
+				actionFactory = apiv1.NewActionFactory(createVMCPIFactory{methods.NewCreateVMMethod(
+					&imageServiceBuilder,
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					&volumeServiceBuilder,
+					cpiConfig,
+					&logger,
+				)})
+			})
+
+			It("creates the VM with API version 1", func() {
+				action, err := actionFactory.Create("create_vm", 1, apiv1.CloudPropsImpl{})
+				Expect(err).ToNot(HaveOccurred())
+
+				vmCID, err := action.(func(apiv1.AgentID, apiv1.StemcellCID, apiv1.CloudPropsImpl, apiv1.Networks, []apiv1.DiskCID, apiv1.VMEnv) (apiv1.VMCID, error))(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(vmCID).To(Equal(apiv1.NewVMCID("123-456")))
+				Expect(computeService.CreateServerCallCount()).To(Equal(1))
+			})
+
+			It("creates the VM with API version 2", func() {
+				action, err := actionFactory.Create("create_vm", 2, apiv1.CloudPropsImpl{})
+				Expect(err).ToNot(HaveOccurred())
+
+				vmCID, _, err := action.(func(apiv1.AgentID, apiv1.StemcellCID, apiv1.CloudPropsImpl, apiv1.Networks, []apiv1.DiskCID, apiv1.VMEnv) (apiv1.VMCID, apiv1.Networks, error))(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(vmCID).To(Equal(apiv1.NewVMCID("123-456")))
+				Expect(computeService.CreateServerCallCount()).To(Equal(1))
+			})
+
+			It("returns the error of the VM creation with API version 1", func() {
+				computeService.CreateServerReturns(nil, errors.New("boom"))
+
+				action, err := actionFactory.Create("create_vm", 1, apiv1.CloudPropsImpl{})
+				Expect(err).ToNot(HaveOccurred())
+
+				vmCID, err := action.(func(apiv1.AgentID, apiv1.StemcellCID, apiv1.CloudPropsImpl, apiv1.Networks, []apiv1.DiskCID, apiv1.VMEnv) (apiv1.VMCID, error))(
+					apiv1.NewAgentID("the_agent-id"),
+					apiv1.NewStemcellCID("stemcell-id"),
+					apiv1.CloudPropsImpl{RawMessage: []byte(jsonStr)},
+					networks,
+					[]apiv1.DiskCID{},
+					env,
+				)
+
+				Expect(err.Error()).To(ContainSubstring("boom"))
+				Expect(vmCID).To(Equal(apiv1.VMCID{}))
+			})
+		})
+	})
+})
+
+// createVMCPIFactory provides a CPI which only implements create_vm.
+type createVMCPIFactory struct {
+	createVMMethod methods.CreateVMMethod
+}
+
+func (f createVMCPIFactory) New(_ apiv1.CallContext) (apiv1.CPI, error) {
+	return createVMCPI{createVMMethod: f.createVMMethod}, nil
+}
+
+type createVMCPI struct {
+	apiv1.CPI
+	createVMMethod methods.CreateVMMethod
+}
+
+func (c createVMCPI) CreateVM(
+	agentID apiv1.AgentID, stemcellCID apiv1.StemcellCID, cloudProps apiv1.VMCloudProps,
+	networks apiv1.Networks, diskCIDs []apiv1.DiskCID, env apiv1.VMEnv) (apiv1.VMCID, error) {
+
+	return c.createVMMethod.CreateVM(agentID, stemcellCID, cloudProps, networks, diskCIDs, env)
+}
+
+func (c createVMCPI) CreateVMV2(
+	agentID apiv1.AgentID, stemcellCID apiv1.StemcellCID, cloudProps apiv1.VMCloudProps,
+	networks apiv1.Networks, diskCIDs []apiv1.DiskCID, env apiv1.VMEnv) (apiv1.VMCID, apiv1.Networks, error) {
+
+	return c.createVMMethod.CreateVMV2(agentID, stemcellCID, cloudProps, networks, diskCIDs, env)
+}
@@ -2,6 +2,7 @@ package methods
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/cloudfoundry/bosh-cpi-go/apiv1"
@@ -11,6 +12,7 @@ import (
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/network"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/properties"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
 )
 
 type DeleteVMMethod struct {
@@ -49,7 +51,7 @@ func (a DeleteVMMethod) DeleteVM(cid apiv1.VMCID) error {
 	}
 
 	// Get ports before deleting the server so that it is still assigned to the server
-	ports, err := networkService.GetPorts(cid.AsString(), properties.Network{}, true)
+	serverPorts, err := networkService.GetPorts(cid.AsString(), properties.Network{}, true)
 	if err != nil {
 		return fmt.Errorf("delete_vm: %w", err)
 	}
@@ -59,6 +61,13 @@ func (a DeleteVMMethod) DeleteVM(cid apiv1.VMCID) error {
 		return fmt.Errorf("delete_vm: %w", err)
 	}
 
+	var preCreatedPortIDs []string
+	for key, value := range serverMetadata {
+		if strings.HasPrefix(key, "pre_created_port_") {
+			preCreatedPortIDs = append(preCreatedPortIDs, value)
+		}
+	}
+
 	if len(serverMetadata) > 0 {
 		loadbalancerService, err := a.loadbalancerServiceBuilder.Build()
 		if err != nil {
@@ -76,15 +85,71 @@ func (a DeleteVMMethod) DeleteVM(cid apiv1.VMCID) error {
 		}
 	}
 
+	// Disassociate floating IPs while the ports are still attached to the server
+	err = networkService.DisassociateFloatingIPs(serverPorts)
+	if err != nil {
+		return fmt.Errorf("delete_vm: %w", err)
+	}
+
 	err = computeService.DeleteServer(cid.AsString(), a.cpiConfig)
 	if err != nil {
 		return fmt.Errorf("delete_vm: %w", err)
 	}
 
-	err = networkService.DeletePorts(ports)
+	err = networkService.ReleaseFloatingIPs(cid.AsString())
 	if err != nil {
 		return fmt.Errorf("delete_vm: %w", err)
 	}
 
+	// Pre-created ports have been detached by deleting the server and are kept for the next VM,
+	// as well as the created ports if they are preserved
+	var createdPorts []ports.Port
+	for _, port := range serverPorts {
+		if slices.Contains(preCreatedPortIDs, port.ID) {
+			a.logger.Info("delete_vm", fmt.Sprintf("Keeping pre-created port '%s'", port.ID))
+			continue
+		}
+		createdPorts = append(createdPorts, port)
+	}
+
+	if a.cpiConfig.Cloud.Properties.Openstack.PreservePorts {
+		err = networkService.ParkPorts(createdPorts)
+		if err != nil {
+			return fmt.Errorf("delete_vm: %w", err)
+		}
+
+		err = networkService.DeleteExpiredParkedPorts(a.cpiConfig.Cloud.Properties.Openstack.GetParkedPortExpiry(), directorIdentity(serverPorts))
+		if err != nil {
+			a.logger.Warn("delete_vm", fmt.Sprintf("failed to delete expired parked ports: %s", err.Error()))
+		}
+	} else {
+		err = networkService.DeletePorts(createdPorts)
+		if err != nil {
+			return fmt.Errorf("delete_vm: %w", err)
+		}
+	}
+
+	var securityGroupIDs []string
+	for _, port := range serverPorts {
+		securityGroupIDs = append(securityGroupIDs, port.SecurityGroups...)
+	}
+
+	err = networkService.DeleteUnusedManagedSecurityGroups(securityGroupIDs)
+	if err != nil {
+		a.logger.Warn("delete_vm", fmt.Sprintf("failed to delete unused managed security groups: %s", err.Error()))
+	}
+
 	return nil
 }
+
+// directorIdentity returns the identity of the director which created the ports, as recorded in their tags.
+func directorIdentity(serverPorts []ports.Port) properties.InstanceIdentity {
+	for _, port := range serverPorts {
+		identity := properties.NewInstanceIdentityFromTags(port.Tags)
+		if identity.Director != "" {
+			return properties.InstanceIdentity{Director: identity.Director}
+		}
+	}
+
+	return properties.InstanceIdentity{}
+}
@@ -2,6 +2,7 @@ package methods_test
 
 import (
 	"errors"
+	"time"
 
 	"github.com/cloudfoundry/bosh-cpi-go/apiv1"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/compute/computefakes"
@@ -9,6 +10,7 @@ import (
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/loadbalancer/loadbalancerfakes"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/methods"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/network/networkfakes"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/properties"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils/utilsfakes"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
 	. "github.com/onsi/ginkgo/v2"
@@ -42,7 +44,7 @@ var _ = Describe("DeleteVMMethod", func() {
 
 			computeService.DeleteServerReturns(nil)
 			computeService.GetMetadataReturns(map[string]string{"tag1": "tag1Value", "lbaas_pool_1": "poolID/memberID"}, nil)
-			networkService.GetPortsReturns([]ports.Port{{ID: "test"}}, nil)
+			networkService.GetPortsReturns([]ports.Port{{ID: "test", SecurityGroups: []string{"the-group-id"}}}, nil)
 			networkService.DeletePortsReturns(nil)
 			loadbalancerService.DeletePoolMemberReturns(nil)
 
@@ -290,6 +292,76 @@ var _ = Describe("DeleteVMMethod", func() {
 			Expect(err.Error()).To(Equal("delete_vm: boom"))
 		})
 
+		It("disassociates the floating ips from the ports of the server before deleting it", func() {
+			networkService.DisassociateFloatingIPsStub = func(_ []ports.Port) error {
+				Expect(computeService.DeleteServerCallCount()).To(Equal(0))
+				return nil
+			}
+
+			err := methods.NewDeleteVMMethod(
+				&networkServiceBuilder,
+				&computeServiceBuilder,
+				&loadbalancerServiceBuilder,
+				config.CpiConfig{},
+				&logger,
+			).DeleteVM(
+				apiv1.NewVMCID("vm-id"),
+			)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkService.DisassociateFloatingIPsArgsForCall(0)).To(Equal([]ports.Port{{ID: "test", SecurityGroups: []string{"the-group-id"}}}))
+		})
+
+		It("returns an error if disassociating floating ips fails", func() {
+			networkService.DisassociateFloatingIPsReturns(errors.New("boom"))
+
+			err := methods.NewDeleteVMMethod(
+				&networkServiceBuilder,
+				&computeServiceBuilder,
+				&loadbalancerServiceBuilder,
+				config.CpiConfig{},
+				&logger,
+			).DeleteVM(
+				apiv1.NewVMCID("vm-id"),
+			)
+
+			Expect(err.Error()).To(Equal("delete_vm: boom"))
+			Expect(computeService.DeleteServerCallCount()).To(Equal(0))
+			Expect(networkService.DeletePortsCallCount()).To(Equal(0))
+		})
+
+		It("releases the floating ips allocated for the server", func() {
+			err := methods.NewDeleteVMMethod(
+				&networkServiceBuilder,
+				&computeServiceBuilder,
+				&loadbalancerServiceBuilder,
+				config.CpiConfig{},
+				&logger,
+			).DeleteVM(
+				apiv1.NewVMCID("vm-id"),
+			)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkService.ReleaseFloatingIPsArgsForCall(0)).To(Equal("vm-id"))
+		})
+
+		It("returns an error if releasing floating ips fails", func() {
+			networkService.ReleaseFloatingIPsReturns(errors.New("boom"))
+
+			err := methods.NewDeleteVMMethod(
+				&networkServiceBuilder,
+				&computeServiceBuilder,
+				&loadbalancerServiceBuilder,
+				config.CpiConfig{},
+				&logger,
+			).DeleteVM(
+				apiv1.NewVMCID("vm-id"),
+			)
+
+			Expect(err.Error()).To(Equal("delete_vm: boom"))
+			Expect(networkService.DeletePortsCallCount()).To(Equal(0))
+		})
+
 		It("delete ports has been called once with the correct parameters", func() {
 			err := methods.NewDeleteVMMethod(
 				&networkServiceBuilder,
@@ -301,12 +373,142 @@ var _ = Describe("DeleteVMMethod", func() {
 				apiv1.NewVMCID("vm-id"),
 			)
 
-			exp := []ports.Port{{ID: "test"}}
+			exp := []ports.Port{{ID: "test", SecurityGroups: []string{"the-group-id"}}}
 			ports := networkService.DeletePortsArgsForCall(0)
 			Expect(ports).To(Equal(exp))
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		It("keeps pre-created ports recorded in the server metadata", func() {
+			computeService.GetMetadataReturns(map[string]string{"pre_created_port_1": "the-pre-created-port-id"}, nil)
+			networkService.GetPortsReturns([]ports.Port{{ID: "test"}, {ID: "the-pre-created-port-id"}}, nil)
+
+			err := methods.NewDeleteVMMethod(
+				&networkServiceBuilder,
+				&computeServiceBuilder,
+				&loadbalancerServiceBuilder,
+				config.CpiConfig{},
+				&logger,
+			).DeleteVM(
+				apiv1.NewVMCID("vm-id"),
+			)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkService.DeletePortsArgsForCall(0)).To(Equal([]ports.Port{{ID: "test"}}))
+			Expect(loadbalancerService.DeletePoolMemberCallCount()).To(Equal(0))
+		})
+
+		Context("when ports are preserved", func() {
+			var cpiConfig config.CpiConfig
+
+			BeforeEach(func() {
+				cpiConfig = config.CpiConfig{}
+				cpiConfig.Cloud.Properties.Openstack.PreservePorts = true
+				cpiConfig.Cloud.Properties.Openstack.ParkedPortExpiryHours = 24
+			})
+
+			It("parks the ports instead of deleting them", func() {
+				err := methods.NewDeleteVMMethod(
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					cpiConfig,
+					&logger,
+				).DeleteVM(
+					apiv1.NewVMCID("vm-id"),
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(networkService.DeletePortsCallCount()).To(Equal(0))
+				Expect(networkService.ParkPortsArgsForCall(0)).To(Equal([]ports.Port{{ID: "test", SecurityGroups: []string{"the-group-id"}}}))
+				expiry, _ := networkService.DeleteExpiredParkedPortsArgsForCall(0)
+				Expect(expiry).To(Equal(24 * time.Hour))
+			})
+
+			It("deletes the expired parked ports of the director of the ports", func() {
+				networkService.GetPortsReturns([]ports.Port{{ID: "test", Tags: []string{"bosh", "director:the-director", "deployment:the-deployment"}}}, nil)
+
+				err := methods.NewDeleteVMMethod(
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					cpiConfig,
+					&logger,
+				).DeleteVM(
+					apiv1.NewVMCID("vm-id"),
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				_, identity := networkService.DeleteExpiredParkedPortsArgsForCall(0)
+				Expect(identity).To(Equal(properties.InstanceIdentity{Director: "the-director"}))
+			})
+
+			It("returns an error if parking the ports fails", func() {
+				networkService.ParkPortsReturns(errors.New("boom"))
+
+				err := methods.NewDeleteVMMethod(
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					cpiConfig,
+					&logger,
+				).DeleteVM(
+					apiv1.NewVMCID("vm-id"),
+				)
+
+				Expect(err.Error()).To(Equal("delete_vm: boom"))
+			})
+
+			It("does not fail if expired parked ports cannot be deleted", func() {
+				networkService.DeleteExpiredParkedPortsReturns(errors.New("boom"))
+
+				err := methods.NewDeleteVMMethod(
+					&networkServiceBuilder,
+					&computeServiceBuilder,
+					&loadbalancerServiceBuilder,
+					cpiConfig,
+					&logger,
+				).DeleteVM(
+					apiv1.NewVMCID("vm-id"),
+				)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(logger.WarnCallCount()).To(Equal(1))
+			})
+		})
+
+		It("deletes unused managed security groups of the deleted ports", func() {
+			err := methods.NewDeleteVMMethod(
+				&networkServiceBuilder,
+				&computeServiceBuilder,
+				&loadbalancerServiceBuilder,
+				config.CpiConfig{},
+				&logger,
+			).DeleteVM(
+				apiv1.NewVMCID("vm-id"),
+			)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkService.DeleteUnusedManagedSecurityGroupsArgsForCall(0)).To(Equal([]string{"the-group-id"}))
+		})
+
+		It("does not fail if unused managed security groups cannot be deleted", func() {
+			networkService.DeleteUnusedManagedSecurityGroupsReturns(errors.New("boom"))
+
+			err := methods.NewDeleteVMMethod(
+				&networkServiceBuilder,
+				&computeServiceBuilder,
+				&loadbalancerServiceBuilder,
+				config.CpiConfig{},
+				&logger,
+			).DeleteVM(
+				apiv1.NewVMCID("vm-id"),
+			)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(logger.WarnCallCount()).To(Equal(1))
+		})
+
 		It("returns an error if deleting ports fails", func() {
 			networkService.DeletePortsReturns(errors.New("boom"))
 
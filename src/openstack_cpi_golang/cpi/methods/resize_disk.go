@@ -49,7 +49,8 @@ func (r ResizeDiskMethod) ResizeDisk(cid apiv1.DiskCID, size int) error {
 		r.logger.Info("resize_disk", fmt.Sprintf("Skipping resize of disk %s because current value %d GiB is equal new value %d GiB", cid.AsString(), volume.Size, sizeInGib))
 		return nil
 	case volume.Size > sizeInGib:
-		return fmt.Errorf("cannot resize volume to a smaller size from %d GiB to %d GiB", volume.Size, sizeInGib)
+		// the director falls back to copying the disk contents to a new disk
+		return utils.NewNotImplementedError(fmt.Errorf("cannot resize volume to a smaller size from %d GiB to %d GiB", volume.Size, sizeInGib))
 	case len(volume.Attachments) > 0:
 		return fmt.Errorf("cannot resize volume %s due to attachments", cid.AsString())
 	}
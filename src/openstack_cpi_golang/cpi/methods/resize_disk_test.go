@@ -6,6 +6,7 @@ import (
 	"github.com/cloudfoundry/bosh-cpi-go/apiv1"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/config"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/methods"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils/utilsfakes"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/volume/volumefakes"
 	"github.com/gophercloud/gophercloud/openstack/blockstorage/v3/volumes"
@@ -132,6 +133,7 @@ var _ = Describe("NewResizeDiskMethod", func() {
 			Expect(err).To(HaveOccurred())
 			Expect(volumeService.ExtendVolumeSizeCallCount()).To(Equal(0))
 			Expect(err.Error()).To(Equal("cannot resize volume to a smaller size from 5 GiB to 4 GiB"))
+			Expect(err.(utils.CloudError).Type()).To(Equal(utils.NotImplementedErrorType))
 		})
 
 		It("returns error because volume.Attachment is not nil", func() {
@@ -3,28 +3,43 @@ package methods
 import (
 	"encoding/json"
 	"fmt"
+	"maps"
+	"strings"
 
 	"github.com/cloudfoundry/bosh-cpi-go/apiv1"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/compute"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/config"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/loadbalancer"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/network"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/properties"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/volume"
 )
 
 type SetVMMetadataMethod struct {
-	computeServiceBuilder compute.ComputeServiceBuilder
-	logger                utils.Logger
-	cpiConfig             config.CpiConfig
+	computeServiceBuilder      compute.ComputeServiceBuilder
+	networkServiceBuilder      network.NetworkServiceBuilder
+	volumeServiceBuilder       volume.VolumeServiceBuilder
+	loadbalancerServiceBuilder loadbalancer.LoadbalancerServiceBuilder
+	logger                     utils.Logger
+	cpiConfig                  config.CpiConfig
 }
 
 func NewSetVMMetadataMethod(
 	computeServiceBuilder compute.ComputeServiceBuilder,
+	networkServiceBuilder network.NetworkServiceBuilder,
+	volumeServiceBuilder volume.VolumeServiceBuilder,
+	loadbalancerServiceBuilder loadbalancer.LoadbalancerServiceBuilder,
 	logger utils.Logger,
 	cpiConfig config.CpiConfig,
 ) SetVMMetadataMethod {
 	return SetVMMetadataMethod{
-		computeServiceBuilder: computeServiceBuilder,
-		logger:                logger,
-		cpiConfig:             cpiConfig,
+		computeServiceBuilder:      computeServiceBuilder,
+		networkServiceBuilder:      networkServiceBuilder,
+		volumeServiceBuilder:       volumeServiceBuilder,
+		loadbalancerServiceBuilder: loadbalancerServiceBuilder,
+		logger:                     logger,
+		cpiConfig:                  cpiConfig,
 	}
 }
 
@@ -69,6 +84,142 @@ func (s SetVMMetadataMethod) SetVMMetadata(vmCID apiv1.VMCID, meta apiv1.VMMeta)
 		}
 	}
 
+	if s.cpiConfig.OpenStackConfig().PropagateVMMetadata {
+		s.propagateMetadata(computeService, vmCID, updateMetaDataMap, metaDataMap)
+	}
+
+	return nil
+}
+
+// propagateMetadata sets the VM metadata on the ports, floating IPs, boot volume and load balancer pool
+// members of the VM. Existing values are replaced, so that repeated calls do not add them again.
+// Failures are only logged, the metadata of the server itself has already been set.
+func (s SetVMMetadataMethod) propagateMetadata(
+	computeService compute.ComputeService,
+	vmCID apiv1.VMCID,
+	updateMetaDataMap map[string]interface{},
+	serverMetadata map[string]string,
+) {
+	metadata := map[string]string{}
+	for key, value := range updateMetaDataMap {
+		if s.cpiConfig.OpenStackConfig().PropagatesVMMetadataKey(key) {
+			metadata[key] = fmt.Sprintf("%v", value)
+		}
+	}
+
+	if len(metadata) > 0 {
+		err := s.tagPorts(vmCID, metadata)
+		if err != nil {
+			s.logger.Warn("set_vm_metadata_method", fmt.Sprintf("failed to propagate metadata to the ports of server '%s': %v", vmCID.AsString(), err))
+		}
+
+		err = s.tagBootVolume(computeService, vmCID, metadata)
+		if err != nil {
+			s.logger.Warn("set_vm_metadata_method", fmt.Sprintf("failed to propagate metadata to the boot volume of server '%s': %v", vmCID.AsString(), err))
+		}
+	}
+
+	name, ok := s.humanReadableName(updateMetaDataMap)
+	if ok && s.cpiConfig.OpenStackConfig().PropagatesVMMetadataKey("name") {
+		err := s.namePoolMembers(serverMetadata, name)
+		if err != nil {
+			s.logger.Warn("set_vm_metadata_method", fmt.Sprintf("failed to propagate metadata to the pool members of server '%s': %v", vmCID.AsString(), err))
+		}
+	}
+}
+
+func (s SetVMMetadataMethod) tagPorts(vmCID apiv1.VMCID, metadata map[string]string) error {
+	networkService, err := s.networkServiceBuilder.Build()
+	if err != nil {
+		return fmt.Errorf("failed to create network service: %w", err)
+	}
+
+	serverPorts, err := networkService.GetPorts(vmCID.AsString(), properties.Network{}, true)
+	if err != nil {
+		return fmt.Errorf("failed to get ports: %w", err)
+	}
+
+	return networkService.SetMetadataTags(serverPorts, metadata)
+}
+
+// tagBootVolume merges the metadata into the metadata of the boot volume, which also carries the
+// 'server_id' and 'agent_id' set by create_vm.
+func (s SetVMMetadataMethod) tagBootVolume(computeService compute.ComputeService, vmCID apiv1.VMCID, metadata map[string]string) error {
+	server, err := computeService.GetServer(vmCID.AsString())
+	if err != nil {
+		return fmt.Errorf("failed to get server: %w", err)
+	}
+
+	if len(server.AttachedVolumes) == 0 {
+		return nil
+	}
+
+	volumeService, err := s.volumeServiceBuilder.Build()
+	if err != nil {
+		return fmt.Errorf("failed to create volume service: %w", err)
+	}
+
+	for _, attachedVolume := range server.AttachedVolumes {
+		bootVolume, err := volumeService.GetVolume(attachedVolume.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get volume '%s': %w", attachedVolume.ID, err)
+		}
+
+		if bootVolume.Bootable != "true" {
+			continue
+		}
+
+		volumeMetadata := maps.Clone(bootVolume.Metadata)
+		if volumeMetadata == nil {
+			volumeMetadata = map[string]string{}
+		}
+		maps.Copy(volumeMetadata, metadata)
+
+		if maps.Equal(volumeMetadata, bootVolume.Metadata) {
+			continue
+		}
+
+		err = volumeService.SetDiskMetadata(bootVolume.ID, volumeMetadata)
+		if err != nil {
+			return fmt.Errorf("failed to set metadata of volume '%s': %w", bootVolume.ID, err)
+		}
+		s.logger.Info("set_vm_metadata_method", fmt.Sprintf("Propagated metadata to boot volume '%s' of server '%s'", bootVolume.ID, vmCID.AsString()))
+	}
+
+	return nil
+}
+
+// namePoolMembers names the load balancer pool members of the VM, which are recorded in the
+// 'lbaas_pool_*' server metadata by create_vm.
+func (s SetVMMetadataMethod) namePoolMembers(serverMetadata map[string]string, name string) error {
+	var poolMembers []string
+	for key, value := range serverMetadata {
+		if strings.HasPrefix(key, "lbaas_pool_") {
+			poolMembers = append(poolMembers, value)
+		}
+	}
+
+	if len(poolMembers) == 0 {
+		return nil
+	}
+
+	loadbalancerService, err := s.loadbalancerServiceBuilder.Build()
+	if err != nil {
+		return fmt.Errorf("failed to create loadbalancer service: %w", err)
+	}
+
+	for _, poolMember := range poolMembers {
+		parts := strings.Split(poolMember, "/")
+		if len(parts) != 2 {
+			continue
+		}
+
+		err = loadbalancerService.UpdatePoolMemberName(parts[0], parts[1], name, s.cpiConfig.OpenStackConfig().StateTimeOut)
+		if err != nil {
+			s.logger.Warn("set_vm_metadata_method", fmt.Sprintf("failed to propagate metadata to pool member '%s': %v", poolMember, err))
+		}
+	}
+
 	return nil
 }
 
@@ -91,21 +242,27 @@ func (s SetVMMetadataMethod) metadataToMap(meta apiv1.VMMeta) (map[string]interf
 	return intermediateMap, nil
 }
 
-func (s SetVMMetadataMethod) applyHumanReadableName(computeService compute.ComputeService, vmCID apiv1.VMCID, metaMap map[string]interface{}) error {
-
+func (s SetVMMetadataMethod) humanReadableName(metaMap map[string]interface{}) (string, bool) {
 	name, nameOk := metaMap["name"]
 	job, jobOk := metaMap["job"]
 	index, indexOk := metaMap["index"]
 	compiling, compilingOk := metaMap["compiling"]
 
-	var newServerName string
 	if nameOk {
-		newServerName = name.(string)
+		return name.(string), true
 	} else if jobOk && indexOk {
-		newServerName = job.(string) + "/" + index.(string)
+		return job.(string) + "/" + index.(string), true
 	} else if compilingOk {
-		newServerName = "compiling/" + compiling.(string)
-	} else {
+		return "compiling/" + compiling.(string), true
+	}
+
+	return "", false
+}
+
+func (s SetVMMetadataMethod) applyHumanReadableName(computeService compute.ComputeService, vmCID apiv1.VMCID, metaMap map[string]interface{}) error {
+
+	newServerName, ok := s.humanReadableName(metaMap)
+	if !ok {
 		s.logger.Debug("set_vm_metadata_method", "did not apply human readable name: no name, job/index and compiling provided", nil)
 		return nil
 	}
@@ -8,8 +8,14 @@ import (
 	"github.com/cloudfoundry/bosh-cpi-go/apiv1"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/compute/computefakes"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/config"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/loadbalancer/loadbalancerfakes"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/methods"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/network/networkfakes"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils/utilsfakes"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/volume/volumefakes"
+	"github.com/gophercloud/gophercloud/openstack/blockstorage/v3/volumes"
+	"github.com/gophercloud/gophercloud/openstack/compute/v2/servers"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 )
@@ -18,6 +24,12 @@ var _ = Describe("NewSetVMMetadataMethod", func() {
 
 	var computeServiceBuilder *computefakes.FakeComputeServiceBuilder
 	var computeService *computefakes.FakeComputeService
+	var networkServiceBuilder *networkfakes.FakeNetworkServiceBuilder
+	var networkService *networkfakes.FakeNetworkService
+	var volumeServiceBuilder *volumefakes.FakeVolumeServiceBuilder
+	var volumeService *volumefakes.FakeVolumeService
+	var loadbalancerServiceBuilder *loadbalancerfakes.FakeLoadbalancerServiceBuilder
+	var loadbalancerService *loadbalancerfakes.FakeLoadbalancerService
 	var logger *utilsfakes.FakeLogger
 	var cpiConfig config.CpiConfig
 
@@ -54,9 +66,18 @@ var _ = Describe("NewSetVMMetadataMethod", func() {
 		BeforeEach(func() {
 			computeServiceBuilder = new(computefakes.FakeComputeServiceBuilder)
 			computeService = new(computefakes.FakeComputeService)
+			networkServiceBuilder = new(networkfakes.FakeNetworkServiceBuilder)
+			networkService = new(networkfakes.FakeNetworkService)
+			volumeServiceBuilder = new(volumefakes.FakeVolumeServiceBuilder)
+			volumeService = new(volumefakes.FakeVolumeService)
+			loadbalancerServiceBuilder = new(loadbalancerfakes.FakeLoadbalancerServiceBuilder)
+			loadbalancerService = new(loadbalancerfakes.FakeLoadbalancerService)
 			logger = new(utilsfakes.FakeLogger)
 
 			computeServiceBuilder.BuildReturns(computeService, nil)
+			networkServiceBuilder.BuildReturns(networkService, nil)
+			volumeServiceBuilder.BuildReturns(volumeService, nil)
+			loadbalancerServiceBuilder.BuildReturns(loadbalancerService, nil)
 
 			cpiConfig = config.CpiConfig{}
 			cpiConfig.Cloud.Properties.Openstack = config.OpenstackConfig{IgnoreServerAvailabilityZone: true}
@@ -68,6 +89,9 @@ var _ = Describe("NewSetVMMetadataMethod", func() {
 		It("creates the compute service", func() {
 			_ = methods.NewSetVMMetadataMethod( //nolint:errcheck
 				computeServiceBuilder,
+				networkServiceBuilder,
+				volumeServiceBuilder,
+				loadbalancerServiceBuilder,
 				logger,
 				cpiConfig,
 			).SetVMMetadata(
@@ -82,6 +106,9 @@ var _ = Describe("NewSetVMMetadataMethod", func() {
 			computeServiceBuilder.BuildReturns(nil, errors.New("boom"))
 			err := methods.NewSetVMMetadataMethod(
 				computeServiceBuilder,
+				networkServiceBuilder,
+				volumeServiceBuilder,
+				loadbalancerServiceBuilder,
 				logger,
 				cpiConfig,
 			).SetVMMetadata(
@@ -95,6 +122,9 @@ var _ = Describe("NewSetVMMetadataMethod", func() {
 		It("deletes nil value out imported metadata map", func() {
 			_ = methods.NewSetVMMetadataMethod( //nolint:errcheck
 				computeServiceBuilder,
+				networkServiceBuilder,
+				volumeServiceBuilder,
+				loadbalancerServiceBuilder,
 				logger,
 				cpiConfig,
 			).SetVMMetadata(
@@ -113,6 +143,9 @@ var _ = Describe("NewSetVMMetadataMethod", func() {
 			computeService.GetMetadataReturns(nil, errors.New("boom"))
 			err := methods.NewSetVMMetadataMethod(
 				computeServiceBuilder,
+				networkServiceBuilder,
+				volumeServiceBuilder,
+				loadbalancerServiceBuilder,
 				logger,
 				cpiConfig,
 			).SetVMMetadata(
@@ -127,6 +160,9 @@ var _ = Describe("NewSetVMMetadataMethod", func() {
 			computeService.GetMetadataReturns(metaDataReturn, nil)
 			err := methods.NewSetVMMetadataMethod(
 				computeServiceBuilder,
+				networkServiceBuilder,
+				volumeServiceBuilder,
+				loadbalancerServiceBuilder,
 				logger,
 				cpiConfig,
 			).SetVMMetadata(
@@ -143,6 +179,9 @@ var _ = Describe("NewSetVMMetadataMethod", func() {
 			computeService.GetMetadataReturnsOnCall(1, nil, errors.New("boom"))
 			err := methods.NewSetVMMetadataMethod(
 				computeServiceBuilder,
+				networkServiceBuilder,
+				volumeServiceBuilder,
+				loadbalancerServiceBuilder,
 				logger,
 				cpiConfig,
 			).SetVMMetadata(
@@ -159,6 +198,9 @@ var _ = Describe("NewSetVMMetadataMethod", func() {
 
 			err := methods.NewSetVMMetadataMethod(
 				computeServiceBuilder,
+				networkServiceBuilder,
+				volumeServiceBuilder,
+				loadbalancerServiceBuilder,
 				logger,
 				cpiConfig,
 			).SetVMMetadata(
@@ -175,6 +217,9 @@ var _ = Describe("NewSetVMMetadataMethod", func() {
 
 			err := methods.NewSetVMMetadataMethod(
 				computeServiceBuilder,
+				networkServiceBuilder,
+				volumeServiceBuilder,
+				loadbalancerServiceBuilder,
 				logger,
 				cpiConfig,
 			).SetVMMetadata(
@@ -191,6 +236,9 @@ var _ = Describe("NewSetVMMetadataMethod", func() {
 
 			err := methods.NewSetVMMetadataMethod(
 				computeServiceBuilder,
+				networkServiceBuilder,
+				volumeServiceBuilder,
+				loadbalancerServiceBuilder,
 				logger,
 				cpiConfig,
 			).SetVMMetadata(
@@ -207,6 +255,9 @@ var _ = Describe("NewSetVMMetadataMethod", func() {
 			computeService.GetMetadataReturns(metaDataReturn, nil)
 			err := methods.NewSetVMMetadataMethod(
 				computeServiceBuilder,
+				networkServiceBuilder,
+				volumeServiceBuilder,
+				loadbalancerServiceBuilder,
 				logger,
 				cpiConfig,
 			).SetVMMetadata(
@@ -229,6 +280,9 @@ var _ = Describe("NewSetVMMetadataMethod", func() {
 			computeService.GetMetadataReturns(metaDataReturn, nil)
 			err := methods.NewSetVMMetadataMethod(
 				computeServiceBuilder,
+				networkServiceBuilder,
+				volumeServiceBuilder,
+				loadbalancerServiceBuilder,
 				logger,
 				cpiConfig,
 			).SetVMMetadata(
@@ -251,6 +305,9 @@ var _ = Describe("NewSetVMMetadataMethod", func() {
 			computeService.GetMetadataReturns(metaDataReturn, nil)
 			err := methods.NewSetVMMetadataMethod(
 				computeServiceBuilder,
+				networkServiceBuilder,
+				volumeServiceBuilder,
+				loadbalancerServiceBuilder,
 				logger,
 				cpiConfig,
 			).SetVMMetadata(
@@ -273,6 +330,9 @@ var _ = Describe("NewSetVMMetadataMethod", func() {
 			computeService.GetMetadataReturns(metaDataReturn, nil)
 			err := methods.NewSetVMMetadataMethod(
 				computeServiceBuilder,
+				networkServiceBuilder,
+				volumeServiceBuilder,
+				loadbalancerServiceBuilder,
 				logger,
 				cpiConfig,
 			).SetVMMetadata(
@@ -294,6 +354,9 @@ var _ = Describe("NewSetVMMetadataMethod", func() {
 			computeService.UpdateServerReturns(nil, errors.New("boom"))
 			err := methods.NewSetVMMetadataMethod(
 				computeServiceBuilder,
+				networkServiceBuilder,
+				volumeServiceBuilder,
+				loadbalancerServiceBuilder,
 				logger,
 				cpiConfig,
 			).SetVMMetadata(
@@ -303,5 +366,173 @@ var _ = Describe("NewSetVMMetadataMethod", func() {
 			Expect(computeService.UpdateServerCallCount()).To(Equal(1))
 			Expect(err.Error()).To(Equal("failed to update human readable name on server: boom"))
 		})
+
+		Context("when propagate_vm_metadata is true", func() {
+			var setVMMetadata func(apiv1.VMMeta) error
+
+			BeforeEach(func() {
+				cpiConfig.Cloud.Properties.Openstack.PropagateVMMetadata = true
+				cpiConfig.Cloud.Properties.Openstack.StateTimeOut = 10
+				computeService.GetMetadataReturns(map[string]string{
+					"name":          "new-name",
+					"lbaas_pool_1":  "pool-id/member-id",
+					"registry_key":  "vm-123-456",
+					"lbaas_pool_10": "invalid",
+				}, nil)
+				computeService.GetServerReturns(&servers.Server{
+					ID:              "123-456",
+					AttachedVolumes: []servers.AttachedVolume{{ID: "data-volume-id"}, {ID: "boot-volume-id"}},
+				}, nil)
+				networkService.GetPortsReturns([]ports.Port{{ID: "port-id"}}, nil)
+				volumeService.GetVolumeReturnsOnCall(0, &volumes.Volume{ID: "data-volume-id", Bootable: "false"}, nil)
+				volumeService.GetVolumeReturnsOnCall(1, &volumes.Volume{
+					ID:       "boot-volume-id",
+					Bootable: "true",
+					Metadata: map[string]string{"server_id": "123-456", "name": "old-name"},
+				}, nil)
+
+				setVMMetadata = func(meta apiv1.VMMeta) error {
+					return methods.NewSetVMMetadataMethod(
+						computeServiceBuilder,
+						networkServiceBuilder,
+						volumeServiceBuilder,
+						loadbalancerServiceBuilder,
+						logger,
+						cpiConfig,
+					).SetVMMetadata(id, meta)
+				}
+			})
+
+			It("tags the ports and floating IPs of the server", func() {
+				err := setVMMetadata(metaDataName)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(networkService.GetPortsCallCount()).To(Equal(1))
+				serverID, _, _ := networkService.GetPortsArgsForCall(0)
+				Expect(serverID).To(Equal("123-456"))
+				serverPorts, metadata := networkService.SetMetadataTagsArgsForCall(0)
+				Expect(serverPorts).To(Equal([]ports.Port{{ID: "port-id"}}))
+				Expect(metadata).To(Equal(map[string]string{"name": "new-name"}))
+			})
+
+			It("merges the metadata into the metadata of the boot volume", func() {
+				err := setVMMetadata(metaDataName)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(volumeService.SetDiskMetadataCallCount()).To(Equal(1))
+				volumeID, metadata := volumeService.SetDiskMetadataArgsForCall(0)
+				Expect(volumeID).To(Equal("boot-volume-id"))
+				Expect(metadata).To(Equal(map[string]string{"server_id": "123-456", "name": "new-name"}))
+			})
+
+			It("does not update the boot volume if it already has the metadata", func() {
+				volumeService.GetVolumeReturnsOnCall(1, &volumes.Volume{
+					ID:       "boot-volume-id",
+					Bootable: "true",
+					Metadata: map[string]string{"server_id": "123-456", "name": "new-name"},
+				}, nil)
+
+				err := setVMMetadata(metaDataName)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(volumeService.SetDiskMetadataCallCount()).To(Equal(0))
+			})
+
+			It("names the load balancer pool members of the server", func() {
+				err := setVMMetadata(metaDataJobIndex)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(loadbalancerService.UpdatePoolMemberNameCallCount()).To(Equal(1))
+				poolID, memberID, name, timeout := loadbalancerService.UpdatePoolMemberNameArgsForCall(0)
+				Expect(poolID).To(Equal("pool-id"))
+				Expect(memberID).To(Equal("member-id"))
+				Expect(name).To(Equal("new-job/1"))
+				Expect(timeout).To(Equal(10))
+			})
+
+			It("propagates only the configured metadata keys", func() {
+				cpiConfig.Cloud.Properties.Openstack.PropagatedVMMetadataKeys = []string{"job"}
+
+				err := setVMMetadata(metaDataJobIndex)
+
+				Expect(err).ToNot(HaveOccurred())
+				_, metadata := networkService.SetMetadataTagsArgsForCall(0)
+				Expect(metadata).To(Equal(map[string]string{"job": "new-job"}))
+				Expect(loadbalancerService.UpdatePoolMemberNameCallCount()).To(Equal(0))
+			})
+
+			It("does not propagate anything if no key is propagated", func() {
+				cpiConfig.Cloud.Properties.Openstack.PropagatedVMMetadataKeys = []string{"deployment"}
+
+				err := setVMMetadata(metaDataName)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(networkServiceBuilder.BuildCallCount()).To(Equal(0))
+				Expect(volumeServiceBuilder.BuildCallCount()).To(Equal(0))
+				Expect(loadbalancerServiceBuilder.BuildCallCount()).To(Equal(0))
+			})
+
+			It("logs a warning if the ports cannot be tagged", func() {
+				networkService.SetMetadataTagsReturns(errors.New("boom"))
+
+				err := setVMMetadata(metaDataName)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(volumeService.SetDiskMetadataCallCount()).To(Equal(1))
+				_, message, _ := logger.WarnArgsForCall(0)
+				Expect(message).To(Equal("failed to propagate metadata to the ports of server '123-456': boom"))
+			})
+
+			It("logs a warning if the boot volume metadata cannot be set", func() {
+				volumeService.SetDiskMetadataReturns(errors.New("boom"))
+
+				err := setVMMetadata(metaDataName)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(loadbalancerService.UpdatePoolMemberNameCallCount()).To(Equal(1))
+				_, message, _ := logger.WarnArgsForCall(0)
+				Expect(message).To(Equal("failed to propagate metadata to the boot volume of server '123-456': failed to set metadata of volume 'boot-volume-id': boom"))
+			})
+
+			It("logs a warning if a pool member cannot be named", func() {
+				loadbalancerService.UpdatePoolMemberNameReturns(errors.New("boom"))
+
+				err := setVMMetadata(metaDataName)
+
+				Expect(err).ToNot(HaveOccurred())
+				_, message, _ := logger.WarnArgsForCall(0)
+				Expect(message).To(Equal("failed to propagate metadata to pool member 'pool-id/member-id': boom"))
+			})
+
+			It("returns an error if the server metadata cannot be updated", func() {
+				computeService.UpdateServerMetadataReturns(errors.New("boom"))
+
+				err := setVMMetadata(metaDataName)
+
+				Expect(err.Error()).To(Equal("failed to update Metadata for key 123-456: boom"))
+				Expect(networkService.SetMetadataTagsCallCount()).To(Equal(0))
+			})
+		})
+
+		It("does not propagate the metadata by default", func() {
+			computeService.GetMetadataReturns(metaDataReturn, nil)
+
+			err := methods.NewSetVMMetadataMethod(
+				computeServiceBuilder,
+				networkServiceBuilder,
+				volumeServiceBuilder,
+				loadbalancerServiceBuilder,
+				logger,
+				cpiConfig,
+			).SetVMMetadata(
+				id,
+				metaDataName,
+			)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkService.SetMetadataTagsCallCount()).To(Equal(0))
+			Expect(volumeService.SetDiskMetadataCallCount()).To(Equal(0))
+			Expect(loadbalancerService.UpdatePoolMemberNameCallCount()).To(Equal(0))
+		})
 	})
 })
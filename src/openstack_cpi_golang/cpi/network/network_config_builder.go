@@ -2,6 +2,7 @@ package network
 
 import (
 	"fmt"
+	"net"
 	"slices"
 
 	"github.com/cloudfoundry/bosh-cpi-go/apiv1"
@@ -12,6 +13,7 @@ import (
 
 type networkConfigBuilder struct {
 	securityGroupsResolver SecurityGroupsResolver
+	networkResolver        NetworkResolver
 	networks               apiv1.Networks
 	openstackConfig        config.OpenstackConfig
 	cloudProps             properties.CreateVM
@@ -20,6 +22,7 @@ type networkConfigBuilder struct {
 
 func NewNetworkConfigBuilder(
 	securityGroupsResolver SecurityGroupsResolver,
+	networkResolver NetworkResolver,
 	networks apiv1.Networks,
 	openstackConfig config.OpenstackConfig,
 	cloudProps properties.CreateVM,
@@ -27,6 +30,7 @@ func NewNetworkConfigBuilder(
 ) networkConfigBuilder {
 	return networkConfigBuilder{
 		securityGroupsResolver: securityGroupsResolver,
+		networkResolver:        networkResolver,
 		networks:               networks,
 		openstackConfig:        openstackConfig,
 		cloudProps:             cloudProps,
@@ -35,8 +39,6 @@ func NewNetworkConfigBuilder(
 }
 
 func (b networkConfigBuilder) Build() (properties.NetworkConfig, error) {
-	defaultNetwork := b.createNetwork("", b.networks.Default())
-
 	manualNetworks, err := b.createManualNetwork(b.networks, b.openstackConfig)
 	if err != nil {
 		return properties.NetworkConfig{}, fmt.Errorf("invalid manual network configuration: %w", err)
@@ -47,63 +49,120 @@ func (b networkConfigBuilder) Build() (properties.NetworkConfig, error) {
 		return properties.NetworkConfig{}, fmt.Errorf("invalid vip network configuration: %w", err)
 	}
 
+	err = b.validateVIPNetwork(vipNetwork)
+	if err != nil {
+		return properties.NetworkConfig{}, fmt.Errorf("invalid vip network configuration: %w", err)
+	}
+
 	dynamicNetwork, err := b.createSingleNetwork(b.networks, "dynamic")
 	if err != nil {
 		return properties.NetworkConfig{}, fmt.Errorf("invalid dynamic network configuration: %w", err)
 	}
 
+	err = b.resolveNetIDs(manualNetworks, dynamicNetwork)
+	if err != nil {
+		return properties.NetworkConfig{}, fmt.Errorf("invalid network configuration: %w", err)
+	}
+
+	err = b.resolveTargetNetID(vipNetwork)
+	if err != nil {
+		return properties.NetworkConfig{}, fmt.Errorf("invalid vip network configuration: %w", err)
+	}
+
 	err = b.validateNetIDs(b.combineNetworks(manualNetworks, dynamicNetwork, nil))
 	if err != nil {
 		return properties.NetworkConfig{}, fmt.Errorf("invalid network configuration: %w", err)
 	}
 
-	securityGroups, err := b.securityGroups(b.combineNetworks(manualNetworks, dynamicNetwork, vipNetwork))
+	err = b.validatePortProperties(manualNetworks, b.combineNetworks(nil, dynamicNetwork, vipNetwork))
+	if err != nil {
+		return properties.NetworkConfig{}, fmt.Errorf("invalid port configuration: %w", err)
+	}
+
+	err = b.resolveNetworkSecurityGroups(manualNetworks, dynamicNetwork)
 	if err != nil {
 		return properties.NetworkConfig{}, fmt.Errorf("invalid security group configuration: %w", err)
 	}
 
 	resultNetworkConfig := properties.NetworkConfig{
-		DefaultNetwork: defaultNetwork,
+		DefaultNetwork: b.defaultNetwork(b.combineNetworks(manualNetworks, dynamicNetwork, vipNetwork)),
 		ManualNetworks: manualNetworks,
 		VIPNetwork:     vipNetwork,
 		DynamicNetwork: dynamicNetwork,
-		SecurityGroups: securityGroups,
+		SecurityGroups: b.serverSecurityGroups(dynamicNetwork),
 	}
 
 	return resultNetworkConfig, nil
 }
 
-func (b networkConfigBuilder) securityGroups(networks []properties.Network) ([]string, error) {
-	var securityGroups []string
+// defaultNetwork returns the resolved network which is the default network of the instance, so that a default network
+// referenced by net_name carries its net_id.
+func (b networkConfigBuilder) defaultNetwork(networks []properties.Network) properties.Network {
+	defaultNetwork := b.networks.Default()
 
-	securityGroups = b.cloudProps.SecurityGroups
-	if len(securityGroups) == 0 {
+	for _, network := range networks {
+		if b.networks[network.Key] == defaultNetwork {
+			return network
+		}
+	}
 
-		securityGroups = b.securityGroupsFromNetworks(networks)
-		if len(securityGroups) == 0 {
+	return b.createNetwork("", defaultNetwork)
+}
 
-			securityGroups = b.openstackConfig.DefaultSecurityGroups
-		}
+// serverSecurityGroups returns the security groups passed to Nova, which only applies them to the port it creates
+// for the dynamic network. The ports of manual networks are created with their own security groups.
+func (b networkConfigBuilder) serverSecurityGroups(dynamicNetwork *properties.Network) []string {
+	if dynamicNetwork == nil {
+		return nil
+	}
 
+	return dynamicNetwork.SecurityGroupIDs
+}
+
+// resolveNetworkSecurityGroups resolves the security groups of the port of each network. A network uses its own
+// security groups or the default security groups, which the VM security groups override or are added to.
+func (b networkConfigBuilder) resolveNetworkSecurityGroups(manualNetworks []properties.Network, dynamicNetwork *properties.Network) error {
+	networks := make([]*properties.Network, 0, len(manualNetworks)+1)
+	for i := range manualNetworks {
+		networks = append(networks, &manualNetworks[i])
+	}
+	if dynamicNetwork != nil {
+		networks = append(networks, dynamicNetwork)
 	}
 
-	securityGroupIDs, err := b.securityGroupsResolver.Resolve(securityGroups)
-	if err != nil {
-		return []string{}, fmt.Errorf("failed to resolve security group: %w", err)
+	for _, network := range networks {
+		if network.CloudProps.PortSecurityDisabled() {
+			b.logger.Info("network-config-builder", fmt.Sprintf("port security is disabled on network '%s', not applying security groups", network.Key))
+			continue
+		}
+
+		securityGroupIDs, err := b.securityGroupsResolver.Resolve(b.networkSecurityGroups(*network))
+		if err != nil {
+			return fmt.Errorf("failed to resolve security groups of network '%s': %w", network.Key, err)
+		}
+		network.SecurityGroupIDs = securityGroupIDs
+
+		b.logger.Info("network-config-builder", fmt.Sprintf("using security groups %v for network '%s'", securityGroupIDs, network.Key))
 	}
 
-	b.logger.Info("network-config-builder", "resolved security groups ids: %v", securityGroupIDs)
-	return securityGroupIDs, nil
+	return nil
 }
 
-func (b networkConfigBuilder) securityGroupsFromNetworks(networks []properties.Network) []string {
-	var securityGroups []string
+func (b networkConfigBuilder) networkSecurityGroups(network properties.Network) []string {
+	securityGroups := network.CloudProps.SecurityGroups
+	if len(securityGroups) == 0 {
+		securityGroups = b.openstackConfig.DefaultSecurityGroups
+	}
 
-	for _, network := range networks {
-		securityGroups = append(securityGroups, network.CloudProps.SecurityGroups...)
+	if len(b.cloudProps.SecurityGroups) == 0 {
+		return securityGroups
 	}
 
-	return utils.UniqueArray(securityGroups)
+	if b.cloudProps.GetSecurityGroupsPolicy() == properties.SecurityGroupsPolicyAdd {
+		return utils.UniqueArray(append(slices.Clone(securityGroups), b.cloudProps.SecurityGroups...))
+	}
+
+	return b.cloudProps.SecurityGroups
 }
 
 func (b networkConfigBuilder) combineNetworks(manualNetworks []properties.Network, dynamicNetwork *properties.Network, vipNetwork *properties.Network) []properties.Network {
@@ -129,9 +188,8 @@ func (b networkConfigBuilder) createManualNetwork(networks apiv1.Networks, opens
 		if network.Type() == "manual" {
 			createdNetwork := b.createNetwork(key, network)
 
-			netID := createdNetwork.CloudProps.NetID
-			if netID == "" {
-				return []properties.Network{}, fmt.Errorf("manual network must have a net_id")
+			if createdNetwork.CloudProps.NetID == "" && createdNetwork.CloudProps.NetName == "" {
+				return []properties.Network{}, fmt.Errorf("manual network must have a net_id or net_name")
 			}
 
 			manualNetworks = append(manualNetworks, createdNetwork)
@@ -183,6 +241,105 @@ func (b networkConfigBuilder) createNetwork(key string, network apiv1.Network) p
 	}
 }
 
+func (b networkConfigBuilder) validateVIPNetwork(vipNetwork *properties.Network) error {
+	if vipNetwork == nil {
+		return nil
+	}
+
+	cloudProps := vipNetwork.CloudProps
+	if cloudProps.FloatingNetworkID != "" && cloudProps.FloatingNetworkName != "" {
+		return fmt.Errorf("only one property of 'floating_network_id' and 'floating_network_name' can be configured")
+	}
+
+	if vipNetwork.IP == "" && !cloudProps.AllocatesFloatingIP() {
+		return fmt.Errorf("vip network without ip requires 'floating_network_id' or 'floating_network_name'")
+	}
+
+	if vipNetwork.IP != "" && cloudProps.AllocatesFloatingIP() {
+		return fmt.Errorf("vip network with ip '%s' cannot allocate a floating ip from a floating network", vipNetwork.IP)
+	}
+
+	if cloudProps.TargetNetID != "" && cloudProps.TargetNetName != "" {
+		return fmt.Errorf("only one property of 'target_net_id' and 'target_net_name' can be configured")
+	}
+
+	if cloudProps.TargetFixedIP != "" && net.ParseIP(cloudProps.TargetFixedIP) == nil {
+		return fmt.Errorf("'target_fixed_ip' '%s' is not a valid ip address", cloudProps.TargetFixedIP)
+	}
+
+	return nil
+}
+
+func (b networkConfigBuilder) validatePortProperties(manualNetworks []properties.Network, otherNetworks []properties.Network) error {
+	for _, network := range manualNetworks {
+		err := network.CloudProps.Validate()
+		if err != nil {
+			return fmt.Errorf("network '%s': %w", network.Key, err)
+		}
+
+		if network.CloudProps.PortSecurityDisabled() && len(b.cloudProps.AllowedAddressPairs) > 0 {
+			return fmt.Errorf("network '%s': 'allowed_address_pairs' cannot be used if 'port_security_enabled' is false", network.Key)
+		}
+	}
+
+	for _, network := range otherNetworks {
+		if network.CloudProps.HasPortProperties() {
+			return fmt.Errorf("network '%s': port properties are only supported for manual networks", network.Key)
+		}
+	}
+
+	return nil
+}
+
+// resolveNetIDs sets the net_id of networks which reference their network by net_name.
+func (b networkConfigBuilder) resolveNetIDs(manualNetworks []properties.Network, dynamicNetwork *properties.Network) error {
+	networks := make([]*properties.Network, 0, len(manualNetworks)+1)
+	for i := range manualNetworks {
+		networks = append(networks, &manualNetworks[i])
+	}
+	if dynamicNetwork != nil {
+		networks = append(networks, dynamicNetwork)
+	}
+
+	for _, network := range networks {
+		cloudProps := &network.CloudProps
+		if cloudProps.NetID != "" && cloudProps.NetName != "" {
+			return fmt.Errorf("network '%s': only one property of 'net_id' and 'net_name' can be configured", network.Key)
+		}
+
+		if cloudProps.NetProjectID != "" && cloudProps.NetName == "" {
+			return fmt.Errorf("network '%s': 'net_project_id' requires 'net_name'", network.Key)
+		}
+
+		if cloudProps.NetName == "" {
+			continue
+		}
+
+		netID, err := b.networkResolver.Resolve(cloudProps.NetName, cloudProps.NetProjectID)
+		if err != nil {
+			return fmt.Errorf("network '%s': %w", network.Key, err)
+		}
+		cloudProps.NetID = netID
+	}
+
+	return nil
+}
+
+// resolveTargetNetID sets the target_net_id of a vip network which references its target network by target_net_name.
+func (b networkConfigBuilder) resolveTargetNetID(vipNetwork *properties.Network) error {
+	if vipNetwork == nil || vipNetwork.CloudProps.TargetNetName == "" {
+		return nil
+	}
+
+	targetNetID, err := b.networkResolver.Resolve(vipNetwork.CloudProps.TargetNetName, "")
+	if err != nil {
+		return fmt.Errorf("failed to resolve target network: %w", err)
+	}
+	vipNetwork.CloudProps.TargetNetID = targetNetID
+
+	return nil
+}
+
 func (b networkConfigBuilder) validateNetIDs(networks []properties.Network) error {
 	var usedNetIDs []string
 
@@ -3,6 +3,7 @@ package network_test
 import (
 	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"net"
 	"sort"
 
@@ -20,6 +21,7 @@ import (
 var _ = Describe("NetworkConfigBuilder", func() {
 	var networkingConfig properties.NetworkConfig
 	var securityGroupsResolver networkfakes.FakeSecurityGroupsResolver
+	var networkResolver networkfakes.FakeNetworkResolver
 	var openstackConfig config.OpenstackConfig
 	var cloudProperties properties.CreateVM
 	var logger utils.Logger
@@ -28,12 +30,13 @@ var _ = Describe("NetworkConfigBuilder", func() {
 		openstackConfig = config.OpenstackConfig{}
 		cloudProperties = properties.CreateVM{}
 		securityGroupsResolver = networkfakes.FakeSecurityGroupsResolver{}
+		networkResolver = networkfakes.FakeNetworkResolver{}
 		logger = &utilsfakes.FakeLogger{}
 	})
 
 	Context("NewNetworkConfig", func() {
 		BeforeEach(func() {
-			networkingConfig, _ = createNetworkConfig(&securityGroupsResolver, //nolint:errcheck
+			networkingConfig, _ = createNetworkConfig(&securityGroupsResolver, &networkResolver, //nolint:errcheck
 				[]byte(`{
 				"name1": {
 					"type":    "manual",
@@ -60,7 +63,7 @@ var _ = Describe("NetworkConfigBuilder", func() {
 		})
 
 		It("returns an error if a manual network is missing a netid", func() {
-			_, err := createNetworkConfig(&securityGroupsResolver, []byte(`{
+			_, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
 				"name1": {
 					"type":    "manual",
 					"ip":      "",
@@ -73,11 +76,11 @@ var _ = Describe("NetworkConfigBuilder", func() {
 				}
 			}`), openstackConfig, cloudProperties, logger)
 
-			Expect(err.Error()).To(Equal("invalid manual network configuration: manual network must have a net_id"))
+			Expect(err.Error()).To(Equal("invalid manual network configuration: manual network must have a net_id or net_name"))
 		})
 
 		It("returns an error if multiple manual network exists while dhcp should be used and config drive is not defined", func() {
-			_, err := createNetworkConfig(&securityGroupsResolver, []byte(`{
+			_, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
 				"name1": {
 					"type":    "manual",
 					"ip":      "",
@@ -94,7 +97,7 @@ var _ = Describe("NetworkConfigBuilder", func() {
 		})
 
 		It("returns an error if multiple vip networks exists", func() {
-			_, err := createNetworkConfig(&securityGroupsResolver, []byte(`{
+			_, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
 				"name1": {
 					"type":    "vip",
 					"ip":      "",
@@ -110,8 +113,72 @@ var _ = Describe("NetworkConfigBuilder", func() {
 			Expect(err.Error()).To(Equal("invalid vip network configuration: only one vip should be defined per instance"))
 		})
 
+		It("returns an error if a vip network has neither an ip nor a floating network", func() {
+			_, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "vip",
+					"ip":      "",
+					"cloud_properties": {}
+				}
+			}`), openstackConfig, cloudProperties, logger)
+
+			Expect(err.Error()).To(Equal("invalid vip network configuration: vip network without ip requires 'floating_network_id' or 'floating_network_name'"))
+		})
+
+		It("returns an error if a vip network has an ip and a floating network", func() {
+			_, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "vip",
+					"ip":      "3.3.3.3",
+					"cloud_properties": {"floating_network_id": "the-floating-net-id"}
+				}
+			}`), openstackConfig, cloudProperties, logger)
+
+			Expect(err.Error()).To(Equal("invalid vip network configuration: vip network with ip '3.3.3.3' cannot allocate a floating ip from a floating network"))
+		})
+
+		It("returns an error if a vip network has a floating network id and name", func() {
+			_, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "vip",
+					"cloud_properties": {"floating_network_id": "the-floating-net-id", "floating_network_name": "public"}
+				}
+			}`), openstackConfig, cloudProperties, logger)
+
+			Expect(err.Error()).To(Equal("invalid vip network configuration: only one property of 'floating_network_id' and 'floating_network_name' can be configured"))
+		})
+
+		It("returns an error if the target fixed ip of a vip network is invalid", func() {
+			_, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "vip",
+					"ip":      "3.3.3.3",
+					"cloud_properties": {"target_fixed_ip": "not-an-ip"}
+				}
+			}`), openstackConfig, cloudProperties, logger)
+
+			Expect(err.Error()).To(Equal("invalid vip network configuration: 'target_fixed_ip' 'not-an-ip' is not a valid ip address"))
+		})
+
+		It("accepts a vip network without ip which allocates from a floating network", func() {
+			networkConfig, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "manual",
+					"ip":      "1.1.1.1",
+					"cloud_properties": {"net_id": "the_net_id_1"}
+				},
+				"name2": {
+					"type":    "vip",
+					"cloud_properties": {"floating_network_name": "public"}
+				}
+			}`), openstackConfig, cloudProperties, logger)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkConfig.VIPNetwork.CloudProps.FloatingNetworkName).To(Equal("public"))
+		})
+
 		It("returns an error if multiple dynamic networks exists", func() {
-			_, err := createNetworkConfig(&securityGroupsResolver, []byte(`{
+			_, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
 				"name1": {
 					"type":    "dynamic",
 					"ip":      "",
@@ -128,7 +195,7 @@ var _ = Describe("NetworkConfigBuilder", func() {
 		})
 
 		It("returns an error if same net_id is used by multiple networks", func() {
-			_, err := createNetworkConfig(&securityGroupsResolver, []byte(`{
+			_, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
 				"name1": {
 					"type":    "manual",
 					"ip":      "",
@@ -143,6 +210,78 @@ var _ = Describe("NetworkConfigBuilder", func() {
 
 			Expect(err.Error()).To(Equal("invalid network configuration: network with id same_net_id is defined multiple times"))
 		})
+
+		It("returns an error if security groups are configured on a network with disabled port security", func() {
+			_, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "manual",
+					"ip":      "1.1.1.1",
+					"cloud_properties": {"net_id": "the_net_id_1", "port_security_enabled": false, "security_groups": ["security_group_1"]}
+				}
+			}`), openstackConfig, cloudProperties, logger)
+
+			Expect(err.Error()).To(Equal("invalid port configuration: network 'name1': 'security_groups' cannot be configured if 'port_security_enabled' is false"))
+		})
+
+		It("returns an error if allowed address pairs are configured on a network with disabled port security", func() {
+			_, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "manual",
+					"ip":      "1.1.1.1",
+					"cloud_properties": {"net_id": "the_net_id_1", "port_security_enabled": false}
+				}
+			}`), openstackConfig, properties.CreateVM{AllowedAddressPairs: properties.AllowedAddressPairs{{IPAddress: "10.0.0.10"}}}, logger)
+
+			Expect(err.Error()).To(Equal("invalid port configuration: network 'name1': 'allowed_address_pairs' cannot be used if 'port_security_enabled' is false"))
+		})
+
+		It("returns an error if the vnic type is not supported", func() {
+			_, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "manual",
+					"ip":      "1.1.1.1",
+					"cloud_properties": {"net_id": "the_net_id_1", "vnic_type": "unknown"}
+				}
+			}`), openstackConfig, cloudProperties, logger)
+
+			Expect(err.Error()).To(ContainSubstring("invalid port configuration: network 'name1': unsupported 'vnic_type' 'unknown'"))
+		})
+
+		It("returns an error if port properties are configured on a dynamic network", func() {
+			_, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "dynamic",
+					"cloud_properties": {"net_id": "the_net_id_1", "vnic_type": "direct"}
+				}
+			}`), openstackConfig, cloudProperties, logger)
+
+			Expect(err.Error()).To(Equal("invalid port configuration: network 'name1': port properties are only supported for manual networks"))
+		})
+
+		It("parses advanced port properties of manual networks", func() {
+			networkConfig, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "manual",
+					"ip":      "1.1.1.1",
+					"cloud_properties": {
+						"net_id": "the_net_id_1",
+						"vnic_type": "direct",
+						"port_security_enabled": false,
+						"qos_policy_id": "the-qos-policy-id",
+						"binding_profile": {"trusted": true},
+						"extra_dhcp_opts": [{"opt_name": "mtu", "opt_value": "9000"}]
+					}
+				}
+			}`), openstackConfig, cloudProperties, logger)
+
+			Expect(err).ToNot(HaveOccurred())
+			cloudProps := networkConfig.ManualNetworks[0].CloudProps
+			Expect(cloudProps.VNICType).To(Equal("direct"))
+			Expect(cloudProps.PortSecurityDisabled()).To(BeTrue())
+			Expect(cloudProps.QoSPolicyID).To(Equal("the-qos-policy-id"))
+			Expect(cloudProps.BindingProfile).To(Equal(map[string]interface{}{"trusted": true}))
+			Expect(cloudProps.ExtraDHCPOpts).To(Equal([]properties.ExtraDHCPOpt{{OptName: "mtu", OptValue: "9000"}}))
+		})
 	})
 
 	Context("DefaultNetwork", func() {
@@ -155,7 +294,7 @@ var _ = Describe("NetworkConfigBuilder", func() {
 		})
 
 		It("returns an empty network if no network is provided", func() {
-			networkingConfig, err := createNetworkConfig(&securityGroupsResolver, []byte(`{}`), openstackConfig, cloudProperties, logger)
+			networkingConfig, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{}`), openstackConfig, cloudProperties, logger)
 			Expect(err).ToNot(HaveOccurred())
 			defaultNetwork := networkingConfig.DefaultNetwork
 
@@ -200,125 +339,328 @@ var _ = Describe("NetworkConfigBuilder", func() {
 	})
 
 	Context("SecurityGroups", func() {
-		It("returns cloud properties security groups", func() {
-			securityGroupsResolver.ResolveReturns([]string{"resolved_security_group_1", "resolved_security_group_2"}, nil)
+		BeforeEach(func() {
+			securityGroupsResolver.ResolveStub = func(securityGroups []string) ([]string, error) {
+				var resolved []string
+				for _, securityGroup := range securityGroups {
+					resolved = append(resolved, "resolved_"+securityGroup)
+				}
+				return resolved, nil
+			}
+		})
 
-			networkingConfig, _ = createNetworkConfig(&securityGroupsResolver, //nolint:errcheck
-				[]byte(`{
-					"name1": {
-						"type":    "manual",
-						"ip":      "1.1.1.1",
-						"default": ["gateway"],
-						"cloud_properties": {"net_id": "the_net_id_1", "security_groups": ["security_group_1", "security_group_2"]}
-					}
-				}`),
-				config.OpenstackConfig{
-					DefaultSecurityGroups: []string{"default_security_group_1", "default_security_group_2"},
+		It("uses the security groups of the dynamic network for the server", func() {
+			networkingConfig, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "manual",
+					"ip":      "1.1.1.1",
+					"default": ["gateway"],
+					"cloud_properties": {"net_id": "the_net_id_1", "security_groups": ["security_group_1"]}
+				},
+				"name2": {
+					"type":    "vip",
+					"ip":      "3.3.3.3",
+					"cloud_properties": {"security_groups": ["security_group_3"]}
 				},
-				properties.CreateVM{
-					SecurityGroups: []string{"cloud_config_security_group_1", "cloud_config_security_group_2"},
-				}, logger)
-			securityGroups := networkingConfig.SecurityGroups
+				"name3": {
+					"type":    "dynamic",
+					"cloud_properties": {"net_id": "the_net_id_4", "security_groups": ["security_group_4"]}
+				}
+			}`), openstackConfig, cloudProperties, logger)
 
-			securityGroupsParam := securityGroupsResolver.ResolveArgsForCall(0)
-			Expect(securityGroupsParam).To(ContainElements("cloud_config_security_group_1", "cloud_config_security_group_2"))
-			Expect(securityGroups).To(ContainElements("resolved_security_group_1", "resolved_security_group_2"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingConfig.SecurityGroups).To(Equal([]string{"resolved_security_group_4"}))
+			Expect(securityGroupsResolver.ResolveCallCount()).To(Equal(2))
 		})
 
-		It("returns network security groups if cloud properties do not define security groups", func() {
-			securityGroupsResolver.ResolveReturns([]string{"resolved_network_security_group_1", "resolved_network_security_group_2"}, nil)
+		It("does not use security groups for the server without a dynamic network", func() {
+			networkingConfig, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "manual",
+					"ip":      "1.1.1.1",
+					"default": ["gateway"],
+					"cloud_properties": {"net_id": "the_net_id_1", "security_groups": ["security_group_1"]}
+				}
+			}`), openstackConfig, properties.CreateVM{SecurityGroups: []string{"cloud_config_security_group_1"}}, logger)
 
-			networkingConfig, _ = createNetworkConfig(&securityGroupsResolver, //nolint:errcheck
-				[]byte(`{
-					"name1": {
-						"type":    "manual",
-						"ip":      "1.1.1.1",
-						"default": ["gateway"],
-						"cloud_properties": {"net_id": "the_net_id_1", "security_groups": ["security_group_1", "security_group_2"]}
-					}
-				}`),
-				config.OpenstackConfig{
-					DefaultSecurityGroups: []string{"default_security_group_1", "default_security_group_2"},
-				},
-				properties.CreateVM{},
-				logger,
-			)
-			securityGroups := networkingConfig.SecurityGroups
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingConfig.SecurityGroups).To(BeNil())
+			Expect(networkingConfig.ManualNetworks[0].SecurityGroupIDs).To(Equal([]string{"resolved_cloud_config_security_group_1"}))
+			Expect(securityGroupsResolver.ResolveCallCount()).To(Equal(1))
+		})
 
-			securityGroupsParam := securityGroupsResolver.ResolveArgsForCall(0)
-			Expect(securityGroupsParam).To(ContainElements("security_group_1", "security_group_2"))
-			Expect(securityGroups).To(ContainElements("resolved_network_security_group_1", "resolved_network_security_group_2"))
+		It("uses the default security groups if the network does not define security groups", func() {
+			networkingConfig, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "dynamic",
+					"cloud_properties": {"net_id": "the_net_id_1"}
+				}
+			}`), config.OpenstackConfig{
+				DefaultSecurityGroups: []string{"default_security_group_1", "default_security_group_2"},
+			}, properties.CreateVM{}, logger)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingConfig.SecurityGroups).To(Equal([]string{"resolved_default_security_group_1", "resolved_default_security_group_2"}))
 		})
+	})
 
-		It("returns default security groups if network security groups are not defined", func() {
-			securityGroupsResolver.ResolveReturns([]string{"resolved_default_group_1", "resolved_default_group_2"}, nil)
+	Context("NetNames", func() {
+		BeforeEach(func() {
+			networkResolver.ResolveStub = func(name string, projectID string) (string, error) {
+				return "resolved_" + name + projectID, nil
+			}
+		})
 
-			networkingConfig, _ = createNetworkConfig(&securityGroupsResolver, //nolint:errcheck
-				[]byte(`{
-					"name1": {
-						"type":    "manual",
-						"ip":      "1.1.1.1",
-						"default": ["gateway"],
-						"cloud_properties": {"net_id": "the_net_id_1", "security_groups": ["security_group_1", "security_group_2"]}
-					}
-				}`),
-				config.OpenstackConfig{
-					DefaultSecurityGroups: []string{"default_security_group_1", "default_security_group_2"},
+		It("resolves the net_id of manual and dynamic networks configured by net_name", func() {
+			networkingConfig, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "manual",
+					"ip":      "1.1.1.1",
+					"cloud_properties": {"net_name": "the_net_1"}
 				},
-				properties.CreateVM{},
-				logger,
-			)
-			securityGroups := networkingConfig.SecurityGroups
+				"name2": {
+					"type":    "dynamic",
+					"cloud_properties": {"net_name": "the_net_2", "net_project_id": "_the_project"}
+				}
+			}`), openstackConfig, cloudProperties, logger)
 
-			securityGroupsParam := securityGroupsResolver.ResolveArgsForCall(0)
-			Expect(securityGroupsParam).To(ContainElements("security_group_1", "security_group_2"))
-			Expect(securityGroups).To(ContainElements("resolved_default_group_1", "resolved_default_group_2"))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingConfig.ManualNetworks[0].CloudProps.NetID).To(Equal("resolved_the_net_1"))
+			Expect(networkingConfig.DynamicNetwork.CloudProps.NetID).To(Equal("resolved_the_net_2_the_project"))
+			Expect(networkResolver.ResolveCallCount()).To(Equal(2))
 		})
 
-		It("network security groups merge all networks", func() {
-			securityGroupsResolver.ResolveReturns([]string{
-				"resolved_security_group_1", "resolved_security_group_2", "resolved_security_group_3", "resolved_security_group_4",
-			}, nil)
-
-			networkingConfig, _ = createNetworkConfig(&securityGroupsResolver, //nolint:errcheck
-				[]byte(`{
+		It("uses the resolved net_id for a default network configured by net_name", func() {
+			networkingConfig, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
 				"name1": {
 					"type":    "manual",
 					"ip":      "1.1.1.1",
 					"default": ["gateway"],
-					"cloud_properties": {"net_id": "the_net_id_1", "security_groups": ["security_group_1", "security_group_2"]}
+					"cloud_properties": {"net_name": "the_net_1"}
 				},
 				"name2": {
+					"type":    "vip",
+					"ip":      "3.3.3.3",
+					"cloud_properties": {}
+				}
+			}`), openstackConfig, cloudProperties, logger)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingConfig.DefaultNetwork.Key).To(Equal("name1"))
+			Expect(networkingConfig.DefaultNetwork.IP).To(Equal("1.1.1.1"))
+			Expect(networkingConfig.DefaultNetwork.CloudProps.NetID).To(Equal("resolved_the_net_1"))
+		})
+
+		It("resolves the target_net_id of a vip network configured by target_net_name", func() {
+			networkingConfig, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
 					"type":    "manual",
-					"ip":      "2.2.2.2",
-					"cloud_properties": {"net_id": "the_net_id_2"}
+					"ip":      "1.1.1.1",
+					"default": ["gateway"],
+					"cloud_properties": {"net_id": "the_net_id_1"}
 				},
-				"name3": {
+				"name2": {
 					"type":    "vip",
 					"ip":      "3.3.3.3",
-					"cloud_properties": {"net_id": "the_net_id_3", "security_groups": ["security_group_3"]}
+					"cloud_properties": {"target_net_name": "the_target_net"}
+				}
+			}`), openstackConfig, cloudProperties, logger)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingConfig.VIPNetwork.CloudProps.TargetNetID).To(Equal("resolved_the_target_net"))
+		})
+
+		It("returns an error if target_net_id and target_net_name are configured", func() {
+			_, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "vip",
+					"ip":      "3.3.3.3",
+					"cloud_properties": {"target_net_id": "the_target_net_id", "target_net_name": "the_target_net"}
+				}
+			}`), openstackConfig, cloudProperties, logger)
+
+			Expect(err.Error()).To(Equal("invalid vip network configuration: only one property of 'target_net_id' and 'target_net_name' can be configured"))
+		})
+
+		It("returns an error if a target_net_name cannot be resolved", func() {
+			networkResolver.ResolveStub = nil
+			networkResolver.ResolveReturns("", errors.New("boom"))
+
+			_, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "vip",
+					"ip":      "3.3.3.3",
+					"cloud_properties": {"target_net_name": "the_target_net"}
+				}
+			}`), openstackConfig, cloudProperties, logger)
+
+			Expect(err.Error()).To(Equal("invalid vip network configuration: failed to resolve target network: boom"))
+		})
+
+		It("returns an error if net_id and net_name are configured", func() {
+			_, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "manual",
+					"ip":      "1.1.1.1",
+					"cloud_properties": {"net_id": "the_net_id_1", "net_name": "the_net_1"}
+				}
+			}`), openstackConfig, cloudProperties, logger)
+
+			Expect(err.Error()).To(Equal("invalid network configuration: network 'name1': only one property of 'net_id' and 'net_name' can be configured"))
+		})
+
+		It("returns an error if net_project_id is configured without net_name", func() {
+			_, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "manual",
+					"ip":      "1.1.1.1",
+					"cloud_properties": {"net_id": "the_net_id_1", "net_project_id": "the_project"}
+				}
+			}`), openstackConfig, cloudProperties, logger)
+
+			Expect(err.Error()).To(Equal("invalid network configuration: network 'name1': 'net_project_id' requires 'net_name'"))
+		})
+
+		It("returns an error if two networks resolve to the same net_id", func() {
+			networkResolver.ResolveStub = nil
+			networkResolver.ResolveReturns("the_net_id_1", nil)
+
+			_, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "manual",
+					"ip":      "1.1.1.1",
+					"cloud_properties": {"net_id": "the_net_id_1"}
 				},
-				"name4": {
+				"name2": {
+					"type":    "manual",
+					"ip":      "2.2.2.2",
+					"cloud_properties": {"net_name": "the_net_1"}
+				}
+			}`), openstackConfig, cloudProperties, logger)
+
+			Expect(err.Error()).To(Equal("invalid network configuration: network with id the_net_id_1 is defined multiple times"))
+		})
+
+		It("returns an error if a net_name cannot be resolved", func() {
+			networkResolver.ResolveStub = nil
+			networkResolver.ResolveReturns("", errors.New("boom"))
+
+			_, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "manual",
+					"ip":      "1.1.1.1",
+					"cloud_properties": {"net_name": "the_net_1"}
+				}
+			}`), openstackConfig, cloudProperties, logger)
+
+			Expect(err.Error()).To(Equal("invalid network configuration: network 'name1': boom"))
+		})
+	})
+
+	Context("NetworkSecurityGroups", func() {
+		var networksJSON []byte
+
+		BeforeEach(func() {
+			securityGroupsResolver.ResolveStub = func(securityGroups []string) ([]string, error) {
+				var resolved []string
+				for _, securityGroup := range securityGroups {
+					resolved = append(resolved, "resolved_"+securityGroup)
+				}
+				return resolved, nil
+			}
+
+			networksJSON = []byte(`{
+				"public": {
+					"type":    "manual",
+					"ip":      "1.1.1.1",
+					"default": ["gateway"],
+					"cloud_properties": {"net_id": "the_net_id_1", "security_groups": ["public_group"]}
+				},
+				"management": {
+					"type":    "manual",
+					"ip":      "2.2.2.2",
+					"cloud_properties": {"net_id": "the_net_id_2", "security_groups": ["management_group"]}
+				},
+				"other": {
 					"type":    "dynamic",
-					"ip":      "4.4.4.4",
-					"cloud_properties": {"net_id": "the_net_id_4", "security_groups": ["security_group_4"]}
+					"cloud_properties": {"net_id": "the_net_id_3"}
+				}
+			}`)
+			openstackConfig.DefaultSecurityGroups = []string{"default_group"}
+		})
+
+		It("uses the security groups of each network for its port", func() {
+			networkingConfig, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, networksJSON, openstackConfig, cloudProperties, logger)
+
+			Expect(err).ToNot(HaveOccurred())
+			manualNetworks := sortNetworks(networkingConfig.ManualNetworks)
+			Expect(manualNetworks[0].SecurityGroupIDs).To(Equal([]string{"resolved_public_group"}))
+			Expect(manualNetworks[1].SecurityGroupIDs).To(Equal([]string{"resolved_management_group"}))
+			Expect(networkingConfig.DynamicNetwork.SecurityGroupIDs).To(Equal([]string{"resolved_default_group"}))
+		})
+
+		It("overrides the security groups of the networks with the vm security groups", func() {
+			cloudProperties.SecurityGroups = []string{"vm_group"}
+
+			networkingConfig, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, networksJSON, openstackConfig, cloudProperties, logger)
+
+			Expect(err).ToNot(HaveOccurred())
+			for _, manualNetwork := range networkingConfig.ManualNetworks {
+				Expect(manualNetwork.SecurityGroupIDs).To(Equal([]string{"resolved_vm_group"}))
+			}
+			Expect(networkingConfig.DynamicNetwork.SecurityGroupIDs).To(Equal([]string{"resolved_vm_group"}))
+		})
+
+		It("adds the vm security groups to the security groups of the networks with the add policy", func() {
+			cloudProperties.SecurityGroups = []string{"vm_group"}
+			cloudProperties.SecurityGroupsPolicy = properties.SecurityGroupsPolicyAdd
+
+			networkingConfig, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, networksJSON, openstackConfig, cloudProperties, logger)
+
+			Expect(err).ToNot(HaveOccurred())
+			manualNetworks := sortNetworks(networkingConfig.ManualNetworks)
+			Expect(manualNetworks[0].SecurityGroupIDs).To(Equal([]string{"resolved_public_group", "resolved_vm_group"}))
+			Expect(manualNetworks[1].SecurityGroupIDs).To(Equal([]string{"resolved_management_group", "resolved_vm_group"}))
+			Expect(networkingConfig.DynamicNetwork.SecurityGroupIDs).To(Equal([]string{"resolved_default_group", "resolved_vm_group"}))
+		})
+
+		It("does not resolve security groups of networks with disabled port security", func() {
+			networkingConfig, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "manual",
+					"ip":      "1.1.1.1",
+					"cloud_properties": {"net_id": "the_net_id_1", "port_security_enabled": false}
+				}
+			}`), openstackConfig, cloudProperties, logger)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingConfig.ManualNetworks[0].SecurityGroupIDs).To(BeNil())
+			Expect(securityGroupsResolver.ResolveCallCount()).To(Equal(0))
+		})
+
+		It("returns an error if the security groups of a network cannot be resolved", func() {
+			securityGroupsResolver.ResolveStub = nil
+			securityGroupsResolver.ResolveReturns(nil, errors.New("boom"))
+
+			_, err := createNetworkConfig(&securityGroupsResolver, &networkResolver, []byte(`{
+				"name1": {
+					"type":    "manual",
+					"ip":      "1.1.1.1",
+					"cloud_properties": {"net_id": "the_net_id_1"}
 				}
 			}`), openstackConfig, cloudProperties, logger)
-			securityGroups := sortSecurityGroups(networkingConfig.SecurityGroups)
 
-			securityGroupsParam := securityGroupsResolver.ResolveArgsForCall(0)
-			Expect(securityGroupsParam).To(ContainElements("security_group_1", "security_group_2", "security_group_4", "security_group_3"))
-			Expect(securityGroups).To(ContainElements("resolved_security_group_1", "resolved_security_group_2", "resolved_security_group_3", "resolved_security_group_4"))
+			Expect(err.Error()).To(Equal("invalid security group configuration: failed to resolve security groups of network 'name1': boom"))
 		})
 	})
 })
 
-func createNetworkConfig(securityGroupsResolver network.SecurityGroupsResolver, bytes []byte, openstackConfig config.OpenstackConfig, cloudProperties properties.CreateVM, logger utils.Logger) (properties.NetworkConfig, error) {
+func createNetworkConfig(securityGroupsResolver network.SecurityGroupsResolver, networkResolver network.NetworkResolver, bytes []byte, openstackConfig config.OpenstackConfig, cloudProperties properties.CreateVM, logger utils.Logger) (properties.NetworkConfig, error) {
 	var networks apiv1.Networks
 	err := json.Unmarshal(bytes, &networks)
 	Expect(err).ToNot(HaveOccurred())
 
-	networkConfig, err := network.NewNetworkConfigBuilder(securityGroupsResolver, networks, openstackConfig, cloudProperties, logger).Build()
+	networkConfig, err := network.NewNetworkConfigBuilder(securityGroupsResolver, networkResolver, networks, openstackConfig, cloudProperties, logger).Build()
 
 	return networkConfig, err
 }
@@ -0,0 +1,65 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/networks"
+)
+
+//counterfeiter:generate . NetworkResolver
+type NetworkResolver interface {
+	Resolve(name string, projectID string) (string, error)
+}
+
+type networkResolver struct {
+	serviceClients   utils.ServiceClients
+	networkingFacade NetworkingFacade
+	logger           utils.Logger
+}
+
+func NewNetworkResolver(
+	serviceClients utils.ServiceClients,
+	networkingFacade NetworkingFacade,
+	logger utils.Logger,
+) networkResolver {
+	return networkResolver{
+		serviceClients:   serviceClients,
+		networkingFacade: networkingFacade,
+		logger:           logger,
+	}
+}
+
+// Resolve returns the ID of the network with the given name, optionally scoped to the project owning it.
+// Neutron lists the networks of the project together with the networks shared with it by RBAC policies
+// and the external networks, so all of them are found by name.
+func (r networkResolver) Resolve(name string, projectID string) (string, error) {
+	page, err := r.networkingFacade.ListNetworks(r.serviceClients.RetryableServiceClient, networks.ListOpts{Name: name, ProjectID: projectID})
+	if err != nil {
+		return "", fmt.Errorf("failed to list networks: %w", err)
+	}
+
+	allNetworks, err := r.networkingFacade.ExtractNetworks(page)
+	if err != nil {
+		return "", fmt.Errorf("failed to extract networks: %w", err)
+	}
+
+	if len(allNetworks) == 0 {
+		if projectID != "" {
+			return "", fmt.Errorf("network '%s' not found in project '%s'", name, projectID)
+		}
+		return "", fmt.Errorf("network '%s' not found", name)
+	}
+
+	if len(allNetworks) > 1 {
+		var networkIDs []string
+		for _, network := range allNetworks {
+			networkIDs = append(networkIDs, network.ID)
+		}
+		return "", fmt.Errorf("network name '%s' is ambiguous, found %d networks %v", name, len(allNetworks), networkIDs)
+	}
+
+	r.logger.Info("network-resolver", fmt.Sprintf("resolved network '%s' to id '%s'", name, allNetworks[0].ID))
+
+	return allNetworks[0].ID, nil
+}
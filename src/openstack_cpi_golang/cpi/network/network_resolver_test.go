@@ -0,0 +1,88 @@
+package network_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/network"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/network/networkfakes"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils/utilsfakes"
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/networks"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NetworkResolver", func() {
+	var retryableServiceClient gophercloud.ServiceClient
+	var serviceClients utils.ServiceClients
+	var networkingFacade networkfakes.FakeNetworkingFacade
+	var logger utilsfakes.FakeLogger
+
+	BeforeEach(func() {
+		retryableServiceClient = gophercloud.ServiceClient{}
+		serviceClients = utils.ServiceClients{ServiceClient: &gophercloud.ServiceClient{}, RetryableServiceClient: &retryableServiceClient}
+		networkingFacade = networkfakes.FakeNetworkingFacade{}
+		logger = utilsfakes.FakeLogger{}
+
+		networkingFacade.ExtractNetworksReturns([]networks.Network{{ID: "the-net-id"}}, nil)
+	})
+
+	It("resolves the network id by name", func() {
+		netID, err := network.NewNetworkResolver(serviceClients, &networkingFacade, &logger).Resolve("the-net", "")
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(netID).To(Equal("the-net-id"))
+		serviceClient, listOpts := networkingFacade.ListNetworksArgsForCall(0)
+		Expect(serviceClient).To(BeIdenticalTo(serviceClients.RetryableServiceClient))
+		Expect(listOpts).To(Equal(networks.ListOpts{Name: "the-net"}))
+	})
+
+	It("scopes the lookup to the given project", func() {
+		_, err := network.NewNetworkResolver(serviceClients, &networkingFacade, &logger).Resolve("the-net", "the-project-id")
+
+		Expect(err).ToNot(HaveOccurred())
+		_, listOpts := networkingFacade.ListNetworksArgsForCall(0)
+		Expect(listOpts).To(Equal(networks.ListOpts{Name: "the-net", ProjectID: "the-project-id"}))
+	})
+
+	It("returns an error if the networks cannot be listed", func() {
+		networkingFacade.ListNetworksReturns(nil, errors.New("boom"))
+
+		_, err := network.NewNetworkResolver(serviceClients, &networkingFacade, &logger).Resolve("the-net", "")
+
+		Expect(err.Error()).To(Equal("failed to list networks: boom"))
+	})
+
+	It("returns an error if the networks cannot be extracted", func() {
+		networkingFacade.ExtractNetworksReturns(nil, errors.New("boom"))
+
+		_, err := network.NewNetworkResolver(serviceClients, &networkingFacade, &logger).Resolve("the-net", "")
+
+		Expect(err.Error()).To(Equal("failed to extract networks: boom"))
+	})
+
+	It("returns an error if the network cannot be found", func() {
+		networkingFacade.ExtractNetworksReturns([]networks.Network{}, nil)
+
+		_, err := network.NewNetworkResolver(serviceClients, &networkingFacade, &logger).Resolve("the-net", "")
+
+		Expect(err.Error()).To(Equal("network 'the-net' not found"))
+	})
+
+	It("returns an error if the network cannot be found in the project", func() {
+		networkingFacade.ExtractNetworksReturns([]networks.Network{}, nil)
+
+		_, err := network.NewNetworkResolver(serviceClients, &networkingFacade, &logger).Resolve("the-net", "the-project-id")
+
+		Expect(err.Error()).To(Equal("network 'the-net' not found in project 'the-project-id'"))
+	})
+
+	It("returns an error if the network name is ambiguous", func() {
+		networkingFacade.ExtractNetworksReturns([]networks.Network{{ID: "net-1"}, {ID: "net-2"}}, nil)
+
+		_, err := network.NewNetworkResolver(serviceClients, &networkingFacade, &logger).Resolve("the-net", "")
+
+		Expect(err.Error()).To(Equal("network name 'the-net' is ambiguous, found 2 networks [net-1 net-2]"))
+	})
+})
@@ -1,26 +1,57 @@
 package network
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"net"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/cloudfoundry/bosh-cpi-go/apiv1"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/config"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/properties"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils"
 	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/extradhcpopts"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/floatingips"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/portsbinding"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/portsecurity"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/qos/policies"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/subnets"
 )
 
+// FloatingIPAllocatedTag marks floating IPs allocated by the CPI from a floating network.
+const FloatingIPAllocatedTag = "bosh-cpi-allocated"
+
+// ParkedPortTag marks ports which are kept after the deletion of their VM with 'preserve_ports'.
+const ParkedPortTag = "parked"
+
+// parkedAtTagPrefix prefixes the tag recording when a port has been parked.
+const parkedAtTagPrefix = "parked_at:"
+
+var VRRPPortTags = []string{properties.BoshTag, "vrrp"}
+
 //counterfeiter:generate . NetworkService
 type NetworkService interface {
 	ConfigureVIPNetwork(
 		instanceId string,
 		networkConfig properties.NetworkConfig,
-	) error
+		identity properties.InstanceIdentity,
+	) (string, error)
+
+	ReleaseFloatingIPs(instanceId string) error
+
+	DisassociateFloatingIPs(ports []ports.Port) error
+
+	SetMetadataTags(ports []ports.Port, metadata map[string]string) error
+
+	EnsureManagedSecurityGroups(managedSecurityGroups []properties.ManagedSecurityGroup, boshEnv properties.BoshEnv) ([]string, error)
+
+	DeleteUnusedManagedSecurityGroups(securityGroupIDs []string) error
 
 	GetNetworkConfiguration(
 		networks apiv1.Networks,
@@ -30,7 +61,19 @@ type NetworkService interface {
 
 	GetSubnetID(networkID string, ip string) (string, error)
 
-	CreatePort(networkConfig properties.Network, securityGroups []string, cloudProperties properties.CreateVM) (ports.Port, error)
+	GetAdditionalIPs(network properties.Network, port ports.Port) ([]properties.AdditionalIP, error)
+
+	GetNetworkSettings(network properties.Network) (properties.NetworkSettings, error)
+
+	CreatePort(
+		networkConfig properties.Network,
+		securityGroups []string,
+		cloudProperties properties.CreateVM,
+		identity properties.InstanceIdentity,
+		openstackConfig config.OpenstackConfig,
+	) (ports.Port, error)
+
+	GetPreCreatedPort(network properties.Network) (ports.Port, error)
 
 	GetPorts(
 		instanceId string,
@@ -41,6 +84,10 @@ type NetworkService interface {
 	DeletePorts(
 		ports []ports.Port,
 	) error
+
+	ParkPorts(ports []ports.Port) error
+
+	DeleteExpiredParkedPorts(expiry time.Duration, identity properties.InstanceIdentity) error
 }
 
 type networkService struct {
@@ -64,28 +111,131 @@ func NewNetworkService(
 func (c networkService) ConfigureVIPNetwork(
 	instanceId string,
 	networkConfig properties.NetworkConfig,
-) error {
+	identity properties.InstanceIdentity,
+) (string, error) {
 	vipNetwork := networkConfig.VIPNetwork
 
-	if vipNetwork != nil {
-		floatingIp, err := c.getFloatingIp(vipNetwork)
+	if vipNetwork == nil {
+		return "", nil
+	}
+
+	if vipNetwork.IP == "" {
+		return c.allocateFloatingIp(instanceId, networkConfig)
+	}
+
+	floatingIp, err := c.getFloatingIp(vipNetwork)
+	if err != nil {
+		return "", fmt.Errorf("failed to get floating IP: %w", err)
+	}
+
+	instancePort, err := c.getInstancePort(instanceId, networkConfig)
+	if err != nil {
+		return "", err
+	}
+
+	err = c.associateFloatingIp(floatingIp, instancePort, vipNetwork.CloudProps.TargetFixedIP, identity)
+	if err != nil {
+		return "", fmt.Errorf("failed to associate floating ip to port: %w", err)
+	}
+
+	return vipNetwork.IP, nil
+}
+
+func (c networkService) DisassociateFloatingIPs(ports []ports.Port) error {
+	var errDefault404 gophercloud.ErrDefault404
+
+	for _, port := range ports {
+		allPages, err := c.networkingFacade.ListFloatingIps(c.serviceClients.RetryableServiceClient, floatingips.ListOpts{PortID: port.ID})
 		if err != nil {
-			return fmt.Errorf("failed to get floating IP: %w", err)
+			return fmt.Errorf("failed to list floating IPs: %w", err)
 		}
 
-		instancePorts, err := c.GetPorts(instanceId, networkConfig.DefaultNetwork, false)
+		allFIPs, err := c.networkingFacade.ExtractFloatingIPs(allPages)
 		if err != nil {
-			return fmt.Errorf("failed to get port: %w", err)
+			return fmt.Errorf("failed to extract floating IPs: %w", err)
 		}
-		if len(instancePorts) == 0 {
-			return fmt.Errorf("no port allocated by instance %s and network %s", instanceId, networkConfig.DefaultNetwork.CloudProps.NetID)
+
+		for _, floatingIp := range allFIPs {
+			err = c.disassociateFloatingIp(floatingIp.ID)
+			if err != nil && !errors.As(err, &errDefault404) {
+				return fmt.Errorf("failed to disassociate floating IP '%s' from port '%s': %w", floatingIp.FloatingIP, port.ID, err)
+			}
+			c.logger.Info("network-service", fmt.Sprintf("Disassociated floating IP '%s' from port '%s'", floatingIp.FloatingIP, port.ID))
+		}
+	}
+
+	return nil
+}
+
+// SetMetadataTags sets the metadata as 'key:value' tags on the ports and their floating IPs.
+// The tags identifying the instance or parked ports are kept, resources already tagged are not updated.
+func (c networkService) SetMetadataTags(ports []ports.Port, metadata map[string]string) error {
+	for _, port := range ports {
+		err := c.replaceMetadataTags("ports", port.ID, port.Tags, metadata)
+		if err != nil {
+			c.logger.Warn("network-service", fmt.Sprintf("failed to tag port '%s': %v", port.ID, err))
 		}
 
-		err = c.associateFloatingIp(floatingIp.ID, instancePorts[0].ID)
+		allPages, err := c.networkingFacade.ListFloatingIps(c.serviceClients.RetryableServiceClient, floatingips.ListOpts{PortID: port.ID})
 		if err != nil {
-			return fmt.Errorf("failed to associate floating ip to port: %w", err)
+			return fmt.Errorf("failed to list floating IPs: %w", err)
+		}
+
+		allFIPs, err := c.networkingFacade.ExtractFloatingIPs(allPages)
+		if err != nil {
+			return fmt.Errorf("failed to extract floating IPs: %w", err)
+		}
+
+		for _, floatingIp := range allFIPs {
+			err = c.replaceMetadataTags("floatingips", floatingIp.ID, floatingIp.Tags, metadata)
+			if err != nil {
+				c.logger.Warn("network-service", fmt.Sprintf("failed to tag floating IP '%s': %v", floatingIp.FloatingIP, err))
+			}
+		}
+	}
+
+	return nil
+}
+
+func (c networkService) replaceMetadataTags(resourceType string, resourceID string, tags []string, metadata map[string]string) error {
+	metadataTags := properties.MetadataTags(tags, metadata)
+	if slices.Equal(slices.Sorted(slices.Values(metadataTags)), slices.Sorted(slices.Values(tags))) {
+		return nil
+	}
+
+	_, err := c.networkingFacade.ReplaceAllTags(c.serviceClients.ServiceClient, resourceType, resourceID, metadataTags)
+	if err != nil {
+		return err
+	}
+	c.logger.Info("network-service", fmt.Sprintf("Tagged %s '%s' with the VM metadata", resourceType, resourceID))
+
+	return nil
+}
+
+func (c networkService) ReleaseFloatingIPs(instanceId string) error {
+	listOpts := floatingips.ListOpts{
+		Tags: strings.Join(floatingIPTags(instanceId), ","),
+	}
+
+	allPages, err := c.networkingFacade.ListFloatingIps(c.serviceClients.RetryableServiceClient, listOpts)
+	if err != nil {
+		return fmt.Errorf("failed to list floating IPs: %w", err)
+	}
+
+	allFIPs, err := c.networkingFacade.ExtractFloatingIPs(allPages)
+	if err != nil {
+		return fmt.Errorf("failed to extract floating IPs: %w", err)
+	}
+
+	var errDefault404 gophercloud.ErrDefault404
+	for _, floatingIp := range allFIPs {
+		err = c.networkingFacade.DeleteFloatingIP(c.serviceClients.RetryableServiceClient, floatingIp.ID)
+		if err != nil && !errors.As(err, &errDefault404) {
+			return fmt.Errorf("failed to release floating IP '%s': %w", floatingIp.FloatingIP, err)
 		}
+		c.logger.Info("network-service", fmt.Sprintf("Released floating IP '%s' of instance '%s'", floatingIp.FloatingIP, instanceId))
 	}
+
 	return nil
 }
 
@@ -95,29 +245,29 @@ func (c networkService) GetNetworkConfiguration(
 	cloudProps properties.CreateVM,
 ) (properties.NetworkConfig, error) {
 	securityGroupsResolver := NewSecurityGroupsResolver(c.serviceClients, c.networkingFacade, c.logger)
+	networkResolver := NewNetworkResolver(c.serviceClients, c.networkingFacade, c.logger)
 
-	networkProperties, err := NewNetworkConfigBuilder(securityGroupsResolver, networks, openstackConfig, cloudProps, c.logger).Build()
+	networkProperties, err := NewNetworkConfigBuilder(securityGroupsResolver, networkResolver, networks, openstackConfig, cloudProps, c.logger).Build()
 	return networkProperties, err
 }
 
+func (c networkService) EnsureManagedSecurityGroups(managedSecurityGroups []properties.ManagedSecurityGroup, boshEnv properties.BoshEnv) ([]string, error) {
+	return NewSecurityGroupsManager(c.serviceClients, c.networkingFacade, c.logger).Ensure(managedSecurityGroups, boshEnv)
+}
+
+func (c networkService) DeleteUnusedManagedSecurityGroups(securityGroupIDs []string) error {
+	return NewSecurityGroupsManager(c.serviceClients, c.networkingFacade, c.logger).DeleteUnused(securityGroupIDs)
+}
+
 func (c networkService) GetSubnetID(networkID string, ip string) (string, error) {
 	ipAddress := net.ParseIP(ip)
 	if ipAddress == nil {
 		return "", fmt.Errorf("failed to parse ip address '%s'", ip)
 	}
 
-	listOpts := subnets.ListOpts{
-		NetworkID: networkID,
-	}
-
-	allPages, err := c.networkingFacade.ListSubnets(c.serviceClients.RetryableServiceClient, listOpts)
+	allSubnets, err := c.listSubnets(networkID)
 	if err != nil {
-		return "", fmt.Errorf("failed to list subnets: %w", err)
-	}
-
-	allSubnets, err := c.networkingFacade.ExtractSubnets(allPages)
-	if err != nil {
-		return "", fmt.Errorf("failed to extract subnets: %w", err)
+		return "", err
 	}
 
 	if len(allSubnets) == 0 {
@@ -126,12 +276,12 @@ func (c networkService) GetSubnetID(networkID string, ip string) (string, error)
 
 	var matchingSubnets []string
 	for _, subnet := range allSubnets {
-		_, ipNet, err := net.ParseCIDR(subnet.CIDR)
-		if ipNet == nil {
-			return "", fmt.Errorf("failed to parse subnet cidr '%s': %w", subnet.CIDR, err)
+		contained, err := c.subnetContainsIP(subnet, ipAddress)
+		if err != nil {
+			return "", err
 		}
 
-		if ipNet.Contains(ipAddress) {
+		if contained {
 			matchingSubnets = append(matchingSubnets, subnet.ID)
 		}
 	}
@@ -145,8 +295,128 @@ func (c networkService) GetSubnetID(networkID string, ip string) (string, error)
 	return matchingSubnets[0], nil
 }
 
-func (c networkService) CreatePort(network properties.Network, securityGroups []string, cloudProperties properties.CreateVM) (ports.Port, error) {
-	createOpts, err := c.getPortCreationNetworkOpts(network, securityGroups, cloudProperties)
+func (c networkService) GetAdditionalIPs(network properties.Network, port ports.Port) ([]properties.AdditionalIP, error) {
+	var additionalFixedIPs []ports.IP
+	for _, fixedIP := range port.FixedIPs {
+		if fixedIP.IPAddress != network.IP {
+			additionalFixedIPs = append(additionalFixedIPs, fixedIP)
+		}
+	}
+
+	if len(additionalFixedIPs) == 0 {
+		return nil, nil
+	}
+
+	allSubnets, err := c.listSubnets(network.CloudProps.NetID)
+	if err != nil {
+		return nil, err
+	}
+
+	var additionalIPs []properties.AdditionalIP
+	for _, fixedIP := range additionalFixedIPs {
+		subnetIndex := slices.IndexFunc(allSubnets, func(subnet subnets.Subnet) bool {
+			return subnet.ID == fixedIP.SubnetID
+		})
+		if subnetIndex < 0 {
+			return nil, fmt.Errorf("subnet '%s' of ip '%s' not found in network '%s'", fixedIP.SubnetID, fixedIP.IPAddress, network.CloudProps.NetID)
+		}
+
+		_, ipNet, err := net.ParseCIDR(allSubnets[subnetIndex].CIDR)
+		if ipNet == nil {
+			return nil, fmt.Errorf("failed to parse subnet cidr '%s': %w", allSubnets[subnetIndex].CIDR, err)
+		}
+
+		additionalIPs = append(additionalIPs, properties.AdditionalIP{
+			SubnetID: fixedIP.SubnetID,
+			IP:       fixedIP.IPAddress,
+			Netmask:  net.IP(ipNet.Mask).String(),
+			Gateway:  allSubnets[subnetIndex].GatewayIP,
+		})
+	}
+
+	return additionalIPs, nil
+}
+
+// GetNetworkSettings returns the host routes and DNS servers of the subnet holding the IP of a
+// manual network, or of all IPv4 subnets of a dynamic network, together with the network MTU.
+func (c networkService) GetNetworkSettings(network properties.Network) (properties.NetworkSettings, error) {
+	networkID := network.CloudProps.NetID
+
+	allSubnets, err := c.listSubnets(networkID)
+	if err != nil {
+		return properties.NetworkSettings{}, err
+	}
+
+	var networkSubnets []subnets.Subnet
+	for _, subnet := range allSubnets {
+		if network.IP == "" {
+			if subnet.IPVersion == 4 {
+				networkSubnets = append(networkSubnets, subnet)
+			}
+			continue
+		}
+
+		contained, err := c.subnetContainsIP(subnet, net.ParseIP(network.IP))
+		if err != nil {
+			return properties.NetworkSettings{}, err
+		}
+
+		if contained {
+			networkSubnets = append(networkSubnets, subnet)
+			break
+		}
+	}
+
+	if network.IP != "" && len(networkSubnets) == 0 {
+		return properties.NetworkSettings{}, fmt.Errorf("no matching subnet found for the ip '%s'", network.IP)
+	}
+
+	var settings properties.NetworkSettings
+	for _, subnet := range networkSubnets {
+		for _, hostRoute := range subnet.HostRoutes {
+			_, destination, err := net.ParseCIDR(hostRoute.DestinationCIDR)
+			if destination == nil {
+				return properties.NetworkSettings{}, fmt.Errorf("failed to parse destination '%s' of a host route of subnet '%s': %w", hostRoute.DestinationCIDR, subnet.ID, err)
+			}
+
+			settings.Routes = append(settings.Routes, properties.Route{
+				Destination: destination.IP.String(),
+				Gateway:     hostRoute.NextHop,
+				Netmask:     net.IP(destination.Mask).String(),
+			})
+		}
+
+		settings.DNS = utils.UniqueArray(append(settings.DNS, subnet.DNSNameservers...))
+	}
+
+	networkWithMTU, err := c.networkingFacade.GetNetworkWithMTU(c.serviceClients.RetryableServiceClient, networkID)
+	if err != nil {
+		return properties.NetworkSettings{}, fmt.Errorf("failed to get network '%s': %w", networkID, err)
+	}
+	settings.MTU = networkWithMTU.MTU
+
+	return settings, nil
+}
+
+func (c networkService) CreatePort(
+	network properties.Network,
+	securityGroups []string,
+	cloudProperties properties.CreateVM,
+	identity properties.InstanceIdentity,
+	openstackConfig config.OpenstackConfig,
+) (ports.Port, error) {
+	if openstackConfig.PreservePorts {
+		parkedPort, err := c.adoptParkedPort(network, securityGroups, cloudProperties, identity)
+		if err != nil {
+			return ports.Port{}, fmt.Errorf("failed to adopt parked port: %w", err)
+		}
+
+		if parkedPort != nil {
+			return *parkedPort, nil
+		}
+	}
+
+	createOpts, err := c.getPortCreationNetworkOpts(network, securityGroups, cloudProperties, identity)
 	if err != nil {
 		return ports.Port{}, fmt.Errorf("failed create network opts: %w", err)
 	}
@@ -158,6 +428,14 @@ func (c networkService) CreatePort(network properties.Network, securityGroups []
 		c.logger.Warn("network-service",
 			fmt.Sprintf("failed to create port on network '%s' for ip '%s': %v",
 				network.CloudProps.NetID, network.IP, err))
+
+		portConflictPolicy := openstackConfig.GetPortConflictPolicy()
+		if portConflictPolicy == config.PortConflictPolicyNever {
+			return ports.Port{}, fmt.Errorf("failed to create port on network '%s' for ip '%s', "+
+				"conflicting ports are not deleted with port conflict policy '%s': %w",
+				network.CloudProps.NetID, network.IP, portConflictPolicy, err)
+		}
+
 		c.logger.Warn("network-service", "checking for conflicting ports now")
 
 		listOpts := ports.ListOpts{
@@ -176,6 +454,13 @@ func (c networkService) CreatePort(network properties.Network, securityGroups []
 
 		for _, port := range existingPorts {
 			if port.Status == "DOWN" && port.DeviceID == "" && port.DeviceOwner == "" {
+				if portConflictPolicy == config.PortConflictPolicyBoshOwnedOnly && !identity.Owns(port.Tags) {
+					c.logger.Warn("network-service", fmt.Sprintf("port '%s' on network '%s' for ip '%s' "+
+						"is allocated but unused, not deleting it as it is not owned by BOSH.",
+						port.ID, network.CloudProps.NetID, network.IP))
+					continue
+				}
+
 				c.logger.Warn("network-service", fmt.Sprintf("port on network '%s' for ip '%s' "+
 					"is already allocated but unused, deleting conflicting port now.",
 					network.CloudProps.NetID, network.IP))
@@ -203,9 +488,43 @@ func (c networkService) CreatePort(network properties.Network, securityGroups []
 				createdPort.ID, network.CloudProps.NetID, network.IP))
 	}
 
+	tags, err := c.networkingFacade.ReplaceAllTags(c.serviceClients.ServiceClient, "ports", createdPort.ID, identity.Tags())
+	if err != nil {
+		c.logger.Warn("network-service", fmt.Sprintf("failed to tag port '%s': %v", createdPort.ID, err))
+	} else {
+		createdPort.Tags = tags
+	}
+
 	return *createdPort, nil
 }
 
+// GetPreCreatedPort returns the port configured by 'port_id' after validating that it belongs to the network,
+// holds the IP assigned by BOSH and is not attached to another device.
+func (c networkService) GetPreCreatedPort(network properties.Network) (ports.Port, error) {
+	portID := network.CloudProps.PortID
+
+	port, err := c.networkingFacade.GetPort(c.serviceClients.RetryableServiceClient, portID)
+	if err != nil {
+		return ports.Port{}, fmt.Errorf("failed to get port '%s': %w", portID, err)
+	}
+
+	if port.NetworkID != network.CloudProps.NetID {
+		return ports.Port{}, fmt.Errorf("port '%s' belongs to network '%s' instead of network '%s'", portID, port.NetworkID, network.CloudProps.NetID)
+	}
+
+	if network.IP != "" && !slices.ContainsFunc(port.FixedIPs, func(fixedIP ports.IP) bool { return fixedIP.IPAddress == network.IP }) {
+		return ports.Port{}, fmt.Errorf("port '%s' does not hold the ip '%s' of network '%s'", portID, network.IP, network.Key)
+	}
+
+	if port.DeviceID != "" {
+		return ports.Port{}, fmt.Errorf("port '%s' is already attached to device '%s'", portID, port.DeviceID)
+	}
+
+	c.logger.Info("network-service", fmt.Sprintf("using pre-created port '%s' with mac '%s' for network '%s'", portID, port.MACAddress, network.Key))
+
+	return *port, nil
+}
+
 func (c networkService) GetPorts(instanceId string, defaultNetwork properties.Network, retryable bool) ([]ports.Port, error) {
 	listOpts := ports.ListOpts{
 		DeviceID: instanceId,
@@ -246,60 +565,517 @@ func (c networkService) DeletePorts(ports []ports.Port) error {
 	return nil
 }
 
+// ParkPorts keeps the ports of a deleted VM for adoption by the next VM with the same network and IP,
+// so that the MAC address of the ports survives the recreation of the VM.
+func (c networkService) ParkPorts(portsToPark []ports.Port) error {
+	var errDefault404 gophercloud.ErrDefault404
+
+	parkedAt := time.Now().UTC().Format(time.RFC3339)
+
+	for _, port := range portsToPark {
+		tags := slices.DeleteFunc(slices.Clone(port.Tags), isParkedPortTag)
+		if !slices.Contains(tags, properties.BoshTag) {
+			tags = append([]string{properties.BoshTag}, tags...)
+		}
+		tags = append(tags, ParkedPortTag, parkedAtTagPrefix+parkedAt)
+
+		_, err := c.networkingFacade.ReplaceAllTags(c.serviceClients.ServiceClient, "ports", port.ID, tags)
+		if err != nil {
+			if errors.As(err, &errDefault404) {
+				c.logger.Info("network-service", fmt.Sprintf("SKIPPING: Port with id '%s' to be parked is not found", port.ID))
+				continue
+			}
+			return fmt.Errorf("failed to park port '%s': %w", port.ID, err)
+		}
+		c.logger.Info("network-service", fmt.Sprintf("parked port '%s' with mac '%s'", port.ID, port.MACAddress))
+	}
+
+	return nil
+}
+
+// DeleteExpiredParkedPorts deletes parked ports of the director which have not been adopted by a VM within the expiry.
+// Nothing is deleted if the director is not known, as the parked ports of other directors cannot be told apart.
+func (c networkService) DeleteExpiredParkedPorts(expiry time.Duration, identity properties.InstanceIdentity) error {
+	var errDefault404 gophercloud.ErrDefault404
+
+	if identity.Director == "" {
+		c.logger.Info("network-service", "SKIPPING: Deletion of expired parked ports, the director is not known")
+		return nil
+	}
+
+	parkedPorts, err := c.listParkedPorts(ports.ListOpts{}, identity)
+	if err != nil {
+		return err
+	}
+
+	for _, port := range parkedPorts {
+		if port.DeviceID != "" || !identity.Owns(port.Tags) {
+			continue
+		}
+
+		parkedAt, err := getParkedAt(port.Tags)
+		if err != nil {
+			c.logger.Warn("network-service", fmt.Sprintf("failed to determine when port '%s' was parked: %v", port.ID, err))
+			continue
+		}
+
+		if time.Since(parkedAt) < expiry {
+			continue
+		}
+
+		err = c.networkingFacade.DeletePort(c.serviceClients.RetryableServiceClient, port.ID)
+		if err != nil && !errors.As(err, &errDefault404) {
+			return fmt.Errorf("failed to delete expired parked port '%s': %w", port.ID, err)
+		}
+		c.logger.Info("network-service", fmt.Sprintf("deleted port '%s' parked at '%s'", port.ID, parkedAt.Format(time.RFC3339)))
+	}
+
+	return nil
+}
+
+// adoptParkedPort returns a port parked by the same director for the network and IP, or nil if there is none.
+// The port is updated with the current port configuration and unparked. A parked port whose port properties
+// differ from the network configuration is deleted instead, so that a port with the new properties is created.
+func (c networkService) adoptParkedPort(
+	network properties.Network,
+	securityGroups []string,
+	cloudProperties properties.CreateVM,
+	identity properties.InstanceIdentity,
+) (*ports.Port, error) {
+	if network.IP == "" {
+		return nil, nil
+	}
+
+	var errDefault404 gophercloud.ErrDefault404
+
+	parkedPorts, err := c.listParkedPorts(ports.ListOpts{
+		NetworkID: network.CloudProps.NetID,
+		FixedIPs:  []ports.FixedIPOpts{{IPAddress: network.IP}},
+	}, identity)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, parkedPort := range parkedPorts {
+		if parkedPort.DeviceID != "" || !identity.Owns(parkedPort.Tags) {
+			continue
+		}
+
+		parkedPortWithExtensions, err := c.networkingFacade.GetPortWithExtensions(c.serviceClients.RetryableServiceClient, parkedPort.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get parked port '%s': %w", parkedPort.ID, err)
+		}
+
+		if !portPropertiesMatch(*parkedPortWithExtensions, network.CloudProps) {
+			c.logger.Info("network-service", fmt.Sprintf("deleting parked port '%s' as its port properties differ from the configuration of network '%s'",
+				parkedPort.ID, network.CloudProps.NetID))
+
+			err = c.networkingFacade.DeletePort(c.serviceClients.RetryableServiceClient, parkedPort.ID)
+			if err != nil && !errors.As(err, &errDefault404) {
+				return nil, fmt.Errorf("failed to delete parked port '%s': %w", parkedPort.ID, err)
+			}
+			continue
+		}
+
+		if network.CloudProps.PortSecurityDisabled() {
+			securityGroups = []string{}
+		}
+
+		addressPairs := []ports.AddressPair{}
+		for _, pair := range cloudProperties.AllowedAddressPairs {
+			addressPairs = append(addressPairs, ports.AddressPair{IPAddress: pair.IPAddress, MACAddress: pair.MACAddress})
+		}
+
+		name := identity.PortName(network.Key)
+		description := fmt.Sprintf("Created by the BOSH OpenStack CPI for agent %s", identity.AgentID)
+		updateOpts := ports.UpdateOpts{
+			Name:                &name,
+			Description:         &description,
+			SecurityGroups:      &securityGroups,
+			AllowedAddressPairs: &addressPairs,
+		}
+
+		port, err := c.networkingFacade.UpdatePort(c.serviceClients.ServiceClient, parkedPort.ID, updateOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update parked port '%s': %w", parkedPort.ID, err)
+		}
+
+		tags, err := c.networkingFacade.ReplaceAllTags(c.serviceClients.ServiceClient, "ports", port.ID, identity.Tags())
+		if err != nil {
+			return nil, fmt.Errorf("failed to unpark port '%s': %w", port.ID, err)
+		}
+		port.Tags = tags
+
+		c.logger.Info("network-service", fmt.Sprintf("adopted parked port '%s' with mac '%s' on network '%s' for ip '%s'",
+			port.ID, port.MACAddress, network.CloudProps.NetID, network.IP))
+
+		return port, nil
+	}
+
+	return nil, nil
+}
+
+func (c networkService) listParkedPorts(listOpts ports.ListOpts, identity properties.InstanceIdentity) ([]ports.Port, error) {
+	tags := []string{properties.BoshTag, ParkedPortTag}
+	if identity.Director != "" {
+		tags = append(tags, identity.DirectorTag())
+	}
+	listOpts.Tags = strings.Join(tags, ",")
+
+	page, err := c.networkingFacade.ListPorts(c.serviceClients.RetryableServiceClient, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list parked ports: %w", err)
+	}
+
+	parkedPorts, err := c.networkingFacade.ExtractPorts(page)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract parked ports: %w", err)
+	}
+
+	return parkedPorts, nil
+}
+
+// portPropertiesMatch reports whether the port has the vNIC type, port security, QoS policy, binding profile and
+// extra DHCP options configured for the network. Properties not configured for the network must not be set on the port,
+// except port security, which defaults to the setting of the network.
+func portPropertiesMatch(port PortWithExtensions, cloudProps properties.NetworkCloudProps) bool {
+	if cmp.Or(port.VNICType, "normal") != cmp.Or(cloudProps.VNICType, "normal") {
+		return false
+	}
+
+	if cloudProps.PortSecurityEnabled != nil && *cloudProps.PortSecurityEnabled != port.PortSecurityEnabled {
+		return false
+	}
+
+	if port.QoSPolicyID != cloudProps.QoSPolicyID {
+		return false
+	}
+
+	if (len(port.Profile) > 0 || len(cloudProps.BindingProfile) > 0) && !reflect.DeepEqual(port.Profile, cloudProps.BindingProfile) {
+		return false
+	}
+
+	var portDHCPOpts []properties.ExtraDHCPOpt
+	for _, opt := range port.ExtraDHCPOpts {
+		portDHCPOpts = append(portDHCPOpts, normalizeExtraDHCPOpt(properties.ExtraDHCPOpt{OptName: opt.OptName, OptValue: opt.OptValue, IPVersion: opt.IPVersion}))
+	}
+
+	var configuredDHCPOpts []properties.ExtraDHCPOpt
+	for _, opt := range cloudProps.ExtraDHCPOpts {
+		configuredDHCPOpts = append(configuredDHCPOpts, normalizeExtraDHCPOpt(opt))
+	}
+
+	return slices.Equal(sortExtraDHCPOpts(portDHCPOpts), sortExtraDHCPOpts(configuredDHCPOpts))
+}
+
+// normalizeExtraDHCPOpt sets the IP version Neutron defaults to if it is not configured.
+func normalizeExtraDHCPOpt(opt properties.ExtraDHCPOpt) properties.ExtraDHCPOpt {
+	if opt.IPVersion == 0 {
+		opt.IPVersion = 4
+	}
+
+	return opt
+}
+
+func sortExtraDHCPOpts(opts []properties.ExtraDHCPOpt) []properties.ExtraDHCPOpt {
+	slices.SortFunc(opts, func(a, b properties.ExtraDHCPOpt) int {
+		return cmp.Or(cmp.Compare(a.OptName, b.OptName), cmp.Compare(a.IPVersion, b.IPVersion), cmp.Compare(a.OptValue, b.OptValue))
+	})
+
+	return opts
+}
+
+func isParkedPortTag(tag string) bool {
+	return tag == ParkedPortTag || strings.HasPrefix(tag, parkedAtTagPrefix)
+}
+
+func getParkedAt(tags []string) (time.Time, error) {
+	for _, tag := range tags {
+		if parkedAt, found := strings.CutPrefix(tag, parkedAtTagPrefix); found {
+			return time.Parse(time.RFC3339, parkedAt)
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("no '%s' tag found", strings.TrimSuffix(parkedAtTagPrefix, ":"))
+}
+
 func (c networkService) getPortCreationNetworkOpts(
 	network properties.Network,
 	securityGroups []string,
 	cloudProperties properties.CreateVM,
-) (ports.CreateOpts, error) {
-	subnetID, err := c.GetSubnetID(network.CloudProps.NetID, network.IP)
+	identity properties.InstanceIdentity,
+) (ports.CreateOptsBuilder, error) {
+	fixedIPs, err := c.getFixedIPs(network)
 	if err != nil {
-		return ports.CreateOpts{}, fmt.Errorf("failed to get subnet: %w", err)
+		return nil, err
+	}
+
+	if network.CloudProps.PortSecurityDisabled() {
+		c.logger.Info("network-service", fmt.Sprintf("port security is disabled on network '%s', not applying security groups %v", network.CloudProps.NetID, securityGroups))
+		securityGroups = []string{}
 	}
 
 	createOpts := ports.CreateOpts{
-		NetworkID: network.CloudProps.NetID,
-		FixedIPs: []ports.IP{
-			{SubnetID: subnetID, IPAddress: network.IP},
-		},
+		Name:           identity.PortName(network.Key),
+		Description:    fmt.Sprintf("Created by the BOSH OpenStack CPI for agent %s", identity.AgentID),
+		NetworkID:      network.CloudProps.NetID,
+		FixedIPs:       fixedIPs,
 		SecurityGroups: &securityGroups,
 	}
 
-	if cloudProperties.AllowedAddressPairs != "" {
-		vrrpPortExisting, err := c.isVRRPPortExisting(cloudProperties)
+	if len(cloudProperties.AllowedAddressPairs) > 0 {
+		err = c.ensureVRRPPorts(network, cloudProperties)
 		if err != nil {
-			return ports.CreateOpts{}, fmt.Errorf("VRRP port existence check failed: %w", err)
+			return nil, err
+		}
+
+		for _, pair := range cloudProperties.AllowedAddressPairs {
+			createOpts.AllowedAddressPairs = append(createOpts.AllowedAddressPairs, ports.AddressPair{
+				IPAddress:  pair.IPAddress,
+				MACAddress: pair.MACAddress,
+			})
+		}
+	}
+
+	return c.addPortExtensionOpts(createOpts, network.CloudProps), nil
+}
+
+func (c networkService) addPortExtensionOpts(createOpts ports.CreateOptsBuilder, cloudProps properties.NetworkCloudProps) ports.CreateOptsBuilder {
+	if cloudProps.VNICType != "" || len(cloudProps.BindingProfile) > 0 {
+		createOpts = portsbinding.CreateOptsExt{
+			CreateOptsBuilder: createOpts,
+			VNICType:          cloudProps.VNICType,
+			Profile:           cloudProps.BindingProfile,
 		}
+	}
 
-		if !vrrpPortExisting {
-			return ports.CreateOpts{}, fmt.Errorf("configured VRRP port with ip '%s' does not exist", cloudProperties.AllowedAddressPairs)
+	if cloudProps.PortSecurityEnabled != nil {
+		createOpts = portsecurity.PortCreateOptsExt{
+			CreateOptsBuilder:   createOpts,
+			PortSecurityEnabled: cloudProps.PortSecurityEnabled,
 		}
+	}
 
-		createOpts.AllowedAddressPairs = []ports.AddressPair{{IPAddress: cloudProperties.AllowedAddressPairs}}
+	if cloudProps.QoSPolicyID != "" {
+		createOpts = policies.PortCreateOptsExt{
+			CreateOptsBuilder: createOpts,
+			QoSPolicyID:       cloudProps.QoSPolicyID,
+		}
 	}
-	return createOpts, nil
+
+	if len(cloudProps.ExtraDHCPOpts) > 0 {
+		var extraDHCPOpts []extradhcpopts.CreateExtraDHCPOpt
+		for _, opt := range cloudProps.ExtraDHCPOpts {
+			extraDHCPOpts = append(extraDHCPOpts, extradhcpopts.CreateExtraDHCPOpt{
+				OptName:   opt.OptName,
+				OptValue:  opt.OptValue,
+				IPVersion: gophercloud.IPVersion(opt.IPVersion),
+			})
+		}
+
+		createOpts = extradhcpopts.CreateOptsExt{
+			CreateOptsBuilder: createOpts,
+			ExtraDHCPOpts:     extraDHCPOpts,
+		}
+	}
+
+	return createOpts
 }
 
-func (c networkService) isVRRPPortExisting(cloudProperties properties.CreateVM) (bool, error) {
-	vrrpPortCheck := cloudProperties.VRRPPortCheck
-	if vrrpPortCheck != nil && *vrrpPortCheck {
-		listOpts := ports.ListOpts{
-			FixedIPs: []ports.FixedIPOpts{{IPAddress: cloudProperties.AllowedAddressPairs}},
+func (c networkService) listSubnets(networkID string) ([]subnets.Subnet, error) {
+	listOpts := subnets.ListOpts{
+		NetworkID: networkID,
+	}
+
+	allPages, err := c.networkingFacade.ListSubnets(c.serviceClients.RetryableServiceClient, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list subnets: %w", err)
+	}
+
+	allSubnets, err := c.networkingFacade.ExtractSubnets(allPages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract subnets: %w", err)
+	}
+
+	return allSubnets, nil
+}
+
+func (c networkService) subnetContainsIP(subnet subnets.Subnet, ipAddress net.IP) (bool, error) {
+	_, ipNet, err := net.ParseCIDR(subnet.CIDR)
+	if ipNet == nil {
+		return false, fmt.Errorf("failed to parse subnet cidr '%s': %w", subnet.CIDR, err)
+	}
+
+	return ipNet.Contains(ipAddress), nil
+}
+
+// getFixedIPs returns the fixed IPs of a port. Without explicitly configured subnets, the subnet
+// is looked up by the IP assigned by BOSH. With 'subnet_ids', the BOSH IP is placed in the first
+// listed subnet containing it, every other subnet contributes an address allocated by Neutron.
+func (c networkService) getFixedIPs(network properties.Network) ([]ports.IP, error) {
+	cloudProps := network.CloudProps
+
+	if cloudProps.SubnetID != "" {
+		return []ports.IP{{SubnetID: cloudProps.SubnetID, IPAddress: network.IP}}, nil
+	}
+
+	if len(cloudProps.SubnetIDs) == 0 {
+		subnetID, err := c.GetSubnetID(cloudProps.NetID, network.IP)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get subnet: %w", err)
 		}
-		page, err := c.networkingFacade.ListPorts(c.serviceClients.RetryableServiceClient, listOpts)
+
+		return []ports.IP{{SubnetID: subnetID, IPAddress: network.IP}}, nil
+	}
+
+	ipAddress := net.ParseIP(network.IP)
+	if ipAddress == nil {
+		return nil, fmt.Errorf("failed to parse ip address '%s'", network.IP)
+	}
+
+	allSubnets, err := c.listSubnets(cloudProps.NetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get subnet: %w", err)
+	}
+
+	var fixedIPs []ports.IP
+	primaryAssigned := false
+	for _, subnetID := range cloudProps.SubnetIDs {
+		subnetIndex := slices.IndexFunc(allSubnets, func(subnet subnets.Subnet) bool {
+			return subnet.ID == subnetID
+		})
+		if subnetIndex < 0 {
+			return nil, fmt.Errorf("subnet '%s' not found in network '%s'", subnetID, cloudProps.NetID)
+		}
+
+		contained, err := c.subnetContainsIP(allSubnets[subnetIndex], ipAddress)
 		if err != nil {
-			return false, fmt.Errorf("failed to list VRRP ports: %w", err)
+			return nil, err
 		}
 
-		vrrpPorts, err := c.networkingFacade.ExtractPorts(page)
+		if contained && !primaryAssigned {
+			fixedIPs = append(fixedIPs, ports.IP{SubnetID: subnetID, IPAddress: network.IP})
+			primaryAssigned = true
+			continue
+		}
+
+		fixedIPs = append(fixedIPs, ports.IP{SubnetID: subnetID})
+	}
+
+	if !primaryAssigned {
+		return nil, fmt.Errorf("none of the subnets %v contains the ip '%s'", cloudProps.SubnetIDs, network.IP)
+	}
+
+	return fixedIPs, nil
+}
+
+// ensureVRRPPorts makes sure that the IPs of the allowed address pairs are reserved by a port,
+// so that Neutron does not hand them out to other ports. CIDR ranges cannot be reserved.
+func (c networkService) ensureVRRPPorts(network properties.Network, cloudProperties properties.CreateVM) error {
+	vrrpPortPolicy := cloudProperties.GetVRRPPortPolicy()
+	if vrrpPortPolicy == properties.VRRPPortPolicyIgnore {
+		return nil
+	}
+
+	for _, pair := range cloudProperties.AllowedAddressPairs {
+		if pair.IsCIDR() {
+			continue
+		}
+
+		vrrpPortExisting, err := c.isVRRPPortExisting(pair.IPAddress)
 		if err != nil {
-			return false, fmt.Errorf("failed to extract VRRP ports: %w", err)
+			return fmt.Errorf("VRRP port existence check failed: %w", err)
+		}
+
+		if vrrpPortExisting {
+			continue
 		}
 
-		if len(vrrpPorts) == 0 {
-			return false, nil
+		if vrrpPortPolicy != properties.VRRPPortPolicyCreate {
+			return fmt.Errorf("configured VRRP port with ip '%s' does not exist", pair.IPAddress)
+		}
+
+		err = c.createVRRPPort(network, pair.IPAddress)
+		if err != nil {
+			return fmt.Errorf("failed to create VRRP port with ip '%s': %w", pair.IPAddress, err)
 		}
 	}
-	return true, nil
+
+	return nil
+}
+
+func (c networkService) isVRRPPortExisting(ip string) (bool, error) {
+	listOpts := ports.ListOpts{
+		FixedIPs: []ports.FixedIPOpts{{IPAddress: ip}},
+	}
+	page, err := c.networkingFacade.ListPorts(c.serviceClients.RetryableServiceClient, listOpts)
+	if err != nil {
+		return false, fmt.Errorf("failed to list VRRP ports: %w", err)
+	}
+
+	vrrpPorts, err := c.networkingFacade.ExtractPorts(page)
+	if err != nil {
+		return false, fmt.Errorf("failed to extract VRRP ports: %w", err)
+	}
+
+	return len(vrrpPorts) > 0, nil
+}
+
+func (c networkService) createVRRPPort(network properties.Network, ip string) error {
+	ipAddress := net.ParseIP(ip)
+	if ipAddress == nil {
+		return fmt.Errorf("failed to parse ip address '%s'", ip)
+	}
+
+	allSubnets, err := c.listSubnets(network.CloudProps.NetID)
+	if err != nil {
+		return err
+	}
+
+	var subnetID string
+	for _, subnet := range allSubnets {
+		contained, err := c.subnetContainsIP(subnet, ipAddress)
+		if err != nil {
+			return err
+		}
+
+		if contained {
+			subnetID = subnet.ID
+			break
+		}
+	}
+
+	if subnetID == "" {
+		c.logger.Info("network-service", fmt.Sprintf("no subnet of network '%s' contains VRRP ip '%s', not creating VRRP port on this network", network.CloudProps.NetID, ip))
+		return nil
+	}
+
+	createOpts := ports.CreateOpts{
+		NetworkID:   network.CloudProps.NetID,
+		Name:        "vrrp-" + ip,
+		Description: "VRRP ip reservation created by the BOSH OpenStack CPI",
+		FixedIPs:    []ports.IP{{SubnetID: subnetID, IPAddress: ip}},
+	}
+
+	vrrpPort, err := c.networkingFacade.CreatePort(c.serviceClients.ServiceClient, createOpts)
+	if err != nil {
+		// another VM of the same cluster might have created the port concurrently
+		vrrpPortExisting, checkErr := c.isVRRPPortExisting(ip)
+		if checkErr == nil && vrrpPortExisting {
+			return nil
+		}
+
+		return err
+	}
+
+	_, err = c.networkingFacade.ReplaceAllTags(c.serviceClients.ServiceClient, "ports", vrrpPort.ID, VRRPPortTags)
+	if err != nil {
+		c.logger.Warn("network-service", fmt.Sprintf("failed to tag VRRP port '%s': %v", vrrpPort.ID, err))
+	}
+
+	c.logger.Info("network-service", fmt.Sprintf("created VRRP port '%s' with ip '%s' on network '%s'", vrrpPort.ID, ip, network.CloudProps.NetID))
+
+	return nil
 }
 
 func (c networkService) getFloatingIp(vipNetwork *properties.Network) (floatingips.FloatingIP, error) {
@@ -324,11 +1100,153 @@ func (c networkService) getFloatingIp(vipNetwork *properties.Network) (floatingi
 	return allFIPs[0], err
 }
 
-func (c networkService) associateFloatingIp(floatingIpId string, portId string) error {
+func (c networkService) allocateFloatingIp(instanceId string, networkConfig properties.NetworkConfig) (string, error) {
+	floatingNetworkID, err := c.resolveFloatingNetworkID(networkConfig.VIPNetwork.CloudProps)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve floating network: %w", err)
+	}
+
+	instancePort, err := c.getInstancePort(instanceId, networkConfig)
+	if err != nil {
+		return "", err
+	}
+
+	createOpts := floatingips.CreateOpts{
+		FloatingNetworkID: floatingNetworkID,
+		PortID:            instancePort.ID,
+		FixedIP:           networkConfig.VIPNetwork.CloudProps.TargetFixedIP,
+		Description:       fmt.Sprintf("Allocated by the BOSH OpenStack CPI for instance %s", instanceId),
+	}
+
+	floatingIp, err := c.networkingFacade.CreateFloatingIP(c.serviceClients.ServiceClient, createOpts)
+	if err != nil {
+		return "", fmt.Errorf("failed to allocate floating IP from network '%s': %w", floatingNetworkID, err)
+	}
+
+	_, err = c.networkingFacade.ReplaceAllTags(c.serviceClients.ServiceClient, "floatingips", floatingIp.ID, floatingIPTags(instanceId))
+	if err != nil {
+		deleteErr := c.networkingFacade.DeleteFloatingIP(c.serviceClients.RetryableServiceClient, floatingIp.ID)
+		if deleteErr != nil {
+			c.logger.Warn("network-service", fmt.Sprintf("failed to release untagged floating IP '%s': %v", floatingIp.FloatingIP, deleteErr))
+		}
+		return "", fmt.Errorf("failed to tag floating IP '%s': %w", floatingIp.FloatingIP, err)
+	}
+
+	c.logger.Info("network-service", fmt.Sprintf("Allocated floating IP '%s' for instance '%s'", floatingIp.FloatingIP, instanceId))
+	return floatingIp.FloatingIP, nil
+}
+
+func (c networkService) resolveFloatingNetworkID(cloudProps properties.NetworkCloudProps) (string, error) {
+	if cloudProps.FloatingNetworkID != "" {
+		return cloudProps.FloatingNetworkID, nil
+	}
+
+	return c.resolveNetworkID(cloudProps.FloatingNetworkName)
+}
+
+func (c networkService) resolveNetworkID(name string) (string, error) {
+	return NewNetworkResolver(c.serviceClients, c.networkingFacade, c.logger).Resolve(name, "")
+}
+
+// getInstancePort returns the port of the instance the floating IP is associated with. This is the port in the
+// default network, unless the vip network targets another network or a fixed IP of the instance.
+func (c networkService) getInstancePort(instanceId string, networkConfig properties.NetworkConfig) (ports.Port, error) {
+	vipCloudProps := networkConfig.VIPNetwork.CloudProps
+
+	targetNetwork := networkConfig.DefaultNetwork
+	if vipCloudProps.TargetNetID != "" {
+		targetNetwork = properties.Network{CloudProps: properties.NetworkCloudProps{NetID: vipCloudProps.TargetNetID}}
+	} else if vipCloudProps.TargetFixedIP != "" {
+		targetNetwork = properties.Network{}
+	}
+
+	instancePorts, err := c.GetPorts(instanceId, targetNetwork, false)
+	if err != nil {
+		return ports.Port{}, fmt.Errorf("failed to get port: %w", err)
+	}
+
+	if vipCloudProps.TargetFixedIP != "" {
+		instancePorts = slices.DeleteFunc(instancePorts, func(port ports.Port) bool {
+			return !slices.ContainsFunc(port.FixedIPs, func(fixedIP ports.IP) bool {
+				return fixedIP.IPAddress == vipCloudProps.TargetFixedIP
+			})
+		})
+		if len(instancePorts) == 0 {
+			return ports.Port{}, fmt.Errorf("no port allocated by instance %s with fixed ip %s", instanceId, vipCloudProps.TargetFixedIP)
+		}
+	}
+
+	if len(instancePorts) == 0 {
+		return ports.Port{}, fmt.Errorf("no port allocated by instance %s and network %s", instanceId, targetNetwork.CloudProps.NetID)
+	}
+
+	return instancePorts[0], nil
+}
+
+// floatingIPTags identify floating IPs allocated by the CPI, which are released when the instance is deleted.
+func floatingIPTags(instanceId string) []string {
+	return []string{FloatingIPAllocatedTag, "instance-id:" + instanceId}
+}
+
+// associateFloatingIp associates the floating IP with the port. A floating IP which is still bound to a port
+// of the same director, e.g. of the instance being replaced, is disassociated first.
+func (c networkService) associateFloatingIp(floatingIp floatingips.FloatingIP, port ports.Port, fixedIP string, identity properties.InstanceIdentity) error {
+	if floatingIp.PortID == port.ID && (fixedIP == "" || floatingIp.FixedIP == fixedIP) {
+		c.logger.Info("network-service", fmt.Sprintf("Floating IP '%s' is already associated with port '%s'", floatingIp.FloatingIP, port.ID))
+		return nil
+	}
+
+	if floatingIp.PortID != "" && floatingIp.PortID != port.ID {
+		err := c.takeOverFloatingIp(floatingIp, identity)
+		if err != nil {
+			return err
+		}
+	}
+
 	updateOpts := floatingips.UpdateOpts{
-		PortID: &portId,
+		PortID:  &port.ID,
+		FixedIP: fixedIP,
+	}
+
+	_, err := c.networkingFacade.UpdateFloatingIP(c.serviceClients.ServiceClient, floatingIp.ID, updateOpts)
+	return err
+}
+
+// takeOverFloatingIp disassociates the floating IP from the port it is bound to. Only ports tagged by the CPI for
+// the same director and untagged VM ports created before the CPI tagged its ports are taken over, ports of network
+// services or tagged for another director are refused.
+func (c networkService) takeOverFloatingIp(floatingIp floatingips.FloatingIP, identity properties.InstanceIdentity) error {
+	var errDefault404 gophercloud.ErrDefault404
+
+	boundPort, err := c.networkingFacade.GetPort(c.serviceClients.RetryableServiceClient, floatingIp.PortID)
+	if err != nil {
+		if errors.As(err, &errDefault404) {
+			return nil
+		}
+		return fmt.Errorf("failed to get port '%s' bound to floating IP '%s': %w", floatingIp.PortID, floatingIp.FloatingIP, err)
+	}
+
+	if !identity.Owns(boundPort.Tags) && !isLegacyVMPort(*boundPort) {
+		return fmt.Errorf("floating IP '%s' is bound to port '%s' with device owner '%s' which is not managed by BOSH", floatingIp.FloatingIP, boundPort.ID, boundPort.DeviceOwner)
 	}
 
-	_, err := c.networkingFacade.UpdateFloatingIP(c.serviceClients.ServiceClient, floatingIpId, updateOpts)
+	c.logger.Info("network-service", fmt.Sprintf("Disassociating floating IP '%s' from port '%s' of device '%s'", floatingIp.FloatingIP, boundPort.ID, boundPort.DeviceID))
+	err = c.disassociateFloatingIp(floatingIp.ID)
+	if err != nil && !errors.As(err, &errDefault404) {
+		return fmt.Errorf("failed to disassociate floating IP '%s' from port '%s': %w", floatingIp.FloatingIP, boundPort.ID, err)
+	}
+
+	return nil
+}
+
+// isLegacyVMPort reports whether the port is attached to a VM and has not been tagged by the CPI, as ports created
+// by earlier CPI versions.
+func isLegacyVMPort(port ports.Port) bool {
+	return !slices.Contains(port.Tags, properties.BoshTag) && strings.HasPrefix(port.DeviceOwner, "compute:")
+}
+
+func (c networkService) disassociateFloatingIp(floatingIpId string) error {
+	noPort := ""
+	_, err := c.networkingFacade.UpdateFloatingIP(c.serviceClients.ServiceClient, floatingIpId, floatingips.UpdateOpts{PortID: &noPort})
 	return err
 }
@@ -2,7 +2,9 @@ package network_test
 
 import (
 	"errors"
+	"time"
 
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/config"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/mocks"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/network"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/network/networkfakes"
@@ -10,7 +12,11 @@ import (
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils/utilsfakes"
 	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/extradhcpopts"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/floatingips"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/mtu"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/qos/policies"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/networks"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/subnets"
 	. "github.com/onsi/ginkgo/v2"
@@ -24,6 +30,7 @@ var _ = Describe("NetworkService", func() {
 	var utilsRetryableServiceClient utils.RetryableServiceClient
 	var defaultNetwork properties.Network
 	var networkConfig properties.NetworkConfig
+	var identity properties.InstanceIdentity
 	var networkingFacade networkfakes.FakeNetworkingFacade
 	var logger utilsfakes.FakeLogger
 	var floatingIpPage mocks.MockPage
@@ -60,11 +67,12 @@ var _ = Describe("NetworkService", func() {
 			VIPNetwork:     &properties.Network{IP: "3.3.3.3"},
 			SecurityGroups: []string{"sec-id1", "sec-id2"},
 		}
+		identity = properties.InstanceIdentity{Director: "the-director"}
 	})
 
 	Context("ConfigureVIPNetwork", func() {
 		It("lists floating ips", func() {
-			_ = network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig) //nolint:errcheck
+			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity) //nolint:errcheck
 
 			_, listOpts := networkingFacade.ListFloatingIpsArgsForCall(0)
 			Expect(listOpts.FloatingIP).To(Equal("3.3.3.3"))
@@ -73,12 +81,12 @@ var _ = Describe("NetworkService", func() {
 		It("returns an error if floating ips cannot be fetched from openstack", func() {
 			networkingFacade.ListFloatingIpsReturns(nil, errors.New("boom"))
 
-			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig)
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
 			Expect(err.Error()).To(Equal("failed to get floating IP: failed to list floating IPs: boom"))
 		})
 
 		It("extracts floating ips", func() {
-			_ = network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig) //nolint:errcheck
+			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity) //nolint:errcheck
 
 			pages := networkingFacade.ExtractFloatingIPsArgsForCall(0)
 			Expect(pages).To(Equal(floatingIpPage))
@@ -87,19 +95,19 @@ var _ = Describe("NetworkService", func() {
 		It("returns an error if floating ips cannot be extracted from pages", func() {
 			networkingFacade.ExtractFloatingIPsReturns(nil, errors.New("boom"))
 
-			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig)
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
 			Expect(err.Error()).To(Equal("failed to get floating IP: failed to extract floating IPs: boom"))
 		})
 
 		It("returns an error if floating ips are empty", func() {
 			networkingFacade.ExtractFloatingIPsReturns([]floatingips.FloatingIP{}, nil)
 
-			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig)
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
 			Expect(err.Error()).To(Equal("failed to get floating IP: floating IP 3.3.3.3 not allocated"))
 		})
 
 		It("gets ports", func() {
-			_ = network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig) //nolint:errcheck
+			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity) //nolint:errcheck
 
 			serviceClient, listOpts := networkingFacade.ListPortsArgsForCall(0)
 			Expect(listOpts.DeviceID).To(Equal("123-456"))
@@ -111,19 +119,19 @@ var _ = Describe("NetworkService", func() {
 		It("returns an error if getting ports failed", func() {
 			networkingFacade.ListPortsReturns(nil, errors.New("boom"))
 
-			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig)
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
 			Expect(err.Error()).To(Equal("failed to get port: failed to list ports: boom"))
 		})
 
 		It("returns an error if no ports are allocated", func() {
 			networkingFacade.ExtractPortsReturns([]ports.Port{}, nil)
 
-			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig)
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
 			Expect(err.Error()).To(Equal("no port allocated by instance 123-456 and network the_net_id_1"))
 		})
 
 		It("associates the floating ip to a port", func() {
-			_ = network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig) //nolint:errcheck
+			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity) //nolint:errcheck
 
 			_, floatingIpId, updateOpts := networkingFacade.UpdateFloatingIPArgsForCall(0)
 			Expect(floatingIpId).To(Equal("the_floating_ip_id"))
@@ -133,9 +141,381 @@ var _ = Describe("NetworkService", func() {
 		It("returns an error if port association fails", func() {
 			networkingFacade.UpdateFloatingIPReturns(nil, errors.New("boom"))
 
-			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig)
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
 			Expect(err.Error()).To(Equal("failed to associate floating ip to port: boom"))
 		})
+
+		It("does not associate the floating ip if it is already associated to the port", func() {
+			networkingFacade.ExtractFloatingIPsReturns([]floatingips.FloatingIP{{ID: "the_floating_ip_id", PortID: "5678"}}, nil)
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingFacade.UpdateFloatingIPCallCount()).To(Equal(0))
+		})
+
+		It("disassociates the floating ip from the port of another instance before associating it", func() {
+			networkingFacade.ExtractFloatingIPsReturns([]floatingips.FloatingIP{{ID: "the_floating_ip_id", PortID: "old-port"}}, nil)
+			networkingFacade.GetPortReturns(&ports.Port{
+				ID: "old-port", DeviceOwner: "compute:z1", DeviceID: "old-vm", Tags: []string{"bosh", "director:the-director"},
+			}, nil)
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
+
+			Expect(err).ToNot(HaveOccurred())
+			_, portID := networkingFacade.GetPortArgsForCall(0)
+			Expect(portID).To(Equal("old-port"))
+			Expect(networkingFacade.UpdateFloatingIPCallCount()).To(Equal(2))
+			_, _, disassociateOpts := networkingFacade.UpdateFloatingIPArgsForCall(0)
+			Expect(*disassociateOpts.PortID).To(BeEmpty())
+			_, _, associateOpts := networkingFacade.UpdateFloatingIPArgsForCall(1)
+			Expect(*associateOpts.PortID).To(Equal("5678"))
+		})
+
+		It("associates the floating ip if the previously bound port no longer exists", func() {
+			networkingFacade.ExtractFloatingIPsReturns([]floatingips.FloatingIP{{ID: "the_floating_ip_id", PortID: "old-port"}}, nil)
+			networkingFacade.GetPortReturns(nil, gophercloud.ErrDefault404{})
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingFacade.UpdateFloatingIPCallCount()).To(Equal(1))
+		})
+
+		It("returns an error if the floating ip is bound to a port not managed by BOSH", func() {
+			networkingFacade.ExtractFloatingIPsReturns([]floatingips.FloatingIP{{ID: "the_floating_ip_id", FloatingIP: "3.3.3.3", PortID: "lb-port"}}, nil)
+			networkingFacade.GetPortReturns(&ports.Port{ID: "lb-port", DeviceOwner: "Octavia"}, nil)
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
+
+			Expect(err.Error()).To(Equal("failed to associate floating ip to port: floating IP '3.3.3.3' is bound to port 'lb-port' with device owner 'Octavia' which is not managed by BOSH"))
+			Expect(networkingFacade.UpdateFloatingIPCallCount()).To(Equal(0))
+		})
+
+		It("disassociates the floating ip from an untagged legacy port of another instance before associating it", func() {
+			networkingFacade.ExtractFloatingIPsReturns([]floatingips.FloatingIP{{ID: "the_floating_ip_id", FloatingIP: "3.3.3.3", PortID: "legacy-port"}}, nil)
+			networkingFacade.GetPortReturns(&ports.Port{ID: "legacy-port", DeviceOwner: "compute:nova", DeviceID: "old-vm"}, nil)
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingFacade.UpdateFloatingIPCallCount()).To(Equal(2))
+			_, _, disassociateOpts := networkingFacade.UpdateFloatingIPArgsForCall(0)
+			Expect(*disassociateOpts.PortID).To(BeEmpty())
+			_, _, associateOpts := networkingFacade.UpdateFloatingIPArgsForCall(1)
+			Expect(*associateOpts.PortID).To(Equal("5678"))
+		})
+
+		It("returns an error if the floating ip is bound to an untagged port not attached to a VM", func() {
+			networkingFacade.ExtractFloatingIPsReturns([]floatingips.FloatingIP{{ID: "the_floating_ip_id", FloatingIP: "3.3.3.3", PortID: "router-port"}}, nil)
+			networkingFacade.GetPortReturns(&ports.Port{ID: "router-port", DeviceOwner: "network:router_interface", DeviceID: "the-router"}, nil)
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
+
+			Expect(err.Error()).To(Equal("failed to associate floating ip to port: floating IP '3.3.3.3' is bound to port 'router-port' with device owner 'network:router_interface' which is not managed by BOSH"))
+			Expect(networkingFacade.UpdateFloatingIPCallCount()).To(Equal(0))
+		})
+
+		It("returns an error if the floating ip is bound to a VM of another director", func() {
+			networkingFacade.ExtractFloatingIPsReturns([]floatingips.FloatingIP{{ID: "the_floating_ip_id", FloatingIP: "3.3.3.3", PortID: "other-port"}}, nil)
+			networkingFacade.GetPortReturns(&ports.Port{
+				ID: "other-port", DeviceOwner: "compute:z1", DeviceID: "other-vm", Tags: []string{"bosh", "director:other-director"},
+			}, nil)
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
+
+			Expect(err).To(HaveOccurred())
+			Expect(networkingFacade.UpdateFloatingIPCallCount()).To(Equal(0))
+		})
+
+		It("associates the floating ip to the port in the target network", func() {
+			networkConfig.VIPNetwork.CloudProps = properties.NetworkCloudProps{TargetNetID: "the-target-net-id"}
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
+
+			Expect(err).ToNot(HaveOccurred())
+			_, listOpts := networkingFacade.ListPortsArgsForCall(0)
+			Expect(listOpts.NetworkID).To(Equal("the-target-net-id"))
+		})
+
+		It("associates the floating ip to the port with the target fixed ip", func() {
+			networkConfig.VIPNetwork.CloudProps = properties.NetworkCloudProps{TargetFixedIP: "2.2.2.2"}
+			networkingFacade.ExtractPortsReturns([]ports.Port{
+				{ID: "port-1", FixedIPs: []ports.IP{{IPAddress: "1.1.1.1"}}},
+				{ID: "port-2", FixedIPs: []ports.IP{{IPAddress: "2.2.2.2"}}},
+			}, nil)
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
+
+			Expect(err).ToNot(HaveOccurred())
+			_, listOpts := networkingFacade.ListPortsArgsForCall(0)
+			Expect(listOpts.NetworkID).To(BeEmpty())
+			_, _, updateOpts := networkingFacade.UpdateFloatingIPArgsForCall(0)
+			Expect(*updateOpts.PortID).To(Equal("port-2"))
+			Expect(updateOpts.FixedIP).To(Equal("2.2.2.2"))
+		})
+
+		It("returns an error if no port has the target fixed ip", func() {
+			networkConfig.VIPNetwork.CloudProps = properties.NetworkCloudProps{TargetFixedIP: "9.9.9.9"}
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
+
+			Expect(err.Error()).To(Equal("no port allocated by instance 123-456 with fixed ip 9.9.9.9"))
+		})
+
+		It("returns the floating ip", func() {
+			floatingIP, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(floatingIP).To(Equal("3.3.3.3"))
+		})
+
+		It("returns no floating ip if no vip network is configured", func() {
+			networkConfig.VIPNetwork = nil
+
+			floatingIP, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(floatingIP).To(BeEmpty())
+			Expect(networkingFacade.ListFloatingIpsCallCount()).To(Equal(0))
+		})
+
+		Context("when a floating network is configured", func() {
+			BeforeEach(func() {
+				networkConfig.VIPNetwork = &properties.Network{
+					CloudProps: properties.NetworkCloudProps{FloatingNetworkID: "the-floating-net-id"},
+				}
+				networkingFacade.CreateFloatingIPReturns(&floatingips.FloatingIP{ID: "the-new-fip-id", FloatingIP: "4.4.4.4"}, nil)
+			})
+
+			It("allocates a floating ip for the port of the instance", func() {
+				floatingIP, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(floatingIP).To(Equal("4.4.4.4"))
+				Expect(networkingFacade.ListFloatingIpsCallCount()).To(Equal(0))
+				Expect(networkingFacade.UpdateFloatingIPCallCount()).To(Equal(0))
+
+				_, createOpts := networkingFacade.CreateFloatingIPArgsForCall(0)
+				Expect(createOpts.FloatingNetworkID).To(Equal("the-floating-net-id"))
+				Expect(createOpts.PortID).To(Equal("5678"))
+			})
+
+			It("tags the allocated floating ip with the instance id", func() {
+				_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity) //nolint:errcheck
+
+				_, resourceType, resourceID, tags := networkingFacade.ReplaceAllTagsArgsForCall(0)
+				Expect(resourceType).To(Equal("floatingips"))
+				Expect(resourceID).To(Equal("the-new-fip-id"))
+				Expect(tags).To(Equal([]string{network.FloatingIPAllocatedTag, "instance-id:123-456"}))
+			})
+
+			It("resolves the floating network by name", func() {
+				networkConfig.VIPNetwork.CloudProps = properties.NetworkCloudProps{FloatingNetworkName: "public"}
+				networkingFacade.ExtractNetworksReturns([]networks.Network{{ID: "the-public-net-id"}}, nil)
+
+				_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
+
+				Expect(err).ToNot(HaveOccurred())
+				_, listOpts := networkingFacade.ListNetworksArgsForCall(0)
+				Expect(listOpts.Name).To(Equal("public"))
+				_, createOpts := networkingFacade.CreateFloatingIPArgsForCall(0)
+				Expect(createOpts.FloatingNetworkID).To(Equal("the-public-net-id"))
+			})
+
+			It("returns an error if the floating network name is ambiguous", func() {
+				networkConfig.VIPNetwork.CloudProps = properties.NetworkCloudProps{FloatingNetworkName: "public"}
+				networkingFacade.ExtractNetworksReturns([]networks.Network{{ID: "net-1"}, {ID: "net-2"}}, nil)
+
+				_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
+
+				Expect(err.Error()).To(Equal("failed to resolve floating network: network name 'public' is ambiguous, found 2 networks [net-1 net-2]"))
+			})
+
+			It("returns an error if the floating network name cannot be found", func() {
+				networkConfig.VIPNetwork.CloudProps = properties.NetworkCloudProps{FloatingNetworkName: "public"}
+				networkingFacade.ExtractNetworksReturns([]networks.Network{}, nil)
+
+				_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
+
+				Expect(err.Error()).To(Equal("failed to resolve floating network: network 'public' not found"))
+			})
+
+			It("returns an error if the floating ip cannot be allocated", func() {
+				networkingFacade.CreateFloatingIPReturns(nil, errors.New("boom"))
+
+				_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
+
+				Expect(err.Error()).To(Equal("failed to allocate floating IP from network 'the-floating-net-id': boom"))
+			})
+
+			It("releases the floating ip and returns an error if tagging fails", func() {
+				networkingFacade.ReplaceAllTagsReturns(nil, errors.New("boom"))
+
+				_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ConfigureVIPNetwork("123-456", networkConfig, identity)
+
+				Expect(err.Error()).To(Equal("failed to tag floating IP '4.4.4.4': boom"))
+				_, floatingIpId := networkingFacade.DeleteFloatingIPArgsForCall(0)
+				Expect(floatingIpId).To(Equal("the-new-fip-id"))
+			})
+		})
+	})
+
+	Context("DisassociateFloatingIPs", func() {
+		It("disassociates the floating ips of each port", func() {
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).DisassociateFloatingIPs([]ports.Port{{ID: "port-1"}, {ID: "port-2"}})
+
+			Expect(err).ToNot(HaveOccurred())
+			_, listOpts := networkingFacade.ListFloatingIpsArgsForCall(1)
+			Expect(listOpts.PortID).To(Equal("port-2"))
+			Expect(networkingFacade.UpdateFloatingIPCallCount()).To(Equal(2))
+			_, floatingIpId, updateOpts := networkingFacade.UpdateFloatingIPArgsForCall(0)
+			Expect(floatingIpId).To(Equal("the_floating_ip_id"))
+			Expect(*updateOpts.PortID).To(BeEmpty())
+		})
+
+		It("ignores floating ips which are already deleted", func() {
+			networkingFacade.UpdateFloatingIPReturns(nil, gophercloud.ErrDefault404{})
+
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).DisassociateFloatingIPs([]ports.Port{{ID: "port-1"}})
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns an error if listing floating ips fails", func() {
+			networkingFacade.ListFloatingIpsReturns(nil, errors.New("boom"))
+
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).DisassociateFloatingIPs([]ports.Port{{ID: "port-1"}})
+
+			Expect(err.Error()).To(Equal("failed to list floating IPs: boom"))
+		})
+
+		It("returns an error if disassociating a floating ip fails", func() {
+			networkingFacade.ExtractFloatingIPsReturns([]floatingips.FloatingIP{{ID: "the_floating_ip_id", FloatingIP: "3.3.3.3"}}, nil)
+			networkingFacade.UpdateFloatingIPReturns(nil, errors.New("boom"))
+
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).DisassociateFloatingIPs([]ports.Port{{ID: "port-1"}})
+
+			Expect(err.Error()).To(Equal("failed to disassociate floating IP '3.3.3.3' from port 'port-1': boom"))
+		})
+	})
+
+	Context("SetMetadataTags", func() {
+		var metadata map[string]string
+
+		BeforeEach(func() {
+			metadata = map[string]string{"deployment": "the-deployment", "name": "the-job/the-id"}
+		})
+
+		It("tags the ports and their floating ips with the metadata", func() {
+			networkingFacade.ExtractFloatingIPsReturns([]floatingips.FloatingIP{{ID: "the_floating_ip_id", Tags: []string{network.FloatingIPAllocatedTag}}}, nil)
+
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				SetMetadataTags([]ports.Port{{ID: "port-1", Tags: []string{"bosh", "deployment:the-deployment"}}}, metadata)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingFacade.ReplaceAllTagsCallCount()).To(Equal(2))
+			_, resourceType, resourceID, tags := networkingFacade.ReplaceAllTagsArgsForCall(0)
+			Expect(resourceType).To(Equal("ports"))
+			Expect(resourceID).To(Equal("port-1"))
+			Expect(tags).To(Equal([]string{"bosh", "deployment:the-deployment", "name:the-job/the-id"}))
+			_, listOpts := networkingFacade.ListFloatingIpsArgsForCall(0)
+			Expect(listOpts.PortID).To(Equal("port-1"))
+			_, resourceType, resourceID, tags = networkingFacade.ReplaceAllTagsArgsForCall(1)
+			Expect(resourceType).To(Equal("floatingips"))
+			Expect(resourceID).To(Equal("the_floating_ip_id"))
+			Expect(tags).To(Equal([]string{network.FloatingIPAllocatedTag, "deployment:the-deployment", "name:the-job/the-id"}))
+		})
+
+		It("does not tag resources which are already tagged with the metadata", func() {
+			networkingFacade.ExtractFloatingIPsReturns([]floatingips.FloatingIP{{ID: "the_floating_ip_id", Tags: []string{"name:the-job/the-id", "deployment:the-deployment"}}}, nil)
+
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				SetMetadataTags([]ports.Port{{ID: "port-1", Tags: []string{"deployment:the-deployment", "bosh", "name:the-job/the-id"}}}, metadata)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingFacade.ReplaceAllTagsCallCount()).To(Equal(0))
+		})
+
+		It("logs a warning and tags the floating ips if tagging a port fails", func() {
+			networkingFacade.ExtractFloatingIPsReturns([]floatingips.FloatingIP{{ID: "the_floating_ip_id", FloatingIP: "3.3.3.3"}}, nil)
+			networkingFacade.ReplaceAllTagsReturnsOnCall(0, nil, errors.New("boom"))
+
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				SetMetadataTags([]ports.Port{{ID: "port-1"}}, metadata)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingFacade.ReplaceAllTagsCallCount()).To(Equal(2))
+			_, message, _ := logger.WarnArgsForCall(0)
+			Expect(message).To(Equal("failed to tag port 'port-1': boom"))
+		})
+
+		It("logs a warning if tagging a floating ip fails", func() {
+			networkingFacade.ExtractFloatingIPsReturns([]floatingips.FloatingIP{{ID: "the_floating_ip_id", FloatingIP: "3.3.3.3"}}, nil)
+			networkingFacade.ReplaceAllTagsReturnsOnCall(1, nil, errors.New("boom"))
+
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				SetMetadataTags([]ports.Port{{ID: "port-1"}}, metadata)
+
+			Expect(err).ToNot(HaveOccurred())
+			_, message, _ := logger.WarnArgsForCall(0)
+			Expect(message).To(Equal("failed to tag floating IP '3.3.3.3': boom"))
+		})
+
+		It("returns an error if listing floating ips fails", func() {
+			networkingFacade.ListFloatingIpsReturns(nil, errors.New("boom"))
+
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				SetMetadataTags([]ports.Port{{ID: "port-1"}}, metadata)
+
+			Expect(err.Error()).To(Equal("failed to list floating IPs: boom"))
+		})
+	})
+
+	Context("ReleaseFloatingIPs", func() {
+		It("deletes the floating ips allocated for the instance", func() {
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ReleaseFloatingIPs("123-456")
+
+			Expect(err).ToNot(HaveOccurred())
+			_, listOpts := networkingFacade.ListFloatingIpsArgsForCall(0)
+			Expect(listOpts.Tags).To(Equal(network.FloatingIPAllocatedTag + ",instance-id:123-456"))
+			_, floatingIpId := networkingFacade.DeleteFloatingIPArgsForCall(0)
+			Expect(floatingIpId).To(Equal("the_floating_ip_id"))
+		})
+
+		It("does not delete floating ips if none were allocated", func() {
+			networkingFacade.ExtractFloatingIPsReturns([]floatingips.FloatingIP{}, nil)
+
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ReleaseFloatingIPs("123-456")
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingFacade.DeleteFloatingIPCallCount()).To(Equal(0))
+		})
+
+		It("ignores floating ips which are already deleted", func() {
+			networkingFacade.DeleteFloatingIPReturns(gophercloud.ErrDefault404{})
+
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ReleaseFloatingIPs("123-456")
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns an error if listing floating ips fails", func() {
+			networkingFacade.ListFloatingIpsReturns(nil, errors.New("boom"))
+
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ReleaseFloatingIPs("123-456")
+
+			Expect(err.Error()).To(Equal("failed to list floating IPs: boom"))
+		})
+
+		It("returns an error if deleting a floating ip fails", func() {
+			networkingFacade.ExtractFloatingIPsReturns([]floatingips.FloatingIP{{ID: "the_floating_ip_id", FloatingIP: "3.3.3.3"}}, nil)
+			networkingFacade.DeleteFloatingIPReturns(errors.New("boom"))
+
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).ReleaseFloatingIPs("123-456")
+
+			Expect(err.Error()).To(Equal("failed to release floating IP '3.3.3.3': boom"))
+		})
 	})
 
 	Context("GetSubnetID", func() {
@@ -232,8 +612,12 @@ var _ = Describe("NetworkService", func() {
 		var cloudProperties properties.CreateVM
 		var createdPort ports.Port
 		var securityGroups []string
+		var identity properties.InstanceIdentity
+		var openstackConfig config.OpenstackConfig
 
 		BeforeEach(func() {
+			identity = properties.InstanceIdentity{Director: "the-director", Deployment: "the-deployment", InstanceGroup: "the-instance-group", AgentID: "the-agent-id"}
+			openstackConfig = config.OpenstackConfig{}
 			createdPort = ports.Port{ID: "the-port-id"}
 			networkingFacade.CreatePortReturns(&createdPort, nil)
 			networkingFacade.ExtractPortsReturns([]ports.Port{createdPort}, nil)
@@ -241,35 +625,35 @@ var _ = Describe("NetworkService", func() {
 
 			vrrpPortCheck := true
 			cloudProperties = properties.CreateVM{
-				AllowedAddressPairs: "allowed-address-pairs",
+				AllowedAddressPairs: properties.AllowedAddressPairs{{IPAddress: "allowed-address-pairs"}},
 				VRRPPortCheck:       &vrrpPortCheck,
 			}
 		})
 
 		It("lists VRRP ports if the port check is enabled", func() {
 			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger). //nolint:errcheck
-													CreatePort(defaultNetwork, securityGroups, cloudProperties)
+													CreatePort(defaultNetwork, securityGroups, cloudProperties, identity, openstackConfig)
 
 			Expect(networkingFacade.ListPortsCallCount()).To(Equal(1))
 		})
 
 		It("skips listing VRRP ports if the port check is not defined", func() {
 			cloudProperties := properties.CreateVM{
-				AllowedAddressPairs: "allowed-address-pairs",
+				AllowedAddressPairs: properties.AllowedAddressPairs{{IPAddress: "allowed-address-pairs"}},
 			}
 			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger). //nolint:errcheck
-													CreatePort(defaultNetwork, securityGroups, cloudProperties)
+													CreatePort(defaultNetwork, securityGroups, cloudProperties, identity, openstackConfig)
 
 			Expect(networkingFacade.ListPortsCallCount()).To(Equal(0))
 		})
 
 		It("skips listing VRRP ports if the port check is false", func() {
 			cloudProperties := properties.CreateVM{
-				AllowedAddressPairs: "allowed-address-pairs",
+				AllowedAddressPairs: properties.AllowedAddressPairs{{IPAddress: "allowed-address-pairs"}},
 				VRRPPortCheck:       new(bool),
 			}
 			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger). //nolint:errcheck
-													CreatePort(defaultNetwork, securityGroups, cloudProperties)
+													CreatePort(defaultNetwork, securityGroups, cloudProperties, identity, openstackConfig)
 
 			Expect(networkingFacade.ListPortsCallCount()).To(Equal(0))
 		})
@@ -278,7 +662,7 @@ var _ = Describe("NetworkService", func() {
 			networkingFacade.ListPortsReturns(nil, errors.New("boom"))
 
 			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
-				CreatePort(defaultNetwork, securityGroups, cloudProperties)
+				CreatePort(defaultNetwork, securityGroups, cloudProperties, identity, openstackConfig)
 
 			Expect(err.Error()).To(Equal("failed create network opts: VRRP port existence check failed: " +
 				"failed to list VRRP ports: boom"))
@@ -286,7 +670,7 @@ var _ = Describe("NetworkService", func() {
 
 		It("extracts VRRP ports if the port check is enabled", func() {
 			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger). //nolint:errcheck
-													CreatePort(defaultNetwork, securityGroups, cloudProperties)
+													CreatePort(defaultNetwork, securityGroups, cloudProperties, identity, openstackConfig)
 
 			Expect(networkingFacade.ExtractPortsCallCount()).To(Equal(1))
 		})
@@ -295,7 +679,7 @@ var _ = Describe("NetworkService", func() {
 			networkingFacade.ExtractPortsReturns(nil, errors.New("boom"))
 
 			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
-				CreatePort(defaultNetwork, securityGroups, cloudProperties)
+				CreatePort(defaultNetwork, securityGroups, cloudProperties, identity, openstackConfig)
 
 			Expect(err.Error()).To(Equal("failed create network opts: VRRP port existence check failed: " +
 				"failed to extract VRRP ports: boom"))
@@ -305,7 +689,7 @@ var _ = Describe("NetworkService", func() {
 			networkingFacade.ExtractPortsReturns([]ports.Port{}, nil)
 
 			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
-				CreatePort(defaultNetwork, securityGroups, cloudProperties)
+				CreatePort(defaultNetwork, securityGroups, cloudProperties, identity, openstackConfig)
 
 			Expect(err.Error()).To(Equal("failed create network opts: " +
 				"configured VRRP port with ip 'allowed-address-pairs' does not exist"))
@@ -317,9 +701,10 @@ var _ = Describe("NetworkService", func() {
 			}
 
 			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger). //nolint:errcheck
-													CreatePort(defaultNetwork, securityGroups, cloudProperties)
+													CreatePort(defaultNetwork, securityGroups, cloudProperties, identity, openstackConfig)
 
-			_, createOpts := networkingFacade.CreatePortArgsForCall(0)
+			_, createOptsBuilder := networkingFacade.CreatePortArgsForCall(0)
+			createOpts := createOptsBuilder.(ports.CreateOpts)
 
 			Expect(createOpts.NetworkID).To(ContainSubstring("the_net_id_1"))
 			Expect(createOpts.FixedIPs.([]ports.IP)[0].SubnetID).To(Equal("the-subnet-id-1"))
@@ -334,20 +719,225 @@ var _ = Describe("NetworkService", func() {
 
 		It("creates the port with VRRP port", func() {
 			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger). //nolint:errcheck
-													CreatePort(defaultNetwork, securityGroups, cloudProperties)
+													CreatePort(defaultNetwork, securityGroups, cloudProperties, identity, openstackConfig)
 
-			_, createOpts := networkingFacade.CreatePortArgsForCall(0)
+			_, createOptsBuilder := networkingFacade.CreatePortArgsForCall(0)
+			createOpts := createOptsBuilder.(ports.CreateOpts)
 
 			Expect(createOpts.NetworkID).To(ContainSubstring("the_net_id_1"))
 			Expect(createOpts.FixedIPs.([]ports.IP)[0].IPAddress).To(Equal("1.1.1.1"))
 			Expect(createOpts.AllowedAddressPairs[0].IPAddress).To(Equal("allowed-address-pairs"))
 		})
 
+		It("creates the port with multiple allowed address pairs", func() {
+			cloudProperties = properties.CreateVM{
+				AllowedAddressPairs: properties.AllowedAddressPairs{
+					{IPAddress: "1.1.1.10"},
+					{IPAddress: "1.1.2.0/24", MACAddress: "fa:16:3e:00:00:01"},
+				},
+			}
+
+			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger). //nolint:errcheck
+													CreatePort(defaultNetwork, securityGroups, cloudProperties, identity, openstackConfig)
+
+			_, createOptsBuilder := networkingFacade.CreatePortArgsForCall(0)
+			createOpts := createOptsBuilder.(ports.CreateOpts)
+
+			Expect(createOpts.AllowedAddressPairs).To(Equal([]ports.AddressPair{
+				{IPAddress: "1.1.1.10"},
+				{IPAddress: "1.1.2.0/24", MACAddress: "fa:16:3e:00:00:01"},
+			}))
+		})
+
+		It("does not check VRRP ports of cidr ranges", func() {
+			cloudProperties = properties.CreateVM{
+				AllowedAddressPairs: properties.AllowedAddressPairs{{IPAddress: "1.1.2.0/24"}},
+				VRRPPortPolicy:      properties.VRRPPortPolicyCheck,
+			}
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				CreatePort(defaultNetwork, securityGroups, cloudProperties, identity, openstackConfig)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingFacade.ListPortsCallCount()).To(Equal(0))
+		})
+
+		Context("with the create VRRP port policy", func() {
+			var vrrpPort ports.Port
+
+			BeforeEach(func() {
+				cloudProperties = properties.CreateVM{
+					AllowedAddressPairs: properties.AllowedAddressPairs{{IPAddress: "1.1.1.10"}},
+					VRRPPortPolicy:      properties.VRRPPortPolicyCreate,
+				}
+				vrrpPort = ports.Port{ID: "the-vrrp-port-id"}
+				networkingFacade.ExtractPortsReturns([]ports.Port{}, nil)
+				networkingFacade.CreatePortReturnsOnCall(0, &vrrpPort, nil)
+			})
+
+			It("creates and tags the missing VRRP port", func() {
+				port, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+					CreatePort(defaultNetwork, securityGroups, cloudProperties, identity, openstackConfig)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(port.ID).To(Equal("the-port-id"))
+				Expect(networkingFacade.CreatePortCallCount()).To(Equal(2))
+
+				_, createOptsBuilder := networkingFacade.CreatePortArgsForCall(0)
+				createOpts := createOptsBuilder.(ports.CreateOpts)
+				Expect(createOpts.NetworkID).To(Equal("the_net_id_1"))
+				Expect(createOpts.Name).To(Equal("vrrp-1.1.1.10"))
+				Expect(createOpts.FixedIPs).To(Equal([]ports.IP{{SubnetID: "the-subnet-id-1", IPAddress: "1.1.1.10"}}))
+
+				Expect(networkingFacade.ReplaceAllTagsCallCount()).To(Equal(2))
+				_, resourceType, resourceID, tags := networkingFacade.ReplaceAllTagsArgsForCall(0)
+				Expect(resourceType).To(Equal("ports"))
+				Expect(resourceID).To(Equal("the-vrrp-port-id"))
+				Expect(tags).To(Equal(network.VRRPPortTags))
+			})
+
+			It("does not create the VRRP port if it exists", func() {
+				networkingFacade.ExtractPortsReturns([]ports.Port{{ID: "the-vrrp-port-id"}}, nil)
+
+				_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+					CreatePort(defaultNetwork, securityGroups, cloudProperties, identity, openstackConfig)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(networkingFacade.CreatePortCallCount()).To(Equal(1))
+				Expect(networkingFacade.ReplaceAllTagsCallCount()).To(Equal(1))
+			})
+
+			It("does not create the VRRP port if no subnet of the network contains its ip", func() {
+				cloudProperties.AllowedAddressPairs = properties.AllowedAddressPairs{{IPAddress: "9.9.9.9"}}
+
+				_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+					CreatePort(defaultNetwork, securityGroups, cloudProperties, identity, openstackConfig)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(networkingFacade.CreatePortCallCount()).To(Equal(1))
+			})
+
+			It("accepts a VRRP port which was created concurrently", func() {
+				networkingFacade.CreatePortReturnsOnCall(0, nil, errors.New("ip address already allocated"))
+				networkingFacade.CreatePortReturnsOnCall(1, &createdPort, nil)
+				networkingFacade.ExtractPortsReturnsOnCall(1, []ports.Port{{ID: "the-other-vrrp-port-id"}}, nil)
+
+				_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+					CreatePort(defaultNetwork, securityGroups, cloudProperties, identity, openstackConfig)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(networkingFacade.ReplaceAllTagsCallCount()).To(Equal(1))
+				_, _, resourceID, _ := networkingFacade.ReplaceAllTagsArgsForCall(0)
+				Expect(resourceID).To(Equal("the-port-id"))
+			})
+
+			It("returns an error if the VRRP port creation fails", func() {
+				networkingFacade.CreatePortReturnsOnCall(0, nil, errors.New("boom"))
+
+				_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+					CreatePort(defaultNetwork, securityGroups, cloudProperties, identity, openstackConfig)
+
+				Expect(err.Error()).To(Equal("failed create network opts: failed to create VRRP port with ip '1.1.1.10': boom"))
+			})
+		})
+
+		It("creates the port with advanced port properties", func() {
+			portSecurityEnabled := true
+			defaultNetwork.CloudProps.VNICType = "direct"
+			defaultNetwork.CloudProps.PortSecurityEnabled = &portSecurityEnabled
+			defaultNetwork.CloudProps.QoSPolicyID = "the-qos-policy-id"
+			defaultNetwork.CloudProps.BindingProfile = map[string]interface{}{"capabilities": []string{"switchdev"}}
+			defaultNetwork.CloudProps.ExtraDHCPOpts = []properties.ExtraDHCPOpt{{OptName: "mtu", OptValue: "9000", IPVersion: 4}}
+
+			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger). //nolint:errcheck
+													CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+			_, createOptsBuilder := networkingFacade.CreatePortArgsForCall(0)
+			createMap, err := createOptsBuilder.ToPortCreateMap()
+			Expect(err).ToNot(HaveOccurred())
+
+			port := createMap["port"].(map[string]interface{})
+			Expect(port["network_id"]).To(Equal("the_net_id_1"))
+			Expect(port["binding:vnic_type"]).To(Equal("direct"))
+			Expect(port["binding:profile"]).To(Equal(map[string]interface{}{"capabilities": []string{"switchdev"}}))
+			Expect(**port["port_security_enabled"].(**bool)).To(BeTrue())
+			Expect(port["qos_policy_id"]).To(Equal("the-qos-policy-id"))
+			Expect(port["extra_dhcp_opts"]).To(Equal([]map[string]interface{}{
+				{"opt_name": "mtu", "opt_value": "9000", "ip_version": float64(4)},
+			}))
+			Expect(port["security_groups"]).To(ConsistOf("sec-id1", "sec-id2"))
+		})
+
+		It("does not apply security groups if port security is disabled", func() {
+			portSecurityEnabled := false
+			defaultNetwork.CloudProps.PortSecurityEnabled = &portSecurityEnabled
+
+			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger). //nolint:errcheck
+													CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+			_, createOptsBuilder := networkingFacade.CreatePortArgsForCall(0)
+			createMap, err := createOptsBuilder.ToPortCreateMap()
+			Expect(err).ToNot(HaveOccurred())
+
+			port := createMap["port"].(map[string]interface{})
+			Expect(**port["port_security_enabled"].(**bool)).To(BeFalse())
+			Expect(port["security_groups"]).To(BeEmpty())
+		})
+
+		It("creates the port in the configured subnet without looking it up", func() {
+			defaultNetwork.CloudProps.SubnetID = "the-subnet-id-2"
+
+			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger). //nolint:errcheck
+													CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+			_, createOptsBuilder := networkingFacade.CreatePortArgsForCall(0)
+			createOpts := createOptsBuilder.(ports.CreateOpts)
+
+			Expect(networkingFacade.ListSubnetsCallCount()).To(Equal(0))
+			Expect(createOpts.FixedIPs).To(Equal([]ports.IP{{SubnetID: "the-subnet-id-2", IPAddress: "1.1.1.1"}}))
+		})
+
+		It("creates a dual-stack port if multiple subnets are configured", func() {
+			networkingFacade.ExtractSubnetsReturns([]subnets.Subnet{
+				{ID: "the-subnet-id-1", CIDR: "1.1.1.0/24"}, {ID: "the-ipv6-subnet-id", CIDR: "fd00::/64"},
+			}, nil)
+			defaultNetwork.CloudProps.SubnetIDs = []string{"the-ipv6-subnet-id", "the-subnet-id-1"}
+
+			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger). //nolint:errcheck
+													CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+			_, createOptsBuilder := networkingFacade.CreatePortArgsForCall(0)
+			createOpts := createOptsBuilder.(ports.CreateOpts)
+
+			Expect(createOpts.FixedIPs).To(Equal([]ports.IP{
+				{SubnetID: "the-ipv6-subnet-id"},
+				{SubnetID: "the-subnet-id-1", IPAddress: "1.1.1.1"},
+			}))
+		})
+
+		It("returns an error if none of the configured subnets contains the ip", func() {
+			defaultNetwork.CloudProps.SubnetIDs = []string{"the-subnet-id-2"}
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+			Expect(err.Error()).To(Equal("failed create network opts: none of the subnets [the-subnet-id-2] contains the ip '1.1.1.1'"))
+		})
+
+		It("returns an error if a configured subnet does not belong to the network", func() {
+			defaultNetwork.CloudProps.SubnetIDs = []string{"the-subnet-id-1", "the-unknown-subnet-id"}
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+			Expect(err.Error()).To(Equal("failed create network opts: subnet 'the-unknown-subnet-id' not found in network 'the_net_id_1'"))
+		})
+
 		It("logs that if initial port creation fails", func() {
 			networkingFacade.CreatePortReturns(nil, errors.New("boom"))
 
 			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger). //nolint:errcheck
-													CreatePort(defaultNetwork, securityGroups, cloudProperties)
+													CreatePort(defaultNetwork, securityGroups, cloudProperties, identity, openstackConfig)
 
 			tag, msg, _ := logger.WarnArgsForCall(0)
 
@@ -359,7 +949,7 @@ var _ = Describe("NetworkService", func() {
 			networkingFacade.CreatePortReturns(nil, errors.New("boom"))
 
 			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger). //nolint:errcheck
-													CreatePort(defaultNetwork, securityGroups, cloudProperties)
+													CreatePort(defaultNetwork, securityGroups, cloudProperties, identity, openstackConfig)
 
 			_, listOpts := networkingFacade.ListPortsArgsForCall(1)
 
@@ -372,7 +962,7 @@ var _ = Describe("NetworkService", func() {
 			networkingFacade.ListPortsReturnsOnCall(0, nil, errors.New("boom"))
 
 			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
-				CreatePort(defaultNetwork, securityGroups, properties.CreateVM{})
+				CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
 
 			Expect(err.Error()).To(Equal("failed to list Ports: boom"))
 		})
@@ -381,7 +971,7 @@ var _ = Describe("NetworkService", func() {
 			networkingFacade.CreatePortReturnsOnCall(0, nil, errors.New("boom"))
 
 			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger). //nolint:errcheck
-													CreatePort(defaultNetwork, securityGroups, properties.CreateVM{})
+													CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
 
 			Expect(networkingFacade.ExtractPortsCallCount()).To(Equal(1))
 		})
@@ -391,7 +981,7 @@ var _ = Describe("NetworkService", func() {
 			networkingFacade.ExtractPortsReturns(nil, errors.New("boom"))
 
 			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
-				CreatePort(defaultNetwork, securityGroups, properties.CreateVM{})
+				CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
 
 			Expect(err.Error()).To(Equal("failed to extract ports: boom"))
 		})
@@ -405,7 +995,7 @@ var _ = Describe("NetworkService", func() {
 				}, nil)
 
 			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger). //nolint:errcheck
-													CreatePort(defaultNetwork, securityGroups, properties.CreateVM{})
+													CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
 
 			Expect(networkingFacade.DeletePortCallCount()).To(Equal(2))
 			_, portID := networkingFacade.DeletePortArgsForCall(0)
@@ -423,7 +1013,7 @@ var _ = Describe("NetworkService", func() {
 				}, nil)
 
 			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger). //nolint:errcheck
-													CreatePort(defaultNetwork, securityGroups, properties.CreateVM{})
+													CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
 
 			Expect(networkingFacade.DeletePortCallCount()).To(Equal(1))
 			_, portID := networkingFacade.DeletePortArgsForCall(0)
@@ -438,7 +1028,7 @@ var _ = Describe("NetworkService", func() {
 				}, nil)
 
 			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger). //nolint:errcheck
-													CreatePort(defaultNetwork, securityGroups, properties.CreateVM{})
+													CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
 
 			Expect(networkingFacade.CreatePortCallCount()).To(Equal(2))
 		})
@@ -452,20 +1042,501 @@ var _ = Describe("NetworkService", func() {
 				}, nil)
 
 			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
-				CreatePort(defaultNetwork, securityGroups, properties.CreateVM{})
+				CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
 
 			Expect(err.Error()).To(Equal("failed to recreate port on network 'the_net_id_1' for ip '1.1.1.1' boom"))
 		})
 
+		It("does not delete conflicting ports with the port conflict policy never", func() {
+			openstackConfig.PortConflictPolicy = config.PortConflictPolicyNever
+			networkingFacade.CreatePortReturnsOnCall(0, nil, errors.New("boom"))
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+			Expect(err.Error()).To(Equal("failed to create port on network 'the_net_id_1' for ip '1.1.1.1', " +
+				"conflicting ports are not deleted with port conflict policy 'never': boom"))
+			Expect(networkingFacade.ListPortsCallCount()).To(Equal(0))
+			Expect(networkingFacade.DeletePortCallCount()).To(Equal(0))
+		})
+
+		It("deletes only conflicting ports owned by BOSH with the port conflict policy bosh_owned_only", func() {
+			openstackConfig.PortConflictPolicy = config.PortConflictPolicyBoshOwnedOnly
+			networkingFacade.CreatePortReturnsOnCall(0, nil, errors.New("boom"))
+			networkingFacade.ExtractPortsReturns(
+				[]ports.Port{
+					{ID: "the-foreign-port-id", Status: "DOWN"},
+					{ID: "the-other-directors-port-id", Status: "DOWN", Tags: []string{"bosh", "director:the-other-director"}},
+					{ID: "the-bosh-port-id", Status: "DOWN", Tags: []string{"bosh", "director:the-director"}},
+				}, nil)
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingFacade.DeletePortCallCount()).To(Equal(1))
+			_, portID := networkingFacade.DeletePortArgsForCall(0)
+			Expect(portID).To(Equal("the-bosh-port-id"))
+		})
+
+		It("names the port after the instance", func() {
+			defaultNetwork.Key = "the-network"
+
+			_, _ = network.NewNetworkService(serviceClients, &networkingFacade, &logger). //nolint:errcheck
+													CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+			_, createOptsBuilder := networkingFacade.CreatePortArgsForCall(0)
+			createOpts := createOptsBuilder.(ports.CreateOpts)
+
+			Expect(createOpts.Name).To(Equal("the-director-the-deployment-the-instance-group-the-network"))
+			Expect(createOpts.Description).To(Equal("Created by the BOSH OpenStack CPI for agent the-agent-id"))
+		})
+
+		It("tags the created port", func() {
+			networkingFacade.ReplaceAllTagsReturns(identity.Tags(), nil)
+
+			port, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingFacade.ReplaceAllTagsCallCount()).To(Equal(1))
+			_, resourceType, resourceID, tags := networkingFacade.ReplaceAllTagsArgsForCall(0)
+			Expect(resourceType).To(Equal("ports"))
+			Expect(resourceID).To(Equal("the-port-id"))
+			Expect(tags).To(Equal([]string{"bosh", "director:the-director", "deployment:the-deployment",
+				"instance_group:the-instance-group", "agent_id:the-agent-id"}))
+			Expect(port.Tags).To(Equal(tags))
+		})
+
+		It("does not fail if tagging the created port fails", func() {
+			networkingFacade.ReplaceAllTagsReturns(nil, errors.New("boom"))
+
+			port, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(port.ID).To(Equal("the-port-id"))
+		})
+
+		Context("when ports are preserved", func() {
+			var parkedPort ports.Port
+
+			BeforeEach(func() {
+				openstackConfig.PreservePorts = true
+				defaultNetwork.Key = "the-network"
+				parkedPort = ports.Port{ID: "the-parked-port-id", MACAddress: "fa:16:3e:00:00:01", Tags: []string{"bosh", "director:the-director", "parked"}}
+				networkingFacade.ExtractPortsReturns([]ports.Port{parkedPort}, nil)
+				networkingFacade.GetPortWithExtensionsReturns(&network.PortWithExtensions{Port: parkedPort}, nil)
+				networkingFacade.UpdatePortReturns(&parkedPort, nil)
+				networkingFacade.ReplaceAllTagsReturns(identity.Tags(), nil)
+			})
+
+			It("adopts a parked port with the same network and ip", func() {
+				port, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+					CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(port.ID).To(Equal("the-parked-port-id"))
+				Expect(port.Tags).To(Equal(identity.Tags()))
+				Expect(networkingFacade.CreatePortCallCount()).To(Equal(0))
+
+				_, listOpts := networkingFacade.ListPortsArgsForCall(0)
+				Expect(listOpts.NetworkID).To(Equal("the_net_id_1"))
+				Expect(listOpts.FixedIPs).To(Equal([]ports.FixedIPOpts{{IPAddress: "1.1.1.1"}}))
+				Expect(listOpts.Tags).To(Equal("bosh,parked,director:the-director"))
+
+				_, portID, updateOptsBuilder := networkingFacade.UpdatePortArgsForCall(0)
+				updateOpts := updateOptsBuilder.(ports.UpdateOpts)
+				Expect(portID).To(Equal("the-parked-port-id"))
+				Expect(*updateOpts.Name).To(Equal("the-director-the-deployment-the-instance-group-the-network"))
+				Expect(*updateOpts.SecurityGroups).To(Equal([]string{"sec-id1", "sec-id2"}))
+				Expect(*updateOpts.AllowedAddressPairs).To(BeEmpty())
+
+				_, resourceType, resourceID, tags := networkingFacade.ReplaceAllTagsArgsForCall(0)
+				Expect(resourceType).To(Equal("ports"))
+				Expect(resourceID).To(Equal("the-parked-port-id"))
+				Expect(tags).To(Equal(identity.Tags()))
+			})
+
+			It("does not check the VRRP ports if a parked port is adopted", func() {
+				vrrpPortCheck := true
+				cloudProperties := properties.CreateVM{
+					AllowedAddressPairs: properties.AllowedAddressPairs{{IPAddress: "allowed-address-pairs"}},
+					VRRPPortCheck:       &vrrpPortCheck,
+				}
+
+				port, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+					CreatePort(defaultNetwork, securityGroups, cloudProperties, identity, openstackConfig)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(port.ID).To(Equal("the-parked-port-id"))
+				Expect(networkingFacade.ListPortsCallCount()).To(Equal(1))
+				Expect(networkingFacade.CreatePortCallCount()).To(Equal(0))
+			})
+
+			It("does not adopt ports parked by another director", func() {
+				parkedPort.Tags = []string{"bosh", "director:the-other-director", "parked"}
+				networkingFacade.ExtractPortsReturnsOnCall(0, []ports.Port{parkedPort}, nil)
+
+				port, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+					CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(port.ID).To(Equal("the-port-id"))
+				Expect(networkingFacade.UpdatePortCallCount()).To(Equal(0))
+				Expect(networkingFacade.CreatePortCallCount()).To(Equal(1))
+			})
+
+			It("adopts a parked port with the port properties of the network", func() {
+				portSecurityEnabled := true
+				defaultNetwork.CloudProps.VNICType = "direct"
+				defaultNetwork.CloudProps.PortSecurityEnabled = &portSecurityEnabled
+				defaultNetwork.CloudProps.QoSPolicyID = "the-qos-policy-id"
+				defaultNetwork.CloudProps.BindingProfile = map[string]interface{}{"capabilities": []interface{}{"switchdev"}}
+				defaultNetwork.CloudProps.ExtraDHCPOpts = []properties.ExtraDHCPOpt{{OptName: "mtu", OptValue: "9000"}, {OptName: "domain-name", OptValue: "example.com"}}
+
+				parkedPortWithExtensions := network.PortWithExtensions{Port: parkedPort}
+				parkedPortWithExtensions.VNICType = "direct"
+				parkedPortWithExtensions.PortSecurityEnabled = true
+				parkedPortWithExtensions.QoSPolicyID = "the-qos-policy-id"
+				parkedPortWithExtensions.Profile = map[string]interface{}{"capabilities": []interface{}{"switchdev"}}
+				parkedPortWithExtensions.ExtraDHCPOpts = []extradhcpopts.ExtraDHCPOpt{
+					{OptName: "domain-name", OptValue: "example.com", IPVersion: 4},
+					{OptName: "mtu", OptValue: "9000", IPVersion: 4},
+				}
+				networkingFacade.GetPortWithExtensionsReturns(&parkedPortWithExtensions, nil)
+
+				port, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+					CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(port.ID).To(Equal("the-parked-port-id"))
+				Expect(networkingFacade.DeletePortCallCount()).To(Equal(0))
+				Expect(networkingFacade.CreatePortCallCount()).To(Equal(0))
+			})
+
+			It("deletes a parked port with a different vnic type and creates a port", func() {
+				parkedPortWithExtensions := network.PortWithExtensions{Port: parkedPort}
+				parkedPortWithExtensions.VNICType = "direct"
+				networkingFacade.GetPortWithExtensionsReturns(&parkedPortWithExtensions, nil)
+
+				port, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+					CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(port.ID).To(Equal("the-port-id"))
+				_, portID := networkingFacade.DeletePortArgsForCall(0)
+				Expect(portID).To(Equal("the-parked-port-id"))
+				Expect(networkingFacade.UpdatePortCallCount()).To(Equal(0))
+				Expect(networkingFacade.CreatePortCallCount()).To(Equal(1))
+			})
+
+			It("deletes a parked port with a QoS policy which is no longer configured", func() {
+				parkedPortWithExtensions := network.PortWithExtensions{Port: parkedPort}
+				parkedPortWithExtensions.QoSPolicyID = "the-old-qos-policy-id"
+				networkingFacade.GetPortWithExtensionsReturns(&parkedPortWithExtensions, nil)
+
+				port, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+					CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(port.ID).To(Equal("the-port-id"))
+				Expect(networkingFacade.DeletePortCallCount()).To(Equal(1))
+			})
+
+			It("deletes a parked port with a different binding profile", func() {
+				parkedPortWithExtensions := network.PortWithExtensions{Port: parkedPort}
+				parkedPortWithExtensions.Profile = map[string]interface{}{"trusted": true}
+				networkingFacade.GetPortWithExtensionsReturns(&parkedPortWithExtensions, nil)
+
+				port, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+					CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(port.ID).To(Equal("the-port-id"))
+				Expect(networkingFacade.DeletePortCallCount()).To(Equal(1))
+			})
+
+			It("deletes a parked port with different extra DHCP options", func() {
+				parkedPortWithExtensions := network.PortWithExtensions{Port: parkedPort}
+				parkedPortWithExtensions.ExtraDHCPOpts = []extradhcpopts.ExtraDHCPOpt{{OptName: "mtu", OptValue: "9000", IPVersion: 4}}
+				networkingFacade.GetPortWithExtensionsReturns(&parkedPortWithExtensions, nil)
+
+				port, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+					CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(port.ID).To(Equal("the-port-id"))
+				Expect(networkingFacade.DeletePortCallCount()).To(Equal(1))
+			})
+
+			It("deletes a parked port with different port security and creates a port", func() {
+				portSecurityEnabled := false
+				defaultNetwork.CloudProps.PortSecurityEnabled = &portSecurityEnabled
+				parkedPortWithExtensions := network.PortWithExtensions{Port: parkedPort}
+				parkedPortWithExtensions.PortSecurityEnabled = true
+				networkingFacade.GetPortWithExtensionsReturns(&parkedPortWithExtensions, nil)
+
+				port, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+					CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(port.ID).To(Equal("the-port-id"))
+				Expect(networkingFacade.DeletePortCallCount()).To(Equal(1))
+			})
+
+			It("returns an error if the parked port cannot be retrieved", func() {
+				networkingFacade.GetPortWithExtensionsReturns(nil, errors.New("boom"))
+
+				_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+					CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+				Expect(err.Error()).To(Equal("failed to adopt parked port: failed to get parked port 'the-parked-port-id': boom"))
+			})
+
+			It("returns an error if a parked port with different port properties cannot be deleted", func() {
+				networkingFacade.GetPortWithExtensionsReturns(&network.PortWithExtensions{Port: parkedPort, QoSPolicyExt: policies.QoSPolicyExt{QoSPolicyID: "the-old-qos-policy-id"}}, nil)
+				networkingFacade.DeletePortReturns(errors.New("boom"))
+
+				_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+					CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+				Expect(err.Error()).To(Equal("failed to adopt parked port: failed to delete parked port 'the-parked-port-id': boom"))
+			})
+
+			It("creates a port if no port is parked", func() {
+				networkingFacade.ExtractPortsReturns([]ports.Port{}, nil)
+
+				port, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+					CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+				Expect(err).ToNot(HaveOccurred())
+				Expect(port.ID).To(Equal("the-port-id"))
+				Expect(networkingFacade.CreatePortCallCount()).To(Equal(1))
+			})
+
+			It("returns an error if the parked port cannot be updated", func() {
+				networkingFacade.UpdatePortReturns(nil, errors.New("boom"))
+
+				_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+					CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+				Expect(err.Error()).To(Equal("failed to adopt parked port: failed to update parked port 'the-parked-port-id': boom"))
+			})
+
+			It("returns an error if the parked port cannot be unparked", func() {
+				networkingFacade.ReplaceAllTagsReturns(nil, errors.New("boom"))
+
+				_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+					CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
+
+				Expect(err.Error()).To(Equal("failed to adopt parked port: failed to unpark port 'the-parked-port-id': boom"))
+			})
+		})
+
 		It("returns the created port", func() {
 			port, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
-				CreatePort(defaultNetwork, securityGroups, properties.CreateVM{})
+				CreatePort(defaultNetwork, securityGroups, properties.CreateVM{}, identity, openstackConfig)
 
 			Expect(err).To(Not(HaveOccurred()))
 			Expect(port.ID).To(Equal(createdPort.ID))
 		})
 	})
 
+	Context("GetPreCreatedPort", func() {
+		BeforeEach(func() {
+			defaultNetwork.Key = "the-network"
+			defaultNetwork.CloudProps.PortID = "the-pre-created-port-id"
+			networkingFacade.GetPortReturns(&ports.Port{
+				ID:        "the-pre-created-port-id",
+				NetworkID: "the_net_id_1",
+				FixedIPs:  []ports.IP{{SubnetID: "the-subnet-id-1", IPAddress: "1.1.1.1"}},
+			}, nil)
+		})
+
+		It("returns the pre-created port", func() {
+			port, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).GetPreCreatedPort(defaultNetwork)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(port.ID).To(Equal("the-pre-created-port-id"))
+			_, portID := networkingFacade.GetPortArgsForCall(0)
+			Expect(portID).To(Equal("the-pre-created-port-id"))
+		})
+
+		It("returns an error if the port cannot be retrieved", func() {
+			networkingFacade.GetPortReturns(nil, errors.New("boom"))
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).GetPreCreatedPort(defaultNetwork)
+
+			Expect(err.Error()).To(Equal("failed to get port 'the-pre-created-port-id': boom"))
+		})
+
+		It("returns an error if the port belongs to another network", func() {
+			defaultNetwork.CloudProps.NetID = "the_net_id_2"
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).GetPreCreatedPort(defaultNetwork)
+
+			Expect(err.Error()).To(Equal("port 'the-pre-created-port-id' belongs to network 'the_net_id_1' instead of network 'the_net_id_2'"))
+		})
+
+		It("returns an error if the port does not hold the ip of the network", func() {
+			defaultNetwork.IP = "1.1.1.2"
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).GetPreCreatedPort(defaultNetwork)
+
+			Expect(err.Error()).To(Equal("port 'the-pre-created-port-id' does not hold the ip '1.1.1.2' of network 'the-network'"))
+		})
+
+		It("returns an error if the port is attached to another device", func() {
+			networkingFacade.GetPortReturns(&ports.Port{
+				ID:        "the-pre-created-port-id",
+				NetworkID: "the_net_id_1",
+				FixedIPs:  []ports.IP{{IPAddress: "1.1.1.1"}},
+				DeviceID:  "the-other-server-id",
+			}, nil)
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).GetPreCreatedPort(defaultNetwork)
+
+			Expect(err.Error()).To(Equal("port 'the-pre-created-port-id' is already attached to device 'the-other-server-id'"))
+		})
+	})
+
+	Context("GetAdditionalIPs", func() {
+
+		BeforeEach(func() {
+			networkingFacade.ExtractSubnetsReturns([]subnets.Subnet{
+				{ID: "the-subnet-id-1", CIDR: "1.1.1.0/24", GatewayIP: "1.1.1.254"},
+				{ID: "the-ipv6-subnet-id", CIDR: "fd00::/64", GatewayIP: "fd00::1"},
+			}, nil)
+		})
+
+		It("does not list subnets if the port has a single fixed ip", func() {
+			additionalIPs, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				GetAdditionalIPs(defaultNetwork, ports.Port{FixedIPs: []ports.IP{{SubnetID: "the-subnet-id-1", IPAddress: "1.1.1.1"}}})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(additionalIPs).To(BeEmpty())
+			Expect(networkingFacade.ListSubnetsCallCount()).To(Equal(0))
+		})
+
+		It("returns the fixed ips besides the bosh ip with netmask and gateway of their subnet", func() {
+			additionalIPs, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				GetAdditionalIPs(defaultNetwork, ports.Port{FixedIPs: []ports.IP{
+					{SubnetID: "the-subnet-id-1", IPAddress: "1.1.1.1"},
+					{SubnetID: "the-ipv6-subnet-id", IPAddress: "fd00::f816:3eff:fe00:1"},
+				}})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(additionalIPs).To(Equal([]properties.AdditionalIP{{
+				SubnetID: "the-ipv6-subnet-id",
+				IP:       "fd00::f816:3eff:fe00:1",
+				Netmask:  "ffff:ffff:ffff:ffff::",
+				Gateway:  "fd00::1",
+			}}))
+		})
+
+		It("returns an error if listing subnets fails", func() {
+			networkingFacade.ListSubnetsReturns(nil, errors.New("boom"))
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				GetAdditionalIPs(defaultNetwork, ports.Port{FixedIPs: []ports.IP{{SubnetID: "the-ipv6-subnet-id", IPAddress: "fd00::10"}}})
+
+			Expect(err.Error()).To(Equal("failed to list subnets: boom"))
+		})
+
+		It("returns an error if the subnet of a fixed ip is unknown", func() {
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				GetAdditionalIPs(defaultNetwork, ports.Port{FixedIPs: []ports.IP{{SubnetID: "the-unknown-subnet-id", IPAddress: "fd00::10"}}})
+
+			Expect(err.Error()).To(Equal("subnet 'the-unknown-subnet-id' of ip 'fd00::10' not found in network 'the_net_id_1'"))
+		})
+	})
+
+	Context("GetNetworkSettings", func() {
+		BeforeEach(func() {
+			networkingFacade.ExtractSubnetsReturns([]subnets.Subnet{
+				{
+					ID:             "the-subnet-id-1",
+					CIDR:           "1.1.1.0/24",
+					IPVersion:      4,
+					DNSNameservers: []string{"8.8.8.8"},
+					HostRoutes:     []subnets.HostRoute{{DestinationCIDR: "10.0.0.0/8", NextHop: "1.1.1.253"}},
+				},
+				{
+					ID:             "the-subnet-id-2",
+					CIDR:           "1.1.2.0/24",
+					IPVersion:      4,
+					DNSNameservers: []string{"8.8.4.4"},
+					HostRoutes:     []subnets.HostRoute{{DestinationCIDR: "192.168.0.0/16", NextHop: "1.1.2.253"}},
+				},
+				{ID: "the-ipv6-subnet-id", CIDR: "fd00::/64", IPVersion: 6, DNSNameservers: []string{"fd00::53"}},
+			}, nil)
+			networkingFacade.GetNetworkWithMTUReturns(&network.NetworkWithMTU{NetworkMTUExt: mtu.NetworkMTUExt{MTU: 1450}}, nil)
+		})
+
+		It("returns the settings of the subnet holding the ip of a manual network", func() {
+			settings, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).GetNetworkSettings(defaultNetwork)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(settings).To(Equal(properties.NetworkSettings{
+				Routes: []properties.Route{{Destination: "10.0.0.0", Gateway: "1.1.1.253", Netmask: "255.0.0.0"}},
+				DNS:    []string{"8.8.8.8"},
+				MTU:    1450,
+			}))
+			_, listOpts := networkingFacade.ListSubnetsArgsForCall(0)
+			Expect(listOpts.NetworkID).To(Equal("the_net_id_1"))
+			_, networkID := networkingFacade.GetNetworkWithMTUArgsForCall(0)
+			Expect(networkID).To(Equal("the_net_id_1"))
+		})
+
+		It("returns the settings of all ipv4 subnets of a dynamic network", func() {
+			settings, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				GetNetworkSettings(properties.Network{Type: "dynamic", CloudProps: properties.NetworkCloudProps{NetID: "the_net_id_1"}})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(settings.Routes).To(Equal([]properties.Route{
+				{Destination: "10.0.0.0", Gateway: "1.1.1.253", Netmask: "255.0.0.0"},
+				{Destination: "192.168.0.0", Gateway: "1.1.2.253", Netmask: "255.255.0.0"},
+			}))
+			Expect(settings.DNS).To(Equal([]string{"8.8.8.8", "8.8.4.4"}))
+			Expect(settings.MTU).To(Equal(1450))
+		})
+
+		It("returns an error if no subnet holds the ip of a manual network", func() {
+			defaultNetwork.IP = "1.1.3.1"
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).GetNetworkSettings(defaultNetwork)
+
+			Expect(err.Error()).To(Equal("no matching subnet found for the ip '1.1.3.1'"))
+		})
+
+		It("returns an error if listing subnets fails", func() {
+			networkingFacade.ListSubnetsReturns(nil, errors.New("boom"))
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).GetNetworkSettings(defaultNetwork)
+
+			Expect(err.Error()).To(Equal("failed to list subnets: boom"))
+		})
+
+		It("returns an error if a host route cannot be parsed", func() {
+			networkingFacade.ExtractSubnetsReturns([]subnets.Subnet{
+				{ID: "the-subnet-id-1", CIDR: "1.1.1.0/24", HostRoutes: []subnets.HostRoute{{DestinationCIDR: "invalid", NextHop: "1.1.1.253"}}},
+			}, nil)
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).GetNetworkSettings(defaultNetwork)
+
+			Expect(err.Error()).To(Equal("failed to parse destination 'invalid' of a host route of subnet 'the-subnet-id-1': invalid CIDR address: invalid"))
+		})
+
+		It("returns an error if the network cannot be retrieved", func() {
+			networkingFacade.GetNetworkWithMTUReturns(nil, errors.New("boom"))
+
+			_, err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).GetNetworkSettings(defaultNetwork)
+
+			Expect(err.Error()).To(Equal("failed to get network 'the_net_id_1': boom"))
+		})
+	})
+
 	Context("GetPorts", func() {
 
 		It("serviceClient is retryable", func() {
@@ -545,4 +1616,107 @@ var _ = Describe("NetworkService", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 	})
+
+	Context("ParkPorts", func() {
+		It("tags the ports as parked keeping their tags", func() {
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				ParkPorts([]ports.Port{{ID: "the-port-id", Tags: []string{"bosh", "director:the-director"}}})
+
+			Expect(err).ToNot(HaveOccurred())
+			_, resourceType, resourceID, tags := networkingFacade.ReplaceAllTagsArgsForCall(0)
+			Expect(resourceType).To(Equal("ports"))
+			Expect(resourceID).To(Equal("the-port-id"))
+			Expect(tags[:3]).To(Equal([]string{"bosh", "director:the-director", "parked"}))
+
+			parkedAt, err := time.Parse(time.RFC3339, tags[3][len("parked_at:"):])
+			Expect(err).ToNot(HaveOccurred())
+			Expect(parkedAt).To(BeTemporally("~", time.Now(), time.Minute))
+		})
+
+		It("replaces the parked tags of ports parked before and adds the bosh tag", func() {
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				ParkPorts([]ports.Port{{ID: "the-port-id", Tags: []string{"parked", "parked_at:2020-01-01T00:00:00Z"}}})
+
+			Expect(err).ToNot(HaveOccurred())
+			_, _, _, tags := networkingFacade.ReplaceAllTagsArgsForCall(0)
+			Expect(tags).To(HaveLen(3))
+			Expect(tags[:2]).To(Equal([]string{"bosh", "parked"}))
+			Expect(tags[2]).ToNot(Equal("parked_at:2020-01-01T00:00:00Z"))
+		})
+
+		It("skips ports which do not exist anymore", func() {
+			networkingFacade.ReplaceAllTagsReturnsOnCall(0, nil, gophercloud.ErrDefault404{})
+
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				ParkPorts([]ports.Port{{ID: "the-port-id-1"}, {ID: "the-port-id-2"}})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingFacade.ReplaceAllTagsCallCount()).To(Equal(2))
+		})
+
+		It("returns an error if a port cannot be parked", func() {
+			networkingFacade.ReplaceAllTagsReturns(nil, errors.New("boom"))
+
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).
+				ParkPorts([]ports.Port{{ID: "the-port-id"}})
+
+			Expect(err.Error()).To(Equal("failed to park port 'the-port-id': boom"))
+		})
+	})
+
+	Context("DeleteExpiredParkedPorts", func() {
+		var expiredParkedAt string
+		var recentParkedAt string
+		var identity properties.InstanceIdentity
+
+		BeforeEach(func() {
+			identity = properties.InstanceIdentity{Director: "the-director"}
+			expiredParkedAt = "parked_at:" + time.Now().Add(-2*time.Hour).UTC().Format(time.RFC3339)
+			recentParkedAt = "parked_at:" + time.Now().Add(-30*time.Minute).UTC().Format(time.RFC3339)
+		})
+
+		It("deletes parked ports after the expiry", func() {
+			networkingFacade.ExtractPortsReturns([]ports.Port{
+				{ID: "the-expired-port-id", Tags: []string{"bosh", "director:the-director", "parked", expiredParkedAt}},
+				{ID: "the-recent-port-id", Tags: []string{"bosh", "director:the-director", "parked", recentParkedAt}},
+				{ID: "the-adopted-port-id", DeviceID: "the-server-id", Tags: []string{"bosh", "director:the-director", "parked", expiredParkedAt}},
+				{ID: "the-unknown-port-id", Tags: []string{"bosh", "director:the-director", "parked"}},
+				{ID: "the-other-directors-port-id", Tags: []string{"bosh", "director:the-other-director", "parked", expiredParkedAt}},
+			}, nil)
+
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).DeleteExpiredParkedPorts(time.Hour, identity)
+
+			Expect(err).ToNot(HaveOccurred())
+			_, listOpts := networkingFacade.ListPortsArgsForCall(0)
+			Expect(listOpts.Tags).To(Equal("bosh,parked,director:the-director"))
+			Expect(networkingFacade.DeletePortCallCount()).To(Equal(1))
+			_, portID := networkingFacade.DeletePortArgsForCall(0)
+			Expect(portID).To(Equal("the-expired-port-id"))
+		})
+
+		It("returns an error if the parked ports cannot be listed", func() {
+			networkingFacade.ListPortsReturns(nil, errors.New("boom"))
+
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).DeleteExpiredParkedPorts(time.Hour, identity)
+
+			Expect(err.Error()).To(Equal("failed to list parked ports: boom"))
+		})
+
+		It("does not delete parked ports if the director is not known", func() {
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).DeleteExpiredParkedPorts(time.Hour, properties.InstanceIdentity{})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingFacade.ListPortsCallCount()).To(Equal(0))
+			Expect(networkingFacade.DeletePortCallCount()).To(Equal(0))
+		})
+
+		It("returns an error if an expired port cannot be deleted", func() {
+			networkingFacade.ExtractPortsReturns([]ports.Port{{ID: "the-expired-port-id", Tags: []string{"bosh", "director:the-director", "parked", expiredParkedAt}}}, nil)
+			networkingFacade.DeletePortReturns(errors.New("boom"))
+
+			err := network.NewNetworkService(serviceClients, &networkingFacade, &logger).DeleteExpiredParkedPorts(time.Hour, identity)
+
+			Expect(err.Error()).To(Equal("failed to delete expired parked port 'the-expired-port-id': boom"))
+		})
+	})
 })
@@ -3,6 +3,7 @@ package networkfakes
 
 import (
 	"sync"
+	"time"
 
 	"github.com/cloudfoundry/bosh-cpi-go/apiv1"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/config"
@@ -12,24 +13,29 @@ import (
 )
 
 type FakeNetworkService struct {
-	ConfigureVIPNetworkStub        func(string, properties.NetworkConfig) error
+	ConfigureVIPNetworkStub        func(string, properties.NetworkConfig, properties.InstanceIdentity) (string, error)
 	configureVIPNetworkMutex       sync.RWMutex
 	configureVIPNetworkArgsForCall []struct {
 		arg1 string
 		arg2 properties.NetworkConfig
+		arg3 properties.InstanceIdentity
 	}
 	configureVIPNetworkReturns struct {
-		result1 error
+		result1 string
+		result2 error
 	}
 	configureVIPNetworkReturnsOnCall map[int]struct {
-		result1 error
+		result1 string
+		result2 error
 	}
-	CreatePortStub        func(properties.Network, []string, properties.CreateVM) (ports.Port, error)
+	CreatePortStub        func(properties.Network, []string, properties.CreateVM, properties.InstanceIdentity, config.OpenstackConfig) (ports.Port, error)
 	createPortMutex       sync.RWMutex
 	createPortArgsForCall []struct {
 		arg1 properties.Network
 		arg2 []string
 		arg3 properties.CreateVM
+		arg4 properties.InstanceIdentity
+		arg5 config.OpenstackConfig
 	}
 	createPortReturns struct {
 		result1 ports.Port
@@ -39,6 +45,18 @@ type FakeNetworkService struct {
 		result1 ports.Port
 		result2 error
 	}
+	DeleteExpiredParkedPortsStub        func(time.Duration, properties.InstanceIdentity) error
+	deleteExpiredParkedPortsMutex       sync.RWMutex
+	deleteExpiredParkedPortsArgsForCall []struct {
+		arg1 time.Duration
+		arg2 properties.InstanceIdentity
+	}
+	deleteExpiredParkedPortsReturns struct {
+		result1 error
+	}
+	deleteExpiredParkedPortsReturnsOnCall map[int]struct {
+		result1 error
+	}
 	DeletePortsStub        func([]ports.Port) error
 	deletePortsMutex       sync.RWMutex
 	deletePortsArgsForCall []struct {
@@ -50,6 +68,56 @@ type FakeNetworkService struct {
 	deletePortsReturnsOnCall map[int]struct {
 		result1 error
 	}
+	DeleteUnusedManagedSecurityGroupsStub        func([]string) error
+	deleteUnusedManagedSecurityGroupsMutex       sync.RWMutex
+	deleteUnusedManagedSecurityGroupsArgsForCall []struct {
+		arg1 []string
+	}
+	deleteUnusedManagedSecurityGroupsReturns struct {
+		result1 error
+	}
+	deleteUnusedManagedSecurityGroupsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DisassociateFloatingIPsStub        func([]ports.Port) error
+	disassociateFloatingIPsMutex       sync.RWMutex
+	disassociateFloatingIPsArgsForCall []struct {
+		arg1 []ports.Port
+	}
+	disassociateFloatingIPsReturns struct {
+		result1 error
+	}
+	disassociateFloatingIPsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	EnsureManagedSecurityGroupsStub        func([]properties.ManagedSecurityGroup, properties.BoshEnv) ([]string, error)
+	ensureManagedSecurityGroupsMutex       sync.RWMutex
+	ensureManagedSecurityGroupsArgsForCall []struct {
+		arg1 []properties.ManagedSecurityGroup
+		arg2 properties.BoshEnv
+	}
+	ensureManagedSecurityGroupsReturns struct {
+		result1 []string
+		result2 error
+	}
+	ensureManagedSecurityGroupsReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	GetAdditionalIPsStub        func(properties.Network, ports.Port) ([]properties.AdditionalIP, error)
+	getAdditionalIPsMutex       sync.RWMutex
+	getAdditionalIPsArgsForCall []struct {
+		arg1 properties.Network
+		arg2 ports.Port
+	}
+	getAdditionalIPsReturns struct {
+		result1 []properties.AdditionalIP
+		result2 error
+	}
+	getAdditionalIPsReturnsOnCall map[int]struct {
+		result1 []properties.AdditionalIP
+		result2 error
+	}
 	GetNetworkConfigurationStub        func(apiv1.Networks, config.OpenstackConfig, properties.CreateVM) (properties.NetworkConfig, error)
 	getNetworkConfigurationMutex       sync.RWMutex
 	getNetworkConfigurationArgsForCall []struct {
@@ -65,6 +133,19 @@ type FakeNetworkService struct {
 		result1 properties.NetworkConfig
 		result2 error
 	}
+	GetNetworkSettingsStub        func(properties.Network) (properties.NetworkSettings, error)
+	getNetworkSettingsMutex       sync.RWMutex
+	getNetworkSettingsArgsForCall []struct {
+		arg1 properties.Network
+	}
+	getNetworkSettingsReturns struct {
+		result1 properties.NetworkSettings
+		result2 error
+	}
+	getNetworkSettingsReturnsOnCall map[int]struct {
+		result1 properties.NetworkSettings
+		result2 error
+	}
 	GetPortsStub        func(string, properties.Network, bool) ([]ports.Port, error)
 	getPortsMutex       sync.RWMutex
 	getPortsArgsForCall []struct {
@@ -80,6 +161,19 @@ type FakeNetworkService struct {
 		result1 []ports.Port
 		result2 error
 	}
+	GetPreCreatedPortStub        func(properties.Network) (ports.Port, error)
+	getPreCreatedPortMutex       sync.RWMutex
+	getPreCreatedPortArgsForCall []struct {
+		arg1 properties.Network
+	}
+	getPreCreatedPortReturns struct {
+		result1 ports.Port
+		result2 error
+	}
+	getPreCreatedPortReturnsOnCall map[int]struct {
+		result1 ports.Port
+		result2 error
+	}
 	GetSubnetIDStub        func(string, string) (string, error)
 	getSubnetIDMutex       sync.RWMutex
 	getSubnetIDArgsForCall []struct {
@@ -94,28 +188,63 @@ type FakeNetworkService struct {
 		result1 string
 		result2 error
 	}
+	ParkPortsStub        func([]ports.Port) error
+	parkPortsMutex       sync.RWMutex
+	parkPortsArgsForCall []struct {
+		arg1 []ports.Port
+	}
+	parkPortsReturns struct {
+		result1 error
+	}
+	parkPortsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	ReleaseFloatingIPsStub        func(string) error
+	releaseFloatingIPsMutex       sync.RWMutex
+	releaseFloatingIPsArgsForCall []struct {
+		arg1 string
+	}
+	releaseFloatingIPsReturns struct {
+		result1 error
+	}
+	releaseFloatingIPsReturnsOnCall map[int]struct {
+		result1 error
+	}
+	SetMetadataTagsStub        func([]ports.Port, map[string]string) error
+	setMetadataTagsMutex       sync.RWMutex
+	setMetadataTagsArgsForCall []struct {
+		arg1 []ports.Port
+		arg2 map[string]string
+	}
+	setMetadataTagsReturns struct {
+		result1 error
+	}
+	setMetadataTagsReturnsOnCall map[int]struct {
+		result1 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeNetworkService) ConfigureVIPNetwork(arg1 string, arg2 properties.NetworkConfig) error {
+func (fake *FakeNetworkService) ConfigureVIPNetwork(arg1 string, arg2 properties.NetworkConfig, arg3 properties.InstanceIdentity) (string, error) {
 	fake.configureVIPNetworkMutex.Lock()
 	ret, specificReturn := fake.configureVIPNetworkReturnsOnCall[len(fake.configureVIPNetworkArgsForCall)]
 	fake.configureVIPNetworkArgsForCall = append(fake.configureVIPNetworkArgsForCall, struct {
 		arg1 string
 		arg2 properties.NetworkConfig
-	}{arg1, arg2})
+		arg3 properties.InstanceIdentity
+	}{arg1, arg2, arg3})
 	stub := fake.ConfigureVIPNetworkStub
 	fakeReturns := fake.configureVIPNetworkReturns
-	fake.recordInvocation("ConfigureVIPNetwork", []interface{}{arg1, arg2})
+	fake.recordInvocation("ConfigureVIPNetwork", []interface{}{arg1, arg2, arg3})
 	fake.configureVIPNetworkMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2)
+		return stub(arg1, arg2, arg3)
 	}
 	if specificReturn {
-		return ret.result1
+		return ret.result1, ret.result2
 	}
-	return fakeReturns.result1
+	return fakeReturns.result1, fakeReturns.result2
 }
 
 func (fake *FakeNetworkService) ConfigureVIPNetworkCallCount() int {
@@ -124,43 +253,46 @@ func (fake *FakeNetworkService) ConfigureVIPNetworkCallCount() int {
 	return len(fake.configureVIPNetworkArgsForCall)
 }
 
-func (fake *FakeNetworkService) ConfigureVIPNetworkCalls(stub func(string, properties.NetworkConfig) error) {
+func (fake *FakeNetworkService) ConfigureVIPNetworkCalls(stub func(string, properties.NetworkConfig, properties.InstanceIdentity) (string, error)) {
 	fake.configureVIPNetworkMutex.Lock()
 	defer fake.configureVIPNetworkMutex.Unlock()
 	fake.ConfigureVIPNetworkStub = stub
 }
 
-func (fake *FakeNetworkService) ConfigureVIPNetworkArgsForCall(i int) (string, properties.NetworkConfig) {
+func (fake *FakeNetworkService) ConfigureVIPNetworkArgsForCall(i int) (string, properties.NetworkConfig, properties.InstanceIdentity) {
 	fake.configureVIPNetworkMutex.RLock()
 	defer fake.configureVIPNetworkMutex.RUnlock()
 	argsForCall := fake.configureVIPNetworkArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
 }
 
-func (fake *FakeNetworkService) ConfigureVIPNetworkReturns(result1 error) {
+func (fake *FakeNetworkService) ConfigureVIPNetworkReturns(result1 string, result2 error) {
 	fake.configureVIPNetworkMutex.Lock()
 	defer fake.configureVIPNetworkMutex.Unlock()
 	fake.ConfigureVIPNetworkStub = nil
 	fake.configureVIPNetworkReturns = struct {
-		result1 error
-	}{result1}
+		result1 string
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeNetworkService) ConfigureVIPNetworkReturnsOnCall(i int, result1 error) {
+func (fake *FakeNetworkService) ConfigureVIPNetworkReturnsOnCall(i int, result1 string, result2 error) {
 	fake.configureVIPNetworkMutex.Lock()
 	defer fake.configureVIPNetworkMutex.Unlock()
 	fake.ConfigureVIPNetworkStub = nil
 	if fake.configureVIPNetworkReturnsOnCall == nil {
 		fake.configureVIPNetworkReturnsOnCall = make(map[int]struct {
-			result1 error
+			result1 string
+			result2 error
 		})
 	}
 	fake.configureVIPNetworkReturnsOnCall[i] = struct {
-		result1 error
-	}{result1}
+		result1 string
+		result2 error
+	}{result1, result2}
 }
 
-func (fake *FakeNetworkService) CreatePort(arg1 properties.Network, arg2 []string, arg3 properties.CreateVM) (ports.Port, error) {
+func (fake *FakeNetworkService) CreatePort(arg1 properties.Network, arg2 []string, arg3 properties.CreateVM, arg4 properties.InstanceIdentity, arg5 config.OpenstackConfig) (ports.Port, error) {
 	var arg2Copy []string
 	if arg2 != nil {
 		arg2Copy = make([]string, len(arg2))
@@ -172,13 +304,15 @@ func (fake *FakeNetworkService) CreatePort(arg1 properties.Network, arg2 []strin
 		arg1 properties.Network
 		arg2 []string
 		arg3 properties.CreateVM
-	}{arg1, arg2Copy, arg3})
+		arg4 properties.InstanceIdentity
+		arg5 config.OpenstackConfig
+	}{arg1, arg2Copy, arg3, arg4, arg5})
 	stub := fake.CreatePortStub
 	fakeReturns := fake.createPortReturns
-	fake.recordInvocation("CreatePort", []interface{}{arg1, arg2Copy, arg3})
+	fake.recordInvocation("CreatePort", []interface{}{arg1, arg2Copy, arg3, arg4, arg5})
 	fake.createPortMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3)
+		return stub(arg1, arg2, arg3, arg4, arg5)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -192,17 +326,17 @@ func (fake *FakeNetworkService) CreatePortCallCount() int {
 	return len(fake.createPortArgsForCall)
 }
 
-func (fake *FakeNetworkService) CreatePortCalls(stub func(properties.Network, []string, properties.CreateVM) (ports.Port, error)) {
+func (fake *FakeNetworkService) CreatePortCalls(stub func(properties.Network, []string, properties.CreateVM, properties.InstanceIdentity, config.OpenstackConfig) (ports.Port, error)) {
 	fake.createPortMutex.Lock()
 	defer fake.createPortMutex.Unlock()
 	fake.CreatePortStub = stub
 }
 
-func (fake *FakeNetworkService) CreatePortArgsForCall(i int) (properties.Network, []string, properties.CreateVM) {
+func (fake *FakeNetworkService) CreatePortArgsForCall(i int) (properties.Network, []string, properties.CreateVM, properties.InstanceIdentity, config.OpenstackConfig) {
 	fake.createPortMutex.RLock()
 	defer fake.createPortMutex.RUnlock()
 	argsForCall := fake.createPortArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
 }
 
 func (fake *FakeNetworkService) CreatePortReturns(result1 ports.Port, result2 error) {
@@ -231,6 +365,68 @@ func (fake *FakeNetworkService) CreatePortReturnsOnCall(i int, result1 ports.Por
 	}{result1, result2}
 }
 
+func (fake *FakeNetworkService) DeleteExpiredParkedPorts(arg1 time.Duration, arg2 properties.InstanceIdentity) error {
+	fake.deleteExpiredParkedPortsMutex.Lock()
+	ret, specificReturn := fake.deleteExpiredParkedPortsReturnsOnCall[len(fake.deleteExpiredParkedPortsArgsForCall)]
+	fake.deleteExpiredParkedPortsArgsForCall = append(fake.deleteExpiredParkedPortsArgsForCall, struct {
+		arg1 time.Duration
+		arg2 properties.InstanceIdentity
+	}{arg1, arg2})
+	stub := fake.DeleteExpiredParkedPortsStub
+	fakeReturns := fake.deleteExpiredParkedPortsReturns
+	fake.recordInvocation("DeleteExpiredParkedPorts", []interface{}{arg1, arg2})
+	fake.deleteExpiredParkedPortsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeNetworkService) DeleteExpiredParkedPortsCallCount() int {
+	fake.deleteExpiredParkedPortsMutex.RLock()
+	defer fake.deleteExpiredParkedPortsMutex.RUnlock()
+	return len(fake.deleteExpiredParkedPortsArgsForCall)
+}
+
+func (fake *FakeNetworkService) DeleteExpiredParkedPortsCalls(stub func(time.Duration, properties.InstanceIdentity) error) {
+	fake.deleteExpiredParkedPortsMutex.Lock()
+	defer fake.deleteExpiredParkedPortsMutex.Unlock()
+	fake.DeleteExpiredParkedPortsStub = stub
+}
+
+func (fake *FakeNetworkService) DeleteExpiredParkedPortsArgsForCall(i int) (time.Duration, properties.InstanceIdentity) {
+	fake.deleteExpiredParkedPortsMutex.RLock()
+	defer fake.deleteExpiredParkedPortsMutex.RUnlock()
+	argsForCall := fake.deleteExpiredParkedPortsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeNetworkService) DeleteExpiredParkedPortsReturns(result1 error) {
+	fake.deleteExpiredParkedPortsMutex.Lock()
+	defer fake.deleteExpiredParkedPortsMutex.Unlock()
+	fake.DeleteExpiredParkedPortsStub = nil
+	fake.deleteExpiredParkedPortsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworkService) DeleteExpiredParkedPortsReturnsOnCall(i int, result1 error) {
+	fake.deleteExpiredParkedPortsMutex.Lock()
+	defer fake.deleteExpiredParkedPortsMutex.Unlock()
+	fake.DeleteExpiredParkedPortsStub = nil
+	if fake.deleteExpiredParkedPortsReturnsOnCall == nil {
+		fake.deleteExpiredParkedPortsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteExpiredParkedPortsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeNetworkService) DeletePorts(arg1 []ports.Port) error {
 	var arg1Copy []ports.Port
 	if arg1 != nil {
@@ -297,6 +493,273 @@ func (fake *FakeNetworkService) DeletePortsReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeNetworkService) DeleteUnusedManagedSecurityGroups(arg1 []string) error {
+	var arg1Copy []string
+	if arg1 != nil {
+		arg1Copy = make([]string, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.deleteUnusedManagedSecurityGroupsMutex.Lock()
+	ret, specificReturn := fake.deleteUnusedManagedSecurityGroupsReturnsOnCall[len(fake.deleteUnusedManagedSecurityGroupsArgsForCall)]
+	fake.deleteUnusedManagedSecurityGroupsArgsForCall = append(fake.deleteUnusedManagedSecurityGroupsArgsForCall, struct {
+		arg1 []string
+	}{arg1Copy})
+	stub := fake.DeleteUnusedManagedSecurityGroupsStub
+	fakeReturns := fake.deleteUnusedManagedSecurityGroupsReturns
+	fake.recordInvocation("DeleteUnusedManagedSecurityGroups", []interface{}{arg1Copy})
+	fake.deleteUnusedManagedSecurityGroupsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeNetworkService) DeleteUnusedManagedSecurityGroupsCallCount() int {
+	fake.deleteUnusedManagedSecurityGroupsMutex.RLock()
+	defer fake.deleteUnusedManagedSecurityGroupsMutex.RUnlock()
+	return len(fake.deleteUnusedManagedSecurityGroupsArgsForCall)
+}
+
+func (fake *FakeNetworkService) DeleteUnusedManagedSecurityGroupsCalls(stub func([]string) error) {
+	fake.deleteUnusedManagedSecurityGroupsMutex.Lock()
+	defer fake.deleteUnusedManagedSecurityGroupsMutex.Unlock()
+	fake.DeleteUnusedManagedSecurityGroupsStub = stub
+}
+
+func (fake *FakeNetworkService) DeleteUnusedManagedSecurityGroupsArgsForCall(i int) []string {
+	fake.deleteUnusedManagedSecurityGroupsMutex.RLock()
+	defer fake.deleteUnusedManagedSecurityGroupsMutex.RUnlock()
+	argsForCall := fake.deleteUnusedManagedSecurityGroupsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeNetworkService) DeleteUnusedManagedSecurityGroupsReturns(result1 error) {
+	fake.deleteUnusedManagedSecurityGroupsMutex.Lock()
+	defer fake.deleteUnusedManagedSecurityGroupsMutex.Unlock()
+	fake.DeleteUnusedManagedSecurityGroupsStub = nil
+	fake.deleteUnusedManagedSecurityGroupsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworkService) DeleteUnusedManagedSecurityGroupsReturnsOnCall(i int, result1 error) {
+	fake.deleteUnusedManagedSecurityGroupsMutex.Lock()
+	defer fake.deleteUnusedManagedSecurityGroupsMutex.Unlock()
+	fake.DeleteUnusedManagedSecurityGroupsStub = nil
+	if fake.deleteUnusedManagedSecurityGroupsReturnsOnCall == nil {
+		fake.deleteUnusedManagedSecurityGroupsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteUnusedManagedSecurityGroupsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworkService) DisassociateFloatingIPs(arg1 []ports.Port) error {
+	var arg1Copy []ports.Port
+	if arg1 != nil {
+		arg1Copy = make([]ports.Port, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.disassociateFloatingIPsMutex.Lock()
+	ret, specificReturn := fake.disassociateFloatingIPsReturnsOnCall[len(fake.disassociateFloatingIPsArgsForCall)]
+	fake.disassociateFloatingIPsArgsForCall = append(fake.disassociateFloatingIPsArgsForCall, struct {
+		arg1 []ports.Port
+	}{arg1Copy})
+	stub := fake.DisassociateFloatingIPsStub
+	fakeReturns := fake.disassociateFloatingIPsReturns
+	fake.recordInvocation("DisassociateFloatingIPs", []interface{}{arg1Copy})
+	fake.disassociateFloatingIPsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeNetworkService) DisassociateFloatingIPsCallCount() int {
+	fake.disassociateFloatingIPsMutex.RLock()
+	defer fake.disassociateFloatingIPsMutex.RUnlock()
+	return len(fake.disassociateFloatingIPsArgsForCall)
+}
+
+func (fake *FakeNetworkService) DisassociateFloatingIPsCalls(stub func([]ports.Port) error) {
+	fake.disassociateFloatingIPsMutex.Lock()
+	defer fake.disassociateFloatingIPsMutex.Unlock()
+	fake.DisassociateFloatingIPsStub = stub
+}
+
+func (fake *FakeNetworkService) DisassociateFloatingIPsArgsForCall(i int) []ports.Port {
+	fake.disassociateFloatingIPsMutex.RLock()
+	defer fake.disassociateFloatingIPsMutex.RUnlock()
+	argsForCall := fake.disassociateFloatingIPsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeNetworkService) DisassociateFloatingIPsReturns(result1 error) {
+	fake.disassociateFloatingIPsMutex.Lock()
+	defer fake.disassociateFloatingIPsMutex.Unlock()
+	fake.DisassociateFloatingIPsStub = nil
+	fake.disassociateFloatingIPsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworkService) DisassociateFloatingIPsReturnsOnCall(i int, result1 error) {
+	fake.disassociateFloatingIPsMutex.Lock()
+	defer fake.disassociateFloatingIPsMutex.Unlock()
+	fake.DisassociateFloatingIPsStub = nil
+	if fake.disassociateFloatingIPsReturnsOnCall == nil {
+		fake.disassociateFloatingIPsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.disassociateFloatingIPsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworkService) EnsureManagedSecurityGroups(arg1 []properties.ManagedSecurityGroup, arg2 properties.BoshEnv) ([]string, error) {
+	var arg1Copy []properties.ManagedSecurityGroup
+	if arg1 != nil {
+		arg1Copy = make([]properties.ManagedSecurityGroup, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.ensureManagedSecurityGroupsMutex.Lock()
+	ret, specificReturn := fake.ensureManagedSecurityGroupsReturnsOnCall[len(fake.ensureManagedSecurityGroupsArgsForCall)]
+	fake.ensureManagedSecurityGroupsArgsForCall = append(fake.ensureManagedSecurityGroupsArgsForCall, struct {
+		arg1 []properties.ManagedSecurityGroup
+		arg2 properties.BoshEnv
+	}{arg1Copy, arg2})
+	stub := fake.EnsureManagedSecurityGroupsStub
+	fakeReturns := fake.ensureManagedSecurityGroupsReturns
+	fake.recordInvocation("EnsureManagedSecurityGroups", []interface{}{arg1Copy, arg2})
+	fake.ensureManagedSecurityGroupsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNetworkService) EnsureManagedSecurityGroupsCallCount() int {
+	fake.ensureManagedSecurityGroupsMutex.RLock()
+	defer fake.ensureManagedSecurityGroupsMutex.RUnlock()
+	return len(fake.ensureManagedSecurityGroupsArgsForCall)
+}
+
+func (fake *FakeNetworkService) EnsureManagedSecurityGroupsCalls(stub func([]properties.ManagedSecurityGroup, properties.BoshEnv) ([]string, error)) {
+	fake.ensureManagedSecurityGroupsMutex.Lock()
+	defer fake.ensureManagedSecurityGroupsMutex.Unlock()
+	fake.EnsureManagedSecurityGroupsStub = stub
+}
+
+func (fake *FakeNetworkService) EnsureManagedSecurityGroupsArgsForCall(i int) ([]properties.ManagedSecurityGroup, properties.BoshEnv) {
+	fake.ensureManagedSecurityGroupsMutex.RLock()
+	defer fake.ensureManagedSecurityGroupsMutex.RUnlock()
+	argsForCall := fake.ensureManagedSecurityGroupsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeNetworkService) EnsureManagedSecurityGroupsReturns(result1 []string, result2 error) {
+	fake.ensureManagedSecurityGroupsMutex.Lock()
+	defer fake.ensureManagedSecurityGroupsMutex.Unlock()
+	fake.EnsureManagedSecurityGroupsStub = nil
+	fake.ensureManagedSecurityGroupsReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworkService) EnsureManagedSecurityGroupsReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.ensureManagedSecurityGroupsMutex.Lock()
+	defer fake.ensureManagedSecurityGroupsMutex.Unlock()
+	fake.EnsureManagedSecurityGroupsStub = nil
+	if fake.ensureManagedSecurityGroupsReturnsOnCall == nil {
+		fake.ensureManagedSecurityGroupsReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.ensureManagedSecurityGroupsReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworkService) GetAdditionalIPs(arg1 properties.Network, arg2 ports.Port) ([]properties.AdditionalIP, error) {
+	fake.getAdditionalIPsMutex.Lock()
+	ret, specificReturn := fake.getAdditionalIPsReturnsOnCall[len(fake.getAdditionalIPsArgsForCall)]
+	fake.getAdditionalIPsArgsForCall = append(fake.getAdditionalIPsArgsForCall, struct {
+		arg1 properties.Network
+		arg2 ports.Port
+	}{arg1, arg2})
+	stub := fake.GetAdditionalIPsStub
+	fakeReturns := fake.getAdditionalIPsReturns
+	fake.recordInvocation("GetAdditionalIPs", []interface{}{arg1, arg2})
+	fake.getAdditionalIPsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNetworkService) GetAdditionalIPsCallCount() int {
+	fake.getAdditionalIPsMutex.RLock()
+	defer fake.getAdditionalIPsMutex.RUnlock()
+	return len(fake.getAdditionalIPsArgsForCall)
+}
+
+func (fake *FakeNetworkService) GetAdditionalIPsCalls(stub func(properties.Network, ports.Port) ([]properties.AdditionalIP, error)) {
+	fake.getAdditionalIPsMutex.Lock()
+	defer fake.getAdditionalIPsMutex.Unlock()
+	fake.GetAdditionalIPsStub = stub
+}
+
+func (fake *FakeNetworkService) GetAdditionalIPsArgsForCall(i int) (properties.Network, ports.Port) {
+	fake.getAdditionalIPsMutex.RLock()
+	defer fake.getAdditionalIPsMutex.RUnlock()
+	argsForCall := fake.getAdditionalIPsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeNetworkService) GetAdditionalIPsReturns(result1 []properties.AdditionalIP, result2 error) {
+	fake.getAdditionalIPsMutex.Lock()
+	defer fake.getAdditionalIPsMutex.Unlock()
+	fake.GetAdditionalIPsStub = nil
+	fake.getAdditionalIPsReturns = struct {
+		result1 []properties.AdditionalIP
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworkService) GetAdditionalIPsReturnsOnCall(i int, result1 []properties.AdditionalIP, result2 error) {
+	fake.getAdditionalIPsMutex.Lock()
+	defer fake.getAdditionalIPsMutex.Unlock()
+	fake.GetAdditionalIPsStub = nil
+	if fake.getAdditionalIPsReturnsOnCall == nil {
+		fake.getAdditionalIPsReturnsOnCall = make(map[int]struct {
+			result1 []properties.AdditionalIP
+			result2 error
+		})
+	}
+	fake.getAdditionalIPsReturnsOnCall[i] = struct {
+		result1 []properties.AdditionalIP
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeNetworkService) GetNetworkConfiguration(arg1 apiv1.Networks, arg2 config.OpenstackConfig, arg3 properties.CreateVM) (properties.NetworkConfig, error) {
 	fake.getNetworkConfigurationMutex.Lock()
 	ret, specificReturn := fake.getNetworkConfigurationReturnsOnCall[len(fake.getNetworkConfigurationArgsForCall)]
@@ -363,6 +826,70 @@ func (fake *FakeNetworkService) GetNetworkConfigurationReturnsOnCall(i int, resu
 	}{result1, result2}
 }
 
+func (fake *FakeNetworkService) GetNetworkSettings(arg1 properties.Network) (properties.NetworkSettings, error) {
+	fake.getNetworkSettingsMutex.Lock()
+	ret, specificReturn := fake.getNetworkSettingsReturnsOnCall[len(fake.getNetworkSettingsArgsForCall)]
+	fake.getNetworkSettingsArgsForCall = append(fake.getNetworkSettingsArgsForCall, struct {
+		arg1 properties.Network
+	}{arg1})
+	stub := fake.GetNetworkSettingsStub
+	fakeReturns := fake.getNetworkSettingsReturns
+	fake.recordInvocation("GetNetworkSettings", []interface{}{arg1})
+	fake.getNetworkSettingsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNetworkService) GetNetworkSettingsCallCount() int {
+	fake.getNetworkSettingsMutex.RLock()
+	defer fake.getNetworkSettingsMutex.RUnlock()
+	return len(fake.getNetworkSettingsArgsForCall)
+}
+
+func (fake *FakeNetworkService) GetNetworkSettingsCalls(stub func(properties.Network) (properties.NetworkSettings, error)) {
+	fake.getNetworkSettingsMutex.Lock()
+	defer fake.getNetworkSettingsMutex.Unlock()
+	fake.GetNetworkSettingsStub = stub
+}
+
+func (fake *FakeNetworkService) GetNetworkSettingsArgsForCall(i int) properties.Network {
+	fake.getNetworkSettingsMutex.RLock()
+	defer fake.getNetworkSettingsMutex.RUnlock()
+	argsForCall := fake.getNetworkSettingsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeNetworkService) GetNetworkSettingsReturns(result1 properties.NetworkSettings, result2 error) {
+	fake.getNetworkSettingsMutex.Lock()
+	defer fake.getNetworkSettingsMutex.Unlock()
+	fake.GetNetworkSettingsStub = nil
+	fake.getNetworkSettingsReturns = struct {
+		result1 properties.NetworkSettings
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworkService) GetNetworkSettingsReturnsOnCall(i int, result1 properties.NetworkSettings, result2 error) {
+	fake.getNetworkSettingsMutex.Lock()
+	defer fake.getNetworkSettingsMutex.Unlock()
+	fake.GetNetworkSettingsStub = nil
+	if fake.getNetworkSettingsReturnsOnCall == nil {
+		fake.getNetworkSettingsReturnsOnCall = make(map[int]struct {
+			result1 properties.NetworkSettings
+			result2 error
+		})
+	}
+	fake.getNetworkSettingsReturnsOnCall[i] = struct {
+		result1 properties.NetworkSettings
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeNetworkService) GetPorts(arg1 string, arg2 properties.Network, arg3 bool) ([]ports.Port, error) {
 	fake.getPortsMutex.Lock()
 	ret, specificReturn := fake.getPortsReturnsOnCall[len(fake.getPortsArgsForCall)]
@@ -429,6 +956,70 @@ func (fake *FakeNetworkService) GetPortsReturnsOnCall(i int, result1 []ports.Por
 	}{result1, result2}
 }
 
+func (fake *FakeNetworkService) GetPreCreatedPort(arg1 properties.Network) (ports.Port, error) {
+	fake.getPreCreatedPortMutex.Lock()
+	ret, specificReturn := fake.getPreCreatedPortReturnsOnCall[len(fake.getPreCreatedPortArgsForCall)]
+	fake.getPreCreatedPortArgsForCall = append(fake.getPreCreatedPortArgsForCall, struct {
+		arg1 properties.Network
+	}{arg1})
+	stub := fake.GetPreCreatedPortStub
+	fakeReturns := fake.getPreCreatedPortReturns
+	fake.recordInvocation("GetPreCreatedPort", []interface{}{arg1})
+	fake.getPreCreatedPortMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNetworkService) GetPreCreatedPortCallCount() int {
+	fake.getPreCreatedPortMutex.RLock()
+	defer fake.getPreCreatedPortMutex.RUnlock()
+	return len(fake.getPreCreatedPortArgsForCall)
+}
+
+func (fake *FakeNetworkService) GetPreCreatedPortCalls(stub func(properties.Network) (ports.Port, error)) {
+	fake.getPreCreatedPortMutex.Lock()
+	defer fake.getPreCreatedPortMutex.Unlock()
+	fake.GetPreCreatedPortStub = stub
+}
+
+func (fake *FakeNetworkService) GetPreCreatedPortArgsForCall(i int) properties.Network {
+	fake.getPreCreatedPortMutex.RLock()
+	defer fake.getPreCreatedPortMutex.RUnlock()
+	argsForCall := fake.getPreCreatedPortArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeNetworkService) GetPreCreatedPortReturns(result1 ports.Port, result2 error) {
+	fake.getPreCreatedPortMutex.Lock()
+	defer fake.getPreCreatedPortMutex.Unlock()
+	fake.GetPreCreatedPortStub = nil
+	fake.getPreCreatedPortReturns = struct {
+		result1 ports.Port
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworkService) GetPreCreatedPortReturnsOnCall(i int, result1 ports.Port, result2 error) {
+	fake.getPreCreatedPortMutex.Lock()
+	defer fake.getPreCreatedPortMutex.Unlock()
+	fake.GetPreCreatedPortStub = nil
+	if fake.getPreCreatedPortReturnsOnCall == nil {
+		fake.getPreCreatedPortReturnsOnCall = make(map[int]struct {
+			result1 ports.Port
+			result2 error
+		})
+	}
+	fake.getPreCreatedPortReturnsOnCall[i] = struct {
+		result1 ports.Port
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeNetworkService) GetSubnetID(arg1 string, arg2 string) (string, error) {
 	fake.getSubnetIDMutex.Lock()
 	ret, specificReturn := fake.getSubnetIDReturnsOnCall[len(fake.getSubnetIDArgsForCall)]
@@ -494,21 +1085,203 @@ func (fake *FakeNetworkService) GetSubnetIDReturnsOnCall(i int, result1 string,
 	}{result1, result2}
 }
 
+func (fake *FakeNetworkService) ParkPorts(arg1 []ports.Port) error {
+	var arg1Copy []ports.Port
+	if arg1 != nil {
+		arg1Copy = make([]ports.Port, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.parkPortsMutex.Lock()
+	ret, specificReturn := fake.parkPortsReturnsOnCall[len(fake.parkPortsArgsForCall)]
+	fake.parkPortsArgsForCall = append(fake.parkPortsArgsForCall, struct {
+		arg1 []ports.Port
+	}{arg1Copy})
+	stub := fake.ParkPortsStub
+	fakeReturns := fake.parkPortsReturns
+	fake.recordInvocation("ParkPorts", []interface{}{arg1Copy})
+	fake.parkPortsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeNetworkService) ParkPortsCallCount() int {
+	fake.parkPortsMutex.RLock()
+	defer fake.parkPortsMutex.RUnlock()
+	return len(fake.parkPortsArgsForCall)
+}
+
+func (fake *FakeNetworkService) ParkPortsCalls(stub func([]ports.Port) error) {
+	fake.parkPortsMutex.Lock()
+	defer fake.parkPortsMutex.Unlock()
+	fake.ParkPortsStub = stub
+}
+
+func (fake *FakeNetworkService) ParkPortsArgsForCall(i int) []ports.Port {
+	fake.parkPortsMutex.RLock()
+	defer fake.parkPortsMutex.RUnlock()
+	argsForCall := fake.parkPortsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeNetworkService) ParkPortsReturns(result1 error) {
+	fake.parkPortsMutex.Lock()
+	defer fake.parkPortsMutex.Unlock()
+	fake.ParkPortsStub = nil
+	fake.parkPortsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworkService) ParkPortsReturnsOnCall(i int, result1 error) {
+	fake.parkPortsMutex.Lock()
+	defer fake.parkPortsMutex.Unlock()
+	fake.ParkPortsStub = nil
+	if fake.parkPortsReturnsOnCall == nil {
+		fake.parkPortsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.parkPortsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworkService) ReleaseFloatingIPs(arg1 string) error {
+	fake.releaseFloatingIPsMutex.Lock()
+	ret, specificReturn := fake.releaseFloatingIPsReturnsOnCall[len(fake.releaseFloatingIPsArgsForCall)]
+	fake.releaseFloatingIPsArgsForCall = append(fake.releaseFloatingIPsArgsForCall, struct {
+		arg1 string
+	}{arg1})
+	stub := fake.ReleaseFloatingIPsStub
+	fakeReturns := fake.releaseFloatingIPsReturns
+	fake.recordInvocation("ReleaseFloatingIPs", []interface{}{arg1})
+	fake.releaseFloatingIPsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeNetworkService) ReleaseFloatingIPsCallCount() int {
+	fake.releaseFloatingIPsMutex.RLock()
+	defer fake.releaseFloatingIPsMutex.RUnlock()
+	return len(fake.releaseFloatingIPsArgsForCall)
+}
+
+func (fake *FakeNetworkService) ReleaseFloatingIPsCalls(stub func(string) error) {
+	fake.releaseFloatingIPsMutex.Lock()
+	defer fake.releaseFloatingIPsMutex.Unlock()
+	fake.ReleaseFloatingIPsStub = stub
+}
+
+func (fake *FakeNetworkService) ReleaseFloatingIPsArgsForCall(i int) string {
+	fake.releaseFloatingIPsMutex.RLock()
+	defer fake.releaseFloatingIPsMutex.RUnlock()
+	argsForCall := fake.releaseFloatingIPsArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeNetworkService) ReleaseFloatingIPsReturns(result1 error) {
+	fake.releaseFloatingIPsMutex.Lock()
+	defer fake.releaseFloatingIPsMutex.Unlock()
+	fake.ReleaseFloatingIPsStub = nil
+	fake.releaseFloatingIPsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworkService) ReleaseFloatingIPsReturnsOnCall(i int, result1 error) {
+	fake.releaseFloatingIPsMutex.Lock()
+	defer fake.releaseFloatingIPsMutex.Unlock()
+	fake.ReleaseFloatingIPsStub = nil
+	if fake.releaseFloatingIPsReturnsOnCall == nil {
+		fake.releaseFloatingIPsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.releaseFloatingIPsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworkService) SetMetadataTags(arg1 []ports.Port, arg2 map[string]string) error {
+	var arg1Copy []ports.Port
+	if arg1 != nil {
+		arg1Copy = make([]ports.Port, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.setMetadataTagsMutex.Lock()
+	ret, specificReturn := fake.setMetadataTagsReturnsOnCall[len(fake.setMetadataTagsArgsForCall)]
+	fake.setMetadataTagsArgsForCall = append(fake.setMetadataTagsArgsForCall, struct {
+		arg1 []ports.Port
+		arg2 map[string]string
+	}{arg1Copy, arg2})
+	stub := fake.SetMetadataTagsStub
+	fakeReturns := fake.setMetadataTagsReturns
+	fake.recordInvocation("SetMetadataTags", []interface{}{arg1Copy, arg2})
+	fake.setMetadataTagsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeNetworkService) SetMetadataTagsCallCount() int {
+	fake.setMetadataTagsMutex.RLock()
+	defer fake.setMetadataTagsMutex.RUnlock()
+	return len(fake.setMetadataTagsArgsForCall)
+}
+
+func (fake *FakeNetworkService) SetMetadataTagsCalls(stub func([]ports.Port, map[string]string) error) {
+	fake.setMetadataTagsMutex.Lock()
+	defer fake.setMetadataTagsMutex.Unlock()
+	fake.SetMetadataTagsStub = stub
+}
+
+func (fake *FakeNetworkService) SetMetadataTagsArgsForCall(i int) ([]ports.Port, map[string]string) {
+	fake.setMetadataTagsMutex.RLock()
+	defer fake.setMetadataTagsMutex.RUnlock()
+	argsForCall := fake.setMetadataTagsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeNetworkService) SetMetadataTagsReturns(result1 error) {
+	fake.setMetadataTagsMutex.Lock()
+	defer fake.setMetadataTagsMutex.Unlock()
+	fake.SetMetadataTagsStub = nil
+	fake.setMetadataTagsReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworkService) SetMetadataTagsReturnsOnCall(i int, result1 error) {
+	fake.setMetadataTagsMutex.Lock()
+	defer fake.setMetadataTagsMutex.Unlock()
+	fake.SetMetadataTagsStub = nil
+	if fake.setMetadataTagsReturnsOnCall == nil {
+		fake.setMetadataTagsReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.setMetadataTagsReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeNetworkService) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.configureVIPNetworkMutex.RLock()
-	defer fake.configureVIPNetworkMutex.RUnlock()
-	fake.createPortMutex.RLock()
-	defer fake.createPortMutex.RUnlock()
-	fake.deletePortsMutex.RLock()
-	defer fake.deletePortsMutex.RUnlock()
-	fake.getNetworkConfigurationMutex.RLock()
-	defer fake.getNetworkConfigurationMutex.RUnlock()
-	fake.getPortsMutex.RLock()
-	defer fake.getPortsMutex.RUnlock()
-	fake.getSubnetIDMutex.RLock()
-	defer fake.getSubnetIDMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
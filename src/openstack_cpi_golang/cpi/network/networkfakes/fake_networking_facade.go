@@ -8,17 +8,33 @@ import (
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/floatingips"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/groups"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/networks"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/subnets"
 	"github.com/gophercloud/gophercloud/pagination"
 )
 
 type FakeNetworkingFacade struct {
-	CreatePortStub        func(utils.ServiceClient, ports.CreateOpts) (*ports.Port, error)
+	CreateFloatingIPStub        func(utils.ServiceClient, floatingips.CreateOpts) (*floatingips.FloatingIP, error)
+	createFloatingIPMutex       sync.RWMutex
+	createFloatingIPArgsForCall []struct {
+		arg1 utils.ServiceClient
+		arg2 floatingips.CreateOpts
+	}
+	createFloatingIPReturns struct {
+		result1 *floatingips.FloatingIP
+		result2 error
+	}
+	createFloatingIPReturnsOnCall map[int]struct {
+		result1 *floatingips.FloatingIP
+		result2 error
+	}
+	CreatePortStub        func(utils.ServiceClient, ports.CreateOptsBuilder) (*ports.Port, error)
 	createPortMutex       sync.RWMutex
 	createPortArgsForCall []struct {
 		arg1 utils.ServiceClient
-		arg2 ports.CreateOpts
+		arg2 ports.CreateOptsBuilder
 	}
 	createPortReturns struct {
 		result1 *ports.Port
@@ -28,6 +44,46 @@ type FakeNetworkingFacade struct {
 		result1 *ports.Port
 		result2 error
 	}
+	CreateSecurityGroupStub        func(utils.ServiceClient, groups.CreateOpts) (*groups.SecGroup, error)
+	createSecurityGroupMutex       sync.RWMutex
+	createSecurityGroupArgsForCall []struct {
+		arg1 utils.ServiceClient
+		arg2 groups.CreateOpts
+	}
+	createSecurityGroupReturns struct {
+		result1 *groups.SecGroup
+		result2 error
+	}
+	createSecurityGroupReturnsOnCall map[int]struct {
+		result1 *groups.SecGroup
+		result2 error
+	}
+	CreateSecurityGroupRuleStub        func(utils.ServiceClient, rules.CreateOpts) (*rules.SecGroupRule, error)
+	createSecurityGroupRuleMutex       sync.RWMutex
+	createSecurityGroupRuleArgsForCall []struct {
+		arg1 utils.ServiceClient
+		arg2 rules.CreateOpts
+	}
+	createSecurityGroupRuleReturns struct {
+		result1 *rules.SecGroupRule
+		result2 error
+	}
+	createSecurityGroupRuleReturnsOnCall map[int]struct {
+		result1 *rules.SecGroupRule
+		result2 error
+	}
+	DeleteFloatingIPStub        func(utils.RetryableServiceClient, string) error
+	deleteFloatingIPMutex       sync.RWMutex
+	deleteFloatingIPArgsForCall []struct {
+		arg1 utils.RetryableServiceClient
+		arg2 string
+	}
+	deleteFloatingIPReturns struct {
+		result1 error
+	}
+	deleteFloatingIPReturnsOnCall map[int]struct {
+		result1 error
+	}
 	DeletePortStub        func(utils.RetryableServiceClient, string) error
 	deletePortMutex       sync.RWMutex
 	deletePortArgsForCall []struct {
@@ -40,6 +96,30 @@ type FakeNetworkingFacade struct {
 	deletePortReturnsOnCall map[int]struct {
 		result1 error
 	}
+	DeleteSecurityGroupStub        func(utils.RetryableServiceClient, string) error
+	deleteSecurityGroupMutex       sync.RWMutex
+	deleteSecurityGroupArgsForCall []struct {
+		arg1 utils.RetryableServiceClient
+		arg2 string
+	}
+	deleteSecurityGroupReturns struct {
+		result1 error
+	}
+	deleteSecurityGroupReturnsOnCall map[int]struct {
+		result1 error
+	}
+	DeleteSecurityGroupRuleStub        func(utils.RetryableServiceClient, string) error
+	deleteSecurityGroupRuleMutex       sync.RWMutex
+	deleteSecurityGroupRuleArgsForCall []struct {
+		arg1 utils.RetryableServiceClient
+		arg2 string
+	}
+	deleteSecurityGroupRuleReturns struct {
+		result1 error
+	}
+	deleteSecurityGroupRuleReturnsOnCall map[int]struct {
+		result1 error
+	}
 	ExtractFloatingIPsStub        func(pagination.Page) ([]floatingips.FloatingIP, error)
 	extractFloatingIPsMutex       sync.RWMutex
 	extractFloatingIPsArgsForCall []struct {
@@ -53,6 +133,19 @@ type FakeNetworkingFacade struct {
 		result1 []floatingips.FloatingIP
 		result2 error
 	}
+	ExtractNetworksStub        func(pagination.Page) ([]networks.Network, error)
+	extractNetworksMutex       sync.RWMutex
+	extractNetworksArgsForCall []struct {
+		arg1 pagination.Page
+	}
+	extractNetworksReturns struct {
+		result1 []networks.Network
+		result2 error
+	}
+	extractNetworksReturnsOnCall map[int]struct {
+		result1 []networks.Network
+		result2 error
+	}
 	ExtractPortsStub        func(pagination.Page) ([]ports.Port, error)
 	extractPortsMutex       sync.RWMutex
 	extractPortsArgsForCall []struct {
@@ -92,6 +185,48 @@ type FakeNetworkingFacade struct {
 		result1 []subnets.Subnet
 		result2 error
 	}
+	GetNetworkWithMTUStub        func(utils.RetryableServiceClient, string) (*network.NetworkWithMTU, error)
+	getNetworkWithMTUMutex       sync.RWMutex
+	getNetworkWithMTUArgsForCall []struct {
+		arg1 utils.RetryableServiceClient
+		arg2 string
+	}
+	getNetworkWithMTUReturns struct {
+		result1 *network.NetworkWithMTU
+		result2 error
+	}
+	getNetworkWithMTUReturnsOnCall map[int]struct {
+		result1 *network.NetworkWithMTU
+		result2 error
+	}
+	GetPortStub        func(utils.RetryableServiceClient, string) (*ports.Port, error)
+	getPortMutex       sync.RWMutex
+	getPortArgsForCall []struct {
+		arg1 utils.RetryableServiceClient
+		arg2 string
+	}
+	getPortReturns struct {
+		result1 *ports.Port
+		result2 error
+	}
+	getPortReturnsOnCall map[int]struct {
+		result1 *ports.Port
+		result2 error
+	}
+	GetPortWithExtensionsStub        func(utils.RetryableServiceClient, string) (*network.PortWithExtensions, error)
+	getPortWithExtensionsMutex       sync.RWMutex
+	getPortWithExtensionsArgsForCall []struct {
+		arg1 utils.RetryableServiceClient
+		arg2 string
+	}
+	getPortWithExtensionsReturns struct {
+		result1 *network.PortWithExtensions
+		result2 error
+	}
+	getPortWithExtensionsReturnsOnCall map[int]struct {
+		result1 *network.PortWithExtensions
+		result2 error
+	}
 	GetSecurityGroupsStub        func(utils.RetryableServiceClient, string) (*groups.SecGroup, error)
 	getSecurityGroupsMutex       sync.RWMutex
 	getSecurityGroupsArgsForCall []struct {
@@ -120,6 +255,20 @@ type FakeNetworkingFacade struct {
 		result1 pagination.Page
 		result2 error
 	}
+	ListNetworksStub        func(utils.RetryableServiceClient, networks.ListOpts) (pagination.Page, error)
+	listNetworksMutex       sync.RWMutex
+	listNetworksArgsForCall []struct {
+		arg1 utils.RetryableServiceClient
+		arg2 networks.ListOpts
+	}
+	listNetworksReturns struct {
+		result1 pagination.Page
+		result2 error
+	}
+	listNetworksReturnsOnCall map[int]struct {
+		result1 pagination.Page
+		result2 error
+	}
 	ListPortsStub        func(utils.RetryableServiceClient, ports.ListOpts) (pagination.Page, error)
 	listPortsMutex       sync.RWMutex
 	listPortsArgsForCall []struct {
@@ -162,6 +311,22 @@ type FakeNetworkingFacade struct {
 		result1 pagination.Page
 		result2 error
 	}
+	ReplaceAllTagsStub        func(utils.ServiceClient, string, string, []string) ([]string, error)
+	replaceAllTagsMutex       sync.RWMutex
+	replaceAllTagsArgsForCall []struct {
+		arg1 utils.ServiceClient
+		arg2 string
+		arg3 string
+		arg4 []string
+	}
+	replaceAllTagsReturns struct {
+		result1 []string
+		result2 error
+	}
+	replaceAllTagsReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
 	UpdateFloatingIPStub        func(utils.ServiceClient, string, floatingips.UpdateOpts) (*floatingips.FloatingIP, error)
 	updateFloatingIPMutex       sync.RWMutex
 	updateFloatingIPArgsForCall []struct {
@@ -177,16 +342,96 @@ type FakeNetworkingFacade struct {
 		result1 *floatingips.FloatingIP
 		result2 error
 	}
+	UpdatePortStub        func(utils.ServiceClient, string, ports.UpdateOptsBuilder) (*ports.Port, error)
+	updatePortMutex       sync.RWMutex
+	updatePortArgsForCall []struct {
+		arg1 utils.ServiceClient
+		arg2 string
+		arg3 ports.UpdateOptsBuilder
+	}
+	updatePortReturns struct {
+		result1 *ports.Port
+		result2 error
+	}
+	updatePortReturnsOnCall map[int]struct {
+		result1 *ports.Port
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeNetworkingFacade) CreatePort(arg1 utils.ServiceClient, arg2 ports.CreateOpts) (*ports.Port, error) {
+func (fake *FakeNetworkingFacade) CreateFloatingIP(arg1 utils.ServiceClient, arg2 floatingips.CreateOpts) (*floatingips.FloatingIP, error) {
+	fake.createFloatingIPMutex.Lock()
+	ret, specificReturn := fake.createFloatingIPReturnsOnCall[len(fake.createFloatingIPArgsForCall)]
+	fake.createFloatingIPArgsForCall = append(fake.createFloatingIPArgsForCall, struct {
+		arg1 utils.ServiceClient
+		arg2 floatingips.CreateOpts
+	}{arg1, arg2})
+	stub := fake.CreateFloatingIPStub
+	fakeReturns := fake.createFloatingIPReturns
+	fake.recordInvocation("CreateFloatingIP", []interface{}{arg1, arg2})
+	fake.createFloatingIPMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNetworkingFacade) CreateFloatingIPCallCount() int {
+	fake.createFloatingIPMutex.RLock()
+	defer fake.createFloatingIPMutex.RUnlock()
+	return len(fake.createFloatingIPArgsForCall)
+}
+
+func (fake *FakeNetworkingFacade) CreateFloatingIPCalls(stub func(utils.ServiceClient, floatingips.CreateOpts) (*floatingips.FloatingIP, error)) {
+	fake.createFloatingIPMutex.Lock()
+	defer fake.createFloatingIPMutex.Unlock()
+	fake.CreateFloatingIPStub = stub
+}
+
+func (fake *FakeNetworkingFacade) CreateFloatingIPArgsForCall(i int) (utils.ServiceClient, floatingips.CreateOpts) {
+	fake.createFloatingIPMutex.RLock()
+	defer fake.createFloatingIPMutex.RUnlock()
+	argsForCall := fake.createFloatingIPArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeNetworkingFacade) CreateFloatingIPReturns(result1 *floatingips.FloatingIP, result2 error) {
+	fake.createFloatingIPMutex.Lock()
+	defer fake.createFloatingIPMutex.Unlock()
+	fake.CreateFloatingIPStub = nil
+	fake.createFloatingIPReturns = struct {
+		result1 *floatingips.FloatingIP
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworkingFacade) CreateFloatingIPReturnsOnCall(i int, result1 *floatingips.FloatingIP, result2 error) {
+	fake.createFloatingIPMutex.Lock()
+	defer fake.createFloatingIPMutex.Unlock()
+	fake.CreateFloatingIPStub = nil
+	if fake.createFloatingIPReturnsOnCall == nil {
+		fake.createFloatingIPReturnsOnCall = make(map[int]struct {
+			result1 *floatingips.FloatingIP
+			result2 error
+		})
+	}
+	fake.createFloatingIPReturnsOnCall[i] = struct {
+		result1 *floatingips.FloatingIP
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworkingFacade) CreatePort(arg1 utils.ServiceClient, arg2 ports.CreateOptsBuilder) (*ports.Port, error) {
 	fake.createPortMutex.Lock()
 	ret, specificReturn := fake.createPortReturnsOnCall[len(fake.createPortArgsForCall)]
 	fake.createPortArgsForCall = append(fake.createPortArgsForCall, struct {
 		arg1 utils.ServiceClient
-		arg2 ports.CreateOpts
+		arg2 ports.CreateOptsBuilder
 	}{arg1, arg2})
 	stub := fake.CreatePortStub
 	fakeReturns := fake.createPortReturns
@@ -207,13 +452,13 @@ func (fake *FakeNetworkingFacade) CreatePortCallCount() int {
 	return len(fake.createPortArgsForCall)
 }
 
-func (fake *FakeNetworkingFacade) CreatePortCalls(stub func(utils.ServiceClient, ports.CreateOpts) (*ports.Port, error)) {
+func (fake *FakeNetworkingFacade) CreatePortCalls(stub func(utils.ServiceClient, ports.CreateOptsBuilder) (*ports.Port, error)) {
 	fake.createPortMutex.Lock()
 	defer fake.createPortMutex.Unlock()
 	fake.CreatePortStub = stub
 }
 
-func (fake *FakeNetworkingFacade) CreatePortArgsForCall(i int) (utils.ServiceClient, ports.CreateOpts) {
+func (fake *FakeNetworkingFacade) CreatePortArgsForCall(i int) (utils.ServiceClient, ports.CreateOptsBuilder) {
 	fake.createPortMutex.RLock()
 	defer fake.createPortMutex.RUnlock()
 	argsForCall := fake.createPortArgsForCall[i]
@@ -246,6 +491,198 @@ func (fake *FakeNetworkingFacade) CreatePortReturnsOnCall(i int, result1 *ports.
 	}{result1, result2}
 }
 
+func (fake *FakeNetworkingFacade) CreateSecurityGroup(arg1 utils.ServiceClient, arg2 groups.CreateOpts) (*groups.SecGroup, error) {
+	fake.createSecurityGroupMutex.Lock()
+	ret, specificReturn := fake.createSecurityGroupReturnsOnCall[len(fake.createSecurityGroupArgsForCall)]
+	fake.createSecurityGroupArgsForCall = append(fake.createSecurityGroupArgsForCall, struct {
+		arg1 utils.ServiceClient
+		arg2 groups.CreateOpts
+	}{arg1, arg2})
+	stub := fake.CreateSecurityGroupStub
+	fakeReturns := fake.createSecurityGroupReturns
+	fake.recordInvocation("CreateSecurityGroup", []interface{}{arg1, arg2})
+	fake.createSecurityGroupMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNetworkingFacade) CreateSecurityGroupCallCount() int {
+	fake.createSecurityGroupMutex.RLock()
+	defer fake.createSecurityGroupMutex.RUnlock()
+	return len(fake.createSecurityGroupArgsForCall)
+}
+
+func (fake *FakeNetworkingFacade) CreateSecurityGroupCalls(stub func(utils.ServiceClient, groups.CreateOpts) (*groups.SecGroup, error)) {
+	fake.createSecurityGroupMutex.Lock()
+	defer fake.createSecurityGroupMutex.Unlock()
+	fake.CreateSecurityGroupStub = stub
+}
+
+func (fake *FakeNetworkingFacade) CreateSecurityGroupArgsForCall(i int) (utils.ServiceClient, groups.CreateOpts) {
+	fake.createSecurityGroupMutex.RLock()
+	defer fake.createSecurityGroupMutex.RUnlock()
+	argsForCall := fake.createSecurityGroupArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeNetworkingFacade) CreateSecurityGroupReturns(result1 *groups.SecGroup, result2 error) {
+	fake.createSecurityGroupMutex.Lock()
+	defer fake.createSecurityGroupMutex.Unlock()
+	fake.CreateSecurityGroupStub = nil
+	fake.createSecurityGroupReturns = struct {
+		result1 *groups.SecGroup
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworkingFacade) CreateSecurityGroupReturnsOnCall(i int, result1 *groups.SecGroup, result2 error) {
+	fake.createSecurityGroupMutex.Lock()
+	defer fake.createSecurityGroupMutex.Unlock()
+	fake.CreateSecurityGroupStub = nil
+	if fake.createSecurityGroupReturnsOnCall == nil {
+		fake.createSecurityGroupReturnsOnCall = make(map[int]struct {
+			result1 *groups.SecGroup
+			result2 error
+		})
+	}
+	fake.createSecurityGroupReturnsOnCall[i] = struct {
+		result1 *groups.SecGroup
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworkingFacade) CreateSecurityGroupRule(arg1 utils.ServiceClient, arg2 rules.CreateOpts) (*rules.SecGroupRule, error) {
+	fake.createSecurityGroupRuleMutex.Lock()
+	ret, specificReturn := fake.createSecurityGroupRuleReturnsOnCall[len(fake.createSecurityGroupRuleArgsForCall)]
+	fake.createSecurityGroupRuleArgsForCall = append(fake.createSecurityGroupRuleArgsForCall, struct {
+		arg1 utils.ServiceClient
+		arg2 rules.CreateOpts
+	}{arg1, arg2})
+	stub := fake.CreateSecurityGroupRuleStub
+	fakeReturns := fake.createSecurityGroupRuleReturns
+	fake.recordInvocation("CreateSecurityGroupRule", []interface{}{arg1, arg2})
+	fake.createSecurityGroupRuleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNetworkingFacade) CreateSecurityGroupRuleCallCount() int {
+	fake.createSecurityGroupRuleMutex.RLock()
+	defer fake.createSecurityGroupRuleMutex.RUnlock()
+	return len(fake.createSecurityGroupRuleArgsForCall)
+}
+
+func (fake *FakeNetworkingFacade) CreateSecurityGroupRuleCalls(stub func(utils.ServiceClient, rules.CreateOpts) (*rules.SecGroupRule, error)) {
+	fake.createSecurityGroupRuleMutex.Lock()
+	defer fake.createSecurityGroupRuleMutex.Unlock()
+	fake.CreateSecurityGroupRuleStub = stub
+}
+
+func (fake *FakeNetworkingFacade) CreateSecurityGroupRuleArgsForCall(i int) (utils.ServiceClient, rules.CreateOpts) {
+	fake.createSecurityGroupRuleMutex.RLock()
+	defer fake.createSecurityGroupRuleMutex.RUnlock()
+	argsForCall := fake.createSecurityGroupRuleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeNetworkingFacade) CreateSecurityGroupRuleReturns(result1 *rules.SecGroupRule, result2 error) {
+	fake.createSecurityGroupRuleMutex.Lock()
+	defer fake.createSecurityGroupRuleMutex.Unlock()
+	fake.CreateSecurityGroupRuleStub = nil
+	fake.createSecurityGroupRuleReturns = struct {
+		result1 *rules.SecGroupRule
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworkingFacade) CreateSecurityGroupRuleReturnsOnCall(i int, result1 *rules.SecGroupRule, result2 error) {
+	fake.createSecurityGroupRuleMutex.Lock()
+	defer fake.createSecurityGroupRuleMutex.Unlock()
+	fake.CreateSecurityGroupRuleStub = nil
+	if fake.createSecurityGroupRuleReturnsOnCall == nil {
+		fake.createSecurityGroupRuleReturnsOnCall = make(map[int]struct {
+			result1 *rules.SecGroupRule
+			result2 error
+		})
+	}
+	fake.createSecurityGroupRuleReturnsOnCall[i] = struct {
+		result1 *rules.SecGroupRule
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworkingFacade) DeleteFloatingIP(arg1 utils.RetryableServiceClient, arg2 string) error {
+	fake.deleteFloatingIPMutex.Lock()
+	ret, specificReturn := fake.deleteFloatingIPReturnsOnCall[len(fake.deleteFloatingIPArgsForCall)]
+	fake.deleteFloatingIPArgsForCall = append(fake.deleteFloatingIPArgsForCall, struct {
+		arg1 utils.RetryableServiceClient
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DeleteFloatingIPStub
+	fakeReturns := fake.deleteFloatingIPReturns
+	fake.recordInvocation("DeleteFloatingIP", []interface{}{arg1, arg2})
+	fake.deleteFloatingIPMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeNetworkingFacade) DeleteFloatingIPCallCount() int {
+	fake.deleteFloatingIPMutex.RLock()
+	defer fake.deleteFloatingIPMutex.RUnlock()
+	return len(fake.deleteFloatingIPArgsForCall)
+}
+
+func (fake *FakeNetworkingFacade) DeleteFloatingIPCalls(stub func(utils.RetryableServiceClient, string) error) {
+	fake.deleteFloatingIPMutex.Lock()
+	defer fake.deleteFloatingIPMutex.Unlock()
+	fake.DeleteFloatingIPStub = stub
+}
+
+func (fake *FakeNetworkingFacade) DeleteFloatingIPArgsForCall(i int) (utils.RetryableServiceClient, string) {
+	fake.deleteFloatingIPMutex.RLock()
+	defer fake.deleteFloatingIPMutex.RUnlock()
+	argsForCall := fake.deleteFloatingIPArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeNetworkingFacade) DeleteFloatingIPReturns(result1 error) {
+	fake.deleteFloatingIPMutex.Lock()
+	defer fake.deleteFloatingIPMutex.Unlock()
+	fake.DeleteFloatingIPStub = nil
+	fake.deleteFloatingIPReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworkingFacade) DeleteFloatingIPReturnsOnCall(i int, result1 error) {
+	fake.deleteFloatingIPMutex.Lock()
+	defer fake.deleteFloatingIPMutex.Unlock()
+	fake.DeleteFloatingIPStub = nil
+	if fake.deleteFloatingIPReturnsOnCall == nil {
+		fake.deleteFloatingIPReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteFloatingIPReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeNetworkingFacade) DeletePort(arg1 utils.RetryableServiceClient, arg2 string) error {
 	fake.deletePortMutex.Lock()
 	ret, specificReturn := fake.deletePortReturnsOnCall[len(fake.deletePortArgsForCall)]
@@ -278,32 +715,156 @@ func (fake *FakeNetworkingFacade) DeletePortCalls(stub func(utils.RetryableServi
 	fake.DeletePortStub = stub
 }
 
-func (fake *FakeNetworkingFacade) DeletePortArgsForCall(i int) (utils.RetryableServiceClient, string) {
-	fake.deletePortMutex.RLock()
-	defer fake.deletePortMutex.RUnlock()
-	argsForCall := fake.deletePortArgsForCall[i]
+func (fake *FakeNetworkingFacade) DeletePortArgsForCall(i int) (utils.RetryableServiceClient, string) {
+	fake.deletePortMutex.RLock()
+	defer fake.deletePortMutex.RUnlock()
+	argsForCall := fake.deletePortArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeNetworkingFacade) DeletePortReturns(result1 error) {
+	fake.deletePortMutex.Lock()
+	defer fake.deletePortMutex.Unlock()
+	fake.DeletePortStub = nil
+	fake.deletePortReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworkingFacade) DeletePortReturnsOnCall(i int, result1 error) {
+	fake.deletePortMutex.Lock()
+	defer fake.deletePortMutex.Unlock()
+	fake.DeletePortStub = nil
+	if fake.deletePortReturnsOnCall == nil {
+		fake.deletePortReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deletePortReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworkingFacade) DeleteSecurityGroup(arg1 utils.RetryableServiceClient, arg2 string) error {
+	fake.deleteSecurityGroupMutex.Lock()
+	ret, specificReturn := fake.deleteSecurityGroupReturnsOnCall[len(fake.deleteSecurityGroupArgsForCall)]
+	fake.deleteSecurityGroupArgsForCall = append(fake.deleteSecurityGroupArgsForCall, struct {
+		arg1 utils.RetryableServiceClient
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DeleteSecurityGroupStub
+	fakeReturns := fake.deleteSecurityGroupReturns
+	fake.recordInvocation("DeleteSecurityGroup", []interface{}{arg1, arg2})
+	fake.deleteSecurityGroupMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeNetworkingFacade) DeleteSecurityGroupCallCount() int {
+	fake.deleteSecurityGroupMutex.RLock()
+	defer fake.deleteSecurityGroupMutex.RUnlock()
+	return len(fake.deleteSecurityGroupArgsForCall)
+}
+
+func (fake *FakeNetworkingFacade) DeleteSecurityGroupCalls(stub func(utils.RetryableServiceClient, string) error) {
+	fake.deleteSecurityGroupMutex.Lock()
+	defer fake.deleteSecurityGroupMutex.Unlock()
+	fake.DeleteSecurityGroupStub = stub
+}
+
+func (fake *FakeNetworkingFacade) DeleteSecurityGroupArgsForCall(i int) (utils.RetryableServiceClient, string) {
+	fake.deleteSecurityGroupMutex.RLock()
+	defer fake.deleteSecurityGroupMutex.RUnlock()
+	argsForCall := fake.deleteSecurityGroupArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeNetworkingFacade) DeleteSecurityGroupReturns(result1 error) {
+	fake.deleteSecurityGroupMutex.Lock()
+	defer fake.deleteSecurityGroupMutex.Unlock()
+	fake.DeleteSecurityGroupStub = nil
+	fake.deleteSecurityGroupReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworkingFacade) DeleteSecurityGroupReturnsOnCall(i int, result1 error) {
+	fake.deleteSecurityGroupMutex.Lock()
+	defer fake.deleteSecurityGroupMutex.Unlock()
+	fake.DeleteSecurityGroupStub = nil
+	if fake.deleteSecurityGroupReturnsOnCall == nil {
+		fake.deleteSecurityGroupReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteSecurityGroupReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeNetworkingFacade) DeleteSecurityGroupRule(arg1 utils.RetryableServiceClient, arg2 string) error {
+	fake.deleteSecurityGroupRuleMutex.Lock()
+	ret, specificReturn := fake.deleteSecurityGroupRuleReturnsOnCall[len(fake.deleteSecurityGroupRuleArgsForCall)]
+	fake.deleteSecurityGroupRuleArgsForCall = append(fake.deleteSecurityGroupRuleArgsForCall, struct {
+		arg1 utils.RetryableServiceClient
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.DeleteSecurityGroupRuleStub
+	fakeReturns := fake.deleteSecurityGroupRuleReturns
+	fake.recordInvocation("DeleteSecurityGroupRule", []interface{}{arg1, arg2})
+	fake.deleteSecurityGroupRuleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeNetworkingFacade) DeleteSecurityGroupRuleCallCount() int {
+	fake.deleteSecurityGroupRuleMutex.RLock()
+	defer fake.deleteSecurityGroupRuleMutex.RUnlock()
+	return len(fake.deleteSecurityGroupRuleArgsForCall)
+}
+
+func (fake *FakeNetworkingFacade) DeleteSecurityGroupRuleCalls(stub func(utils.RetryableServiceClient, string) error) {
+	fake.deleteSecurityGroupRuleMutex.Lock()
+	defer fake.deleteSecurityGroupRuleMutex.Unlock()
+	fake.DeleteSecurityGroupRuleStub = stub
+}
+
+func (fake *FakeNetworkingFacade) DeleteSecurityGroupRuleArgsForCall(i int) (utils.RetryableServiceClient, string) {
+	fake.deleteSecurityGroupRuleMutex.RLock()
+	defer fake.deleteSecurityGroupRuleMutex.RUnlock()
+	argsForCall := fake.deleteSecurityGroupRuleArgsForCall[i]
 	return argsForCall.arg1, argsForCall.arg2
 }
 
-func (fake *FakeNetworkingFacade) DeletePortReturns(result1 error) {
-	fake.deletePortMutex.Lock()
-	defer fake.deletePortMutex.Unlock()
-	fake.DeletePortStub = nil
-	fake.deletePortReturns = struct {
+func (fake *FakeNetworkingFacade) DeleteSecurityGroupRuleReturns(result1 error) {
+	fake.deleteSecurityGroupRuleMutex.Lock()
+	defer fake.deleteSecurityGroupRuleMutex.Unlock()
+	fake.DeleteSecurityGroupRuleStub = nil
+	fake.deleteSecurityGroupRuleReturns = struct {
 		result1 error
 	}{result1}
 }
 
-func (fake *FakeNetworkingFacade) DeletePortReturnsOnCall(i int, result1 error) {
-	fake.deletePortMutex.Lock()
-	defer fake.deletePortMutex.Unlock()
-	fake.DeletePortStub = nil
-	if fake.deletePortReturnsOnCall == nil {
-		fake.deletePortReturnsOnCall = make(map[int]struct {
+func (fake *FakeNetworkingFacade) DeleteSecurityGroupRuleReturnsOnCall(i int, result1 error) {
+	fake.deleteSecurityGroupRuleMutex.Lock()
+	defer fake.deleteSecurityGroupRuleMutex.Unlock()
+	fake.DeleteSecurityGroupRuleStub = nil
+	if fake.deleteSecurityGroupRuleReturnsOnCall == nil {
+		fake.deleteSecurityGroupRuleReturnsOnCall = make(map[int]struct {
 			result1 error
 		})
 	}
-	fake.deletePortReturnsOnCall[i] = struct {
+	fake.deleteSecurityGroupRuleReturnsOnCall[i] = struct {
 		result1 error
 	}{result1}
 }
@@ -372,6 +933,70 @@ func (fake *FakeNetworkingFacade) ExtractFloatingIPsReturnsOnCall(i int, result1
 	}{result1, result2}
 }
 
+func (fake *FakeNetworkingFacade) ExtractNetworks(arg1 pagination.Page) ([]networks.Network, error) {
+	fake.extractNetworksMutex.Lock()
+	ret, specificReturn := fake.extractNetworksReturnsOnCall[len(fake.extractNetworksArgsForCall)]
+	fake.extractNetworksArgsForCall = append(fake.extractNetworksArgsForCall, struct {
+		arg1 pagination.Page
+	}{arg1})
+	stub := fake.ExtractNetworksStub
+	fakeReturns := fake.extractNetworksReturns
+	fake.recordInvocation("ExtractNetworks", []interface{}{arg1})
+	fake.extractNetworksMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNetworkingFacade) ExtractNetworksCallCount() int {
+	fake.extractNetworksMutex.RLock()
+	defer fake.extractNetworksMutex.RUnlock()
+	return len(fake.extractNetworksArgsForCall)
+}
+
+func (fake *FakeNetworkingFacade) ExtractNetworksCalls(stub func(pagination.Page) ([]networks.Network, error)) {
+	fake.extractNetworksMutex.Lock()
+	defer fake.extractNetworksMutex.Unlock()
+	fake.ExtractNetworksStub = stub
+}
+
+func (fake *FakeNetworkingFacade) ExtractNetworksArgsForCall(i int) pagination.Page {
+	fake.extractNetworksMutex.RLock()
+	defer fake.extractNetworksMutex.RUnlock()
+	argsForCall := fake.extractNetworksArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeNetworkingFacade) ExtractNetworksReturns(result1 []networks.Network, result2 error) {
+	fake.extractNetworksMutex.Lock()
+	defer fake.extractNetworksMutex.Unlock()
+	fake.ExtractNetworksStub = nil
+	fake.extractNetworksReturns = struct {
+		result1 []networks.Network
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworkingFacade) ExtractNetworksReturnsOnCall(i int, result1 []networks.Network, result2 error) {
+	fake.extractNetworksMutex.Lock()
+	defer fake.extractNetworksMutex.Unlock()
+	fake.ExtractNetworksStub = nil
+	if fake.extractNetworksReturnsOnCall == nil {
+		fake.extractNetworksReturnsOnCall = make(map[int]struct {
+			result1 []networks.Network
+			result2 error
+		})
+	}
+	fake.extractNetworksReturnsOnCall[i] = struct {
+		result1 []networks.Network
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeNetworkingFacade) ExtractPorts(arg1 pagination.Page) ([]ports.Port, error) {
 	fake.extractPortsMutex.Lock()
 	ret, specificReturn := fake.extractPortsReturnsOnCall[len(fake.extractPortsArgsForCall)]
@@ -564,6 +1189,201 @@ func (fake *FakeNetworkingFacade) ExtractSubnetsReturnsOnCall(i int, result1 []s
 	}{result1, result2}
 }
 
+func (fake *FakeNetworkingFacade) GetNetworkWithMTU(arg1 utils.RetryableServiceClient, arg2 string) (*network.NetworkWithMTU, error) {
+	fake.getNetworkWithMTUMutex.Lock()
+	ret, specificReturn := fake.getNetworkWithMTUReturnsOnCall[len(fake.getNetworkWithMTUArgsForCall)]
+	fake.getNetworkWithMTUArgsForCall = append(fake.getNetworkWithMTUArgsForCall, struct {
+		arg1 utils.RetryableServiceClient
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetNetworkWithMTUStub
+	fakeReturns := fake.getNetworkWithMTUReturns
+	fake.recordInvocation("GetNetworkWithMTU", []interface{}{arg1, arg2})
+	fake.getNetworkWithMTUMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNetworkingFacade) GetNetworkWithMTUCallCount() int {
+	fake.getNetworkWithMTUMutex.RLock()
+	defer fake.getNetworkWithMTUMutex.RUnlock()
+	return len(fake.getNetworkWithMTUArgsForCall)
+}
+
+func (fake *FakeNetworkingFacade) GetNetworkWithMTUCalls(stub func(utils.RetryableServiceClient, string) (*network.NetworkWithMTU, error)) {
+	fake.getNetworkWithMTUMutex.Lock()
+	defer fake.getNetworkWithMTUMutex.Unlock()
+	fake.GetNetworkWithMTUStub = stub
+}
+
+func (fake *FakeNetworkingFacade) GetNetworkWithMTUArgsForCall(i int) (utils.RetryableServiceClient, string) {
+	fake.getNetworkWithMTUMutex.RLock()
+	defer fake.getNetworkWithMTUMutex.RUnlock()
+	argsForCall := fake.getNetworkWithMTUArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeNetworkingFacade) GetNetworkWithMTUReturns(result1 *network.NetworkWithMTU, result2 error) {
+	fake.getNetworkWithMTUMutex.Lock()
+	defer fake.getNetworkWithMTUMutex.Unlock()
+	fake.GetNetworkWithMTUStub = nil
+	fake.getNetworkWithMTUReturns = struct {
+		result1 *network.NetworkWithMTU
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworkingFacade) GetNetworkWithMTUReturnsOnCall(i int, result1 *network.NetworkWithMTU, result2 error) {
+	fake.getNetworkWithMTUMutex.Lock()
+	defer fake.getNetworkWithMTUMutex.Unlock()
+	fake.GetNetworkWithMTUStub = nil
+	if fake.getNetworkWithMTUReturnsOnCall == nil {
+		fake.getNetworkWithMTUReturnsOnCall = make(map[int]struct {
+			result1 *network.NetworkWithMTU
+			result2 error
+		})
+	}
+	fake.getNetworkWithMTUReturnsOnCall[i] = struct {
+		result1 *network.NetworkWithMTU
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworkingFacade) GetPort(arg1 utils.RetryableServiceClient, arg2 string) (*ports.Port, error) {
+	fake.getPortMutex.Lock()
+	ret, specificReturn := fake.getPortReturnsOnCall[len(fake.getPortArgsForCall)]
+	fake.getPortArgsForCall = append(fake.getPortArgsForCall, struct {
+		arg1 utils.RetryableServiceClient
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetPortStub
+	fakeReturns := fake.getPortReturns
+	fake.recordInvocation("GetPort", []interface{}{arg1, arg2})
+	fake.getPortMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNetworkingFacade) GetPortCallCount() int {
+	fake.getPortMutex.RLock()
+	defer fake.getPortMutex.RUnlock()
+	return len(fake.getPortArgsForCall)
+}
+
+func (fake *FakeNetworkingFacade) GetPortCalls(stub func(utils.RetryableServiceClient, string) (*ports.Port, error)) {
+	fake.getPortMutex.Lock()
+	defer fake.getPortMutex.Unlock()
+	fake.GetPortStub = stub
+}
+
+func (fake *FakeNetworkingFacade) GetPortArgsForCall(i int) (utils.RetryableServiceClient, string) {
+	fake.getPortMutex.RLock()
+	defer fake.getPortMutex.RUnlock()
+	argsForCall := fake.getPortArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeNetworkingFacade) GetPortReturns(result1 *ports.Port, result2 error) {
+	fake.getPortMutex.Lock()
+	defer fake.getPortMutex.Unlock()
+	fake.GetPortStub = nil
+	fake.getPortReturns = struct {
+		result1 *ports.Port
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworkingFacade) GetPortReturnsOnCall(i int, result1 *ports.Port, result2 error) {
+	fake.getPortMutex.Lock()
+	defer fake.getPortMutex.Unlock()
+	fake.GetPortStub = nil
+	if fake.getPortReturnsOnCall == nil {
+		fake.getPortReturnsOnCall = make(map[int]struct {
+			result1 *ports.Port
+			result2 error
+		})
+	}
+	fake.getPortReturnsOnCall[i] = struct {
+		result1 *ports.Port
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworkingFacade) GetPortWithExtensions(arg1 utils.RetryableServiceClient, arg2 string) (*network.PortWithExtensions, error) {
+	fake.getPortWithExtensionsMutex.Lock()
+	ret, specificReturn := fake.getPortWithExtensionsReturnsOnCall[len(fake.getPortWithExtensionsArgsForCall)]
+	fake.getPortWithExtensionsArgsForCall = append(fake.getPortWithExtensionsArgsForCall, struct {
+		arg1 utils.RetryableServiceClient
+		arg2 string
+	}{arg1, arg2})
+	stub := fake.GetPortWithExtensionsStub
+	fakeReturns := fake.getPortWithExtensionsReturns
+	fake.recordInvocation("GetPortWithExtensions", []interface{}{arg1, arg2})
+	fake.getPortWithExtensionsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNetworkingFacade) GetPortWithExtensionsCallCount() int {
+	fake.getPortWithExtensionsMutex.RLock()
+	defer fake.getPortWithExtensionsMutex.RUnlock()
+	return len(fake.getPortWithExtensionsArgsForCall)
+}
+
+func (fake *FakeNetworkingFacade) GetPortWithExtensionsCalls(stub func(utils.RetryableServiceClient, string) (*network.PortWithExtensions, error)) {
+	fake.getPortWithExtensionsMutex.Lock()
+	defer fake.getPortWithExtensionsMutex.Unlock()
+	fake.GetPortWithExtensionsStub = stub
+}
+
+func (fake *FakeNetworkingFacade) GetPortWithExtensionsArgsForCall(i int) (utils.RetryableServiceClient, string) {
+	fake.getPortWithExtensionsMutex.RLock()
+	defer fake.getPortWithExtensionsMutex.RUnlock()
+	argsForCall := fake.getPortWithExtensionsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeNetworkingFacade) GetPortWithExtensionsReturns(result1 *network.PortWithExtensions, result2 error) {
+	fake.getPortWithExtensionsMutex.Lock()
+	defer fake.getPortWithExtensionsMutex.Unlock()
+	fake.GetPortWithExtensionsStub = nil
+	fake.getPortWithExtensionsReturns = struct {
+		result1 *network.PortWithExtensions
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworkingFacade) GetPortWithExtensionsReturnsOnCall(i int, result1 *network.PortWithExtensions, result2 error) {
+	fake.getPortWithExtensionsMutex.Lock()
+	defer fake.getPortWithExtensionsMutex.Unlock()
+	fake.GetPortWithExtensionsStub = nil
+	if fake.getPortWithExtensionsReturnsOnCall == nil {
+		fake.getPortWithExtensionsReturnsOnCall = make(map[int]struct {
+			result1 *network.PortWithExtensions
+			result2 error
+		})
+	}
+	fake.getPortWithExtensionsReturnsOnCall[i] = struct {
+		result1 *network.PortWithExtensions
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeNetworkingFacade) GetSecurityGroups(arg1 utils.RetryableServiceClient, arg2 string) (*groups.SecGroup, error) {
 	fake.getSecurityGroupsMutex.Lock()
 	ret, specificReturn := fake.getSecurityGroupsReturnsOnCall[len(fake.getSecurityGroupsArgsForCall)]
@@ -694,6 +1514,71 @@ func (fake *FakeNetworkingFacade) ListFloatingIpsReturnsOnCall(i int, result1 pa
 	}{result1, result2}
 }
 
+func (fake *FakeNetworkingFacade) ListNetworks(arg1 utils.RetryableServiceClient, arg2 networks.ListOpts) (pagination.Page, error) {
+	fake.listNetworksMutex.Lock()
+	ret, specificReturn := fake.listNetworksReturnsOnCall[len(fake.listNetworksArgsForCall)]
+	fake.listNetworksArgsForCall = append(fake.listNetworksArgsForCall, struct {
+		arg1 utils.RetryableServiceClient
+		arg2 networks.ListOpts
+	}{arg1, arg2})
+	stub := fake.ListNetworksStub
+	fakeReturns := fake.listNetworksReturns
+	fake.recordInvocation("ListNetworks", []interface{}{arg1, arg2})
+	fake.listNetworksMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNetworkingFacade) ListNetworksCallCount() int {
+	fake.listNetworksMutex.RLock()
+	defer fake.listNetworksMutex.RUnlock()
+	return len(fake.listNetworksArgsForCall)
+}
+
+func (fake *FakeNetworkingFacade) ListNetworksCalls(stub func(utils.RetryableServiceClient, networks.ListOpts) (pagination.Page, error)) {
+	fake.listNetworksMutex.Lock()
+	defer fake.listNetworksMutex.Unlock()
+	fake.ListNetworksStub = stub
+}
+
+func (fake *FakeNetworkingFacade) ListNetworksArgsForCall(i int) (utils.RetryableServiceClient, networks.ListOpts) {
+	fake.listNetworksMutex.RLock()
+	defer fake.listNetworksMutex.RUnlock()
+	argsForCall := fake.listNetworksArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeNetworkingFacade) ListNetworksReturns(result1 pagination.Page, result2 error) {
+	fake.listNetworksMutex.Lock()
+	defer fake.listNetworksMutex.Unlock()
+	fake.ListNetworksStub = nil
+	fake.listNetworksReturns = struct {
+		result1 pagination.Page
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworkingFacade) ListNetworksReturnsOnCall(i int, result1 pagination.Page, result2 error) {
+	fake.listNetworksMutex.Lock()
+	defer fake.listNetworksMutex.Unlock()
+	fake.ListNetworksStub = nil
+	if fake.listNetworksReturnsOnCall == nil {
+		fake.listNetworksReturnsOnCall = make(map[int]struct {
+			result1 pagination.Page
+			result2 error
+		})
+	}
+	fake.listNetworksReturnsOnCall[i] = struct {
+		result1 pagination.Page
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeNetworkingFacade) ListPorts(arg1 utils.RetryableServiceClient, arg2 ports.ListOpts) (pagination.Page, error) {
 	fake.listPortsMutex.Lock()
 	ret, specificReturn := fake.listPortsReturnsOnCall[len(fake.listPortsArgsForCall)]
@@ -889,6 +1774,78 @@ func (fake *FakeNetworkingFacade) ListSubnetsReturnsOnCall(i int, result1 pagina
 	}{result1, result2}
 }
 
+func (fake *FakeNetworkingFacade) ReplaceAllTags(arg1 utils.ServiceClient, arg2 string, arg3 string, arg4 []string) ([]string, error) {
+	var arg4Copy []string
+	if arg4 != nil {
+		arg4Copy = make([]string, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	fake.replaceAllTagsMutex.Lock()
+	ret, specificReturn := fake.replaceAllTagsReturnsOnCall[len(fake.replaceAllTagsArgsForCall)]
+	fake.replaceAllTagsArgsForCall = append(fake.replaceAllTagsArgsForCall, struct {
+		arg1 utils.ServiceClient
+		arg2 string
+		arg3 string
+		arg4 []string
+	}{arg1, arg2, arg3, arg4Copy})
+	stub := fake.ReplaceAllTagsStub
+	fakeReturns := fake.replaceAllTagsReturns
+	fake.recordInvocation("ReplaceAllTags", []interface{}{arg1, arg2, arg3, arg4Copy})
+	fake.replaceAllTagsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNetworkingFacade) ReplaceAllTagsCallCount() int {
+	fake.replaceAllTagsMutex.RLock()
+	defer fake.replaceAllTagsMutex.RUnlock()
+	return len(fake.replaceAllTagsArgsForCall)
+}
+
+func (fake *FakeNetworkingFacade) ReplaceAllTagsCalls(stub func(utils.ServiceClient, string, string, []string) ([]string, error)) {
+	fake.replaceAllTagsMutex.Lock()
+	defer fake.replaceAllTagsMutex.Unlock()
+	fake.ReplaceAllTagsStub = stub
+}
+
+func (fake *FakeNetworkingFacade) ReplaceAllTagsArgsForCall(i int) (utils.ServiceClient, string, string, []string) {
+	fake.replaceAllTagsMutex.RLock()
+	defer fake.replaceAllTagsMutex.RUnlock()
+	argsForCall := fake.replaceAllTagsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeNetworkingFacade) ReplaceAllTagsReturns(result1 []string, result2 error) {
+	fake.replaceAllTagsMutex.Lock()
+	defer fake.replaceAllTagsMutex.Unlock()
+	fake.ReplaceAllTagsStub = nil
+	fake.replaceAllTagsReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworkingFacade) ReplaceAllTagsReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.replaceAllTagsMutex.Lock()
+	defer fake.replaceAllTagsMutex.Unlock()
+	fake.ReplaceAllTagsStub = nil
+	if fake.replaceAllTagsReturnsOnCall == nil {
+		fake.replaceAllTagsReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.replaceAllTagsReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeNetworkingFacade) UpdateFloatingIP(arg1 utils.ServiceClient, arg2 string, arg3 floatingips.UpdateOpts) (*floatingips.FloatingIP, error) {
 	fake.updateFloatingIPMutex.Lock()
 	ret, specificReturn := fake.updateFloatingIPReturnsOnCall[len(fake.updateFloatingIPArgsForCall)]
@@ -955,33 +1912,75 @@ func (fake *FakeNetworkingFacade) UpdateFloatingIPReturnsOnCall(i int, result1 *
 	}{result1, result2}
 }
 
+func (fake *FakeNetworkingFacade) UpdatePort(arg1 utils.ServiceClient, arg2 string, arg3 ports.UpdateOptsBuilder) (*ports.Port, error) {
+	fake.updatePortMutex.Lock()
+	ret, specificReturn := fake.updatePortReturnsOnCall[len(fake.updatePortArgsForCall)]
+	fake.updatePortArgsForCall = append(fake.updatePortArgsForCall, struct {
+		arg1 utils.ServiceClient
+		arg2 string
+		arg3 ports.UpdateOptsBuilder
+	}{arg1, arg2, arg3})
+	stub := fake.UpdatePortStub
+	fakeReturns := fake.updatePortReturns
+	fake.recordInvocation("UpdatePort", []interface{}{arg1, arg2, arg3})
+	fake.updatePortMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeNetworkingFacade) UpdatePortCallCount() int {
+	fake.updatePortMutex.RLock()
+	defer fake.updatePortMutex.RUnlock()
+	return len(fake.updatePortArgsForCall)
+}
+
+func (fake *FakeNetworkingFacade) UpdatePortCalls(stub func(utils.ServiceClient, string, ports.UpdateOptsBuilder) (*ports.Port, error)) {
+	fake.updatePortMutex.Lock()
+	defer fake.updatePortMutex.Unlock()
+	fake.UpdatePortStub = stub
+}
+
+func (fake *FakeNetworkingFacade) UpdatePortArgsForCall(i int) (utils.ServiceClient, string, ports.UpdateOptsBuilder) {
+	fake.updatePortMutex.RLock()
+	defer fake.updatePortMutex.RUnlock()
+	argsForCall := fake.updatePortArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeNetworkingFacade) UpdatePortReturns(result1 *ports.Port, result2 error) {
+	fake.updatePortMutex.Lock()
+	defer fake.updatePortMutex.Unlock()
+	fake.UpdatePortStub = nil
+	fake.updatePortReturns = struct {
+		result1 *ports.Port
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeNetworkingFacade) UpdatePortReturnsOnCall(i int, result1 *ports.Port, result2 error) {
+	fake.updatePortMutex.Lock()
+	defer fake.updatePortMutex.Unlock()
+	fake.UpdatePortStub = nil
+	if fake.updatePortReturnsOnCall == nil {
+		fake.updatePortReturnsOnCall = make(map[int]struct {
+			result1 *ports.Port
+			result2 error
+		})
+	}
+	fake.updatePortReturnsOnCall[i] = struct {
+		result1 *ports.Port
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeNetworkingFacade) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
-	fake.createPortMutex.RLock()
-	defer fake.createPortMutex.RUnlock()
-	fake.deletePortMutex.RLock()
-	defer fake.deletePortMutex.RUnlock()
-	fake.extractFloatingIPsMutex.RLock()
-	defer fake.extractFloatingIPsMutex.RUnlock()
-	fake.extractPortsMutex.RLock()
-	defer fake.extractPortsMutex.RUnlock()
-	fake.extractSecurityGroupsMutex.RLock()
-	defer fake.extractSecurityGroupsMutex.RUnlock()
-	fake.extractSubnetsMutex.RLock()
-	defer fake.extractSubnetsMutex.RUnlock()
-	fake.getSecurityGroupsMutex.RLock()
-	defer fake.getSecurityGroupsMutex.RUnlock()
-	fake.listFloatingIpsMutex.RLock()
-	defer fake.listFloatingIpsMutex.RUnlock()
-	fake.listPortsMutex.RLock()
-	defer fake.listPortsMutex.RUnlock()
-	fake.listSecurityGroupsMutex.RLock()
-	defer fake.listSecurityGroupsMutex.RUnlock()
-	fake.listSubnetsMutex.RLock()
-	defer fake.listSubnetsMutex.RUnlock()
-	fake.updateFloatingIPMutex.RLock()
-	defer fake.updateFloatingIPMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
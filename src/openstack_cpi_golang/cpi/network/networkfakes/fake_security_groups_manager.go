@@ -0,0 +1,199 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package networkfakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/network"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/properties"
+)
+
+type FakeSecurityGroupsManager struct {
+	DeleteUnusedStub        func([]string) error
+	deleteUnusedMutex       sync.RWMutex
+	deleteUnusedArgsForCall []struct {
+		arg1 []string
+	}
+	deleteUnusedReturns struct {
+		result1 error
+	}
+	deleteUnusedReturnsOnCall map[int]struct {
+		result1 error
+	}
+	EnsureStub        func([]properties.ManagedSecurityGroup, properties.BoshEnv) ([]string, error)
+	ensureMutex       sync.RWMutex
+	ensureArgsForCall []struct {
+		arg1 []properties.ManagedSecurityGroup
+		arg2 properties.BoshEnv
+	}
+	ensureReturns struct {
+		result1 []string
+		result2 error
+	}
+	ensureReturnsOnCall map[int]struct {
+		result1 []string
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeSecurityGroupsManager) DeleteUnused(arg1 []string) error {
+	var arg1Copy []string
+	if arg1 != nil {
+		arg1Copy = make([]string, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.deleteUnusedMutex.Lock()
+	ret, specificReturn := fake.deleteUnusedReturnsOnCall[len(fake.deleteUnusedArgsForCall)]
+	fake.deleteUnusedArgsForCall = append(fake.deleteUnusedArgsForCall, struct {
+		arg1 []string
+	}{arg1Copy})
+	stub := fake.DeleteUnusedStub
+	fakeReturns := fake.deleteUnusedReturns
+	fake.recordInvocation("DeleteUnused", []interface{}{arg1Copy})
+	fake.deleteUnusedMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSecurityGroupsManager) DeleteUnusedCallCount() int {
+	fake.deleteUnusedMutex.RLock()
+	defer fake.deleteUnusedMutex.RUnlock()
+	return len(fake.deleteUnusedArgsForCall)
+}
+
+func (fake *FakeSecurityGroupsManager) DeleteUnusedCalls(stub func([]string) error) {
+	fake.deleteUnusedMutex.Lock()
+	defer fake.deleteUnusedMutex.Unlock()
+	fake.DeleteUnusedStub = stub
+}
+
+func (fake *FakeSecurityGroupsManager) DeleteUnusedArgsForCall(i int) []string {
+	fake.deleteUnusedMutex.RLock()
+	defer fake.deleteUnusedMutex.RUnlock()
+	argsForCall := fake.deleteUnusedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeSecurityGroupsManager) DeleteUnusedReturns(result1 error) {
+	fake.deleteUnusedMutex.Lock()
+	defer fake.deleteUnusedMutex.Unlock()
+	fake.DeleteUnusedStub = nil
+	fake.deleteUnusedReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSecurityGroupsManager) DeleteUnusedReturnsOnCall(i int, result1 error) {
+	fake.deleteUnusedMutex.Lock()
+	defer fake.deleteUnusedMutex.Unlock()
+	fake.DeleteUnusedStub = nil
+	if fake.deleteUnusedReturnsOnCall == nil {
+		fake.deleteUnusedReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.deleteUnusedReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeSecurityGroupsManager) Ensure(arg1 []properties.ManagedSecurityGroup, arg2 properties.BoshEnv) ([]string, error) {
+	var arg1Copy []properties.ManagedSecurityGroup
+	if arg1 != nil {
+		arg1Copy = make([]properties.ManagedSecurityGroup, len(arg1))
+		copy(arg1Copy, arg1)
+	}
+	fake.ensureMutex.Lock()
+	ret, specificReturn := fake.ensureReturnsOnCall[len(fake.ensureArgsForCall)]
+	fake.ensureArgsForCall = append(fake.ensureArgsForCall, struct {
+		arg1 []properties.ManagedSecurityGroup
+		arg2 properties.BoshEnv
+	}{arg1Copy, arg2})
+	stub := fake.EnsureStub
+	fakeReturns := fake.ensureReturns
+	fake.recordInvocation("Ensure", []interface{}{arg1Copy, arg2})
+	fake.ensureMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeSecurityGroupsManager) EnsureCallCount() int {
+	fake.ensureMutex.RLock()
+	defer fake.ensureMutex.RUnlock()
+	return len(fake.ensureArgsForCall)
+}
+
+func (fake *FakeSecurityGroupsManager) EnsureCalls(stub func([]properties.ManagedSecurityGroup, properties.BoshEnv) ([]string, error)) {
+	fake.ensureMutex.Lock()
+	defer fake.ensureMutex.Unlock()
+	fake.EnsureStub = stub
+}
+
+func (fake *FakeSecurityGroupsManager) EnsureArgsForCall(i int) ([]properties.ManagedSecurityGroup, properties.BoshEnv) {
+	fake.ensureMutex.RLock()
+	defer fake.ensureMutex.RUnlock()
+	argsForCall := fake.ensureArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeSecurityGroupsManager) EnsureReturns(result1 []string, result2 error) {
+	fake.ensureMutex.Lock()
+	defer fake.ensureMutex.Unlock()
+	fake.EnsureStub = nil
+	fake.ensureReturns = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSecurityGroupsManager) EnsureReturnsOnCall(i int, result1 []string, result2 error) {
+	fake.ensureMutex.Lock()
+	defer fake.ensureMutex.Unlock()
+	fake.EnsureStub = nil
+	if fake.ensureReturnsOnCall == nil {
+		fake.ensureReturnsOnCall = make(map[int]struct {
+			result1 []string
+			result2 error
+		})
+	}
+	fake.ensureReturnsOnCall[i] = struct {
+		result1 []string
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeSecurityGroupsManager) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeSecurityGroupsManager) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ network.SecurityGroupsManager = new(FakeSecurityGroupsManager)
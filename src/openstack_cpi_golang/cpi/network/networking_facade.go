@@ -2,13 +2,34 @@ package network
 
 import (
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/attributestags"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/extradhcpopts"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/layer3/floatingips"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/mtu"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/portsbinding"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/portsecurity"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/qos/policies"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/groups"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/networks"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/subnets"
 	"github.com/gophercloud/gophercloud/pagination"
 )
 
+type NetworkWithMTU struct {
+	networks.Network
+	mtu.NetworkMTUExt
+}
+
+type PortWithExtensions struct {
+	ports.Port
+	portsbinding.PortsBindingExt
+	portsecurity.PortSecurityExt
+	policies.QoSPolicyExt
+	extradhcpopts.ExtraDHCPOptsExt
+}
+
 //counterfeiter:generate . NetworkingFacade
 type NetworkingFacade interface {
 	ListFloatingIps(serviceClient utils.RetryableServiceClient, opts floatingips.ListOpts) (pagination.Page, error)
@@ -17,10 +38,26 @@ type NetworkingFacade interface {
 
 	UpdateFloatingIP(serviceClient utils.ServiceClient, floatingIpId string, updateOpts floatingips.UpdateOpts) (*floatingips.FloatingIP, error)
 
-	CreatePort(serviceClient utils.ServiceClient, createOpts ports.CreateOpts) (*ports.Port, error)
+	CreateFloatingIP(serviceClient utils.ServiceClient, createOpts floatingips.CreateOpts) (*floatingips.FloatingIP, error)
+
+	DeleteFloatingIP(serviceClient utils.RetryableServiceClient, floatingIpId string) error
+
+	ListNetworks(serviceClient utils.RetryableServiceClient, opts networks.ListOpts) (pagination.Page, error)
+
+	ExtractNetworks(page pagination.Page) ([]networks.Network, error)
+
+	GetNetworkWithMTU(serviceClient utils.RetryableServiceClient, networkID string) (*NetworkWithMTU, error)
+
+	CreatePort(serviceClient utils.ServiceClient, createOpts ports.CreateOptsBuilder) (*ports.Port, error)
 
 	DeletePort(serviceClient utils.RetryableServiceClient, portID string) error
 
+	GetPort(serviceClient utils.RetryableServiceClient, portID string) (*ports.Port, error)
+
+	GetPortWithExtensions(serviceClient utils.RetryableServiceClient, portID string) (*PortWithExtensions, error)
+
+	UpdatePort(serviceClient utils.ServiceClient, portID string, updateOpts ports.UpdateOptsBuilder) (*ports.Port, error)
+
 	ListPorts(client utils.RetryableServiceClient, opts ports.ListOpts) (pagination.Page, error)
 
 	ExtractPorts(page pagination.Page) ([]ports.Port, error)
@@ -31,9 +68,19 @@ type NetworkingFacade interface {
 
 	ExtractSecurityGroups(page pagination.Page) ([]groups.SecGroup, error)
 
+	CreateSecurityGroup(serviceClient utils.ServiceClient, createOpts groups.CreateOpts) (*groups.SecGroup, error)
+
+	DeleteSecurityGroup(serviceClient utils.RetryableServiceClient, id string) error
+
+	CreateSecurityGroupRule(serviceClient utils.ServiceClient, createOpts rules.CreateOpts) (*rules.SecGroupRule, error)
+
+	DeleteSecurityGroupRule(serviceClient utils.RetryableServiceClient, id string) error
+
 	ListSubnets(serviceClient utils.RetryableServiceClient, opts subnets.ListOpts) (pagination.Page, error)
 
 	ExtractSubnets(page pagination.Page) ([]subnets.Subnet, error)
+
+	ReplaceAllTags(serviceClient utils.ServiceClient, resourceType string, resourceID string, tags []string) ([]string, error)
 }
 
 type networkingFacade struct{}
@@ -54,7 +101,29 @@ func (n networkingFacade) UpdateFloatingIP(serviceClient utils.ServiceClient, fl
 	return floatingips.Update(serviceClient, floatingIpId, updateOpts).Extract()
 }
 
-func (n networkingFacade) CreatePort(serviceClient utils.ServiceClient, createOpts ports.CreateOpts) (*ports.Port, error) {
+func (n networkingFacade) CreateFloatingIP(serviceClient utils.ServiceClient, createOpts floatingips.CreateOpts) (*floatingips.FloatingIP, error) {
+	return floatingips.Create(serviceClient, createOpts).Extract()
+}
+
+func (n networkingFacade) DeleteFloatingIP(serviceClient utils.RetryableServiceClient, floatingIpId string) error {
+	return floatingips.Delete(serviceClient, floatingIpId).ExtractErr()
+}
+
+func (n networkingFacade) ListNetworks(serviceClient utils.RetryableServiceClient, opts networks.ListOpts) (pagination.Page, error) {
+	return networks.List(serviceClient, opts).AllPages()
+}
+
+func (n networkingFacade) ExtractNetworks(page pagination.Page) ([]networks.Network, error) {
+	return networks.ExtractNetworks(page)
+}
+
+func (n networkingFacade) GetNetworkWithMTU(serviceClient utils.RetryableServiceClient, networkID string) (*NetworkWithMTU, error) {
+	var networkWithMTU NetworkWithMTU
+	err := networks.Get(serviceClient, networkID).ExtractInto(&networkWithMTU)
+	return &networkWithMTU, err
+}
+
+func (n networkingFacade) CreatePort(serviceClient utils.ServiceClient, createOpts ports.CreateOptsBuilder) (*ports.Port, error) {
 	return ports.Create(serviceClient, createOpts).Extract()
 }
 
@@ -62,6 +131,20 @@ func (n networkingFacade) DeletePort(serviceClient utils.RetryableServiceClient,
 	return ports.Delete(serviceClient, portID).ExtractErr()
 }
 
+func (n networkingFacade) GetPort(serviceClient utils.RetryableServiceClient, portID string) (*ports.Port, error) {
+	return ports.Get(serviceClient, portID).Extract()
+}
+
+func (n networkingFacade) GetPortWithExtensions(serviceClient utils.RetryableServiceClient, portID string) (*PortWithExtensions, error) {
+	var portWithExtensions PortWithExtensions
+	err := ports.Get(serviceClient, portID).ExtractInto(&portWithExtensions)
+	return &portWithExtensions, err
+}
+
+func (n networkingFacade) UpdatePort(serviceClient utils.ServiceClient, portID string, updateOpts ports.UpdateOptsBuilder) (*ports.Port, error) {
+	return ports.Update(serviceClient, portID, updateOpts).Extract()
+}
+
 func (n networkingFacade) ListPorts(serviceClient utils.RetryableServiceClient, opts ports.ListOpts) (pagination.Page, error) {
 	return ports.List(serviceClient, opts).AllPages()
 }
@@ -81,6 +164,22 @@ func (n networkingFacade) ListSecurityGroups(serviceClient utils.RetryableServic
 func (n networkingFacade) ExtractSecurityGroups(page pagination.Page) ([]groups.SecGroup, error) {
 	return groups.ExtractGroups(page)
 }
+func (n networkingFacade) CreateSecurityGroup(serviceClient utils.ServiceClient, createOpts groups.CreateOpts) (*groups.SecGroup, error) {
+	return groups.Create(serviceClient, createOpts).Extract()
+}
+
+func (n networkingFacade) DeleteSecurityGroup(serviceClient utils.RetryableServiceClient, id string) error {
+	return groups.Delete(serviceClient, id).ExtractErr()
+}
+
+func (n networkingFacade) CreateSecurityGroupRule(serviceClient utils.ServiceClient, createOpts rules.CreateOpts) (*rules.SecGroupRule, error) {
+	return rules.Create(serviceClient, createOpts).Extract()
+}
+
+func (n networkingFacade) DeleteSecurityGroupRule(serviceClient utils.RetryableServiceClient, id string) error {
+	return rules.Delete(serviceClient, id).ExtractErr()
+}
+
 func (n networkingFacade) ListSubnets(serviceClient utils.RetryableServiceClient, opts subnets.ListOpts) (pagination.Page, error) {
 	return subnets.List(serviceClient, opts).AllPages()
 }
@@ -88,3 +187,7 @@ func (n networkingFacade) ListSubnets(serviceClient utils.RetryableServiceClient
 func (n networkingFacade) ExtractSubnets(page pagination.Page) ([]subnets.Subnet, error) {
 	return subnets.ExtractSubnets(page)
 }
+
+func (n networkingFacade) ReplaceAllTags(serviceClient utils.ServiceClient, resourceType string, resourceID string, tags []string) ([]string, error) {
+	return attributestags.ReplaceAll(serviceClient, resourceType, resourceID, attributestags.ReplaceAllOpts{Tags: tags}).Extract()
+}
@@ -0,0 +1,284 @@
+package network
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/properties"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils"
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/groups"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+)
+
+// ManagedSecurityGroupTag marks security groups which are created from the 'managed_security_groups' cloud property.
+const ManagedSecurityGroupTag = "bosh-managed"
+
+//counterfeiter:generate . SecurityGroupsManager
+type SecurityGroupsManager interface {
+	Ensure(managedSecurityGroups []properties.ManagedSecurityGroup, boshEnv properties.BoshEnv) ([]string, error)
+
+	DeleteUnused(securityGroupIDs []string) error
+}
+
+type securityGroupsManager struct {
+	serviceClients   utils.ServiceClients
+	networkingFacade NetworkingFacade
+	logger           utils.Logger
+}
+
+func NewSecurityGroupsManager(
+	serviceClients utils.ServiceClients,
+	networkingFacade NetworkingFacade,
+	logger utils.Logger,
+) securityGroupsManager {
+	return securityGroupsManager{
+		serviceClients:   serviceClients,
+		networkingFacade: networkingFacade,
+		logger:           logger,
+	}
+}
+
+// Ensure creates the managed security groups of a deployment if they do not exist yet and
+// reconciles their rules. It returns the IDs of the security groups.
+func (s securityGroupsManager) Ensure(managedSecurityGroups []properties.ManagedSecurityGroup, boshEnv properties.BoshEnv) ([]string, error) {
+	if len(managedSecurityGroups) == 0 {
+		return nil, nil
+	}
+
+	if boshEnv.Director() == "" || boshEnv.Deployment() == "" {
+		return nil, fmt.Errorf("managed security groups require the director and deployment name in the bosh environment")
+	}
+
+	tags := managedSecurityGroupTags(boshEnv)
+
+	var securityGroupIDs []string
+	for _, managedSecurityGroup := range managedSecurityGroups {
+		name := fmt.Sprintf("%s-%s-%s", boshEnv.Director(), boshEnv.Deployment(), managedSecurityGroup.Name)
+
+		securityGroup, err := s.ensureSecurityGroup(name, managedSecurityGroup.Description, tags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to ensure security group '%s': %w", name, err)
+		}
+
+		err = s.reconcileRules(securityGroup, managedSecurityGroup.Rules)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reconcile rules of security group '%s': %w", name, err)
+		}
+
+		securityGroupIDs = append(securityGroupIDs, securityGroup.ID)
+	}
+
+	return securityGroupIDs, nil
+}
+
+// DeleteUnused deletes those of the given security groups which are managed by the CPI and not referenced by any port.
+func (s securityGroupsManager) DeleteUnused(securityGroupIDs []string) error {
+	var errDefault404 gophercloud.ErrDefault404
+	var errDefault409 gophercloud.ErrDefault409
+
+	for _, securityGroupID := range utils.UniqueArray(securityGroupIDs) {
+		securityGroup, err := s.networkingFacade.GetSecurityGroups(s.serviceClients.RetryableServiceClient, securityGroupID)
+		if err != nil {
+			if errors.As(err, &errDefault404) {
+				continue
+			}
+			return fmt.Errorf("failed to get security group '%s': %w", securityGroupID, err)
+		}
+
+		if !slices.Contains(securityGroup.Tags, ManagedSecurityGroupTag) {
+			continue
+		}
+
+		page, err := s.networkingFacade.ListPorts(s.serviceClients.RetryableServiceClient, ports.ListOpts{SecurityGroups: []string{securityGroupID}})
+		if err != nil {
+			return fmt.Errorf("failed to list ports of security group '%s': %w", securityGroupID, err)
+		}
+
+		referencingPorts, err := s.networkingFacade.ExtractPorts(page)
+		if err != nil {
+			return fmt.Errorf("failed to extract ports of security group '%s': %w", securityGroupID, err)
+		}
+
+		if len(referencingPorts) > 0 {
+			s.logger.Info("security-groups-manager", fmt.Sprintf("Keeping security group '%s' referenced by %d ports", securityGroup.Name, len(referencingPorts)))
+			continue
+		}
+
+		err = s.networkingFacade.DeleteSecurityGroup(s.serviceClients.RetryableServiceClient, securityGroupID)
+		if err != nil {
+			if errors.As(err, &errDefault404) || errors.As(err, &errDefault409) {
+				s.logger.Info("security-groups-manager", fmt.Sprintf("SKIPPING: Security group '%s' is already deleted or in use again: %v", securityGroup.Name, err))
+				continue
+			}
+			return fmt.Errorf("failed to delete security group '%s': %w", securityGroupID, err)
+		}
+		s.logger.Info("security-groups-manager", fmt.Sprintf("Deleted unused security group '%s'", securityGroup.Name))
+	}
+
+	return nil
+}
+
+func (s securityGroupsManager) ensureSecurityGroup(name string, description string, tags []string) (groups.SecGroup, error) {
+	securityGroup, err := s.findSecurityGroup(name, tags)
+	if err != nil {
+		return groups.SecGroup{}, err
+	}
+
+	if securityGroup != nil {
+		return *securityGroup, nil
+	}
+
+	createdSecurityGroup, err := s.networkingFacade.CreateSecurityGroup(s.serviceClients.ServiceClient, groups.CreateOpts{Name: name, Description: description})
+	if err != nil {
+		return groups.SecGroup{}, fmt.Errorf("failed to create security group: %w", err)
+	}
+
+	_, err = s.networkingFacade.ReplaceAllTags(s.serviceClients.ServiceClient, "security-groups", createdSecurityGroup.ID, tags)
+	if err != nil {
+		s.deleteSecurityGroup(createdSecurityGroup.ID)
+		return groups.SecGroup{}, fmt.Errorf("failed to tag security group: %w", err)
+	}
+	s.logger.Info("security-groups-manager", fmt.Sprintf("Created security group '%s' with id '%s'", name, createdSecurityGroup.ID))
+
+	// Instances of the same deployment are created in parallel and might have created the security group
+	// concurrently. All of them agree on the oldest security group and the other ones are removed again.
+	securityGroup, err = s.findSecurityGroup(name, tags)
+	if err != nil {
+		return groups.SecGroup{}, err
+	}
+
+	if securityGroup == nil {
+		return *createdSecurityGroup, nil
+	}
+
+	if securityGroup.ID != createdSecurityGroup.ID {
+		s.logger.Info("security-groups-manager", fmt.Sprintf("Security group '%s' was created concurrently, using id '%s'", name, securityGroup.ID))
+		s.deleteSecurityGroup(createdSecurityGroup.ID)
+	}
+
+	return *securityGroup, nil
+}
+
+func (s securityGroupsManager) findSecurityGroup(name string, tags []string) (*groups.SecGroup, error) {
+	listOpts := groups.ListOpts{
+		Name: name,
+		Tags: strings.Join(tags, ","),
+	}
+
+	allPages, err := s.networkingFacade.ListSecurityGroups(s.serviceClients.RetryableServiceClient, listOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list security groups: %w", err)
+	}
+
+	allSecurityGroups, err := s.networkingFacade.ExtractSecurityGroups(allPages)
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract security groups: %w", err)
+	}
+
+	if len(allSecurityGroups) == 0 {
+		return nil, nil
+	}
+
+	slices.SortFunc(allSecurityGroups, func(a, b groups.SecGroup) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return &allSecurityGroups[0], nil
+}
+
+func (s securityGroupsManager) deleteSecurityGroup(securityGroupID string) {
+	err := s.networkingFacade.DeleteSecurityGroup(s.serviceClients.RetryableServiceClient, securityGroupID)
+	if err != nil {
+		s.logger.Warn("security-groups-manager", fmt.Sprintf("failed to delete security group '%s': %v", securityGroupID, err))
+	}
+}
+
+// reconcileRules creates missing rules and deletes rules which are not declared anymore. Egress rules are only
+// reconciled if at least one egress rule is declared, so that the default egress rules created by Neutron
+// allow all outgoing traffic otherwise.
+func (s securityGroupsManager) reconcileRules(securityGroup groups.SecGroup, declaredRules []properties.SecurityGroupRule) error {
+	var errDefault404 gophercloud.ErrDefault404
+	var errDefault409 gophercloud.ErrDefault409
+
+	declaredRuleKeys := map[string]properties.SecurityGroupRule{}
+	reconciledDirections := []string{properties.SecurityGroupRuleDirectionIngress}
+	for _, rule := range declaredRules {
+		declaredRuleKeys[declaredRuleKey(rule)] = rule
+		if rule.GetDirection() == properties.SecurityGroupRuleDirectionEgress {
+			reconciledDirections = append(reconciledDirections, properties.SecurityGroupRuleDirectionEgress)
+		}
+	}
+
+	existingRuleKeys := map[string]bool{}
+	for _, existingRule := range securityGroup.Rules {
+		key := existingRuleKey(existingRule)
+		existingRuleKeys[key] = true
+
+		if _, declared := declaredRuleKeys[key]; declared || !slices.Contains(reconciledDirections, existingRule.Direction) || existingRule.RemoteGroupID != "" {
+			continue
+		}
+
+		err := s.networkingFacade.DeleteSecurityGroupRule(s.serviceClients.RetryableServiceClient, existingRule.ID)
+		if err != nil && !errors.As(err, &errDefault404) {
+			return fmt.Errorf("failed to delete rule '%s': %w", existingRule.ID, err)
+		}
+		s.logger.Info("security-groups-manager", fmt.Sprintf("Deleted rule '%s' from security group '%s'", key, securityGroup.Name))
+	}
+
+	for key, rule := range declaredRuleKeys {
+		if existingRuleKeys[key] {
+			continue
+		}
+
+		createOpts := rules.CreateOpts{
+			SecGroupID:     securityGroup.ID,
+			Direction:      rules.RuleDirection(rule.GetDirection()),
+			EtherType:      rules.RuleEtherType(rule.GetEtherType()),
+			Protocol:       rules.RuleProtocol(rule.Protocol),
+			PortRangeMin:   rule.PortRangeMin,
+			PortRangeMax:   rule.GetPortRangeMax(),
+			RemoteIPPrefix: rule.RemoteIPPrefix,
+		}
+
+		_, err := s.networkingFacade.CreateSecurityGroupRule(s.serviceClients.ServiceClient, createOpts)
+		if err != nil && !errors.As(err, &errDefault409) {
+			return fmt.Errorf("failed to create rule '%s': %w", key, err)
+		}
+		s.logger.Info("security-groups-manager", fmt.Sprintf("Created rule '%s' in security group '%s'", key, securityGroup.Name))
+	}
+
+	return nil
+}
+
+func declaredRuleKey(rule properties.SecurityGroupRule) string {
+	return ruleKey(rule.GetDirection(), rule.GetEtherType(), rule.Protocol, rule.PortRangeMin, rule.GetPortRangeMax(), rule.RemoteIPPrefix)
+}
+
+func existingRuleKey(rule rules.SecGroupRule) string {
+	return ruleKey(rule.Direction, rule.EtherType, rule.Protocol, rule.PortRangeMin, rule.PortRangeMax, rule.RemoteIPPrefix)
+}
+
+// ruleKey identifies a rule independent of how Neutron normalizes the remote ip prefix, e.g. '0.0.0.0/0' is
+// equivalent to no prefix at all.
+func ruleKey(direction string, etherType string, protocol string, portRangeMin int, portRangeMax int, remoteIPPrefix string) string {
+	if _, ipNet, err := net.ParseCIDR(remoteIPPrefix); err == nil {
+		remoteIPPrefix = ipNet.String()
+		if ones, _ := ipNet.Mask.Size(); ones == 0 {
+			remoteIPPrefix = ""
+		}
+	}
+
+	return fmt.Sprintf("%s %s %s %d-%d %s", direction, etherType, protocol, portRangeMin, portRangeMax, remoteIPPrefix)
+}
+
+func managedSecurityGroupTags(boshEnv properties.BoshEnv) []string {
+	return []string{ManagedSecurityGroupTag, "director:" + boshEnv.Director(), "deployment:" + boshEnv.Deployment()}
+}
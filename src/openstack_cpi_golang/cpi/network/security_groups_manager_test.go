@@ -0,0 +1,252 @@
+package network_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry/bosh-cpi-go/apiv1"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/network"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/network/networkfakes"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/properties"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils/utilsfakes"
+	"github.com/gophercloud/gophercloud"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/groups"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/extensions/security/rules"
+	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SecurityGroupsManager", func() {
+	var serviceClients utils.ServiceClients
+	var networkingFacade networkfakes.FakeNetworkingFacade
+	var logger utilsfakes.FakeLogger
+	var boshEnv properties.BoshEnv
+	var managedSecurityGroups []properties.ManagedSecurityGroup
+
+	BeforeEach(func() {
+		serviceClients = utils.ServiceClients{ServiceClient: &gophercloud.ServiceClient{}, RetryableServiceClient: &gophercloud.ServiceClient{}}
+		networkingFacade = networkfakes.FakeNetworkingFacade{}
+		logger = utilsfakes.FakeLogger{}
+
+		var err error
+		boshEnv, err = properties.NewBoshEnv(apiv1.NewVMEnv(map[string]interface{}{
+			"bosh": map[string]interface{}{"groups": []string{"the-director", "the-deployment", "the-instance-group"}},
+		}))
+		Expect(err).ToNot(HaveOccurred())
+
+		managedSecurityGroups = []properties.ManagedSecurityGroup{{
+			Name:  "web",
+			Rules: []properties.SecurityGroupRule{{Protocol: "tcp", PortRangeMin: 443, RemoteIPPrefix: "10.0.0.0/8"}},
+		}}
+
+		networkingFacade.ExtractSecurityGroupsReturnsOnCall(0, []groups.SecGroup{}, nil)
+		networkingFacade.ExtractSecurityGroupsReturnsOnCall(1, []groups.SecGroup{{ID: "the-group-id"}}, nil)
+		networkingFacade.CreateSecurityGroupReturns(&groups.SecGroup{ID: "the-group-id"}, nil)
+	})
+
+	Context("Ensure", func() {
+		It("creates a missing security group named and tagged by director and deployment", func() {
+			securityGroupIDs, err := network.NewSecurityGroupsManager(serviceClients, &networkingFacade, &logger).Ensure(managedSecurityGroups, boshEnv)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(securityGroupIDs).To(Equal([]string{"the-group-id"}))
+
+			_, listOpts := networkingFacade.ListSecurityGroupsArgsForCall(0)
+			Expect(listOpts.Name).To(Equal("the-director-the-deployment-web"))
+			Expect(listOpts.Tags).To(Equal("bosh-managed,director:the-director,deployment:the-deployment"))
+
+			_, createOpts := networkingFacade.CreateSecurityGroupArgsForCall(0)
+			Expect(createOpts.Name).To(Equal("the-director-the-deployment-web"))
+
+			_, resourceType, resourceID, tags := networkingFacade.ReplaceAllTagsArgsForCall(0)
+			Expect(resourceType).To(Equal("security-groups"))
+			Expect(resourceID).To(Equal("the-group-id"))
+			Expect(tags).To(Equal([]string{network.ManagedSecurityGroupTag, "director:the-director", "deployment:the-deployment"}))
+		})
+
+		It("creates the declared rules", func() {
+			_, err := network.NewSecurityGroupsManager(serviceClients, &networkingFacade, &logger).Ensure(managedSecurityGroups, boshEnv)
+
+			Expect(err).ToNot(HaveOccurred())
+			_, createOpts := networkingFacade.CreateSecurityGroupRuleArgsForCall(0)
+			Expect(createOpts).To(Equal(rules.CreateOpts{
+				SecGroupID:     "the-group-id",
+				Direction:      rules.DirIngress,
+				EtherType:      rules.EtherType4,
+				Protocol:       rules.ProtocolTCP,
+				PortRangeMin:   443,
+				PortRangeMax:   443,
+				RemoteIPPrefix: "10.0.0.0/8",
+			}))
+		})
+
+		It("reuses an existing security group and only reconciles its rules", func() {
+			networkingFacade.ExtractSecurityGroupsReturnsOnCall(0, []groups.SecGroup{{
+				ID: "the-existing-group-id",
+				Rules: []rules.SecGroupRule{
+					{ID: "declared", Direction: "ingress", EtherType: "IPv4", Protocol: "tcp", PortRangeMin: 443, PortRangeMax: 443, RemoteIPPrefix: "10.0.0.0/8"},
+					{ID: "obsolete", Direction: "ingress", EtherType: "IPv4", Protocol: "tcp", PortRangeMin: 22, PortRangeMax: 22},
+					{ID: "default-egress", Direction: "egress", EtherType: "IPv4"},
+				},
+			}}, nil)
+
+			securityGroupIDs, err := network.NewSecurityGroupsManager(serviceClients, &networkingFacade, &logger).Ensure(managedSecurityGroups, boshEnv)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(securityGroupIDs).To(Equal([]string{"the-existing-group-id"}))
+			Expect(networkingFacade.CreateSecurityGroupCallCount()).To(Equal(0))
+			Expect(networkingFacade.CreateSecurityGroupRuleCallCount()).To(Equal(0))
+			Expect(networkingFacade.DeleteSecurityGroupRuleCallCount()).To(Equal(1))
+			_, ruleID := networkingFacade.DeleteSecurityGroupRuleArgsForCall(0)
+			Expect(ruleID).To(Equal("obsolete"))
+		})
+
+		It("reconciles egress rules if egress rules are declared", func() {
+			managedSecurityGroups[0].Rules = []properties.SecurityGroupRule{{Direction: "egress", RemoteIPPrefix: "10.0.0.0/8"}}
+			networkingFacade.ExtractSecurityGroupsReturnsOnCall(0, []groups.SecGroup{{
+				ID:    "the-existing-group-id",
+				Rules: []rules.SecGroupRule{{ID: "default-egress", Direction: "egress", EtherType: "IPv4"}},
+			}}, nil)
+
+			_, err := network.NewSecurityGroupsManager(serviceClients, &networkingFacade, &logger).Ensure(managedSecurityGroups, boshEnv)
+
+			Expect(err).ToNot(HaveOccurred())
+			_, ruleID := networkingFacade.DeleteSecurityGroupRuleArgsForCall(0)
+			Expect(ruleID).To(Equal("default-egress"))
+			_, createOpts := networkingFacade.CreateSecurityGroupRuleArgsForCall(0)
+			Expect(createOpts.Direction).To(Equal(rules.DirEgress))
+		})
+
+		It("treats rules allowing any remote ip as equivalent to rules without prefix", func() {
+			managedSecurityGroups[0].Rules = []properties.SecurityGroupRule{{Protocol: "tcp", PortRangeMin: 443, RemoteIPPrefix: "0.0.0.0/0"}}
+			networkingFacade.ExtractSecurityGroupsReturnsOnCall(0, []groups.SecGroup{{
+				ID:    "the-existing-group-id",
+				Rules: []rules.SecGroupRule{{ID: "any", Direction: "ingress", EtherType: "IPv4", Protocol: "tcp", PortRangeMin: 443, PortRangeMax: 443}},
+			}}, nil)
+
+			_, err := network.NewSecurityGroupsManager(serviceClients, &networkingFacade, &logger).Ensure(managedSecurityGroups, boshEnv)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingFacade.CreateSecurityGroupRuleCallCount()).To(Equal(0))
+			Expect(networkingFacade.DeleteSecurityGroupRuleCallCount()).To(Equal(0))
+		})
+
+		It("uses the oldest security group if it was created concurrently", func() {
+			now := time.Now()
+			networkingFacade.ExtractSecurityGroupsReturnsOnCall(1, []groups.SecGroup{
+				{ID: "the-group-id", CreatedAt: now},
+				{ID: "the-concurrent-group-id", CreatedAt: now.Add(-time.Second)},
+			}, nil)
+
+			securityGroupIDs, err := network.NewSecurityGroupsManager(serviceClients, &networkingFacade, &logger).Ensure(managedSecurityGroups, boshEnv)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(securityGroupIDs).To(Equal([]string{"the-concurrent-group-id"}))
+			_, deletedID := networkingFacade.DeleteSecurityGroupArgsForCall(0)
+			Expect(deletedID).To(Equal("the-group-id"))
+		})
+
+		It("ignores rules which were created concurrently", func() {
+			networkingFacade.CreateSecurityGroupRuleReturns(nil, gophercloud.ErrDefault409{})
+
+			_, err := network.NewSecurityGroupsManager(serviceClients, &networkingFacade, &logger).Ensure(managedSecurityGroups, boshEnv)
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("deletes the security group and returns an error if tagging fails", func() {
+			networkingFacade.ReplaceAllTagsReturns(nil, errors.New("boom"))
+
+			_, err := network.NewSecurityGroupsManager(serviceClients, &networkingFacade, &logger).Ensure(managedSecurityGroups, boshEnv)
+
+			Expect(err.Error()).To(Equal("failed to ensure security group 'the-director-the-deployment-web': failed to tag security group: boom"))
+			_, deletedID := networkingFacade.DeleteSecurityGroupArgsForCall(0)
+			Expect(deletedID).To(Equal("the-group-id"))
+		})
+
+		It("returns an error if the security group cannot be created", func() {
+			networkingFacade.CreateSecurityGroupReturns(nil, errors.New("boom"))
+
+			_, err := network.NewSecurityGroupsManager(serviceClients, &networkingFacade, &logger).Ensure(managedSecurityGroups, boshEnv)
+
+			Expect(err.Error()).To(Equal("failed to ensure security group 'the-director-the-deployment-web': failed to create security group: boom"))
+		})
+
+		It("returns an error if a rule cannot be created", func() {
+			networkingFacade.CreateSecurityGroupRuleReturns(nil, errors.New("boom"))
+
+			_, err := network.NewSecurityGroupsManager(serviceClients, &networkingFacade, &logger).Ensure(managedSecurityGroups, boshEnv)
+
+			Expect(err.Error()).To(Equal("failed to reconcile rules of security group 'the-director-the-deployment-web': failed to create rule 'ingress IPv4 tcp 443-443 10.0.0.0/8': boom"))
+		})
+
+		It("returns an error if the bosh environment does not identify the deployment", func() {
+			_, err := network.NewSecurityGroupsManager(serviceClients, &networkingFacade, &logger).Ensure(managedSecurityGroups, properties.BoshEnv{})
+
+			Expect(err.Error()).To(Equal("managed security groups require the director and deployment name in the bosh environment"))
+		})
+
+		It("does nothing without managed security groups", func() {
+			securityGroupIDs, err := network.NewSecurityGroupsManager(serviceClients, &networkingFacade, &logger).Ensure(nil, properties.BoshEnv{})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(securityGroupIDs).To(BeEmpty())
+			Expect(networkingFacade.ListSecurityGroupsCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("DeleteUnused", func() {
+		BeforeEach(func() {
+			networkingFacade.GetSecurityGroupsReturns(&groups.SecGroup{ID: "the-group-id", Tags: []string{network.ManagedSecurityGroupTag}}, nil)
+			networkingFacade.ExtractPortsReturns([]ports.Port{}, nil)
+		})
+
+		It("deletes managed security groups which are not referenced by any port", func() {
+			err := network.NewSecurityGroupsManager(serviceClients, &networkingFacade, &logger).DeleteUnused([]string{"the-group-id", "the-group-id"})
+
+			Expect(err).ToNot(HaveOccurred())
+			_, listOpts := networkingFacade.ListPortsArgsForCall(0)
+			Expect(listOpts.SecurityGroups).To(Equal([]string{"the-group-id"}))
+			Expect(networkingFacade.DeleteSecurityGroupCallCount()).To(Equal(1))
+			_, deletedID := networkingFacade.DeleteSecurityGroupArgsForCall(0)
+			Expect(deletedID).To(Equal("the-group-id"))
+		})
+
+		It("keeps security groups which are still referenced by ports", func() {
+			networkingFacade.ExtractPortsReturns([]ports.Port{{ID: "the-port-id"}}, nil)
+
+			err := network.NewSecurityGroupsManager(serviceClients, &networkingFacade, &logger).DeleteUnused([]string{"the-group-id"})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingFacade.DeleteSecurityGroupCallCount()).To(Equal(0))
+		})
+
+		It("keeps security groups which are not managed by the CPI", func() {
+			networkingFacade.GetSecurityGroupsReturns(&groups.SecGroup{ID: "the-group-id"}, nil)
+
+			err := network.NewSecurityGroupsManager(serviceClients, &networkingFacade, &logger).DeleteUnused([]string{"the-group-id"})
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(networkingFacade.ListPortsCallCount()).To(Equal(0))
+			Expect(networkingFacade.DeleteSecurityGroupCallCount()).To(Equal(0))
+		})
+
+		It("ignores security groups which are in use again", func() {
+			networkingFacade.DeleteSecurityGroupReturns(gophercloud.ErrDefault409{})
+
+			err := network.NewSecurityGroupsManager(serviceClients, &networkingFacade, &logger).DeleteUnused([]string{"the-group-id"})
+
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns an error if the security group cannot be deleted", func() {
+			networkingFacade.DeleteSecurityGroupReturns(errors.New("boom"))
+
+			err := network.NewSecurityGroupsManager(serviceClients, &networkingFacade, &logger).DeleteUnused([]string{"the-group-id"})
+
+			Expect(err.Error()).To(Equal("failed to delete security group 'the-group-id': boom"))
+		})
+	})
+})
@@ -0,0 +1,53 @@
+package properties
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/cloudfoundry/bosh-cpi-go/apiv1"
+)
+
+// BoshEnv holds the parts of the VM environment sent by the director which identify an instance.
+// The groups are ordered as director, deployment and instance group name followed by their combinations.
+type BoshEnv struct {
+	Bosh struct {
+		Group  string   `json:"group"`
+		Groups []string `json:"groups"`
+	} `json:"bosh"`
+}
+
+func NewBoshEnv(env apiv1.VMEnv) (BoshEnv, error) {
+	boshEnv := BoshEnv{}
+
+	rawEnv, err := env.MarshalJSON()
+	if err != nil {
+		return boshEnv, fmt.Errorf("failed to marshal environment: %w", err)
+	}
+
+	err = json.Unmarshal(rawEnv, &boshEnv)
+	if err != nil {
+		return boshEnv, fmt.Errorf("failed to parse environment: %w", err)
+	}
+
+	return boshEnv, nil
+}
+
+func (e BoshEnv) Director() string {
+	return e.group(0)
+}
+
+func (e BoshEnv) Deployment() string {
+	return e.group(1)
+}
+
+func (e BoshEnv) InstanceGroup() string {
+	return e.group(2)
+}
+
+func (e BoshEnv) group(index int) string {
+	if len(e.Bosh.Groups) <= index {
+		return ""
+	}
+
+	return e.Bosh.Groups[index]
+}
@@ -0,0 +1,35 @@
+package properties_test
+
+import (
+	"github.com/cloudfoundry/bosh-cpi-go/apiv1"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/properties"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BoshEnv", func() {
+
+	It("returns director, deployment and instance group from the bosh groups", func() {
+		env := apiv1.NewVMEnv(map[string]interface{}{
+			"bosh": map[string]interface{}{
+				"group":  "the-director-the-deployment-the-instance-group",
+				"groups": []string{"the-director", "the-deployment", "the-instance-group"},
+			},
+		})
+
+		boshEnv, err := properties.NewBoshEnv(env)
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(boshEnv.Director()).To(Equal("the-director"))
+		Expect(boshEnv.Deployment()).To(Equal("the-deployment"))
+		Expect(boshEnv.InstanceGroup()).To(Equal("the-instance-group"))
+	})
+
+	It("returns empty names if the environment has no bosh groups", func() {
+		boshEnv, err := properties.NewBoshEnv(apiv1.NewVMEnv(map[string]interface{}{}))
+
+		Expect(err).ToNot(HaveOccurred())
+		Expect(boshEnv.Director()).To(BeEmpty())
+		Expect(boshEnv.Deployment()).To(BeEmpty())
+	})
+})
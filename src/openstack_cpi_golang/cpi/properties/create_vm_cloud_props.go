@@ -1,28 +1,123 @@
 package properties
 
 import (
+	"encoding/json"
 	"fmt"
+	"net"
+	"slices"
+	"strings"
 
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/config"
 )
 
 type CreateVM struct {
-	AllowedAddressPairs string             `json:"allowed_address_pairs"`
-	AvailabilityZone    string             `json:"availability_zone"`
-	AvailabilityZones   []string           `json:"availability_zones"`
-	BootFromVolume      *bool              `json:"boot_from_volume,omitempty"`
-	EphemeralDisk       string             `json:"ephemeral_disk"`
-	InstanceType        string             `json:"instance_type"`
-	KeyName             string             `json:"key_name"`
-	LoadbalancerPools   []LoadbalancerPool `json:"loadbalancer_pools"`
-	RootDisk            Disk               `json:"root_disk,omitempty"`
-	SchedulerHints      string             `json:"scheduler_hints"`
-	SecurityGroups      []string           `json:"security_groups"`
-	VRRPPortCheck       *bool              `json:"vrrp_port_check,omitempty"`
+	AllowedAddressPairs       AllowedAddressPairs    `json:"allowed_address_pairs,omitempty"`
+	AvailabilityZone          string                 `json:"availability_zone"`
+	AvailabilityZones         []string               `json:"availability_zones"`
+	AvailabilityZonesStrategy string                 `json:"availability_zones_strategy,omitempty"`
+	BootFromVolume            *bool                  `json:"boot_from_volume,omitempty"`
+	EphemeralDisk             *EphemeralDisk         `json:"ephemeral_disk,omitempty"`
+	InstanceType              string                 `json:"instance_type"`
+	KeyName                   string                 `json:"key_name"`
+	LoadbalancerPools         []LoadbalancerPool     `json:"loadbalancer_pools"`
+	ManagedSecurityGroups     []ManagedSecurityGroup `json:"managed_security_groups,omitempty"`
+	RootDisk                  Disk                   `json:"root_disk,omitempty"`
+	SchedulerHints            string                 `json:"scheduler_hints"`
+	SecurityGroups            []string               `json:"security_groups"`
+	SecurityGroupsPolicy      string                 `json:"security_groups_policy,omitempty"`
+	VRRPPortCheck             *bool                  `json:"vrrp_port_check,omitempty"`
+	VRRPPortPolicy            string                 `json:"vrrp_port_policy,omitempty"`
+
+	// DiskAvailabilityZone is the availability zone of the persistent disks of the VM, which is tried first.
+	DiskAvailabilityZone string `json:"-"`
+}
+
+const (
+	VRRPPortPolicyIgnore = "ignore"
+	VRRPPortPolicyCheck  = "check"
+	VRRPPortPolicyCreate = "create"
+)
+
+const (
+	SecurityGroupsPolicyOverride = "override"
+	SecurityGroupsPolicyAdd      = "add"
+)
+
+const (
+	AvailabilityZonesStrategyRandom   = "random"
+	AvailabilityZonesStrategyBalanced = "balanced"
+)
+
+type AllowedAddressPair struct {
+	IPAddress  string `json:"ip_address"`
+	MACAddress string `json:"mac_address,omitempty"`
+}
+
+// IsCIDR is true if the pair allows a CIDR range instead of a single IP.
+func (a AllowedAddressPair) IsCIDR() bool {
+	return strings.Contains(a.IPAddress, "/")
+}
+
+type AllowedAddressPairs []AllowedAddressPair
+
+// UnmarshalJSON accepts a single IP string, which was the only supported format
+// in the past, as well as a list of IP strings and/or pair objects.
+func (a *AllowedAddressPairs) UnmarshalJSON(data []byte) error {
+	var ipAddress string
+	if err := json.Unmarshal(data, &ipAddress); err == nil {
+		*a = nil
+		if ipAddress != "" {
+			*a = AllowedAddressPairs{{IPAddress: ipAddress}}
+		}
+		return nil
+	}
+
+	var rawPairs []json.RawMessage
+	if err := json.Unmarshal(data, &rawPairs); err != nil {
+		return fmt.Errorf("'allowed_address_pairs' must be an ip or a list of pairs: %w", err)
+	}
+
+	pairs := AllowedAddressPairs{}
+	for _, rawPair := range rawPairs {
+		var pair AllowedAddressPair
+		if err := json.Unmarshal(rawPair, &pair.IPAddress); err != nil {
+			if err := json.Unmarshal(rawPair, &pair); err != nil {
+				return fmt.Errorf("failed to parse allowed address pair '%s': %w", string(rawPair), err)
+			}
+		}
+		pairs = append(pairs, pair)
+	}
+	*a = pairs
+
+	return nil
 }
 
 type Disk struct {
-	Size int `json:"size"`
+	Size                int    `json:"size"`
+	Type                string `json:"type,omitempty"`
+	DeleteOnTermination *bool  `json:"delete_on_termination,omitempty"`
+}
+
+func (d Disk) DeleteVolumeOnTermination() bool {
+	if d.DeleteOnTermination == nil {
+		return true
+	}
+
+	return *d.DeleteOnTermination
+}
+
+type EphemeralDisk struct {
+	Size                int    `json:"size"`
+	Type                string `json:"type,omitempty"`
+	DeleteOnTermination *bool  `json:"delete_on_termination,omitempty"`
+}
+
+func (e EphemeralDisk) DeleteVolumeOnTermination() bool {
+	if e.DeleteOnTermination == nil {
+		return true
+	}
+
+	return *e.DeleteOnTermination
 }
 
 type LoadbalancerPool struct {
@@ -31,6 +126,39 @@ type LoadbalancerPool struct {
 	MonitoringPort *int   `json:"monitoring_port,omitempty"`
 }
 
+// GetVRRPPortPolicy returns the configured VRRP port policy. The deprecated 'vrrp_port_check'
+// is mapped to the 'check' policy.
+func (c CreateVM) GetVRRPPortPolicy() string {
+	if c.VRRPPortPolicy != "" {
+		return c.VRRPPortPolicy
+	}
+
+	if c.VRRPPortCheck != nil && *c.VRRPPortCheck {
+		return VRRPPortPolicyCheck
+	}
+
+	return VRRPPortPolicyIgnore
+}
+
+// GetSecurityGroupsPolicy returns how the VM 'security_groups' are combined with the security groups of a network.
+// They override the security groups of the networks by default.
+func (c CreateVM) GetSecurityGroupsPolicy() string {
+	if c.SecurityGroupsPolicy == "" {
+		return SecurityGroupsPolicyOverride
+	}
+
+	return c.SecurityGroupsPolicy
+}
+
+// GetAvailabilityZonesStrategy returns the order in which the 'availability_zones' are tried, 'random' by default.
+func (c CreateVM) GetAvailabilityZonesStrategy() string {
+	if c.AvailabilityZonesStrategy == "" {
+		return AvailabilityZonesStrategyRandom
+	}
+
+	return c.AvailabilityZonesStrategy
+}
+
 func (c CreateVM) Validate(opentackConfig config.OpenstackConfig) error {
 
 	for _, pool := range c.LoadbalancerPools {
@@ -42,10 +170,55 @@ func (c CreateVM) Validate(opentackConfig config.OpenstackConfig) error {
 		}
 	}
 
+	for _, pair := range c.AllowedAddressPairs {
+		_, _, err := net.ParseCIDR(pair.IPAddress)
+		if pair.IsCIDR() && err != nil || !pair.IsCIDR() && net.ParseIP(pair.IPAddress) == nil {
+			return fmt.Errorf("allowed address pair '%s' is neither an ip nor a cidr", pair.IPAddress)
+		}
+
+		if pair.MACAddress != "" {
+			if _, err := net.ParseMAC(pair.MACAddress); err != nil {
+				return fmt.Errorf("allowed address pair '%s' has an invalid mac address '%s'", pair.IPAddress, pair.MACAddress)
+			}
+		}
+	}
+
+	var managedSecurityGroupNames []string
+	for _, managedSecurityGroup := range c.ManagedSecurityGroups {
+		err := managedSecurityGroup.Validate()
+		if err != nil {
+			return err
+		}
+
+		if slices.Contains(managedSecurityGroupNames, managedSecurityGroup.Name) {
+			return fmt.Errorf("managed security group '%s' is defined multiple times", managedSecurityGroup.Name)
+		}
+		managedSecurityGroupNames = append(managedSecurityGroupNames, managedSecurityGroup.Name)
+	}
+
+	vrrpPortPolicies := []string{VRRPPortPolicyIgnore, VRRPPortPolicyCheck, VRRPPortPolicyCreate}
+	if !slices.Contains(vrrpPortPolicies, c.GetVRRPPortPolicy()) {
+		return fmt.Errorf("unsupported 'vrrp_port_policy' '%s', supported policies are %v", c.VRRPPortPolicy, vrrpPortPolicies)
+	}
+
+	securityGroupsPolicies := []string{SecurityGroupsPolicyOverride, SecurityGroupsPolicyAdd}
+	if !slices.Contains(securityGroupsPolicies, c.GetSecurityGroupsPolicy()) {
+		return fmt.Errorf("unsupported 'security_groups_policy' '%s', supported policies are %v", c.SecurityGroupsPolicy, securityGroupsPolicies)
+	}
+
+	if c.EphemeralDisk != nil && c.EphemeralDisk.Size < 1 {
+		return fmt.Errorf("minimum 'ephemeral_disk.size' is 1 GiB")
+	}
+
 	if c.AvailabilityZone != "" && len(c.AvailabilityZones) > 0 {
 		return fmt.Errorf("only one property of 'availability_zone' and 'availability_zones' can be configured")
 	}
 
+	availabilityZonesStrategies := []string{AvailabilityZonesStrategyRandom, AvailabilityZonesStrategyBalanced}
+	if !slices.Contains(availabilityZonesStrategies, c.GetAvailabilityZonesStrategy()) {
+		return fmt.Errorf("unsupported 'availability_zones_strategy' '%s', supported strategies are %v", c.AvailabilityZonesStrategy, availabilityZonesStrategies)
+	}
+
 	if len(c.AvailabilityZones) > 1 && !opentackConfig.IgnoreServerAvailabilityZone {
 		return fmt.Errorf("cannot use multiple azs without 'openstack.ignore_server_availability_zone' set to true")
 	}
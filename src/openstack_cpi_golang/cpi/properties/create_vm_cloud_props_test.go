@@ -1,6 +1,8 @@
 package properties_test
 
 import (
+	"encoding/json"
+
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/config"
 	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/properties"
 	. "github.com/onsi/ginkgo/v2"
@@ -37,6 +39,97 @@ var _ = Describe("CreateVM", func() {
 			Expect(err.Error()).To(Equal("load balancer pool 'name' has no port definition"))
 		})
 
+		It("returns an error if an allowed address pair is neither an ip nor a cidr", func() {
+			cloudProps := properties.CreateVM{
+				AllowedAddressPairs: properties.AllowedAddressPairs{{IPAddress: "10.0.0.300"}},
+			}
+
+			err := cloudProps.Validate(openstackConfig)
+
+			Expect(err.Error()).To(Equal("allowed address pair '10.0.0.300' is neither an ip nor a cidr"))
+		})
+
+		It("returns an error if an allowed address pair has an invalid mac address", func() {
+			cloudProps := properties.CreateVM{
+				AllowedAddressPairs: properties.AllowedAddressPairs{{IPAddress: "10.0.0.0/24", MACAddress: "invalid"}},
+			}
+
+			err := cloudProps.Validate(openstackConfig)
+
+			Expect(err.Error()).To(Equal("allowed address pair '10.0.0.0/24' has an invalid mac address 'invalid'"))
+		})
+
+		It("returns an error if a managed security group has no name", func() {
+			cloudProps := properties.CreateVM{
+				ManagedSecurityGroups: []properties.ManagedSecurityGroup{{}},
+			}
+
+			err := cloudProps.Validate(openstackConfig)
+
+			Expect(err.Error()).To(Equal("managed security group defined without name"))
+		})
+
+		It("returns an error if a managed security group is defined multiple times", func() {
+			cloudProps := properties.CreateVM{
+				ManagedSecurityGroups: []properties.ManagedSecurityGroup{{Name: "web"}, {Name: "web"}},
+			}
+
+			err := cloudProps.Validate(openstackConfig)
+
+			Expect(err.Error()).To(Equal("managed security group 'web' is defined multiple times"))
+		})
+
+		It("returns an error if a managed security group rule is invalid", func() {
+			cloudProps := properties.CreateVM{
+				ManagedSecurityGroups: []properties.ManagedSecurityGroup{{
+					Name:  "web",
+					Rules: []properties.SecurityGroupRule{{Protocol: "tcp", PortRangeMin: 443, RemoteIPPrefix: "10.0.0.0"}},
+				}},
+			}
+
+			err := cloudProps.Validate(openstackConfig)
+
+			Expect(err.Error()).To(Equal("managed security group 'web': rule 'remote_ip_prefix' '10.0.0.0' is not a valid cidr"))
+		})
+
+		It("returns an error if the vrrp port policy is not supported", func() {
+			cloudProps := properties.CreateVM{VRRPPortPolicy: "unknown"}
+
+			err := cloudProps.Validate(openstackConfig)
+
+			Expect(err.Error()).To(ContainSubstring("unsupported 'vrrp_port_policy' 'unknown'"))
+		})
+
+		It("returns an error if the security groups policy is not supported", func() {
+			cloudProps := properties.CreateVM{SecurityGroupsPolicy: "replace"}
+
+			err := cloudProps.Validate(openstackConfig)
+
+			Expect(err.Error()).To(Equal("unsupported 'security_groups_policy' 'replace', supported policies are [override add]"))
+		})
+
+		It("returns an error if the availability zones strategy is not supported", func() {
+			cloudProps := properties.CreateVM{AvailabilityZonesStrategy: "round_robin"}
+
+			err := cloudProps.Validate(openstackConfig)
+
+			Expect(err.Error()).To(Equal("unsupported 'availability_zones_strategy' 'round_robin', supported strategies are [random balanced]"))
+		})
+
+		It("uses the random availability zones strategy by default", func() {
+			Expect(properties.CreateVM{}.GetAvailabilityZonesStrategy()).To(Equal(properties.AvailabilityZonesStrategyRandom))
+		})
+
+		It("returns an error if the ephemeral disk is smaller than 1 GiB", func() {
+			cloudProps := properties.CreateVM{
+				EphemeralDisk: &properties.EphemeralDisk{Size: 0},
+			}
+
+			err := cloudProps.Validate(openstackConfig)
+
+			Expect(err.Error()).To(Equal("minimum 'ephemeral_disk.size' is 1 GiB"))
+		})
+
 		It("returns an error if 'availability_zone' and 'availability_zones' is configured", func() {
 			cloudProps := properties.CreateVM{
 				AvailabilityZone:  "az1",
@@ -59,4 +152,141 @@ var _ = Describe("CreateVM", func() {
 		})
 
 	})
+
+	Context("EphemeralDisk", func() {
+
+		It("deletes the volume on termination by default", func() {
+			Expect(properties.EphemeralDisk{Size: 10}.DeleteVolumeOnTermination()).To(BeTrue())
+		})
+
+		It("keeps the volume on termination if configured", func() {
+			deleteOnTermination := false
+
+			Expect(properties.EphemeralDisk{Size: 10, DeleteOnTermination: &deleteOnTermination}.DeleteVolumeOnTermination()).To(BeFalse())
+		})
+
+	})
+
+	Context("Disk", func() {
+
+		It("deletes the root disk volume on termination by default", func() {
+			Expect(properties.Disk{Size: 10}.DeleteVolumeOnTermination()).To(BeTrue())
+		})
+
+		It("keeps the root disk volume on termination if configured", func() {
+			deleteOnTermination := false
+
+			Expect(properties.Disk{Size: 10, DeleteOnTermination: &deleteOnTermination}.DeleteVolumeOnTermination()).To(BeFalse())
+		})
+
+	})
+
+	Context("AllowedAddressPairs", func() {
+
+		It("parses a single ip", func() {
+			cloudProps := properties.CreateVM{}
+			err := json.Unmarshal([]byte(`{"allowed_address_pairs": "10.0.0.10"}`), &cloudProps)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cloudProps.AllowedAddressPairs).To(Equal(properties.AllowedAddressPairs{{IPAddress: "10.0.0.10"}}))
+		})
+
+		It("parses an empty string as no pairs", func() {
+			cloudProps := properties.CreateVM{}
+			err := json.Unmarshal([]byte(`{"allowed_address_pairs": ""}`), &cloudProps)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cloudProps.AllowedAddressPairs).To(BeEmpty())
+		})
+
+		It("parses a list of ips and pairs", func() {
+			cloudProps := properties.CreateVM{}
+			err := json.Unmarshal([]byte(`{"allowed_address_pairs": [
+				"10.0.0.10",
+				{"ip_address": "10.0.1.0/24", "mac_address": "fa:16:3e:00:00:01"}
+			]}`), &cloudProps)
+
+			Expect(err).ToNot(HaveOccurred())
+			Expect(cloudProps.AllowedAddressPairs).To(Equal(properties.AllowedAddressPairs{
+				{IPAddress: "10.0.0.10"},
+				{IPAddress: "10.0.1.0/24", MACAddress: "fa:16:3e:00:00:01"},
+			}))
+			Expect(cloudProps.AllowedAddressPairs[0].IsCIDR()).To(BeFalse())
+			Expect(cloudProps.AllowedAddressPairs[1].IsCIDR()).To(BeTrue())
+		})
+
+		It("returns an error for an unsupported format", func() {
+			cloudProps := properties.CreateVM{}
+			err := json.Unmarshal([]byte(`{"allowed_address_pairs": 42}`), &cloudProps)
+
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Context("GetVRRPPortPolicy", func() {
+
+		It("ignores VRRP ports by default", func() {
+			Expect(properties.CreateVM{}.GetVRRPPortPolicy()).To(Equal(properties.VRRPPortPolicyIgnore))
+		})
+
+		It("maps the deprecated vrrp_port_check to the check policy", func() {
+			vrrpPortCheck := true
+
+			Expect(properties.CreateVM{VRRPPortCheck: &vrrpPortCheck}.GetVRRPPortPolicy()).To(Equal(properties.VRRPPortPolicyCheck))
+		})
+
+		It("prefers the configured policy", func() {
+			vrrpPortCheck := true
+			cloudProps := properties.CreateVM{VRRPPortCheck: &vrrpPortCheck, VRRPPortPolicy: properties.VRRPPortPolicyCreate}
+
+			Expect(cloudProps.GetVRRPPortPolicy()).To(Equal(properties.VRRPPortPolicyCreate))
+		})
+	})
+
+	Context("SecurityGroupRule", func() {
+
+		It("defaults to ingress and IPv4", func() {
+			rule := properties.SecurityGroupRule{}
+
+			Expect(rule.GetDirection()).To(Equal("ingress"))
+			Expect(rule.GetEtherType()).To(Equal("IPv4"))
+		})
+
+		It("derives the ethertype from the remote ip prefix", func() {
+			rule := properties.SecurityGroupRule{RemoteIPPrefix: "fd00::/64"}
+
+			Expect(rule.GetEtherType()).To(Equal("IPv6"))
+		})
+
+		It("allows a single port", func() {
+			rule := properties.SecurityGroupRule{Protocol: "tcp", PortRangeMin: 443}
+
+			Expect(rule.GetPortRangeMax()).To(Equal(443))
+			Expect(rule.Validate()).To(Succeed())
+		})
+
+		It("returns an error if the direction is not supported", func() {
+			rule := properties.SecurityGroupRule{Direction: "inbound"}
+
+			Expect(rule.Validate().Error()).To(ContainSubstring("unsupported rule 'direction' 'inbound'"))
+		})
+
+		It("returns an error if the port range is invalid", func() {
+			rule := properties.SecurityGroupRule{Protocol: "tcp", PortRangeMin: 8080, PortRangeMax: 80}
+
+			Expect(rule.Validate().Error()).To(Equal("invalid rule port range '8080-80'"))
+		})
+
+		It("returns an error if a port range is configured without protocol", func() {
+			rule := properties.SecurityGroupRule{PortRangeMin: 443}
+
+			Expect(rule.Validate().Error()).To(Equal("rule port range '443-443' requires a 'protocol'"))
+		})
+
+		It("returns an error if the remote ip prefix does not match the ethertype", func() {
+			rule := properties.SecurityGroupRule{EtherType: "IPv6", RemoteIPPrefix: "10.0.0.0/8"}
+
+			Expect(rule.Validate().Error()).To(Equal("rule 'remote_ip_prefix' '10.0.0.0/8' does not match ethertype 'IPv6'"))
+		})
+	})
 })
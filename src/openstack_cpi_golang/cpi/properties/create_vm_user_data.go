@@ -27,9 +27,11 @@ type UserdataNetwork struct {
 	IP            string            `json:"ip"`
 	Gateway       string            `json:"gateway,omitempty"`
 	Mac           string            `json:"mac,omitempty"`
+	MTU           int               `json:"mtu,omitempty"`
 	Netmask       string            `json:"netmask,omitempty"`
 	Preconfigured *bool             `json:"preconfigured,omitempty"`
 	Resolved      *bool             `json:"resolved,omitempty"`
+	Routes        []Route           `json:"routes,omitempty"`
 	Type          string            `json:"type"`
 	UseDHCP       *bool             `json:"use_dhcp,omitempty"`
 	CloudProps    NetworkCloudProps `json:"cloud_properties"`
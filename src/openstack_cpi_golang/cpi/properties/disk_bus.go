@@ -0,0 +1,72 @@
+package properties
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/config"
+)
+
+const (
+	DiskBusVirtio = "virtio"
+	DiskBusXen    = "xen"
+)
+
+// devicePathPattern matches the device paths of all buses, e.g. '/dev/sdb', '/dev/vdb' or '/dev/xvdb'.
+var devicePathPattern = regexp.MustCompile(`^/dev/(?:sd|vd|xvd|hd)([a-z]+)$`)
+
+// DiskBus is the bus the disks of a server are attached to. It is taken from the 'hw_disk_bus'
+// and 'hw_scsi_model' properties of the server's image, unless 'disk_bus' is configured.
+type DiskBus struct {
+	Bus       string
+	SCSIModel string
+}
+
+func NewDiskBus(imageProperties map[string]interface{}, openstackConfig config.OpenstackConfig) DiskBus {
+	diskBus := DiskBus{}
+	diskBus.Bus, _ = imageProperties["hw_disk_bus"].(string)
+	diskBus.SCSIModel, _ = imageProperties["hw_scsi_model"].(string)
+
+	if openstackConfig.DiskBus != "" {
+		diskBus.Bus = openstackConfig.DiskBus
+	}
+
+	return diskBus
+}
+
+// DevicePrefix returns the prefix of the device paths on the bus. Disks on the 'scsi', 'sata', 'ide'
+// and 'usb' buses are all presented as '/dev/sd*', which is also used if the bus is not known.
+func (d DiskBus) DevicePrefix() string {
+	switch d.Bus {
+	case DiskBusVirtio:
+		return "/dev/vd"
+	case DiskBusXen:
+		return "/dev/xvd"
+	default:
+		return "/dev/sd"
+	}
+}
+
+// DevicePath returns the path of a device reported by Nova on the bus, e.g. '/dev/vdb' for '/dev/sdb'
+// on the 'virtio' bus. Nova reports the requested device, which may not match the bus of the server.
+// The device is returned unchanged if the bus is not known.
+func (d DiskBus) DevicePath(device string) string {
+	if d.Bus == "" {
+		return device
+	}
+
+	match := devicePathPattern.FindStringSubmatch(device)
+	if match == nil {
+		return device
+	}
+
+	return d.DevicePrefix() + match[1]
+}
+
+func (d DiskBus) String() string {
+	if d.SCSIModel == "" {
+		return fmt.Sprintf("bus '%s'", d.Bus)
+	}
+
+	return fmt.Sprintf("bus '%s' (SCSI model '%s')", d.Bus, d.SCSIModel)
+}
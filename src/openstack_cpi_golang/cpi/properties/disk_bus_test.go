@@ -0,0 +1,61 @@
+package properties_test
+
+import (
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/config"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/properties"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DiskBus", func() {
+
+	Context("NewDiskBus", func() {
+		It("reads the bus and the SCSI model from the image properties", func() {
+			diskBus := properties.NewDiskBus(map[string]interface{}{
+				"hw_disk_bus":   "scsi",
+				"hw_scsi_model": "virtio-scsi",
+			}, config.OpenstackConfig{})
+
+			Expect(diskBus).To(Equal(properties.DiskBus{Bus: "scsi", SCSIModel: "virtio-scsi"}))
+		})
+
+		It("prefers the configured disk bus", func() {
+			diskBus := properties.NewDiskBus(map[string]interface{}{"hw_disk_bus": "scsi"}, config.OpenstackConfig{DiskBus: "virtio"})
+
+			Expect(diskBus.Bus).To(Equal("virtio"))
+		})
+
+		It("does not know the bus of images without disk bus property", func() {
+			Expect(properties.NewDiskBus(nil, config.OpenstackConfig{})).To(Equal(properties.DiskBus{}))
+		})
+	})
+
+	Context("DevicePrefix", func() {
+		It("returns the device prefix of the bus", func() {
+			Expect(properties.DiskBus{Bus: "virtio"}.DevicePrefix()).To(Equal("/dev/vd"))
+			Expect(properties.DiskBus{Bus: "xen"}.DevicePrefix()).To(Equal("/dev/xvd"))
+			Expect(properties.DiskBus{Bus: "scsi", SCSIModel: "virtio-scsi"}.DevicePrefix()).To(Equal("/dev/sd"))
+			Expect(properties.DiskBus{Bus: "ide"}.DevicePrefix()).To(Equal("/dev/sd"))
+		})
+
+		It("defaults to the SCSI device prefix", func() {
+			Expect(properties.DiskBus{}.DevicePrefix()).To(Equal("/dev/sd"))
+		})
+	})
+
+	Context("DevicePath", func() {
+		It("returns the device path on the bus", func() {
+			Expect(properties.DiskBus{Bus: "virtio"}.DevicePath("/dev/sdc")).To(Equal("/dev/vdc"))
+			Expect(properties.DiskBus{Bus: "scsi"}.DevicePath("/dev/vdb")).To(Equal("/dev/sdb"))
+			Expect(properties.DiskBus{Bus: "xen"}.DevicePath("/dev/sdb")).To(Equal("/dev/xvdb"))
+		})
+
+		It("returns the device unchanged if the bus is not known", func() {
+			Expect(properties.DiskBus{}.DevicePath("/dev/vdc")).To(Equal("/dev/vdc"))
+		})
+
+		It("returns devices without known prefix unchanged", func() {
+			Expect(properties.DiskBus{Bus: "virtio"}.DevicePath("/dev/nvme0n1")).To(Equal("/dev/nvme0n1"))
+		})
+	})
+})
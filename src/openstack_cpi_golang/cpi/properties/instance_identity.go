@@ -0,0 +1,114 @@
+package properties
+
+import (
+	"slices"
+	"strings"
+)
+
+// BoshTag marks OpenStack resources created by the CPI.
+const BoshTag = "bosh"
+
+// maxTagLength is the maximum length of a Neutron tag.
+const maxTagLength = 60
+
+// InstanceIdentity identifies the BOSH instance OpenStack resources are created for.
+type InstanceIdentity struct {
+	Director      string
+	Deployment    string
+	InstanceGroup string
+	AgentID       string
+}
+
+func NewInstanceIdentity(boshEnv BoshEnv, agentID string) InstanceIdentity {
+	return InstanceIdentity{
+		Director:      boshEnv.Director(),
+		Deployment:    boshEnv.Deployment(),
+		InstanceGroup: boshEnv.InstanceGroup(),
+		AgentID:       agentID,
+	}
+}
+
+// NewInstanceIdentityFromTags returns the identity recorded in the tags of a resource created by the CPI.
+// Parts of the identity truncated to the Neutron tag length are returned truncated.
+func NewInstanceIdentityFromTags(tags []string) InstanceIdentity {
+	identity := InstanceIdentity{}
+	for _, tag := range tags {
+		key, value, found := strings.Cut(tag, ":")
+		if !found {
+			continue
+		}
+
+		switch key {
+		case "director":
+			identity.Director = value
+		case "deployment":
+			identity.Deployment = value
+		case "instance_group":
+			identity.InstanceGroup = value
+		case "agent_id":
+			identity.AgentID = value
+		}
+	}
+
+	return identity
+}
+
+// Tags returns the bosh tag followed by a 'key:value' tag for each known part of the identity.
+func (i InstanceIdentity) Tags() []string {
+	tags := []string{BoshTag}
+
+	for _, tag := range [][2]string{
+		{"director", i.Director},
+		{"deployment", i.Deployment},
+		{"instance_group", i.InstanceGroup},
+		{"agent_id", i.AgentID},
+	} {
+		if tag[1] != "" {
+			tags = append(tags, truncateTag(tag[0]+":"+tag[1]))
+		}
+	}
+
+	return tags
+}
+
+// PortName names the port of the given network, e.g. 'director-deployment-instance_group-network'.
+func (i InstanceIdentity) PortName(networkKey string) string {
+	var parts []string
+	for _, part := range []string{i.Director, i.Deployment, i.InstanceGroup, networkKey} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(parts, "-")
+}
+
+// Owns reports whether a resource with the given tags was created by the CPI for the same director.
+func (i InstanceIdentity) Owns(tags []string) bool {
+	if !slices.Contains(tags, BoshTag) {
+		return false
+	}
+
+	if i.Director == "" {
+		return true
+	}
+
+	return slices.Contains(tags, i.DirectorTag())
+}
+
+// DirectorTag returns the 'director:name' tag of the director, or an empty string if the director is not known.
+func (i InstanceIdentity) DirectorTag() string {
+	if i.Director == "" {
+		return ""
+	}
+
+	return truncateTag("director:" + i.Director)
+}
+
+func truncateTag(tag string) string {
+	if len(tag) > maxTagLength {
+		return tag[:maxTagLength]
+	}
+
+	return tag
+}
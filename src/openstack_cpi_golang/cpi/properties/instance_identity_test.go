@@ -0,0 +1,83 @@
+package properties_test
+
+import (
+	"strings"
+
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/properties"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InstanceIdentity", func() {
+	var identity properties.InstanceIdentity
+
+	BeforeEach(func() {
+		identity = properties.InstanceIdentity{
+			Director:      "the-director",
+			Deployment:    "the-deployment",
+			InstanceGroup: "the-instance-group",
+			AgentID:       "the-agent-id",
+		}
+	})
+
+	Context("Tags", func() {
+		It("returns the bosh tag and the identity tags", func() {
+			Expect(identity.Tags()).To(Equal([]string{
+				"bosh",
+				"director:the-director",
+				"deployment:the-deployment",
+				"instance_group:the-instance-group",
+				"agent_id:the-agent-id",
+			}))
+		})
+
+		It("skips unknown parts of the identity", func() {
+			Expect(properties.InstanceIdentity{AgentID: "the-agent-id"}.Tags()).To(Equal([]string{"bosh", "agent_id:the-agent-id"}))
+		})
+
+		It("truncates tags exceeding the Neutron tag length", func() {
+			identity.Deployment = strings.Repeat("a", 100)
+
+			Expect(identity.Tags()[2]).To(HaveLen(60))
+		})
+	})
+
+	Context("NewInstanceIdentityFromTags", func() {
+		It("reads the identity from its tags", func() {
+			Expect(properties.NewInstanceIdentityFromTags(identity.Tags())).To(Equal(identity))
+		})
+
+		It("ignores other tags", func() {
+			Expect(properties.NewInstanceIdentityFromTags([]string{"bosh", "parked", "name:the-name", "director:the-director"})).
+				To(Equal(properties.InstanceIdentity{Director: "the-director"}))
+		})
+	})
+
+	Context("PortName", func() {
+		It("joins the identity and the network name", func() {
+			Expect(identity.PortName("the-network")).To(Equal("the-director-the-deployment-the-instance-group-the-network"))
+		})
+
+		It("skips unknown parts of the identity", func() {
+			Expect(properties.InstanceIdentity{}.PortName("the-network")).To(Equal("the-network"))
+		})
+	})
+
+	Context("Owns", func() {
+		It("owns resources tagged by the same director", func() {
+			Expect(identity.Owns([]string{"bosh", "director:the-director"})).To(BeTrue())
+		})
+
+		It("does not own resources tagged by another director", func() {
+			Expect(identity.Owns([]string{"bosh", "director:the-other-director"})).To(BeFalse())
+		})
+
+		It("does not own resources without the bosh tag", func() {
+			Expect(identity.Owns([]string{"director:the-director"})).To(BeFalse())
+		})
+
+		It("owns any bosh resource if the director is unknown", func() {
+			Expect(properties.InstanceIdentity{}.Owns([]string{"bosh"})).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,107 @@
+package properties
+
+import (
+	"fmt"
+	"net"
+	"slices"
+)
+
+const (
+	SecurityGroupRuleDirectionIngress = "ingress"
+	SecurityGroupRuleDirectionEgress  = "egress"
+)
+
+// ManagedSecurityGroup declares a security group which is created and kept up to date by the CPI.
+type ManagedSecurityGroup struct {
+	Name        string              `json:"name"`
+	Description string              `json:"description,omitempty"`
+	Rules       []SecurityGroupRule `json:"rules"`
+}
+
+type SecurityGroupRule struct {
+	Direction      string `json:"direction,omitempty"`
+	EtherType      string `json:"ethertype,omitempty"`
+	Protocol       string `json:"protocol,omitempty"`
+	PortRangeMin   int    `json:"port_range_min,omitempty"`
+	PortRangeMax   int    `json:"port_range_max,omitempty"`
+	RemoteIPPrefix string `json:"remote_ip_prefix,omitempty"`
+}
+
+// GetDirection defaults to ingress.
+func (r SecurityGroupRule) GetDirection() string {
+	if r.Direction == "" {
+		return SecurityGroupRuleDirectionIngress
+	}
+
+	return r.Direction
+}
+
+// GetEtherType is derived from the remote ip prefix if not configured and defaults to IPv4.
+func (r SecurityGroupRule) GetEtherType() string {
+	if r.EtherType != "" {
+		return r.EtherType
+	}
+
+	if ip, _, err := net.ParseCIDR(r.RemoteIPPrefix); err == nil && ip.To4() == nil {
+		return "IPv6"
+	}
+
+	return "IPv4"
+}
+
+// GetPortRangeMax defaults to the lower bound of the port range, which allows to configure a single port.
+func (r SecurityGroupRule) GetPortRangeMax() int {
+	if r.PortRangeMax == 0 {
+		return r.PortRangeMin
+	}
+
+	return r.PortRangeMax
+}
+
+func (g ManagedSecurityGroup) Validate() error {
+	if g.Name == "" {
+		return fmt.Errorf("managed security group defined without name")
+	}
+
+	for _, rule := range g.Rules {
+		err := rule.Validate()
+		if err != nil {
+			return fmt.Errorf("managed security group '%s': %w", g.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (r SecurityGroupRule) Validate() error {
+	directions := []string{SecurityGroupRuleDirectionIngress, SecurityGroupRuleDirectionEgress}
+	if !slices.Contains(directions, r.GetDirection()) {
+		return fmt.Errorf("unsupported rule 'direction' '%s', supported directions are %v", r.Direction, directions)
+	}
+
+	etherTypes := []string{"IPv4", "IPv6"}
+	if !slices.Contains(etherTypes, r.GetEtherType()) {
+		return fmt.Errorf("unsupported rule 'ethertype' '%s', supported ethertypes are %v", r.EtherType, etherTypes)
+	}
+
+	if r.PortRangeMin < 0 || r.GetPortRangeMax() > 65535 || r.PortRangeMin > r.GetPortRangeMax() {
+		return fmt.Errorf("invalid rule port range '%d-%d'", r.PortRangeMin, r.GetPortRangeMax())
+	}
+
+	if r.GetPortRangeMax() > 0 && r.Protocol == "" {
+		return fmt.Errorf("rule port range '%d-%d' requires a 'protocol'", r.PortRangeMin, r.GetPortRangeMax())
+	}
+
+	if r.RemoteIPPrefix != "" {
+		ip, _, err := net.ParseCIDR(r.RemoteIPPrefix)
+		if err != nil {
+			return fmt.Errorf("rule 'remote_ip_prefix' '%s' is not a valid cidr", r.RemoteIPPrefix)
+		}
+
+		if (ip.To4() == nil) != (r.GetEtherType() == "IPv6") {
+			return fmt.Errorf("rule 'remote_ip_prefix' '%s' does not match ethertype '%s'", r.RemoteIPPrefix, r.GetEtherType())
+		}
+	}
+
+	return nil
+}
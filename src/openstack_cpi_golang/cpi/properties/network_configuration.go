@@ -1,9 +1,18 @@
 package properties
 
 import (
+	"fmt"
+	"slices"
+
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils"
 	"github.com/gophercloud/gophercloud/openstack/networking/v2/ports"
 )
 
+var supportedVNICTypes = []string{
+	"normal", "direct", "direct-physical", "macvtap", "baremetal", "virtio-forwarder",
+	"smart-nic", "vdpa", "remote-managed", "accelerator-direct", "accelerator-direct-physical",
+}
+
 type NetworkConfig struct {
 	DefaultNetwork Network
 	ManualNetworks []Network
@@ -23,6 +32,46 @@ type Network struct {
 	Type       string            `json:"type"`
 	CloudProps NetworkCloudProps `json:"cloud_properties"`
 	Mac        string            `json:"mac,omitempty"`
+
+	AdditionalIPs    []AdditionalIP `json:"-"`
+	SecurityGroupIDs []string       `json:"-"`
+	Routes           []Route        `json:"-"`
+	MTU              int            `json:"-"`
+}
+
+// NetworkSettings are the settings of a network defined in Neutron instead of the BOSH network configuration.
+type NetworkSettings struct {
+	Routes []Route
+	DNS    []string
+	MTU    int
+}
+
+type Route struct {
+	Destination string `json:"destination"`
+	Gateway     string `json:"gateway"`
+	Netmask     string `json:"netmask"`
+}
+
+// AddSecurityGroups applies the security groups to all networks with a port, e.g. the managed security groups of a VM.
+func (c *NetworkConfig) AddSecurityGroups(securityGroupIDs []string) {
+	for i := range c.ManualNetworks {
+		c.ManualNetworks[i].SecurityGroupIDs = utils.UniqueArray(append(slices.Clone(c.ManualNetworks[i].SecurityGroupIDs), securityGroupIDs...))
+	}
+
+	if c.DynamicNetwork != nil {
+		dynamicNetwork := *c.DynamicNetwork
+		dynamicNetwork.SecurityGroupIDs = utils.UniqueArray(append(slices.Clone(dynamicNetwork.SecurityGroupIDs), securityGroupIDs...))
+		c.DynamicNetwork = &dynamicNetwork
+		c.SecurityGroups = dynamicNetwork.SecurityGroupIDs
+	}
+}
+
+// AdditionalIP is a fixed IP of a port besides the IP assigned by BOSH, e.g. the IPv6 address of a dual-stack port.
+type AdditionalIP struct {
+	SubnetID string
+	IP       string
+	Netmask  string
+	Gateway  string
 }
 
 func (n *Network) ConfigurePort(port ports.Port) {
@@ -30,9 +79,119 @@ func (n *Network) ConfigurePort(port ports.Port) {
 	n.Mac = port.MACAddress
 }
 
+// ApplyNetworkSettings adds the Neutron network settings to the network. DNS servers configured by BOSH take precedence.
+func (n *Network) ApplyNetworkSettings(settings NetworkSettings) {
+	n.Routes = settings.Routes
+	n.MTU = settings.MTU
+
+	if len(n.DNS) == 0 {
+		n.DNS = settings.DNS
+	}
+}
+
+// AdditionalNetworks returns a network per additional IP. They share the MAC address of the network,
+// so that the agent configures all addresses on the same interface.
+func (n Network) AdditionalNetworks() []Network {
+	var additionalNetworks []Network
+
+	for i, additionalIP := range n.AdditionalIPs {
+		additionalNetworks = append(additionalNetworks, Network{
+			Key:        fmt.Sprintf("%s_%d", n.Key, i+1),
+			Port:       n.Port,
+			DNS:        n.DNS,
+			IP:         additionalIP.IP,
+			Gateway:    additionalIP.Gateway,
+			Netmask:    additionalIP.Netmask,
+			Type:       n.Type,
+			CloudProps: n.CloudProps,
+			Mac:        n.Mac,
+			MTU:        n.MTU,
+		})
+	}
+
+	return additionalNetworks
+}
+
 type NetworkCloudProps struct {
-	NetID          string   `json:"net_id,omitempty"`
-	SecurityGroups []string `json:"security_groups,omitempty"`
+	NetID               string                 `json:"net_id,omitempty"`
+	NetName             string                 `json:"net_name,omitempty"`
+	NetProjectID        string                 `json:"net_project_id,omitempty"`
+	SubnetID            string                 `json:"subnet_id,omitempty"`
+	SubnetIDs           []string               `json:"subnet_ids,omitempty"`
+	SecurityGroups      []string               `json:"security_groups,omitempty"`
+	VNICType            string                 `json:"vnic_type,omitempty"`
+	PortSecurityEnabled *bool                  `json:"port_security_enabled,omitempty"`
+	QoSPolicyID         string                 `json:"qos_policy_id,omitempty"`
+	BindingProfile      map[string]interface{} `json:"binding_profile,omitempty"`
+	ExtraDHCPOpts       []ExtraDHCPOpt         `json:"extra_dhcp_opts,omitempty"`
+	FloatingNetworkID   string                 `json:"floating_network_id,omitempty"`
+	FloatingNetworkName string                 `json:"floating_network_name,omitempty"`
+	TargetNetID         string                 `json:"target_net_id,omitempty"`
+	TargetNetName       string                 `json:"target_net_name,omitempty"`
+	TargetFixedIP       string                 `json:"target_fixed_ip,omitempty"`
+	PortID              string                 `json:"port_id,omitempty"`
+}
+
+type ExtraDHCPOpt struct {
+	OptName   string `json:"opt_name"`
+	OptValue  string `json:"opt_value"`
+	IPVersion int    `json:"ip_version,omitempty"`
+}
+
+// PortSecurityDisabled is true only if port security has been switched off explicitly.
+func (n NetworkCloudProps) PortSecurityDisabled() bool {
+	return n.PortSecurityEnabled != nil && !*n.PortSecurityEnabled
+}
+
+// HasPortProperties reports whether any property is set which is applied to the port of the network.
+func (n NetworkCloudProps) HasPortProperties() bool {
+	return n.PortID != "" || n.hasPortCreationProperties()
+}
+
+// hasPortCreationProperties reports whether any property is set which is applied to the port created by the CPI.
+func (n NetworkCloudProps) hasPortCreationProperties() bool {
+	return n.SubnetID != "" ||
+		len(n.SubnetIDs) > 0 ||
+		n.VNICType != "" ||
+		n.PortSecurityEnabled != nil ||
+		n.QoSPolicyID != "" ||
+		len(n.BindingProfile) > 0 ||
+		len(n.ExtraDHCPOpts) > 0
+}
+
+// AllocatesFloatingIP reports whether a floating IP is allocated from a floating network instead of using a pre-allocated one.
+func (n NetworkCloudProps) AllocatesFloatingIP() bool {
+	return n.FloatingNetworkID != "" || n.FloatingNetworkName != ""
+}
+
+func (n NetworkCloudProps) Validate() error {
+	if n.PortID != "" && n.hasPortCreationProperties() {
+		return fmt.Errorf("'port_id' cannot be combined with properties applied to ports created by the CPI")
+	}
+
+	if n.SubnetID != "" && len(n.SubnetIDs) > 0 {
+		return fmt.Errorf("only one property of 'subnet_id' and 'subnet_ids' can be configured")
+	}
+
+	if n.VNICType != "" && !slices.Contains(supportedVNICTypes, n.VNICType) {
+		return fmt.Errorf("unsupported 'vnic_type' '%s', supported types are %v", n.VNICType, supportedVNICTypes)
+	}
+
+	if n.PortSecurityDisabled() && len(n.SecurityGroups) > 0 {
+		return fmt.Errorf("'security_groups' cannot be configured if 'port_security_enabled' is false")
+	}
+
+	for _, opt := range n.ExtraDHCPOpts {
+		if opt.OptName == "" || opt.OptValue == "" {
+			return fmt.Errorf("'extra_dhcp_opts' entries require 'opt_name' and 'opt_value'")
+		}
+
+		if opt.IPVersion != 0 && opt.IPVersion != 4 && opt.IPVersion != 6 {
+			return fmt.Errorf("'ip_version' of extra dhcp option '%s' must be 4 or 6", opt.OptName)
+		}
+	}
+
+	return nil
 }
 
 func (n *NetworkConfig) AllNetworks() []Network {
@@ -50,4 +50,128 @@ var _ = Describe("NetworkConfig", func() {
 			Expect(networkConfig.AllNetworks()).To(ContainElements(manualNetwork1, manualNetwork2, *dynamicNetwork))
 		})
 	})
+
+	Context("AdditionalNetworks", func() {
+
+		It("returns a network per additional ip sharing the mac address", func() {
+			network := properties.Network{
+				Key:  "default",
+				Type: "manual",
+				IP:   "1.1.1.1",
+				DNS:  []string{"8.8.8.8"},
+				Mac:  "the-mac",
+				MTU:  1450,
+				AdditionalIPs: []properties.AdditionalIP{
+					{SubnetID: "the-ipv6-subnet-id", IP: "fd00::10", Netmask: "ffff:ffff:ffff:ffff::", Gateway: "fd00::1"},
+				},
+			}
+
+			Expect(network.AdditionalNetworks()).To(Equal([]properties.Network{{
+				Key:     "default_1",
+				Type:    "manual",
+				IP:      "fd00::10",
+				Netmask: "ffff:ffff:ffff:ffff::",
+				Gateway: "fd00::1",
+				DNS:     []string{"8.8.8.8"},
+				Mac:     "the-mac",
+				MTU:     1450,
+			}}))
+		})
+
+		It("returns no networks without additional ips", func() {
+			Expect(properties.Network{Key: "default"}.AdditionalNetworks()).To(BeEmpty())
+		})
+	})
+
+	Context("ApplyNetworkSettings", func() {
+		var settings properties.NetworkSettings
+
+		BeforeEach(func() {
+			settings = properties.NetworkSettings{
+				Routes: []properties.Route{{Destination: "10.0.0.0", Gateway: "1.1.1.253", Netmask: "255.0.0.0"}},
+				DNS:    []string{"8.8.8.8"},
+				MTU:    1450,
+			}
+		})
+
+		It("applies the routes, dns servers and mtu", func() {
+			network := properties.Network{Key: "default"}
+
+			network.ApplyNetworkSettings(settings)
+
+			Expect(network.Routes).To(Equal(settings.Routes))
+			Expect(network.DNS).To(Equal([]string{"8.8.8.8"}))
+			Expect(network.MTU).To(Equal(1450))
+		})
+
+		It("keeps the dns servers configured by bosh", func() {
+			network := properties.Network{Key: "default", DNS: []string{"1.1.1.1"}}
+
+			network.ApplyNetworkSettings(settings)
+
+			Expect(network.DNS).To(Equal([]string{"1.1.1.1"}))
+		})
+	})
+
+	Context("AddSecurityGroups", func() {
+		It("adds the security groups to all networks with a port", func() {
+			networkConfig := properties.NetworkConfig{
+				ManualNetworks: []properties.Network{{Key: "manual-1", SecurityGroupIDs: []string{"group-1"}}, {Key: "manual-2"}},
+				DynamicNetwork: &properties.Network{Key: "dynamic", SecurityGroupIDs: []string{"group-2"}},
+				SecurityGroups: []string{"group-1", "group-2"},
+			}
+
+			networkConfig.AddSecurityGroups([]string{"managed-group", "group-1"})
+
+			Expect(networkConfig.SecurityGroups).To(Equal([]string{"group-2", "managed-group", "group-1"}))
+			Expect(networkConfig.ManualNetworks[0].SecurityGroupIDs).To(Equal([]string{"group-1", "managed-group"}))
+			Expect(networkConfig.ManualNetworks[1].SecurityGroupIDs).To(Equal([]string{"managed-group", "group-1"}))
+			Expect(networkConfig.DynamicNetwork.SecurityGroupIDs).To(Equal([]string{"group-2", "managed-group", "group-1"}))
+		})
+	})
+
+	Context("NetworkCloudProps", func() {
+
+		It("returns an error if 'subnet_id' and 'subnet_ids' are configured", func() {
+			cloudProps := properties.NetworkCloudProps{SubnetID: "the-subnet-id", SubnetIDs: []string{"the-subnet-id"}}
+
+			Expect(cloudProps.Validate()).To(MatchError("only one property of 'subnet_id' and 'subnet_ids' can be configured"))
+		})
+
+		It("is valid without port properties", func() {
+			Expect(properties.NetworkCloudProps{NetID: "the-net-id"}.Validate()).To(Succeed())
+		})
+
+		It("returns an error if an extra dhcp option has no value", func() {
+			cloudProps := properties.NetworkCloudProps{ExtraDHCPOpts: []properties.ExtraDHCPOpt{{OptName: "mtu"}}}
+
+			Expect(cloudProps.Validate()).To(MatchError("'extra_dhcp_opts' entries require 'opt_name' and 'opt_value'"))
+		})
+
+		It("returns an error if an extra dhcp option has an invalid ip version", func() {
+			cloudProps := properties.NetworkCloudProps{ExtraDHCPOpts: []properties.ExtraDHCPOpt{{OptName: "mtu", OptValue: "9000", IPVersion: 5}}}
+
+			Expect(cloudProps.Validate()).To(MatchError("'ip_version' of extra dhcp option 'mtu' must be 4 or 6"))
+		})
+
+		It("returns an error if 'port_id' is combined with port creation properties", func() {
+			cloudProps := properties.NetworkCloudProps{PortID: "the-port-id", VNICType: "direct"}
+
+			Expect(cloudProps.Validate()).To(MatchError("'port_id' cannot be combined with properties applied to ports created by the CPI"))
+		})
+
+		It("is valid with 'port_id' and 'security_groups'", func() {
+			cloudProps := properties.NetworkCloudProps{NetID: "the-net-id", PortID: "the-port-id", SecurityGroups: []string{"the-group"}}
+
+			Expect(cloudProps.Validate()).To(Succeed())
+			Expect(cloudProps.HasPortProperties()).To(BeTrue())
+		})
+
+		It("reports port security as disabled only if explicitly switched off", func() {
+			portSecurityEnabled := false
+
+			Expect(properties.NetworkCloudProps{}.PortSecurityDisabled()).To(BeFalse())
+			Expect(properties.NetworkCloudProps{PortSecurityEnabled: &portSecurityEnabled}.PortSecurityDisabled()).To(BeTrue())
+		})
+	})
 })
@@ -1,3 +1,28 @@
 package properties
 
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
 type ServerMetadata map[string]interface{}
+
+// MetadataTags returns the tags with a 'key:value' tag for each metadata entry. Existing tags of the
+// metadata keys are replaced, so that setting the same metadata again does not add tags.
+func MetadataTags(tags []string, metadata map[string]string) []string {
+	metadataTags := slices.DeleteFunc(slices.Clone(tags), func(tag string) bool {
+		key, _, found := strings.Cut(tag, ":")
+		_, isMetadataKey := metadata[key]
+		return found && isMetadataKey
+	})
+
+	for _, key := range slices.Sorted(maps.Keys(metadata)) {
+		tag := truncateTag(key + ":" + metadata[key])
+		if !slices.Contains(metadataTags, tag) {
+			metadataTags = append(metadataTags, tag)
+		}
+	}
+
+	return metadataTags
+}
@@ -0,0 +1,43 @@
+package properties_test
+
+import (
+	"strings"
+
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/properties"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MetadataTags", func() {
+
+	It("adds a tag for each metadata entry", func() {
+		tags := properties.MetadataTags([]string{"bosh", "agent_id:the-agent-id"}, map[string]string{
+			"name":       "the-job/the-id",
+			"deployment": "the-deployment",
+		})
+
+		Expect(tags).To(Equal([]string{"bosh", "agent_id:the-agent-id", "deployment:the-deployment", "name:the-job/the-id"}))
+	})
+
+	It("replaces the tags of the metadata keys", func() {
+		tags := properties.MetadataTags([]string{"bosh", "name:the-old-job/the-id", "parked_at:2026-10-19T12:00:00Z"}, map[string]string{
+			"name": "the-job/the-id",
+		})
+
+		Expect(tags).To(Equal([]string{"bosh", "parked_at:2026-10-19T12:00:00Z", "name:the-job/the-id"}))
+	})
+
+	It("does not add tags when setting the same metadata again", func() {
+		metadata := map[string]string{"deployment": "the-deployment", "index": "0"}
+
+		tags := properties.MetadataTags(properties.MetadataTags([]string{"bosh", "deployment:the-deployment"}, metadata), metadata)
+
+		Expect(tags).To(Equal([]string{"bosh", "deployment:the-deployment", "index:0"}))
+	})
+
+	It("truncates tags exceeding the Neutron tag length", func() {
+		tags := properties.MetadataTags(nil, map[string]string{"name": strings.Repeat("a", 100)})
+
+		Expect(tags).To(Equal([]string{"name:" + strings.Repeat("a", 55)}))
+	})
+})
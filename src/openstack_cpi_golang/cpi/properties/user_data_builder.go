@@ -15,13 +15,15 @@ type userDataBuilder struct {
 	vm                VM
 	agentID           string
 	ephemeralDiskSize int
+	ephemeralDevice   string
+	devicePrefix      string
 	env               json.RawMessage
 	disks             Disks
 	mbus              string
 }
 
 func NewUserDataBuilder() userDataBuilder {
-	return userDataBuilder{disks: Disks{System: "/dev/sda"}}
+	return userDataBuilder{disks: Disks{System: "/dev/sda"}, devicePrefix: "/dev/sd"}
 }
 
 func (u userDataBuilder) WithServer(server Server) userDataBuilder {
@@ -61,6 +63,19 @@ func (u userDataBuilder) WithEphemeralDiskSize(diskSize int) userDataBuilder {
 	return u
 }
 
+func (u userDataBuilder) WithEphemeralDiskDevice(device string) userDataBuilder {
+	u.ephemeralDevice = device
+
+	return u
+}
+
+func (u userDataBuilder) WithDiskBus(diskBus DiskBus) userDataBuilder {
+	u.devicePrefix = diskBus.DevicePrefix()
+	u.disks.System = u.devicePrefix + "a"
+
+	return u
+}
+
 func (u userDataBuilder) WithAgentID(agentID apiv1.AgentID) userDataBuilder {
 	u.agentID = agentID.AsString()
 
@@ -75,7 +90,11 @@ func (u userDataBuilder) WithEnvironment(env json.RawMessage) userDataBuilder {
 
 func (u userDataBuilder) Build() UserData {
 	if u.ephemeralDiskSize > 0 {
-		u.disks.Ephemeral = "/dev/sdb"
+		u.disks.Ephemeral = u.devicePrefix + "b"
+
+		if u.ephemeralDevice != "" {
+			u.disks.Ephemeral = u.ephemeralDevice
+		}
 	}
 
 	return UserData{
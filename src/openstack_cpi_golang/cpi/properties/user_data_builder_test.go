@@ -69,6 +69,26 @@ var _ = Describe("userDataBuilder", func() {
 
 			Expect(userData.Disks).To(Equal(properties.Disks{System: "/dev/sda"}))
 		})
+
+		It("uses the given ephemeral disk device", func() {
+			userData := properties.NewUserDataBuilder().WithEphemeralDiskSize(1).WithEphemeralDiskDevice("/dev/sdc").Build()
+
+			Expect(userData.Disks).To(Equal(properties.Disks{System: "/dev/sda", Ephemeral: "/dev/sdc"}))
+		})
+	})
+
+	var _ = Context("WithDiskBus", func() {
+		It("sets the system and ephemeral disk devices of the bus", func() {
+			userData := properties.NewUserDataBuilder().WithDiskBus(properties.DiskBus{Bus: "virtio"}).WithEphemeralDiskSize(1).Build()
+
+			Expect(userData.Disks).To(Equal(properties.Disks{System: "/dev/vda", Ephemeral: "/dev/vdb"}))
+		})
+
+		It("keeps the SCSI devices if the bus is not known", func() {
+			userData := properties.NewUserDataBuilder().WithDiskBus(properties.DiskBus{}).WithEphemeralDiskSize(1).Build()
+
+			Expect(userData.Disks).To(Equal(properties.Disks{System: "/dev/sda", Ephemeral: "/dev/sdb"}))
+		})
 	})
 
 	var _ = Context("WithAgentID", func() {
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"errors"
+)
+
+const (
+	VMCreationFailedErrorType = "Bosh::Clouds::VMCreationFailed"
+	VMNotFoundErrorType       = "Bosh::Clouds::VMNotFound"
+	DiskNotFoundErrorType     = "Bosh::Clouds::DiskNotFound"
+	DiskNotAttachedErrorType  = "Bosh::Clouds::DiskNotAttached"
+	NotImplementedErrorType   = "Bosh::Clouds::NotImplemented"
+)
+
+// CloudError is reported to the director with its BOSH error type and ok_to_retry flag,
+// it implements rpc.CloudError and rpc.RetryableError.
+type CloudError struct {
+	errorType string
+	canRetry  bool
+	err       error
+}
+
+func NewVMCreationFailedError(err error, canRetry bool) error {
+	return CloudError{errorType: VMCreationFailedErrorType, canRetry: canRetry, err: err}
+}
+
+func NewVMNotFoundError(err error) error {
+	return CloudError{errorType: VMNotFoundErrorType, err: err}
+}
+
+func NewDiskNotFoundError(err error) error {
+	return CloudError{errorType: DiskNotFoundErrorType, err: err}
+}
+
+func NewDiskNotAttachedError(err error, canRetry bool) error {
+	return CloudError{errorType: DiskNotAttachedErrorType, canRetry: canRetry, err: err}
+}
+
+func NewNotImplementedError(err error) error {
+	return CloudError{errorType: NotImplementedErrorType, err: err}
+}
+
+func (e CloudError) Error() string {
+	return e.err.Error()
+}
+
+func (e CloudError) Type() string {
+	return e.errorType
+}
+
+func (e CloudError) CanRetry() bool {
+	return e.canRetry
+}
+
+func (e CloudError) Unwrap() error {
+	return e.err
+}
+
+// ClassifyCloudError returns err with the type and retry flag of the outermost CloudError it wraps.
+// The dispatcher only inspects the returned error itself, not the errors it wraps.
+func ClassifyCloudError(err error) error {
+	var cloudError CloudError
+	if !errors.As(err, &cloudError) {
+		return err
+	}
+
+	return CloudError{errorType: cloudError.errorType, canRetry: cloudError.canRetry, err: err}
+}
@@ -0,0 +1,62 @@
+package utils_test
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/cloudfoundry/bosh-cpi-go/rpc"
+	"github.com/cloudfoundry/bosh-openstack-cpi-release/src/openstack_cpi_golang/cpi/utils"
+	"github.com/gophercloud/gophercloud"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CloudError", func() {
+
+	It("reports the error type and retry flag to the dispatcher", func() {
+		err := utils.NewVMCreationFailedError(errors.New("boom"), true)
+
+		Expect(err.Error()).To(Equal("boom"))
+		Expect(err.(rpc.CloudError).Type()).To(Equal("Bosh::Clouds::VMCreationFailed"))
+		Expect(err.(rpc.RetryableError).CanRetry()).To(BeTrue())
+	})
+
+	It("is not retryable unless requested", func() {
+		Expect(utils.NewVMNotFoundError(errors.New("boom")).(rpc.RetryableError).CanRetry()).To(BeFalse())
+		Expect(utils.NewDiskNotFoundError(errors.New("boom")).(rpc.RetryableError).CanRetry()).To(BeFalse())
+		Expect(utils.NewNotImplementedError(errors.New("boom")).(rpc.RetryableError).CanRetry()).To(BeFalse())
+		Expect(utils.NewDiskNotAttachedError(errors.New("boom"), false).(rpc.RetryableError).CanRetry()).To(BeFalse())
+	})
+
+	It("unwraps the original error", func() {
+		err := utils.NewVMNotFoundError(fmt.Errorf("failed to retrieve server information: %w", gophercloud.ErrDefault404{}))
+
+		var errDefault404 gophercloud.ErrDefault404
+		Expect(errors.As(err, &errDefault404)).To(BeTrue())
+	})
+
+	Context("ClassifyCloudError", func() {
+
+		It("keeps the message of the wrapping errors", func() {
+			err := utils.ClassifyCloudError(fmt.Errorf("reboot_vm: %w", utils.NewVMNotFoundError(errors.New("boom"))))
+
+			Expect(err.Error()).To(Equal("reboot_vm: boom"))
+			Expect(err.(rpc.CloudError).Type()).To(Equal("Bosh::Clouds::VMNotFound"))
+		})
+
+		It("uses the outermost cloud error", func() {
+			err := utils.ClassifyCloudError(fmt.Errorf("create_vm: %w",
+				utils.NewVMCreationFailedError(utils.NewVMNotFoundError(errors.New("boom")), true)))
+
+			Expect(err.(rpc.CloudError).Type()).To(Equal("Bosh::Clouds::VMCreationFailed"))
+			Expect(err.(rpc.RetryableError).CanRetry()).To(BeTrue())
+		})
+
+		It("returns errors without a cloud error unchanged", func() {
+			err := errors.New("boom")
+
+			Expect(utils.ClassifyCloudError(err)).To(BeIdenticalTo(err))
+			Expect(utils.ClassifyCloudError(nil)).To(BeNil())
+		})
+	})
+})
@@ -123,8 +123,13 @@ func (v volumeService) WaitForVolumeToBecomeStatus(volumeID string, timeout time
 }
 
 func (v volumeService) GetVolume(volumeID string) (*volumes.Volume, error) {
+	var errDefault404 gophercloud.ErrDefault404
+
 	volume, err := v.volumeFacade.GetVolume(v.serviceClients.RetryableServiceClient, volumeID)
 	if err != nil {
+		if errors.As(err, &errDefault404) {
+			return nil, utils.NewDiskNotFoundError(fmt.Errorf("failed to retrieve volume information: %w", err))
+		}
 		return nil, fmt.Errorf("failed to retrieve volume information: %w", err)
 	}
 	return volume, nil
@@ -56,6 +56,18 @@ var _ = Describe("VolumeService", func() {
 		})
 	})
 
+	Context("GetVolume", func() {
+
+		It("returns a DiskNotFound error if the volume does not exist", func() {
+			volumeFacade.GetVolumeReturns(nil, gophercloud.ErrDefault404{})
+
+			_, err := volumeService.GetVolume("123-456")
+
+			Expect(err.(utils.CloudError).Type()).To(Equal(utils.DiskNotFoundErrorType))
+			Expect(errors.As(err, &gophercloud.ErrDefault404{})).To(BeTrue())
+		})
+	})
+
 	Context("WaitForVolumeToBecomeStatus", func() {
 		It("returns error if volume was failed to become available", func() {
 			volumeFacade.GetVolumeReturns(&volumes.Volume{ID: "123-456", Status: "error"}, nil)
@@ -139,5 +139,26 @@ var _ = Describe("AttachDiskMethod Integration Tests", func() {
 			actual := <-outChannel
 			Expect(actual).To(ContainSubstring(`{"result":"/dev/sdb","error":null,"log":""}`))
 		})
+
+		It("attaches a new volume V2 with the volume ID in the disk hint for stemcell API version 2", func() {
+			writeJsonParamToStdIn(`{
+				"method":"attach_disk",
+				"arguments": [
+					"server-id-ok",
+					"volume-id-ok"
+				],
+				"context": {},
+				"api_version": 2
+			}`)
+
+			config := getDefaultConfig(Endpoint())
+			config.Cloud.Properties.Openstack.VM.Stemcell.APIVersion = 2
+
+			err := cpi.Execute(config, logger)
+			Expect(err).ShouldNot(HaveOccurred())
+			stdOutWriter.Close() //nolint:errcheck
+			actual := <-outChannel
+			Expect(actual).To(ContainSubstring(`{"result":{"path":"/dev/sdb","volume_id":"volume-id-ok"},"error":null,"log":""}`))
+		})
 	})
 })
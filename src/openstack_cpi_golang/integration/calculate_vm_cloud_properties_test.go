@@ -71,7 +71,7 @@ var _ = Describe("Calculate VM cloud properties", func() {
 
 		stdOutWriter.Close() //nolint:errcheck
 		actual := <-outChannel
-		Expect(actual).To(ContainSubstring(`"result":{"instance_type":"m_c2_m16"},"error":null`))
+		Expect(actual).To(ContainSubstring(`"result":{"ephemeral_disk":{"size":4},"instance_type":"m_c2_m16"},"error":null`))
 	})
 
 	It("calculate vm cloud properties with bootFromVolume", func() {
@@ -86,6 +86,19 @@ var _ = Describe("Create VM", func() {
 			}`)
 		})
 
+		Mux.HandleFunc("/v2.1/os-availability-zone", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+
+			fmt.Fprintf(w, //nolint:errcheck
+				`{
+				"availabilityZoneInfo": [
+					{"zoneName": "z1", "zoneState": {"available": true}, "hosts": null},
+					{"zoneName": "z2", "zoneState": {"available": true}, "hosts": null}
+				]
+			}`)
+		})
+
 		Mux.HandleFunc("/v2.0/subnets", func(w http.ResponseWriter, r *http.Request) {
 			if r.URL.Query().Get("network_id") != "fbe64fb7-b47c-4fd1-b158-9411d5c3ebf3" {
 				w.WriteHeader(http.StatusNotFound)
@@ -377,7 +390,7 @@ var _ = Describe("Create VM", func() {
 				Expect(err).ShouldNot(HaveOccurred())
 
 				stdOutWriter.Close() //nolint:errcheck
-				Expect(<-outChannel).To(ContainSubstring("failed to resolve security group: could not resolve security group 'not-exiting-group'"))
+				Expect(<-outChannel).To(ContainSubstring("failed to resolve security groups of network 'bosh': could not resolve security group 'not-exiting-group'"))
 			})
 		})
 
@@ -938,6 +951,46 @@ var _ = Describe("Create VM", func() {
 					stdOutWriter.Close() //nolint:errcheck
 					Expect(<-outChannel).To(ContainSubstring(`"result":["f5dc173b-6804-445a-a6d8-c705dad5b5eb",{"bosh":{"type":"manual","ip":"10.0.11.16","netmask":"255.255.255.0","gateway":"10.0.11.1","dns":null,"default":["dns","gateway"],"routes":null,"cloud_properties":{"availability_zone":"z1","net_id":"fbe64fb7-b47c-4fd1-b158-9411d5c3ebf3","security_groups":["0c8a5d1a-8922-4d65-a0b2-dd78ab869e04","bosh_acceptance_tests"]}}}],"error":null`))
 				})
+
+				It("Creates a VM with CPI API version 1", func() {
+					writeJsonParamToStdIn(`{
+						"method": "create_vm",
+						"arguments": [
+							"a694d798-0b41-4255-9c8e-b282cd504a52",
+							"5bba0da5-dfb3-49d8-a005-d799507518f7",
+							{
+								"instance_type": "m1.tiny",
+								"key_name": "default_key_name",
+								"availability_zones": ["z1"]
+							},
+							{
+								"bosh": {
+									"type": "manual",
+									"ip": "10.0.11.16",
+									"netmask": "255.255.255.0",
+									"cloud_properties": {
+										"availability_zone": "z1",
+										"net_id": "fbe64fb7-b47c-4fd1-b158-9411d5c3ebf3"
+									},
+									"default": [
+										"dns",
+										"gateway"
+									],
+									"gateway": "10.0.11.1"
+								}
+							},
+							[],
+							{}
+						],
+						"api_version": 1
+					}`)
+
+					err := cpi.Execute(getDefaultConfig(Endpoint()), logger)
+					Expect(err).ShouldNot(HaveOccurred())
+
+					stdOutWriter.Close() //nolint:errcheck
+					Expect(<-outChannel).To(ContainSubstring(`"result":"f5dc173b-6804-445a-a6d8-c705dad5b5eb","error":null`))
+				})
 			})
 
 			Context("when validating Cloud Properties", func() {
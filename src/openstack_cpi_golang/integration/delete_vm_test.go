@@ -13,6 +13,7 @@ import (
 
 var _ = Describe("Delete VM", func() {
 	var getServerCount = 0
+	var deletedFloatingIPs []string
 
 	var defaultServerResponse = func(r *http.Request, w http.ResponseWriter, serverId string) {
 		switch r.Method {
@@ -55,6 +56,39 @@ var _ = Describe("Delete VM", func() {
 
 		MockAuthentication()
 
+		deletedFloatingIPs = []string{}
+
+		Mux.HandleFunc("/v2.0/floatingips", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet:
+				w.Header().Add("Content-Type", "application/json")
+				w.WriteHeader(http.StatusOK)
+
+				if r.URL.Query().Get("tags") == "bosh-cpi-allocated,instance-id:1" {
+					fmt.Fprintf(w, //nolint:errcheck
+						`{
+						"floatingips": [
+							{
+								"id": "allocated_floating_ip_id",
+								"floating_ip_address": "4.4.4.4"
+							}
+						]
+					}`)
+					return
+				}
+
+				fmt.Fprintf(w, `{"floatingips": []}`) //nolint:errcheck
+			}
+		})
+
+		Mux.HandleFunc("/v2.0/floatingips/allocated_floating_ip_id", func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodDelete:
+				deletedFloatingIPs = append(deletedFloatingIPs, "allocated_floating_ip_id")
+				w.WriteHeader(http.StatusNoContent)
+			}
+		})
+
 		Mux.HandleFunc("/v2.0/ports/1", func(w http.ResponseWriter, r *http.Request) {
 			switch r.Method {
 			case http.MethodDelete:
@@ -319,6 +353,7 @@ var _ = Describe("Delete VM", func() {
 
 		stdOutWriter.Close() //nolint:errcheck
 		Expect(<-outChannel).To(ContainSubstring(`"result":null,"error":null`))
+		Expect(deletedFloatingIPs).To(Equal([]string{"allocated_floating_ip_id"}))
 	})
 
 	It("times out waiting for the load balancer to become ACTIVE", func() {
@@ -215,9 +215,10 @@ var _ = Describe("REBOOT VM", func() {
 		Expect(err).ShouldNot(HaveOccurred())
 
 		_ = stdOutWriter.Close() //nolint:errcheck
-		Expect(<-outChannel).To(
-			ContainSubstring(`reboot_vm: failed to retrieve server information: Resource not found`),
-		)
+		actual := <-outChannel
+		Expect(actual).To(ContainSubstring(`"type":"Bosh::Clouds::VMNotFound"`))
+		Expect(actual).To(ContainSubstring(`reboot_vm: failed to retrieve server information: Resource not found`))
+		Expect(actual).To(ContainSubstring(`"ok_to_retry":false`))
 	})
 
 	It("Fails if rebooting server raises an error", func() {
@@ -231,9 +232,9 @@ var _ = Describe("REBOOT VM", func() {
 		Expect(err).ShouldNot(HaveOccurred())
 
 		_ = stdOutWriter.Close() //nolint:errcheck
-		Expect(<-outChannel).To(
-			ContainSubstring(`reboot_vm: failed to reboot server: Resource not found`),
-		)
+		actual := <-outChannel
+		Expect(actual).To(ContainSubstring(`"type":"Bosh::Clouds::CloudError"`))
+		Expect(actual).To(ContainSubstring(`reboot_vm: failed to reboot server: Resource not found`))
 	})
 
 	It("Fails if rebooting server results in an erroneous server state", func() {